@@ -9,6 +9,10 @@ import (
 
 var generateMD bool
 var reportsPath string
+var (
+	analyzeRunID     string
+	analyzeIteration int
+)
 
 var analyzeCmd = &cobra.Command{
 	Use:   "analyze",
@@ -22,15 +26,19 @@ Can optionally generate a Markdown table file.`,
 func init() {
 	analyzeCmd.Flags().BoolVar(&generateMD, "markdown", false, "Generate markdown table file")
 	analyzeCmd.Flags().StringVar(&reportsPath, "reports-dir", "reports", "Path to the reports directory")
+	analyzeCmd.Flags().StringVar(&analyzeRunID, "run", "", "Analyze the reports of a recorded run instead of --reports-dir")
+	analyzeCmd.Flags().IntVar(&analyzeIteration, "iteration", 0, "Iteration of a --run recorded with --repeat")
 }
 
 func runAnalyze(cmd *cobra.Command, args []string) {
 	cfg := GetConfig()
+	reportsPath = resolveReportsDir(analyzeRunID, analyzeIteration, reportsPath)
 	switch cfg.Version {
 	case "v0":
 		v0.ExecAnalyzeCommand(cfg, reportsPath, generateMD)
 	default:
-		analyzeer := analyze.New(cfg)
-		analyzeer.DoAnalyze(reportsPath, generateMD)
+		if report := analyze.New(cfg).DoAnalyze(reportsPath, generateMD); report != nil {
+			pushResults(report)
+		}
 	}
 }
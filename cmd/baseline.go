@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"xnetperf/internal/service/baseline"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	baselineDir        string
+	baselineReportsDir string
+	baselineRunID      string
+	baselineIteration  int
+	baselineForce      bool
+)
+
+var baselineCmd = &cobra.Command{
+	Use:   "baseline",
+	Short: "Manage performance baselines used for regression comparison",
+	Long: `Manage named performance baselines. A baseline stores the per-HCA bandwidth
+and per-pair latency of a run so later runs can be compared against it with
+'xnetperf compare'.
+
+Examples:
+  # Save the current reports directory as a baseline
+  xnetperf baseline save before-fw-upgrade
+
+  # Save the reports of a recorded run as a baseline
+  xnetperf baseline save before-fw-upgrade --run 20250101-120000-config-bandwidth
+
+  # List stored baselines
+  xnetperf baseline list`,
+}
+
+var baselineSaveCmd = &cobra.Command{
+	Use:   "save <name>",
+	Short: "Save the current reports as a named baseline",
+	Args:  cobra.ExactArgs(1),
+	Run:   runBaselineSave,
+}
+
+var baselineListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stored baselines",
+	Args:  cobra.NoArgs,
+	Run:   runBaselineList,
+}
+
+func init() {
+	baselineCmd.PersistentFlags().StringVar(&baselineDir, "baseline-dir", baseline.DefaultDir, "Directory where baselines are stored")
+	baselineSaveCmd.Flags().StringVar(&baselineReportsDir, "reports-dir", "reports", "Path to the reports directory")
+	baselineSaveCmd.Flags().StringVar(&baselineRunID, "run", "", "Use the reports of a recorded run instead of --reports-dir")
+	baselineSaveCmd.Flags().IntVar(&baselineIteration, "iteration", 0, "Iteration of a --run recorded with --repeat")
+	baselineSaveCmd.Flags().BoolVar(&baselineForce, "force", false, "Overwrite an existing baseline with the same name")
+	baselineCmd.AddCommand(baselineSaveCmd)
+	baselineCmd.AddCommand(baselineListCmd)
+}
+
+func runBaselineSave(cmd *cobra.Command, args []string) {
+	cfg := GetConfig()
+	manager := baseline.New(cfg, baselineDir)
+
+	b, err := manager.Capture(args[0], resolveReportsDir(baselineRunID, baselineIteration, baselineReportsDir))
+	if err != nil {
+		fmt.Printf("❌ Failed to capture baseline: %v\n", err)
+		os.Exit(1)
+	}
+
+	path, err := manager.Save(b, baselineForce)
+	if err != nil {
+		fmt.Printf("❌ Failed to save baseline: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ Baseline '%s' saved to %s (%d bandwidth entries, %d latency pairs)\n",
+		b.Name, path, len(b.Bandwidth), len(b.Latency))
+}
+
+func runBaselineList(cmd *cobra.Command, args []string) {
+	manager := baseline.New(GetConfig(), baselineDir)
+
+	baselines, err := manager.List()
+	if err != nil {
+		fmt.Printf("❌ Failed to list baselines: %v\n", err)
+		os.Exit(1)
+	}
+	if len(baselines) == 0 {
+		fmt.Printf("No baselines found in %s\n", baselineDir)
+		return
+	}
+
+	for _, b := range baselines {
+		fmt.Printf("%-30s %s  stream_type=%-9s bandwidth=%d latency=%d\n",
+			b.Name, b.CreatedAt.Format("2006-01-02 15:04:05"), b.StreamType, len(b.Bandwidth), len(b.Latency))
+	}
+}
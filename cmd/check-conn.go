@@ -6,6 +6,7 @@ import (
 	"sort"
 	"strings"
 
+	"xnetperf/internal/script"
 	"xnetperf/internal/service/connectivity"
 
 	"github.com/spf13/cobra"
@@ -39,6 +40,11 @@ Examples:
 }
 
 func runCheckConn(cmd *cobra.Command, args []string) {
+	if remoteMode() {
+		runRemote(cmd)
+		return
+	}
+
 	cfg := GetConfig()
 
 	// Only v1 version is supported for connectivity check
@@ -59,12 +65,17 @@ func runCheckConn(cmd *cobra.Command, args []string) {
 	fmt.Println(fmt.Sprintf("   Timeout: %d seconds per test direction", connectivity.GetConnectivityTestTimeout()))
 	fmt.Println()
 
-	checker := connectivity.New(cfg)
+	runStore, run := startRunRecord(script.TestTypeConnectivity.String(), cfg)
+	checker := connectivity.New(cfg).WithRunDir(runDirOf(runStore, run))
 	summary, err := checker.CheckConnectivity()
 	if err != nil {
 		fmt.Printf("❌ Connectivity check failed: %v\n", err)
+		finishRunRecord(runStore, run, err)
 		os.Exit(1)
 	}
+	indexRunReports(runStore, run)
+	recordRunResult(runStore, run, "connectivity", summary)
+	finishRunRecord(runStore, run, nil)
 
 	// Display results
 	displayConnectivityResults(summary)
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"xnetperf/internal/service/baseline"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	compareReportsDir string
+	compareTolerance  float64
+	compareRunID      string
+	compareIteration  int
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <baseline>",
+	Short: "Compare the current reports against a saved baseline",
+	Long: `Re-analyze the current reports directory and compare per-HCA bandwidth and
+per-pair latency against a baseline saved with 'xnetperf baseline save'.
+
+A device is marked REGRESSED when its bandwidth drops, or its latency rises,
+by more than the tolerance percentage. Devices present in the baseline but
+missing from the current reports are marked MISSING. The command exits with
+status 1 when any regression or missing device is found.
+
+Examples:
+  # Compare against a baseline with the default 5% tolerance
+  xnetperf compare before-fw-upgrade
+
+  # Allow up to 10% deviation
+  xnetperf compare before-fw-upgrade --tolerance 10
+
+  # Compare a recorded run
+  xnetperf compare before-fw-upgrade --run 20250101-120000-config-bandwidth`,
+	Args: cobra.ExactArgs(1),
+	Run:  runCompare,
+}
+
+func init() {
+	compareCmd.Flags().StringVar(&compareReportsDir, "reports-dir", "reports", "Path to the reports directory")
+	compareCmd.Flags().StringVar(&compareRunID, "run", "", "Compare the reports of a recorded run instead of --reports-dir")
+	compareCmd.Flags().IntVar(&compareIteration, "iteration", 0, "Iteration of a --run recorded with --repeat")
+	compareCmd.Flags().Float64Var(&compareTolerance, "tolerance", baseline.DefaultTolerancePercent, "Allowed deviation in percent before a device is flagged")
+	compareCmd.Flags().StringVar(&baselineDir, "baseline-dir", baseline.DefaultDir, "Directory where baselines are stored")
+}
+
+func runCompare(cmd *cobra.Command, args []string) {
+	cfg := GetConfig()
+	manager := baseline.New(cfg, baselineDir)
+
+	base, err := manager.Load(args[0])
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	current, err := manager.Capture("current", resolveReportsDir(compareRunID, compareIteration, compareReportsDir))
+	if err != nil {
+		fmt.Printf("❌ Failed to analyze current reports: %v\n", err)
+		os.Exit(1)
+	}
+
+	result := baseline.Compare(base, current, compareTolerance)
+	baseline.DisplayComparison(result)
+
+	if result.HasRegressions() {
+		fmt.Println("\n❌ Regressions detected compared to baseline.")
+		os.Exit(1)
+	}
+	fmt.Println("\n✅ No regressions compared to baseline.")
+}
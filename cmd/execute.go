@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -9,8 +10,10 @@ import (
 	"xnetperf/internal/script"
 	"xnetperf/internal/service/analyze"
 	"xnetperf/internal/service/collect"
+	"xnetperf/internal/service/counters"
 	"xnetperf/internal/service/precheck"
 	"xnetperf/internal/service/probe"
+	"xnetperf/internal/service/stats"
 	v0 "xnetperf/internal/v0"
 
 	"github.com/spf13/cobra"
@@ -33,84 +36,193 @@ Examples:
   xnetperf execute
 
   # Execute with custom config file
-  xnetperf execute -c /path/to/config.yaml`,
+  xnetperf execute -c /path/to/config.yaml
+
+  # Run the test 5 times and report per-device statistics,
+  # flagging links whose coefficient of variation exceeds 3%
+  xnetperf execute --repeat 5 --cv-threshold 3`,
 	Run: runExecute,
 }
 
+var (
+	executeRepeat      int
+	executeCVThreshold float64
+)
+
+func init() {
+	executeCmd.Flags().IntVar(&executeRepeat, "repeat", 1, "Run the test N times and report per-device mean, stddev, min, max and CV")
+	executeCmd.Flags().Float64Var(&executeCVThreshold, "cv-threshold", stats.DefaultCVThresholdPercent, "Coefficient of variation (%) above which a link is flagged unstable with --repeat")
+}
+
 func runExecute(cmd *cobra.Command, args []string) {
+	if remoteMode() {
+		runRemote(cmd)
+		return
+	}
+
 	fmt.Println("🚀 Starting complete xnetperf workflow...")
 	fmt.Println(strings.Repeat("=", 60))
 
 	// Load configuration once for all steps
 	cfg := GetConfig()
-
-	// Step 1: Execute precheck command
-	fmt.Println("\n📋 Step 1/4: Running network tests...")
-	if !executeRunStep(cfg) {
-		fmt.Println("❌ Run step failed. Aborting workflow.")
+	if err := validateRepeat(cfg, executeRepeat); err != nil {
+		fmt.Printf("❌ %v\n", err)
 		os.Exit(1)
 	}
 
-	// Special handling for P2P stream type
-	// 不需要展示效果
-	if cfg.StreamType == config.P2P {
-		fmt.Println("⚠️  P2P stream type detected. Skipping probe step as tests are short-lived.")
-		os.Exit(0)
-	}
-
-	// Step 2: Execute probe command
-	fmt.Println("\n🔍 Step 2/4: Monitoring test progress...")
-	if !executeProbeStep(cfg) {
-		fmt.Println("❌ Probe step failed. Aborting workflow.")
+	runStore, run := startRunRecord(script.TestTypeBandwidth.String(), cfg)
+	runDir := runDirOf(runStore, run)
+	abort := func(step string, err error) {
+		if err != nil {
+			fmt.Printf("❌ %s step failed: %v. Aborting workflow.\n", step, err)
+		} else {
+			fmt.Printf("❌ %s step failed. Aborting workflow.\n", step)
+			err = fmt.Errorf("%s step failed", strings.ToLower(step))
+		}
+		finishRunRecord(runStore, run, err)
 		os.Exit(1)
 	}
 
-	// Step 3: Execute collect command
-	fmt.Println("\n📥 Step 3/4: Collecting reports...")
-	if !executeCollectStep(cfg) {
-		fmt.Println("❌ Collect step failed. Aborting workflow.")
-		os.Exit(1)
+	accumulator := stats.New(executeCVThreshold)
+	var reportsDir, scriptsDir string
+	var report *analyze.ReportData
+	for i := 1; i <= executeRepeat; i++ {
+		reportsDir, scriptsDir = iterationDirs(runDir, i, executeRepeat)
+		if executeRepeat > 1 {
+			fmt.Printf("\n🔁 Iteration %d/%d\n", i, executeRepeat)
+			fmt.Println(strings.Repeat("-", 60))
+		}
+
+		// Snapshot the port counters so errors accumulated during the test can be reported
+		counterReader := counters.New(cfg)
+		before := counterReader.Snapshot()
+
+		// Step 1: Execute precheck command
+		fmt.Println("\n📋 Step 1/4: Running network tests...")
+		excluded, err := executeRunStep(cfg, scriptsDir)
+		if err != nil {
+			abort("Run", err)
+		}
+
+		// Special handling for P2P stream type
+		// 不需要展示效果
+		if cfg.StreamType == config.P2P {
+			fmt.Println("⚠️  P2P stream type detected. Skipping probe step as tests are short-lived.")
+			finishRunRecord(runStore, run, nil)
+			os.Exit(0)
+		}
+
+		// Step 2: Execute probe command
+		fmt.Println("\n🔍 Step 2/4: Monitoring test progress...")
+		if !executeProbeStep(cfg) {
+			abort("Probe", nil)
+		}
+		deltas := counters.Diff(before, counterReader.Snapshot())
+
+		// Step 3: Execute collect command
+		fmt.Println("\n📥 Step 3/4: Collecting reports...")
+		if !executeCollectStep(cfg, reportsDir) {
+			abort("Collect", nil)
+		}
+		saveCounterDeltas(cfg, reportsDir, deltas)
+		saveExcludedHCAs(cfg, reportsDir, excluded)
+
+		// Step 4: Execute analyze command
+		fmt.Println("\n📊 Step 4/4: Analyzing results...")
+		var ok bool
+		if report, ok = executeAnalyzeStep(cfg, reportsDir); !ok {
+			abort("Analyze", nil)
+		}
+
+		if executeRepeat > 1 {
+			devices, err := analyze.New(cfg).CollectDeviceBandwidth(reportsDir)
+			if err != nil {
+				fmt.Printf("⚠️  Iteration %d excluded from statistics: %v\n", i, err)
+				continue
+			}
+			accumulator.AddBandwidth(devices)
+		}
 	}
 
-	// Step 4: Execute analyze command
-	fmt.Println("\n📊 Step 4/4: Analyzing results...")
-	if !executeAnalyzeStep(cfg) {
-		fmt.Println("❌ Analyze step failed. Aborting workflow.")
-		os.Exit(1)
+	if cfg.Report.Enable {
+		indexRunReports(runStore, run)
+		if report != nil {
+			recordRunResult(runStore, run, "bandwidth", report)
+			pushResults(report)
+		}
 	}
+	if executeRepeat > 1 {
+		fmt.Println()
+		result := accumulator.Result()
+		stats.Display(result)
+		recordRunResult(runStore, run, "statistics", result)
+	}
+	finishRunRecord(runStore, run, nil)
 
 	fmt.Println("\n🎉 Complete xnetperf workflow finished successfully!")
+	if runDir != "" {
+		fmt.Printf("📁 Run directory: %s\n", runDir)
+	}
 	fmt.Println(strings.Repeat("=", 60))
 }
 
-// executeRunStep runs the network tests
-func executeRunStep(cfg *config.Config) bool {
+// executeRunStep runs the network tests, saving the generated scripts into
+// scriptsDir, and returns the unhealthy HCAs excluded from the test plan
+func executeRunStep(cfg *config.Config, scriptsDir string) ([]precheck.ExcludedHCA, error) {
 	fmt.Printf("Executing network tests (stream_type: %s)...\n", cfg.StreamType)
 
+	var excluded []precheck.ExcludedHCA
 	if cfg.Version == "v1" {
 		// executor 里面没有precheck 先添加在这里
 		fmt.Println("\n🔍 Step 0/5: Performing network card precheck...")
-		checker := precheck.New(cfg)
-		checker.Display(checker.DoCheck())
-		fmt.Println("✅ Precheck passed! All network cards are healthy. Proceeding with latency tests...")
+		checker := precheck.New(cfg).WithTestType(script.TestTypeBandwidth).WithCache(loadPrecheckCache(), false)
+		results := checker.DoCheck()
+		checker.Display(results)
+		perftest := checker.CheckPerftest()
+		checker.DisplayPerftest(perftest)
+		hostTuning := checker.CheckHostTuning()
+		checker.DisplayHostTuning(hostTuning)
+		checker.SaveCache(results, perftest, hostTuning)
+		firmware := checker.CheckFirmware(results)
+		precheck.DisplayFirmware(firmware)
+		summary := precheck.Summarize(results)
+		if len(results) > 0 {
+			summary.AddPerftest(perftest)
+			summary.AddHostTuning(hostTuning)
+			summary.AddFirmware(firmware)
+			pushResults(summary)
+		}
+		if precheck.PerftestFailedHosts(perftest) > 0 {
+			return nil, errors.New("ib_write_bw is missing or too old on some hosts")
+		}
+		if firmware.PinViolationCount > 0 {
+			return nil, errors.New("some HCAs do not run the pinned firmware version")
+		}
+		decision, err := checker.ApplyUnhealthyHCAPolicy(results, config.UnhealthyHCAWarn)
+		if err != nil {
+			return nil, err
+		}
+		precheck.DisplayUnhealthyHCADecision(decision)
+		excluded = decision.Excluded
+		if summary.AllHealthy {
+			fmt.Println("✅ Precheck passed! All network cards are healthy. Proceeding with latency tests...")
+		}
 
 		executor := script.NewExecutor(cfg, script.TestTypeBandwidth)
 		if executor == nil {
-			fmt.Println("❌ Unsupported stream type for v1 execute workflow. Aborting.")
-			os.Exit(1)
+			return nil, fmt.Errorf("unsupported stream type %s for v1 execute workflow", cfg.StreamType)
 		}
 		fmt.Println("\n📋 Step 1/4: Running network tests...")
-		err := executor.Execute()
+		err = executor.WithScriptsDir(scriptsDir).WithExcludedHCAs(precheck.ExcludedHostHCAs(excluded)).Execute()
 		if err != nil {
-			fmt.Printf("❌ Run step failed: %v. Aborting workflow.\n", err)
-			os.Exit(1)
+			return nil, err
 		}
 	} else {
 		v0.ExecRunCommand(cfg)
 	}
 
 	fmt.Println("✅ Network tests started successfully")
-	return true
+	return excluded, nil
 }
 
 // executeProbeStep monitors the test progress using probe logic
@@ -122,8 +234,8 @@ func executeProbeStep(cfg *config.Config) bool {
 	return true
 }
 
-// executeCollectStep collects report files with cleanup
-func executeCollectStep(cfg *config.Config) bool {
+// executeCollectStep collects report files into reportsDir with cleanup
+func executeCollectStep(cfg *config.Config, reportsDir string) bool {
 	if !cfg.Report.Enable {
 		fmt.Println("⚠️  Report generation is disabled in config. Skipping collect step.")
 		return true
@@ -133,7 +245,7 @@ func executeCollectStep(cfg *config.Config) bool {
 	cleanupRemote = true
 	fmt.Println("Collecting report files from remote hosts...")
 	collector := collect.New(cfg)
-	if err := collector.DoCollect(cleanupRemote); err != nil {
+	if err := collector.DoCollect(reportsDir, cleanupRemote); err != nil {
 		fmt.Printf("❌ Error during report collection: %v\n", err)
 		return false
 	}
@@ -142,26 +254,47 @@ func executeCollectStep(cfg *config.Config) bool {
 	return true
 }
 
-// executeAnalyzeStep analyzes the results
-func executeAnalyzeStep(cfg *config.Config) bool {
+// saveCounterDeltas saves the counter deltas next to the collected reports so
+// analyze shows them, or displays them right away when no reports are collected
+func saveCounterDeltas(cfg *config.Config, reportsDir string, deltas []counters.Delta) {
+	if !cfg.Report.Enable {
+		counters.Display(deltas)
+		return
+	}
+	if err := counters.Save(reportsDir, deltas); err != nil {
+		fmt.Printf("⚠️  Failed to save counter deltas: %v\n", err)
+	}
+}
+
+// saveExcludedHCAs saves the HCAs excluded from the test plan next to the
+// collected reports so analyze reports them as EXCLUDED
+func saveExcludedHCAs(cfg *config.Config, reportsDir string, excluded []precheck.ExcludedHCA) {
+	if !cfg.Report.Enable || len(excluded) == 0 {
+		return
+	}
+	if err := precheck.SaveExcluded(reportsDir, excluded); err != nil {
+		fmt.Printf("⚠️  Failed to save excluded HCAs: %v\n", err)
+	}
+}
+
+// executeAnalyzeStep analyzes the results in reportsDir and returns the report
+// it displayed, nil when report generation is disabled or the analysis failed
+func executeAnalyzeStep(cfg *config.Config, reportsDir string) (*analyze.ReportData, bool) {
 	if !cfg.Report.Enable {
 		fmt.Println("⚠️  Report generation is disabled in config. Skipping analyze step.")
-		return true
+		return nil, true
 	}
 
 	fmt.Println("Analyzing performance results...")
 
-	reportsDir := "reports"
-
 	// Check if reports directory exists
 	if _, err := os.Stat(reportsDir); os.IsNotExist(err) {
 		fmt.Printf("❌ Reports directory not found: %s\n", reportsDir)
-		return false
+		return nil, false
 	}
 
-	analyzeer := analyze.New(cfg)
-	analyzeer.DoAnalyze(reportsPath, generateMD)
+	report := analyze.New(cfg).DoAnalyze(reportsDir, generateMD)
 
 	fmt.Println("✅ Analysis completed successfully")
-	return true
+	return report, true
 }
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 
+	"xnetperf/internal/script"
 	"xnetperf/internal/service/lat"
+	"xnetperf/internal/service/stats"
 	v0 "xnetperf/internal/v0"
 
 	"github.com/spf13/cobra"
@@ -39,20 +41,88 @@ Examples:
   xnetperf lat
 
   # Execute with custom config file
-  xnetperf lat -c /path/to/config.yaml`,
+  xnetperf lat -c /path/to/config.yaml
+
+  # Run the latency test 5 times and report per-pair statistics
+  xnetperf lat --repeat 5`,
 	Run: runLat,
 }
 
+var (
+	latRepeat      int
+	latCVThreshold float64
+)
+
+func init() {
+	latCmd.Flags().IntVar(&latRepeat, "repeat", 1, "Run the test N times and report per-pair mean, stddev, min, max and CV")
+	latCmd.Flags().Float64Var(&latCVThreshold, "cv-threshold", stats.DefaultCVThresholdPercent, "Coefficient of variation (%) above which a link is flagged unstable with --repeat")
+}
+
 func runLat(cmd *cobra.Command, args []string) {
+	if remoteMode() {
+		runRemote(cmd)
+		return
+	}
+
 	cfg := GetConfig()
+	if err := validateRepeat(cfg, latRepeat); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
 
 	if cfg.Version == "v1" {
+		runStore, run := startRunRecord(script.TestTypeLatency.String(), cfg)
+		runDir := runDirOf(runStore, run)
+		accumulator := stats.New(latCVThreshold)
+
+		var reportsDir, scriptsDir string
 		latRunner := lat.New(cfg)
-		if err := latRunner.Execute(); err != nil {
-			fmt.Printf("❌ Latency test failed: %v\n", err)
-			os.Exit(1)
+		precheckCache := loadPrecheckCache()
+		for i := 1; i <= latRepeat; i++ {
+			reportsDir, scriptsDir = iterationDirs(runDir, i, latRepeat)
+			if latRepeat > 1 {
+				fmt.Printf("\n🔁 Iteration %d/%d\n", i, latRepeat)
+			}
+
+			latRunner = lat.New(cfg).WithDirs(reportsDir, scriptsDir).WithPrecheckCache(precheckCache)
+			if err := latRunner.Execute(); err != nil {
+				fmt.Printf("❌ Latency test failed: %v\n", err)
+				finishRunRecord(runStore, run, err)
+				os.Exit(1)
+			}
+
+			if latRepeat > 1 {
+				data, err := latRunner.ParseLatencyReportsFromDir(reportsDir)
+				if err != nil {
+					fmt.Printf("⚠️  Iteration %d excluded from statistics: %v\n", i, err)
+					continue
+				}
+				accumulator.AddLatency(data)
+			}
+		}
+
+		if cfg.Report.Enable {
+			indexRunReports(runStore, run)
+			if report, err := latRunner.GenerateLatencyReport(reportsDir); err == nil {
+				recordRunResult(runStore, run, "latency", report)
+				pushResults(report)
+			}
+		}
+		if latRepeat > 1 {
+			fmt.Println()
+			result := accumulator.Result()
+			stats.Display(result)
+			recordRunResult(runStore, run, "statistics", result)
+		}
+		finishRunRecord(runStore, run, nil)
+		if runDir != "" {
+			fmt.Printf("📁 Run directory: %s\n", runDir)
 		}
 	} else {
+		if latRepeat > 1 {
+			fmt.Println("❌ --repeat is only available in v1 mode")
+			os.Exit(1)
+		}
 		v0.ExecuteLatCommand(cfg)
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"xnetperf/internal/service/precheck"
+	"xnetperf/internal/store"
 	v0 "xnetperf/internal/v0"
 
 	"github.com/spf13/cobra"
@@ -17,8 +18,39 @@ for each HCA on each host configured in the config file.
 
 Only HCAs that are both LinkUp and ACTIVE are considered healthy.
 
+It also checks that ib_write_bw and ib_write_lat exist on every host and
+support the options the tests need (--out_json, ...). Set
+precheck.perftest.bundle_dir to push bundled binaries to hosts that lack them.
+
+Set precheck.host_tuning.enabled to also report the CPU governor, C-states,
+IOMMU mode, hugepages, memlock limit and HCA IRQ affinity of every host and
+compare them with the expected profile.
+
+With --fix, the remediations enabled in precheck.fix are applied to the
+failures found (bring a down port up through its netdev, set the RoCE MTU,
+load missing kernel modules), the fixed hosts and HCAs are re-checked and the
+changes are reported. --dry-run only lists the changes. Raising the memlock
+limit writes a persistent limits.d file and only runs when memlock is listed
+in precheck.fix.actions.
+
+The firmware consistency view groups the HCAs by board_id and flags HCAs
+whose firmware differs from the version pinned in precheck.firmware.pinned,
+or from the version most HCAs of that board run. Pinned versions are
+enforced: a pinned mismatch fails the precheck. --firmware-export writes
+the view to a .json or .csv file.
+
+Results are cached per host in the run store (--runs-dir). Hosts whose
+checks all passed less than precheck.cache_ttl_seconds ago are not checked
+again and their cached results are reused. --recheck-failed ignores the TTL
+and only re-examines the hosts and HCAs that failed the last precheck.
+With --server the server's run store cache is used the same way, without
+--recheck-failed.
+
 Example:
   xnetperf precheck
+  xnetperf precheck --recheck-failed
+  xnetperf precheck --fix --dry-run
+  xnetperf precheck --firmware-export firmware.csv
 `
 
 var precheckCmd = &cobra.Command{
@@ -28,12 +60,74 @@ var precheckCmd = &cobra.Command{
 	Run:   runPrecheck,
 }
 
+var (
+	precheckFix            bool
+	precheckDryRun         bool
+	precheckFirmwareExport string
+	precheckRecheckFailed  bool
+)
+
+func init() {
+	precheckCmd.Flags().BoolVar(&precheckFix, "fix", false, "Apply the remediations enabled in precheck.fix to the failures found")
+	precheckCmd.Flags().BoolVar(&precheckDryRun, "dry-run", false, "With --fix, only list the remediations that would be applied")
+	precheckCmd.Flags().StringVar(&precheckFirmwareExport, "firmware-export", "", "Write the firmware consistency view to this .json or .csv file")
+	precheckCmd.Flags().BoolVar(&precheckRecheckFailed, "recheck-failed", false, "Only re-examine the hosts and HCAs that failed the last precheck")
+}
+
 func runPrecheck(cmd *cobra.Command, args []string) {
+	if remoteMode() {
+		if precheckFix || precheckFirmwareExport != "" || precheckRecheckFailed {
+			fmt.Println("❌ --fix, --firmware-export and --recheck-failed are not supported with --server")
+			os.Exit(1)
+		}
+		runRemote(cmd)
+		return
+	}
+
 	cfg := GetConfig()
+	if (precheckFix || precheckFirmwareExport != "" || precheckRecheckFailed) && cfg.Version != "v1" {
+		fmt.Println("❌ --fix, --firmware-export and --recheck-failed require a v1 config")
+		os.Exit(1)
+	}
 
 	if cfg.Version == "v1" {
-		checker := precheck.New(cfg)
-		checker.Display(checker.DoCheck())
+		checker := precheck.New(cfg).WithCache(loadPrecheckCache(), precheckRecheckFailed)
+		results := checker.DoCheck()
+		checker.Display(results)
+		perftest := checker.CheckPerftest()
+		checker.DisplayPerftest(perftest)
+		hostTuning := checker.CheckHostTuning()
+		checker.DisplayHostTuning(hostTuning)
+		if precheckFix {
+			actions := checker.PlanFixes(results, hostTuning)
+			if !precheckDryRun && len(actions) > 0 {
+				fmt.Println("\n🔧 Applying fixes and re-running the failed checks...")
+				actions = checker.ApplyFixes(actions)
+				results, hostTuning = checker.Recheck(actions, results, hostTuning)
+			}
+			checker.DisplayFixes(actions, precheckDryRun)
+		}
+		checker.SaveCache(results, perftest, hostTuning)
+		firmware := checker.CheckFirmware(results)
+		precheck.DisplayFirmware(firmware)
+		if precheckFirmwareExport != "" {
+			if err := precheck.ExportFirmware(firmware, precheckFirmwareExport); err != nil {
+				fmt.Printf("❌ Failed to export firmware view: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("\n📄 Firmware consistency view written to %s\n", precheckFirmwareExport)
+		}
+		if len(results) > 0 {
+			summary := precheck.Summarize(results)
+			summary.AddPerftest(perftest)
+			summary.AddHostTuning(hostTuning)
+			summary.AddFirmware(firmware)
+			pushResults(summary)
+		}
+		if firmware.PinViolationCount > 0 {
+			fmt.Println("\n❌ Some HCAs do not run the pinned firmware version.")
+			os.Exit(1)
+		}
 		os.Exit(0)
 	}
 
@@ -45,3 +139,14 @@ func runPrecheck(cmd *cobra.Command, args []string) {
 		fmt.Println("\n✅ Precheck passed! All HCAs are healthy.")
 	}
 }
+
+// loadPrecheckCache loads the precheck cache of the run store; precheck checks
+// every host and caches nothing when the store cannot be opened
+func loadPrecheckCache() *precheck.Cache {
+	runStore, err := store.Open(runsDir)
+	if err != nil {
+		fmt.Printf("⚠️  Precheck results will not be cached: %v\n", err)
+		return nil
+	}
+	return precheck.LoadCache(runStore)
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"xnetperf/internal/exporter"
+)
+
+// pushResults pushes precheck, bandwidth and latency results to the
+// Prometheus endpoint of the metrics_push config section, if one is set.
+// A failed push only prints a warning; the run itself succeeded.
+func pushResults(results ...any) {
+	if cfg == nil || !cfg.MetricsPush.Enabled() {
+		return
+	}
+	if err := cfg.MetricsPush.Validate(); err != nil {
+		fmt.Printf("⚠️  Metrics not pushed: %v\n", err)
+		return
+	}
+	if err := exporter.NewPusher(cfg.MetricsPush).Push(context.Background(), filepath.Base(cfgFile), results...); err != nil {
+		fmt.Printf("⚠️  Failed to push metrics: %v\n", err)
+		return
+	}
+	fmt.Printf("📤 Results pushed to %s\n", cfg.MetricsPush.URL)
+}
@@ -3,14 +3,17 @@ package cmd
 import (
 	"log"
 	"xnetperf/config"
+	"xnetperf/internal/audit"
+	"xnetperf/internal/store"
 	"xnetperf/pkg/tools/logger"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	cfgFile string
-	cfg     *config.Config
+	cfgFile      string
+	cfg          *config.Config
+	auditLogPath string
 )
 
 func GetConfig() *config.Config {
@@ -21,6 +24,15 @@ var rootCmd = &cobra.Command{
 	Use:   "xnetperf",
 	Short: "xnetperf network test tool",
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Commands submitted to a server use the config stored there
+		if remoteMode() {
+			if !remoteCommands[cmd.Name()] {
+				log.Fatalf("%s cannot run with --server; supported commands: precheck, execute, lat, check-conn", cmd.Name())
+			}
+			logger.Init(logger.Config{Level: logger.LevelInfo})
+			return
+		}
+
 		// 1. Load configuration
 		var err error
 		cfg, err = config.LoadConfig(cfgFile)
@@ -49,11 +61,26 @@ var rootCmd = &cobra.Command{
 		// 5. Log successful initialization
 		logger.Info("✓ Config loaded", "file", cfgFile)
 		logger.Debug("Logger initialized", "level", cfg.Logger.LogLevel, "format", cfg.Logger.LogFormat)
+
+		// 6. Open the audit log that remote commands are recorded in
+		if auditLogPath != "" {
+			auditLog, err := audit.Open(auditLogPath)
+			if err != nil {
+				logger.Warn("Audit log disabled", "path", auditLogPath, "error", err)
+			} else {
+				audit.SetDefault(auditLog)
+			}
+		}
 	},
 }
 
 func Execute() {
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "./config.yaml", "config file")
+	rootCmd.PersistentFlags().StringVar(&runsDir, "runs-dir", store.DefaultRoot, "directory of the local run store")
+	rootCmd.PersistentFlags().StringVar(&auditLogPath, "audit-log", audit.DefaultPath, "file recording every remote command and who issued it (empty disables)")
+	rootCmd.PersistentFlags().StringVar(&serverURL, "server", "", "submit precheck, execute, lat and check-conn to the xnetperf server at this URL instead of running them locally; --config names the config on the server")
+	rootCmd.PersistentFlags().StringVar(&serverToken, "server-token", "", "API token for --server (default: $"+serverTokenEnv+")")
+	rootCmd.PersistentFlags().StringVar(&serverCAFile, "server-ca", "", "PEM CA bundle that verifies the certificate of --server")
 	rootCmd.AddCommand(precheckCmd)
 	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(stopCmd)
@@ -63,5 +90,9 @@ func Execute() {
 	rootCmd.AddCommand(executeCmd)
 	rootCmd.AddCommand(latCmd)
 	rootCmd.AddCommand(checkConnCmd)
+	rootCmd.AddCommand(baselineCmd)
+	rootCmd.AddCommand(compareCmd)
+	rootCmd.AddCommand(runsCmd)
+	rootCmd.AddCommand(sampleCmd)
 	_ = rootCmd.Execute()
 }
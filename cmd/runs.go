@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"xnetperf/config"
+	"xnetperf/internal/store"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runsDir        string
+	runsConfigName string
+	runsTestType   string
+	runsStatus     string
+	runsLimit      int
+	runsForce      bool
+)
+
+var runsCmd = &cobra.Command{
+	Use:   "runs",
+	Short: "List, show and delete recorded test runs",
+	Long: `Every execute, lat and check-conn run is recorded in the local run store
+(the runs/ directory by default) together with its config snapshot, raw
+report files and analyzed results.
+
+Examples:
+  # List the 20 most recent runs
+  xnetperf runs list
+
+  # Only latency runs of a given config
+  xnetperf runs list --config-name config.yaml --type latency
+
+  # Show a run and its analyzed results
+  xnetperf runs show 20250101-120000-config-bandwidth
+
+  # Delete runs
+  xnetperf runs delete 20250101-120000-config-bandwidth`,
+}
+
+var runsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recorded runs, newest first",
+	Args:  cobra.NoArgs,
+	Run:   runRunsList,
+}
+
+var runsShowCmd = &cobra.Command{
+	Use:   "show <run-id>",
+	Short: "Show a recorded run and its analyzed results",
+	Args:  cobra.ExactArgs(1),
+	Run:   runRunsShow,
+}
+
+var runsDeleteCmd = &cobra.Command{
+	Use:   "delete <run-id>...",
+	Short: "Delete recorded runs",
+	Args:  cobra.MinimumNArgs(1),
+	Run:   runRunsDelete,
+}
+
+func init() {
+	runsListCmd.Flags().StringVar(&runsConfigName, "config-name", "", "Only show runs of this config")
+	runsListCmd.Flags().StringVar(&runsTestType, "type", "", "Only show runs of this test type (bandwidth, latency, connectivity)")
+	runsListCmd.Flags().StringVar(&runsStatus, "status", "", "Only show runs with this status (running, succeeded, failed)")
+	runsListCmd.Flags().IntVar(&runsLimit, "limit", 20, "Maximum number of runs to show (0 for all)")
+	runsCmd.AddCommand(runsListCmd)
+	runsCmd.AddCommand(runsShowCmd)
+	runsDeleteCmd.Flags().BoolVar(&runsForce, "force", false, "Also delete runs that are still marked running")
+	runsCmd.AddCommand(runsDeleteCmd)
+}
+
+func openRunStore() *store.Store {
+	runStore, err := store.Open(runsDir)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	return runStore
+}
+
+// currentConfigName returns the name runs of the loaded config are recorded under
+func currentConfigName() string {
+	return filepath.Base(cfgFile)
+}
+
+// startRunRecord registers a new run in the store; recording failures only warn
+func startRunRecord(testType string, cfg *config.Config) (*store.Store, *store.Run) {
+	runStore, err := store.Open(runsDir)
+	if err != nil {
+		fmt.Printf("⚠️  Run will not be recorded: %v\n", err)
+		return nil, nil
+	}
+	run, err := runStore.Create(currentConfigName(), testType, cfg)
+	if err != nil {
+		fmt.Printf("⚠️  Run will not be recorded: %v\n", err)
+		return nil, nil
+	}
+	fmt.Printf("📝 Recording run %s\n", run.ID)
+	return runStore, run
+}
+
+// runDirOf returns the directory of a recorded run, or "" (the shared reports
+// directory) when the run is not recorded
+func runDirOf(runStore *store.Store, run *store.Run) string {
+	if run == nil {
+		return ""
+	}
+	return runStore.Dir(run.ID)
+}
+
+// resolveReportsDir returns the reports directory of the given recorded run,
+// or reportsDir when no run ID is given. A run repeated with --repeat keeps
+// each iteration apart, so one of them must be chosen with --iteration.
+func resolveReportsDir(runID string, iteration int, reportsDir string) string {
+	if runID == "" {
+		if iteration != 0 {
+			fmt.Println("❌ --iteration requires --run")
+			os.Exit(1)
+		}
+		return reportsDir
+	}
+	runStore := openRunStore()
+	if _, err := runStore.Get(runID); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	dir := runStore.ReportsDir(runID)
+	n := store.Iterations(dir)
+	switch {
+	case n == 0 && iteration != 0:
+		fmt.Printf("❌ Run %s was not repeated; omit --iteration\n", runID)
+		os.Exit(1)
+	case n == 0:
+		return dir
+	case iteration < 1 || iteration > n:
+		fmt.Printf("❌ Run %s has %d iterations; choose one with --iteration 1..%d\n", runID, n, n)
+		os.Exit(1)
+	}
+	return store.IterationDir(dir, iteration)
+}
+
+// iterationDirs returns the reports and scripts directories of one iteration of
+// a run repeated n times; single runs use the run directory itself
+func iterationDirs(runDir string, iteration, n int) (string, string) {
+	reportsDir, scriptsDir := store.ReportsPath(runDir), store.ScriptsPath(runDir)
+	if n <= 1 {
+		return reportsDir, scriptsDir
+	}
+	reportsDir = store.IterationDir(reportsDir, iteration)
+	if scriptsDir != "" {
+		scriptsDir = store.IterationDir(scriptsDir, iteration)
+	}
+	return reportsDir, scriptsDir
+}
+
+// validateRepeat checks that a config can be run several times for statistics
+func validateRepeat(cfg *config.Config, repeat int) error {
+	switch {
+	case repeat < 1:
+		return fmt.Errorf("--repeat must be at least 1")
+	case repeat == 1:
+		return nil
+	case !cfg.Report.Enable:
+		return fmt.Errorf("--repeat requires report generation to be enabled (report.enable: true)")
+	case cfg.Run.Infinitely:
+		return fmt.Errorf("--repeat cannot be used with infinite runs (run.infinitely: true)")
+	case cfg.StreamType == config.P2P:
+		return fmt.Errorf("--repeat is not supported for the p2p stream type")
+	}
+	return nil
+}
+
+// indexRunReports records how many raw reports were collected into the run
+func indexRunReports(runStore *store.Store, run *store.Run) {
+	if run == nil {
+		return
+	}
+	if err := runStore.IndexReports(run); err != nil {
+		fmt.Printf("⚠️  Failed to record reports: %v\n", err)
+	}
+}
+
+// recordRunResult stores an analyzed result in the run
+func recordRunResult(runStore *store.Store, run *store.Run, name string, result any) {
+	if run == nil {
+		return
+	}
+	if err := runStore.SaveResult(run, name, result); err != nil {
+		fmt.Printf("⚠️  Failed to record %s result: %v\n", name, err)
+	}
+}
+
+// finishRunRecord marks the run as finished, failed when runErr is not nil
+func finishRunRecord(runStore *store.Store, run *store.Run, runErr error) {
+	if run == nil {
+		return
+	}
+	if err := runStore.Finish(run, runErr); err != nil {
+		fmt.Printf("⚠️  Failed to record run status: %v\n", err)
+	}
+}
+
+func runRunsList(cmd *cobra.Command, args []string) {
+	runs, err := openRunStore().List(store.Filter{
+		ConfigName: runsConfigName,
+		TestType:   runsTestType,
+		Status:     runsStatus,
+		Limit:      runsLimit,
+	})
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if len(runs) == 0 {
+		fmt.Println("No runs recorded.")
+		return
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleRounded)
+	t.AppendHeader(table.Row{"Run ID", "Config", "Type", "Status", "Started", "Duration", "Reports", "Results"})
+	for _, run := range runs {
+		t.AppendRow(table.Row{
+			run.ID,
+			run.ConfigName,
+			run.TestType,
+			run.Status,
+			run.StartedAt.Format("2006-01-02 15:04:05"),
+			run.Duration().Round(time.Second),
+			run.ReportFiles,
+			strings.Join(run.Results, ","),
+		})
+	}
+	t.Render()
+}
+
+func runRunsShow(cmd *cobra.Command, args []string) {
+	runStore := openRunStore()
+	run, err := runStore.Get(args[0])
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Run ID:      %s\n", run.ID)
+	fmt.Printf("Config:      %s\n", run.ConfigName)
+	fmt.Printf("Test type:   %s\n", run.TestType)
+	fmt.Printf("Status:      %s\n", run.Status)
+	fmt.Printf("Started:     %s\n", run.StartedAt.Format("2006-01-02 15:04:05"))
+	if run.FinishedAt != nil {
+		fmt.Printf("Finished:    %s\n", run.FinishedAt.Format("2006-01-02 15:04:05"))
+	}
+	fmt.Printf("Duration:    %s\n", run.Duration().Round(time.Second))
+	fmt.Printf("Directory:   %s\n", runStore.Dir(run.ID))
+	fmt.Printf("Reports:     %d file(s)\n", run.ReportFiles)
+	if run.Error != "" {
+		fmt.Printf("Error:       %s\n", run.Error)
+	}
+
+	for _, name := range run.Results {
+		data, err := runStore.ReadResult(run.ID, name)
+		if err != nil {
+			fmt.Printf("\n⚠️  %v\n", err)
+			continue
+		}
+		var pretty any
+		if err := json.Unmarshal(data, &pretty); err == nil {
+			if indented, err := json.MarshalIndent(pretty, "", "  "); err == nil {
+				data = indented
+			}
+		}
+		fmt.Printf("\n=== Result: %s ===\n%s\n", name, data)
+	}
+}
+
+func runRunsDelete(cmd *cobra.Command, args []string) {
+	runStore := openRunStore()
+	failed := false
+	for _, id := range args {
+		if err := runStore.Delete(id, runsForce); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			failed = true
+			continue
+		}
+		fmt.Printf("🗑️  Deleted run %s\n", id)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
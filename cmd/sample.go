@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"xnetperf/internal/service/sampler"
+
+	"github.com/spf13/cobra"
+)
+
+// samplesFile is the CSV file samples are written to inside a run directory
+const samplesFile = "samples.csv"
+
+var (
+	sampleInterval int
+	sampleDuration int
+	sampleCSV      string
+	sampleRunID    string
+	sampleQuiet    bool
+)
+
+var sampleCmd = &cobra.Command{
+	Use:   "sample",
+	Short: "Sample per-HCA bandwidth over time from port counters",
+	Long: `Periodically read port_xmit_data/port_rcv_data of every configured HCA on
+all hosts and print the per-HCA bandwidth in Gbps. This is mainly useful
+while an infinite run (run.infinitely: true) is in progress, where no
+report is produced.
+
+Examples:
+  # Sample every 5 seconds until Ctrl-C
+  xnetperf sample
+
+  # Sample every 2 seconds for 10 minutes and write a CSV file
+  xnetperf sample --interval 2 --duration 600 --csv bandwidth.csv
+
+  # Store the samples in a recorded run (runs/<id>/samples.csv)
+  xnetperf sample --run 20250101-120000-config-bandwidth`,
+	Args: cobra.NoArgs,
+	Run:  runSample,
+}
+
+func init() {
+	sampleCmd.Flags().IntVar(&sampleInterval, "interval", int(sampler.DefaultInterval/time.Second), "Sampling interval in seconds")
+	sampleCmd.Flags().IntVar(&sampleDuration, "duration", 0, "Stop after this many seconds (0 to sample until Ctrl-C)")
+	sampleCmd.Flags().StringVar(&sampleCSV, "csv", "", "Write samples to this CSV file")
+	sampleCmd.Flags().StringVar(&sampleRunID, "run", "", "Write samples to the directory of this recorded run")
+	sampleCmd.Flags().BoolVar(&sampleQuiet, "quiet", false, "Do not print samples to the terminal")
+}
+
+func runSample(cmd *cobra.Command, args []string) {
+	cfg := GetConfig()
+	if sampleInterval <= 0 {
+		fmt.Println("❌ --interval must be positive")
+		os.Exit(1)
+	}
+
+	csvPath := sampleCSV
+	if csvPath == "" && sampleRunID != "" {
+		runStore := openRunStore()
+		if _, err := runStore.Get(sampleRunID); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		csvPath = filepath.Join(runStore.Dir(sampleRunID), samplesFile)
+	}
+
+	var sinks []func([]sampler.Sample)
+	if !sampleQuiet {
+		sinks = append(sinks, sampler.Display)
+	}
+	if csvPath != "" {
+		csvWriter, err := sampler.NewCSVWriter(csvPath)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		defer csvWriter.Close()
+		sinks = append(sinks, func(samples []sampler.Sample) {
+			if err := csvWriter.Write(samples); err != nil {
+				fmt.Printf("⚠️  Failed to write samples: %v\n", err)
+			}
+		})
+		fmt.Printf("📝 Writing samples to %s\n", csvPath)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if sampleDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(sampleDuration)*time.Second)
+		defer cancel()
+	}
+
+	fmt.Printf("📈 Sampling bandwidth every %ds, press Ctrl-C to stop\n", sampleInterval)
+	sampler.New(cfg).Run(ctx, time.Duration(sampleInterval)*time.Second, sinks...)
+}
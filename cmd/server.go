@@ -3,26 +3,80 @@ package cmd
 import (
 	"fmt"
 	"xnetperf/config"
+	"xnetperf/internal/auth"
+	"xnetperf/internal/jobs"
+	"xnetperf/internal/store"
 	"xnetperf/server"
 
 	"github.com/spf13/cobra"
 )
 
-var serverPort int
+var (
+	serverPort       int
+	serverAddress    string
+	serverJobWorkers int
+	serverCORSOrigin []string
+
+	tlsCertFile       string
+	tlsKeyFile        string
+	tlsClientCAFile   string
+	tlsClientAuth     string
+	tlsClientCertRole string
+
+	authTokensFile string
+	authUsersFile  string
+	oidcIssuer     string
+	oidcJWKSURL    string
+	oidcAudience   string
+	oidcRoleClaim  string
+	oidcRoleMap    map[string]string
+)
 
 var serverCmd = &cobra.Command{
 	Use:   "server",
 	Short: "Start HTTP API server for configuration management",
 	Long: `Start an HTTP API server that provides endpoints for managing configuration files.
+
+Every option can also be set in the http_server section of the config file;
+flags given on the command line take precedence.
+
+HTTPS is enabled with --tls-cert and --tls-key. The certificate is reloaded
+automatically when the files change. With --tls-client-ca clients must present
+a certificate signed by that CA.
+
+Authentication is enabled by configuring at least one of API tokens, a users
+file, an OIDC issuer or a client certificate role. Callers get one of the roles
+viewer (read configs and reports), operator (run tests) or admin (edit configs
+and dictionaries).
+
 Example:
   xnetperf server
-  xnetperf server --port 8080`,
+  xnetperf server --port 8080 --address 127.0.0.1
+  xnetperf server --tls-cert server.crt --tls-key server.key --cors-origin https://portal.example.com
+  xnetperf server --auth-tokens-file tokens.txt --auth-users-file users.htpasswd
+  xnetperf server --oidc-issuer https://sso.example.com/realms/ops --oidc-audience xnetperf \
+    --oidc-role-claim groups --oidc-role-map netops=operator,netops-admins=admin`,
 	Run: runServer,
 }
 
 func init() {
 	rootCmd.AddCommand(serverCmd)
-	serverCmd.Flags().IntVarP(&serverPort, "port", "p", 8080, "HTTP server port")
+	serverCmd.Flags().IntVarP(&serverPort, "port", "p", server.DefaultPort, "HTTP server port")
+	serverCmd.Flags().StringVar(&serverAddress, "address", "", "Address to listen on (default: all interfaces)")
+	serverCmd.Flags().IntVar(&serverJobWorkers, "job-workers", jobs.DefaultWorkers, "Maximum number of jobs (precheck, run, collect, connectivity) running at the same time")
+	serverCmd.Flags().StringSliceVar(&serverCORSOrigin, "cors-origin", nil, "Origin allowed to call the API from another site, e.g. https://portal.example.com (repeatable; default: same origin only)")
+	serverCmd.Flags().StringVar(&tlsCertFile, "tls-cert", "", "PEM certificate file; enables HTTPS and is reloaded when it changes")
+	serverCmd.Flags().StringVar(&tlsKeyFile, "tls-key", "", "PEM private key file of the certificate")
+	serverCmd.Flags().StringVar(&tlsClientCAFile, "tls-client-ca", "", "PEM CA bundle that verifies client certificates")
+	serverCmd.Flags().StringVar(&tlsClientAuth, "tls-client-auth", "", "Client certificate policy with --tls-client-ca: require or optional (default: require)")
+	serverCmd.Flags().StringVar(&tlsClientCertRole, "tls-client-cert-role", "", "Role granted to clients with a verified certificate: viewer, operator or admin")
+	serverCmd.Flags().StringVar(&authTokensFile, "auth-tokens-file", "", "File of static API tokens, one name:role:token per line")
+	serverCmd.Flags().StringVar(&authUsersFile, "auth-users-file", "", "File of HTTP basic users, one username:bcrypt-hash[:role] per line (htpasswd -B format)")
+	serverCmd.Flags().StringVar(&oidcIssuer, "oidc-issuer", "", "OIDC issuer URL whose signed JWTs are accepted as bearer tokens")
+	serverCmd.Flags().StringVar(&oidcJWKSURL, "oidc-jwks-url", "", "JWKS URL of the OIDC issuer (default: discovered from the issuer)")
+	serverCmd.Flags().StringVar(&oidcAudience, "oidc-audience", "", "Required audience (aud claim) of OIDC tokens")
+	serverCmd.Flags().StringVar(&oidcRoleClaim, "oidc-role-claim", auth.DefaultRoleClaim, "OIDC token claim holding the role or groups; nested claims use dots")
+	serverCmd.Flags().StringToStringVar(&oidcRoleMap, "oidc-role-map", nil, "Map role claim values to roles, e.g. netops=operator,netops-admins=admin (default: values are role names)")
 }
 
 func runServer(cmd *cobra.Command, args []string) {
@@ -31,8 +85,131 @@ func runServer(cmd *cobra.Command, args []string) {
 		fmt.Printf("Warning: %v\n", err)
 	}
 
-	srv := server.NewServer(serverPort)
+	httpConfig := serverConfigFromFlags(cmd)
+	if err := httpConfig.Validate(); err != nil {
+		fmt.Printf("Invalid server configuration: %v\n", err)
+		return
+	}
+
+	runStore, err := store.Open(runsDir)
+	if err != nil {
+		fmt.Printf("Failed to open run store: %v\n", err)
+		return
+	}
+
+	authenticator, err := buildAuthenticator(httpConfig)
+	if err != nil {
+		fmt.Printf("Failed to configure authentication: %v\n", err)
+		return
+	}
+
+	jobManager := jobs.New(serverJobWorkers, jobs.DefaultQueueSize)
+	defer jobManager.Shutdown()
+
+	srv := server.NewServer(httpConfig, runStore, jobManager)
+	if authenticator != nil {
+		srv.WithAuth(authenticator)
+	} else {
+		fmt.Println("Warning: authentication is disabled, anyone who can reach the server can run tests on the fleet")
+	}
 	if err := srv.Start(); err != nil {
 		fmt.Printf("Failed to start server: %v\n", err)
 	}
 }
+
+// serverConfigFromFlags returns the http_server section of the config file
+// with the flags given on the command line applied on top
+func serverConfigFromFlags(cmd *cobra.Command) config.HTTPServer {
+	var httpConfig config.HTTPServer
+	if cfg != nil {
+		httpConfig = cfg.HTTPServer
+	}
+
+	flags := cmd.Flags()
+	setString := func(name string, target *string, value string) {
+		if flags.Changed(name) {
+			*target = value
+		}
+	}
+
+	if flags.Changed("port") || httpConfig.Port == 0 {
+		httpConfig.Port = serverPort
+	}
+	setString("address", &httpConfig.Address, serverAddress)
+	if flags.Changed("cors-origin") {
+		httpConfig.CORSOrigins = serverCORSOrigin
+	}
+
+	setString("tls-cert", &httpConfig.TLS.CertFile, tlsCertFile)
+	setString("tls-key", &httpConfig.TLS.KeyFile, tlsKeyFile)
+	setString("tls-client-ca", &httpConfig.TLS.ClientCAFile, tlsClientCAFile)
+	setString("tls-client-auth", &httpConfig.TLS.ClientAuth, tlsClientAuth)
+	setString("tls-client-cert-role", &httpConfig.TLS.ClientCertRole, tlsClientCertRole)
+
+	setString("auth-tokens-file", &httpConfig.Auth.TokensFile, authTokensFile)
+	setString("auth-users-file", &httpConfig.Auth.UsersFile, authUsersFile)
+	setString("oidc-issuer", &httpConfig.Auth.OIDC.Issuer, oidcIssuer)
+	setString("oidc-jwks-url", &httpConfig.Auth.OIDC.JWKSURL, oidcJWKSURL)
+	setString("oidc-audience", &httpConfig.Auth.OIDC.Audience, oidcAudience)
+	if flags.Changed("oidc-role-claim") || httpConfig.Auth.OIDC.RoleClaim == "" {
+		httpConfig.Auth.OIDC.RoleClaim = oidcRoleClaim
+	}
+	if flags.Changed("oidc-role-map") {
+		httpConfig.Auth.OIDC.RoleMap = oidcRoleMap
+	}
+	return httpConfig
+}
+
+// buildAuthenticator chains the configured authenticators; it returns nil when
+// no authentication is configured
+func buildAuthenticator(httpConfig config.HTTPServer) (auth.Authenticator, error) {
+	var chain auth.Chain
+
+	if httpConfig.Auth.TokensFile != "" {
+		tokens, err := auth.LoadTokenFile(httpConfig.Auth.TokensFile)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, tokens)
+	}
+
+	if oidcConfig := httpConfig.Auth.OIDC; oidcConfig.Issuer != "" {
+		oidc, err := auth.NewOIDCAuthenticator(auth.OIDCConfig{
+			Issuer:    oidcConfig.Issuer,
+			JWKSURL:   oidcConfig.JWKSURL,
+			Audience:  oidcConfig.Audience,
+			RoleClaim: oidcConfig.RoleClaim,
+			RoleMap:   oidcConfig.RoleMap,
+		})
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, oidc)
+	}
+
+	if httpConfig.Auth.UsersFile != "" {
+		users, err := auth.LoadUsersFile(httpConfig.Auth.UsersFile)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, users)
+	}
+
+	// Client certificates come last so that explicit credentials sent by a
+	// client with a certificate decide its role
+	if httpConfig.TLS.ClientCertRole != "" {
+		if httpConfig.TLS.ClientCAFile == "" {
+			return nil, fmt.Errorf("client certificate role requires a client CA")
+		}
+		role, err := auth.ParseRole(httpConfig.TLS.ClientCertRole)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, auth.NewCertAuthenticator(role))
+	}
+
+	if len(chain) == 0 {
+		return nil, nil
+	}
+	return chain, nil
+}
@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
 	"sync"
 	"xnetperf/config"
+	"xnetperf/internal/audit"
 	"xnetperf/pkg/tools"
 
 	"github.com/spf13/cobra"
@@ -40,7 +42,7 @@ func handleStopCommand(cfg *config.Config) {
 
 			fmt.Printf("-> Contacting %s...\n", h)
 			cmd := tools.BuildSSHCommand(h, commandToStop, cfg.SSH.PrivateKey, cfg.SSH.User)
-			output, err := cmd.CombinedOutput()
+			output, err := audit.CombinedOutput(context.Background(), h, commandToStop, cmd)
 
 			if err != nil {
 				fmt.Printf("command failed on %s. Error: %v\n", hostname, err)
@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
 	"sync"
 	"xnetperf/config"
+	"xnetperf/internal/audit"
 	"xnetperf/pkg/tools"
 
 	"github.com/spf13/cobra"
@@ -46,7 +48,7 @@ func handleStopLatCommand(cfg *config.Config) {
 
 			fmt.Printf("-> Contacting %s...\n", h)
 			cmd := tools.BuildSSHCommand(h, commandToStop, cfg.SSH.PrivateKey, cfg.SSH.User)
-			output, err := cmd.CombinedOutput()
+			output, err := audit.CombinedOutput(context.Background(), h, commandToStop, cmd)
 
 			if err != nil {
 				// Check if the "error" is simply because the process wasn't running.
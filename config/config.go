@@ -1,15 +1,14 @@
 package config
 
 import (
-	"context"
 	"fmt"
+	"net/url"
 	"os"
-	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
-	"sync"
-	"xnetperf/internal/tools"
 
-	"golang.org/x/sync/errgroup"
 	"gopkg.in/yaml.v3"
 )
 
@@ -32,6 +31,7 @@ type Config struct {
 	RdmaCm             bool         `yaml:"rdma_cm" json:"rdma_cm"`
 	GidIndex           int          `yaml:"gid_index" json:"gid_index"`                 // GID index for RoCE v2
 	NetworkInterface   string       `yaml:"network_interface" json:"network_interface"` // Network interface name for IP detection
+	IBPort             int          `yaml:"ib_port,omitempty" json:"ib_port,omitempty"` // HCA port the tests run on and whose counters are read; 0 means port 1
 	Report             Report       `yaml:"report" json:"report"`
 	Run                Run          `yaml:"run" json:"run"`
 	SSH                SSH          `yaml:"ssh" json:"ssh"`
@@ -39,6 +39,13 @@ type Config struct {
 	Server             ServerConfig `yaml:"server" json:"server"`
 	Client             ClientConfig `yaml:"client" json:"client"`
 	Version            string       `yaml:"version" json:"version"`
+	Precheck           Precheck     `yaml:"precheck,omitempty" json:"precheck"`
+	// HTTPServer configures `xnetperf server`; it is not exposed through the
+	// config API because it describes the server itself, not a test
+	HTTPServer HTTPServer `yaml:"http_server,omitempty" json:"-"`
+	// MetricsPush configures pushing CLI results to Prometheus; it is not
+	// exposed through the config API because its headers may hold credentials
+	MetricsPush MetricsPush `yaml:"metrics_push,omitempty" json:"-"`
 }
 
 func (cfg *Config) ALLHosts() map[string]bool {
@@ -53,6 +60,35 @@ func (cfg *Config) ALLHosts() map[string]bool {
 	return allHosts
 }
 
+// HCAPort returns the HCA port the tests run on and whose counters are read
+func (cfg *Config) HCAPort() int {
+	if cfg.IBPort > 0 {
+		return cfg.IBPort
+	}
+	return 1
+}
+
+// HostHCAs returns the HCAs of every server and client host; a host that is
+// both server and client gets the union of both HCA lists
+func (cfg *Config) HostHCAs() map[string][]string {
+	hostHCAs := make(map[string][]string)
+	add := func(hosts, hcas []string) {
+		for _, host := range hosts {
+			if _, ok := hostHCAs[host]; !ok {
+				hostHCAs[host] = []string{}
+			}
+			for _, hca := range hcas {
+				if !slices.Contains(hostHCAs[host], hca) {
+					hostHCAs[host] = append(hostHCAs[host], hca)
+				}
+			}
+		}
+	}
+	add(cfg.Server.Hostname, cfg.Server.Hca)
+	add(cfg.Client.Hostname, cfg.Client.Hca)
+	return hostHCAs
+}
+
 type Report struct {
 	Enable bool   `yaml:"enable" json:"enable"`
 	Dir    string `yaml:"dir" json:"dir"`
@@ -120,6 +156,421 @@ type ClientConfig struct {
 	Hca      []string `yaml:"hca" json:"hca"`
 }
 
+// HTTPServer holds the settings of the `xnetperf server` HTTP API
+type HTTPServer struct {
+	Address     string   `yaml:"address,omitempty"`      // Bind address, empty for all interfaces
+	Port        int      `yaml:"port,omitempty"`         // Listen port, 8080 when unset
+	CORSOrigins []string `yaml:"cors_origins,omitempty"` // Origins allowed to call the API from other sites
+	TLS         TLS      `yaml:"tls,omitempty"`
+	Auth        HTTPAuth `yaml:"auth,omitempty"`
+}
+
+// TLS holds the HTTPS settings of the HTTP server
+type TLS struct {
+	CertFile     string `yaml:"cert_file,omitempty"`      // PEM certificate chain, reloaded when it changes
+	KeyFile      string `yaml:"key_file,omitempty"`       // PEM private key, reloaded when it changes
+	ClientCAFile string `yaml:"client_ca_file,omitempty"` // CA bundle that verifies client certificates
+	ClientAuth   string `yaml:"client_auth,omitempty"`    // Client certificate policy: require or optional
+	// ClientCertRole is the role granted to callers with a verified client
+	// certificate when authentication is enabled
+	ClientCertRole string `yaml:"client_cert_role,omitempty"`
+}
+
+// HTTPAuth holds the authentication settings of the HTTP server
+type HTTPAuth struct {
+	TokensFile string   `yaml:"tokens_file,omitempty"` // Static API tokens, one name:role:token per line
+	UsersFile  string   `yaml:"users_file,omitempty"`  // Basic users, one username:bcrypt-hash[:role] per line
+	OIDC       HTTPOIDC `yaml:"oidc,omitempty"`
+}
+
+// HTTPOIDC holds the OIDC/JWT settings of the HTTP server
+type HTTPOIDC struct {
+	Issuer    string            `yaml:"issuer,omitempty"`
+	JWKSURL   string            `yaml:"jwks_url,omitempty"`
+	Audience  string            `yaml:"audience,omitempty"`
+	RoleClaim string            `yaml:"role_claim,omitempty"`
+	RoleMap   map[string]string `yaml:"role_map,omitempty"`
+}
+
+// Enabled returns true if HTTPS is configured
+func (t *TLS) Enabled() bool {
+	return t.CertFile != "" || t.KeyFile != ""
+}
+
+// Validate checks that the HTTP server settings are consistent
+func (h *HTTPServer) Validate() error {
+	if h.Port < 0 || h.Port > 65535 {
+		return fmt.Errorf("invalid http_server port %d", h.Port)
+	}
+	if (h.TLS.CertFile == "") != (h.TLS.KeyFile == "") {
+		return fmt.Errorf("http_server tls requires both cert_file and key_file")
+	}
+	if h.TLS.ClientCAFile != "" && !h.TLS.Enabled() {
+		return fmt.Errorf("http_server tls client_ca_file requires cert_file and key_file")
+	}
+	switch strings.ToLower(h.TLS.ClientAuth) {
+	case "", "require", "optional":
+	default:
+		return fmt.Errorf("invalid http_server tls client_auth '%s', must be 'require' or 'optional'", h.TLS.ClientAuth)
+	}
+	if h.TLS.ClientAuth != "" && h.TLS.ClientCAFile == "" {
+		return fmt.Errorf("http_server tls client_auth requires client_ca_file")
+	}
+	for _, origin := range h.CORSOrigins {
+		if origin == "*" {
+			continue
+		}
+		if strings.Contains(origin, "*") || !(strings.HasPrefix(origin, "http://") || strings.HasPrefix(origin, "https://")) {
+			return fmt.Errorf("invalid http_server cors origin '%s', must be '*' or start with http:// or https://", origin)
+		}
+	}
+	return nil
+}
+
+// Precheck holds the expected values of the extended precheck checks
+type Precheck struct {
+	PCIe       PCIeCheck       `yaml:"pcie,omitempty" json:"pcie"`
+	RoCE       RoCECheck       `yaml:"roce,omitempty" json:"roce"`
+	Perftest   PerftestCheck   `yaml:"perftest,omitempty" json:"perftest"`
+	HostTuning HostTuningCheck `yaml:"host_tuning,omitempty" json:"host_tuning"`
+	Fix        PrecheckFix     `yaml:"fix,omitempty" json:"fix"`
+	Firmware   FirmwareCheck   `yaml:"firmware,omitempty" json:"firmware"`
+
+	// Hosts that passed a precheck less than this many seconds ago are not
+	// checked again; their cached results are reused. 0 checks every host.
+	CacheTTLSeconds int `yaml:"cache_ttl_seconds,omitempty" json:"cache_ttl_seconds,omitempty"`
+
+	// What a test run does when precheck finds unhealthy HCAs: abort, warn
+	// or exclude. Unset keeps the default of the command running the test.
+	UnhealthyHCA string `yaml:"unhealthy_hca,omitempty" json:"unhealthy_hca,omitempty"`
+}
+
+// Policies for unhealthy HCAs found by the precheck of a test run
+const (
+	UnhealthyHCAAbort   = "abort"   // Stop the run
+	UnhealthyHCAWarn    = "warn"    // Report them and run the full test plan
+	UnhealthyHCAExclude = "exclude" // Drop every flow touching them and run on the healthy HCAs
+)
+
+// UnhealthyHCAPolicy returns the configured unhealthy HCA policy, or def if unset
+func (p *Precheck) UnhealthyHCAPolicy(def string) string {
+	if p.UnhealthyHCA == "" {
+		return def
+	}
+	return p.UnhealthyHCA
+}
+
+// Values of the PCIe relaxed_ordering and acs expectations
+const (
+	PCIeEnabled  = "enabled"
+	PCIeDisabled = "disabled"
+)
+
+// PCIeCheck holds the expected PCIe link and device settings of the HCAs.
+// The link must always run at the maximum speed and width of the device;
+// the other settings are only checked when set.
+type PCIeCheck struct {
+	Speed           float64        `yaml:"speed,omitempty" json:"speed,omitempty"`                       // Expected link speed in GT/s, e.g. 32 for Gen5
+	Width           int            `yaml:"width,omitempty" json:"width,omitempty"`                       // Expected link width, e.g. 16
+	NUMANodes       map[string]int `yaml:"numa_nodes,omitempty" json:"numa_nodes,omitempty"`             // Expected NUMA node per HCA, e.g. mlx5_0: 0
+	RelaxedOrdering string         `yaml:"relaxed_ordering,omitempty" json:"relaxed_ordering,omitempty"` // enabled or disabled
+	ACS             string         `yaml:"acs,omitempty" json:"acs,omitempty"`                           // enabled or disabled, on the upstream bridge of the HCA
+}
+
+// Trust modes of RoCE interfaces
+const (
+	TrustPCP  = "pcp"
+	TrustDSCP = "dscp"
+)
+
+// RoCECheck holds the expected RoCE settings of the interfaces of Ethernet
+// HCAs. The GID at gid_index must always be a RoCE v2 GID with an
+// IPv4-mapped address; the other settings are only checked when set.
+type RoCECheck struct {
+	MTU           int    `yaml:"mtu,omitempty" json:"mtu,omitempty"`                       // Expected MTU of the netdev, e.g. 9000
+	Trust         string `yaml:"trust,omitempty" json:"trust,omitempty"`                   // Expected trust mode: pcp or dscp
+	PFCPriorities []int  `yaml:"pfc_priorities,omitempty" json:"pfc_priorities,omitempty"` // Priorities PFC is enabled on, and only on, e.g. [3]
+	ECNPriorities []int  `yaml:"ecn_priorities,omitempty" json:"ecn_priorities,omitempty"` // Priorities ECN must be enabled on
+}
+
+// DefaultPerftestInstallDir is the directory bundled perftest binaries are installed to
+const DefaultPerftestInstallDir = "/usr/local/bin"
+
+// PerftestCheck configures how missing perftest binaries are handled. The
+// binaries are always checked; they are only pushed when BundleDir is set.
+type PerftestCheck struct {
+	BundleDir  string `yaml:"bundle_dir,omitempty" json:"bundle_dir,omitempty"`   // Local directory of binaries pushed to hosts that lack them, e.g. perftest
+	InstallDir string `yaml:"install_dir,omitempty" json:"install_dir,omitempty"` // Remote directory they are installed to, default /usr/local/bin
+}
+
+// IOMMU modes of the host tuning check
+const (
+	IOMMUPassthrough = "passthrough"
+	IOMMUTranslated  = "translated"
+	IOMMUOff         = "off"
+)
+
+// MemlockUnlimited is the memlock limit of hosts without a locked memory limit
+const MemlockUnlimited = "unlimited"
+
+// HostTuningCheck is the expected tuning profile of the hosts. The checks only
+// run when Enabled is set; the settings are then reported and each one that
+// is set is compared with the host.
+type HostTuningCheck struct {
+	Enabled      bool   `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	CPUGovernor  string `yaml:"cpu_governor,omitempty" json:"cpu_governor,omitempty"`   // Expected cpufreq governor of all CPUs, e.g. performance
+	MaxCState    int    `yaml:"max_cstate,omitempty" json:"max_cstate,omitempty"`       // Deepest C-state that may be enabled, e.g. 1
+	IOMMU        string `yaml:"iommu,omitempty" json:"iommu,omitempty"`                 // passthrough, translated or off
+	MinHugePages int    `yaml:"min_hugepages,omitempty" json:"min_hugepages,omitempty"` // Minimum number of free hugepages
+	Memlock      string `yaml:"memlock,omitempty" json:"memlock,omitempty"`             // ulimit -l: unlimited or the minimum in KB
+	IRQAffinity  bool   `yaml:"irq_affinity,omitempty" json:"irq_affinity,omitempty"`   // Require the IRQs of each HCA to be pinned to its local NUMA node
+}
+
+// FirmwareCheck pins the expected firmware version per board type. HCAs of a
+// pinned board type must run the pinned version; the other board types are
+// expected to run the version most of their HCAs run.
+type FirmwareCheck struct {
+	Pinned map[string]string `yaml:"pinned,omitempty" json:"pinned,omitempty"` // board_id -> fw_ver, e.g. MT_0000000838: 28.39.1002
+}
+
+// Remediations precheck --fix can apply, one per failure class
+const (
+	FixPortUp  = "port_up" // Bring up the netdev of HCAs whose port is down
+	FixMTU     = "mtu"     // Set the MTU of RoCE netdevs to precheck.roce.mtu
+	FixMemlock = "memlock" // Persistently raise the memlock limit of SSH sessions to precheck.host_tuning.memlock
+	FixModule  = "module"  // Load the kernel modules of hosts with missing HCAs
+)
+
+// FixActions are all remediations precheck --fix can apply
+var FixActions = []string{FixPortUp, FixMTU, FixMemlock, FixModule}
+
+// DefaultFixModules are the kernel modules loaded on hosts with missing HCAs
+var DefaultFixModules = []string{"mlx5_core", "mlx5_ib", "ib_uverbs", "ib_umad", "rdma_ucm"}
+
+// PrecheckFix selects the remediations precheck --fix applies. They only run
+// when --fix is given; without Actions all of them except memlock are
+// enabled. memlock writes a limits.d file that survives reboots, so it must
+// be listed explicitly.
+type PrecheckFix struct {
+	Actions []string `yaml:"actions,omitempty" json:"actions,omitempty"` // port_up, mtu, memlock and/or module
+	Modules []string `yaml:"modules,omitempty" json:"modules,omitempty"` // Kernel modules the module action loads, default DefaultFixModules
+}
+
+// fixModuleName matches the kernel module names the module action may load
+var fixModuleName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ValidFixModule reports whether name is a kernel module name the module
+// action may pass to modprobe
+func ValidFixModule(name string) bool {
+	return fixModuleName.MatchString(name)
+}
+
+// Enabled returns true if the remediation may be applied
+func (f *PrecheckFix) Enabled(action string) bool {
+	if action == FixMemlock {
+		return slices.Contains(f.Actions, action)
+	}
+	return len(f.Actions) == 0 || slices.Contains(f.Actions, action)
+}
+
+// Validate checks the precheck expectations
+func (p *Precheck) Validate() error {
+	if p.CacheTTLSeconds < 0 {
+		return fmt.Errorf("invalid precheck cache_ttl_seconds %d", p.CacheTTLSeconds)
+	}
+	switch p.UnhealthyHCA {
+	case "", UnhealthyHCAAbort, UnhealthyHCAWarn, UnhealthyHCAExclude:
+	default:
+		return fmt.Errorf("invalid precheck unhealthy_hca %q, must be %s, %s or %s",
+			p.UnhealthyHCA, UnhealthyHCAAbort, UnhealthyHCAWarn, UnhealthyHCAExclude)
+	}
+
+	if p.PCIe.Speed < 0 {
+		return fmt.Errorf("invalid precheck pcie speed %v", p.PCIe.Speed)
+	}
+	switch p.PCIe.Width {
+	case 0, 1, 2, 4, 8, 16, 32:
+	default:
+		return fmt.Errorf("invalid precheck pcie width %d, must be 1, 2, 4, 8, 16 or 32", p.PCIe.Width)
+	}
+	for hca, node := range p.PCIe.NUMANodes {
+		if node < 0 {
+			return fmt.Errorf("invalid precheck pcie numa node %d of %s", node, hca)
+		}
+	}
+	if err := validatePCIeSetting("relaxed_ordering", p.PCIe.RelaxedOrdering); err != nil {
+		return err
+	}
+	if err := validatePCIeSetting("acs", p.PCIe.ACS); err != nil {
+		return err
+	}
+
+	if p.RoCE.MTU < 0 {
+		return fmt.Errorf("invalid precheck roce mtu %d", p.RoCE.MTU)
+	}
+	switch p.RoCE.Trust {
+	case "", TrustPCP, TrustDSCP:
+	default:
+		return fmt.Errorf("invalid precheck roce trust '%s', must be '%s' or '%s'", p.RoCE.Trust, TrustPCP, TrustDSCP)
+	}
+	if err := validatePriorities("pfc_priorities", p.RoCE.PFCPriorities); err != nil {
+		return err
+	}
+	if err := validatePriorities("ecn_priorities", p.RoCE.ECNPriorities); err != nil {
+		return err
+	}
+
+	if dir := p.Perftest.InstallDir; dir != "" && !strings.HasPrefix(dir, "/") {
+		return fmt.Errorf("invalid precheck perftest install_dir '%s', must be an absolute path", dir)
+	}
+
+	tuning := p.HostTuning
+	if tuning.MaxCState < 0 {
+		return fmt.Errorf("invalid precheck host_tuning max_cstate %d", tuning.MaxCState)
+	}
+	switch tuning.IOMMU {
+	case "", IOMMUPassthrough, IOMMUTranslated, IOMMUOff:
+	default:
+		return fmt.Errorf("invalid precheck host_tuning iommu '%s', must be '%s', '%s' or '%s'", tuning.IOMMU, IOMMUPassthrough, IOMMUTranslated, IOMMUOff)
+	}
+	if tuning.MinHugePages < 0 {
+		return fmt.Errorf("invalid precheck host_tuning min_hugepages %d", tuning.MinHugePages)
+	}
+	if tuning.Memlock != "" && tuning.Memlock != MemlockUnlimited {
+		if kb, err := strconv.Atoi(tuning.Memlock); err != nil || kb <= 0 {
+			return fmt.Errorf("invalid precheck host_tuning memlock '%s', must be '%s' or a size in KB", tuning.Memlock, MemlockUnlimited)
+		}
+	}
+
+	for boardID, fwVer := range p.Firmware.Pinned {
+		if boardID == "" || strings.TrimSpace(fwVer) == "" {
+			return fmt.Errorf("invalid precheck firmware pin '%s: %s'", boardID, fwVer)
+		}
+	}
+
+	for _, action := range p.Fix.Actions {
+		if !slices.Contains(FixActions, action) {
+			return fmt.Errorf("invalid precheck fix action '%s', must be one of %s", action, strings.Join(FixActions, ", "))
+		}
+	}
+	for _, module := range p.Fix.Modules {
+		if !ValidFixModule(module) {
+			return fmt.Errorf("invalid precheck fix module '%s', must match %s", module, fixModuleName)
+		}
+	}
+	return nil
+}
+
+func validatePriorities(name string, priorities []int) error {
+	seen := make(map[int]bool)
+	for _, priority := range priorities {
+		if priority < 0 || priority > 7 {
+			return fmt.Errorf("invalid precheck roce %s priority %d, must be 0-7", name, priority)
+		}
+		if seen[priority] {
+			return fmt.Errorf("duplicate precheck roce %s priority %d", name, priority)
+		}
+		seen[priority] = true
+	}
+	return nil
+}
+
+func validatePCIeSetting(name, value string) error {
+	if value != "" && value != PCIeEnabled && value != PCIeDisabled {
+		return fmt.Errorf("invalid precheck pcie %s '%s', must be '%s' or '%s'", name, value, PCIeEnabled, PCIeDisabled)
+	}
+	return nil
+}
+
+// Metrics push modes
+const (
+	PushModePushgateway = "pushgateway"
+	PushModeRemoteWrite = "remote_write"
+)
+
+// MetricsPush holds where the CLI pushes precheck, bandwidth and latency
+// results after each run
+type MetricsPush struct {
+	URL            string            `yaml:"url,omitempty"`             // Pushgateway base URL or remote-write endpoint; empty disables pushing
+	Mode           string            `yaml:"mode,omitempty"`            // pushgateway (default) or remote_write
+	Job            string            `yaml:"job,omitempty"`             // Job label, xnetperf when unset
+	Labels         map[string]string `yaml:"labels,omitempty"`          // Extra labels, the Pushgateway grouping key
+	Headers        map[string]string `yaml:"headers,omitempty"`         // Extra HTTP headers, e.g. Authorization or X-Scope-OrgID
+	TimeoutSeconds int               `yaml:"timeout_seconds,omitempty"` // Request timeout, 10 when unset
+}
+
+// Enabled returns true if a push URL is configured
+func (m *MetricsPush) Enabled() bool {
+	return m.URL != ""
+}
+
+// Validate checks the metrics push settings
+func (m *MetricsPush) Validate() error {
+	if !m.Enabled() {
+		return nil
+	}
+	if !strings.HasPrefix(m.URL, "http://") && !strings.HasPrefix(m.URL, "https://") {
+		return fmt.Errorf("invalid metrics_push url '%s', must start with http:// or https://", m.URL)
+	}
+	switch m.Mode {
+	case "", PushModePushgateway, PushModeRemoteWrite:
+	default:
+		return fmt.Errorf("invalid metrics_push mode '%s', must be '%s' or '%s'", m.Mode, PushModePushgateway, PushModeRemoteWrite)
+	}
+	for name := range m.Labels {
+		if !isLabelName(name) || strings.HasPrefix(name, "__") {
+			return fmt.Errorf("invalid metrics_push label name '%s'", name)
+		}
+	}
+	if m.TimeoutSeconds < 0 {
+		return fmt.Errorf("invalid metrics_push timeout_seconds %d", m.TimeoutSeconds)
+	}
+	return nil
+}
+
+// RedactedValue replaces secrets in config snapshots
+const RedactedValue = "REDACTED"
+
+// Redacted returns a copy of the config with secrets, the metrics_push
+// header values and URL password, replaced by RedactedValue; used for config
+// snapshots written to disk
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redacted.MetricsPush = c.MetricsPush.redacted()
+	return &redacted
+}
+
+func (m MetricsPush) redacted() MetricsPush {
+	if len(m.Headers) > 0 {
+		headers := make(map[string]string, len(m.Headers))
+		for name := range m.Headers {
+			headers[name] = RedactedValue
+		}
+		m.Headers = headers
+	}
+	if u, err := url.Parse(m.URL); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), RedactedValue)
+			m.URL = u.String()
+		}
+	}
+	return m
+}
+
+// isLabelName reports whether name is a valid Prometheus label name
+func isLabelName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
 func (c *Config) IsFullMesh() bool {
 	return c.StreamType == FullMesh
 }
@@ -146,6 +597,9 @@ func LoadConfig(filePath string) (*Config, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse YAML content from '%s': %w", filePath, err)
 	}
+	if err := cfg.Precheck.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config '%s': %w", filePath, err)
+	}
 	return &cfg, nil
 }
 
@@ -269,101 +723,3 @@ func EnsureConfigFile(filePath string) error {
 	}
 	return nil
 }
-
-// LookupClientHostsIP retrieves the IP addresses of all client hosts
-func (cfg *Config) LookupClientHostsIP() (map[string]string, error) {
-	ipMap := make(map[string]string)
-	mu := sync.Mutex{}
-
-	g, _ := errgroup.WithContext(context.Background())
-
-	for _, host := range cfg.Client.Hostname {
-		host := host // capture loop variable
-		g.Go(func() error {
-			// Check if host is already an IP address
-			if tools.IsValidIP(host) {
-				mu.Lock()
-				ipMap[host] = host
-				mu.Unlock()
-				return nil
-			}
-
-			ip, err := cfg.getHostIP(host)
-			if err != nil {
-				return err
-			}
-			mu.Lock()
-			ipMap[host] = ip
-			mu.Unlock()
-			return nil
-		})
-	}
-
-	if err := g.Wait(); err != nil {
-		return nil, err
-	}
-
-	return ipMap, nil
-}
-
-// LookupServerHostsIP retrieves the IP addresses of all server hosts
-func (cfg *Config) LookupServerHostsIP() (map[string]string, error) {
-	ipMap := make(map[string]string)
-	mu := sync.Mutex{}
-
-	g, _ := errgroup.WithContext(context.Background())
-
-	for _, host := range cfg.Server.Hostname {
-		host := host // capture loop variable
-		g.Go(func() error {
-			// Check if host is already an IP address
-			if tools.IsValidIP(host) {
-				mu.Lock()
-				ipMap[host] = host
-				mu.Unlock()
-				return nil
-			}
-
-			ip, err := cfg.getHostIP(host)
-			if err != nil {
-				return err
-			}
-			mu.Lock()
-			ipMap[host] = ip
-			mu.Unlock()
-			return nil
-		})
-	}
-
-	if err := g.Wait(); err != nil {
-		return nil, err
-	}
-
-	return ipMap, nil
-}
-
-// getHostIP retrieves the IP address of a host using specified network interface
-func (cfg *Config) getHostIP(hostname string) (string, error) {
-	command := fmt.Sprintf(`ip -4 addr show %s | grep -oP '(?<=inet\s)\d+(\.\d+){3}'`, cfg.NetworkInterface)
-
-	sshWrapper := tools.NewSSHWrapper(hostname).
-		User(cfg.SSH.User).
-		PrivateKey(cfg.SSH.PrivateKey).
-		Command(command)
-
-	fmt.Println(sshWrapper.String())
-
-	cmd := exec.Command("bash", "-c", sshWrapper.String())
-
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("SSH command failed on %s: %v, output: %s", hostname, err, string(output))
-	}
-
-	ip := strings.TrimSpace(string(output))
-	if ip == "" {
-		return "", fmt.Errorf("no IP address found for %s on %s interface", hostname, cfg.NetworkInterface)
-	}
-
-	return ip, nil
-}
@@ -1,6 +1,10 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -186,3 +190,181 @@ func TestNewDefaultConfig(t *testing.T) {
 		t.Errorf("Client.Hca should be empty")
 	}
 }
+
+func TestHTTPServerValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   HTTPServer
+		wantErr bool
+	}{
+		{name: "empty config is valid", input: HTTPServer{}},
+		{
+			name: "tls with client certificates",
+			input: HTTPServer{
+				Address:     "10.0.0.1",
+				Port:        8443,
+				CORSOrigins: []string{"https://portal.example.com"},
+				TLS:         TLS{CertFile: "server.crt", KeyFile: "server.key", ClientCAFile: "ca.crt", ClientAuth: "optional"},
+			},
+		},
+		{name: "allow all origins", input: HTTPServer{CORSOrigins: []string{"*"}}},
+		{name: "invalid port", input: HTTPServer{Port: 70000}, wantErr: true},
+		{name: "cert without key", input: HTTPServer{TLS: TLS{CertFile: "server.crt"}}, wantErr: true},
+		{name: "client CA without tls", input: HTTPServer{TLS: TLS{ClientCAFile: "ca.crt"}}, wantErr: true},
+		{name: "client auth without CA", input: HTTPServer{TLS: TLS{CertFile: "s.crt", KeyFile: "s.key", ClientAuth: "require"}}, wantErr: true},
+		{name: "invalid client auth", input: HTTPServer{TLS: TLS{CertFile: "s.crt", KeyFile: "s.key", ClientCAFile: "ca.crt", ClientAuth: "maybe"}}, wantErr: true},
+		{name: "origin without scheme", input: HTTPServer{CORSOrigins: []string{"portal.example.com"}}, wantErr: true},
+		{name: "wildcard origin", input: HTTPServer{CORSOrigins: []string{"https://*.example.com"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.input.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMetricsPushValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   MetricsPush
+		wantErr bool
+	}{
+		{name: "disabled", input: MetricsPush{Mode: "anything"}},
+		{name: "pushgateway", input: MetricsPush{URL: "http://pushgateway:9091", Labels: map[string]string{"cluster": "a1"}}},
+		{name: "remote write", input: MetricsPush{URL: "https://mimir/api/v1/push", Mode: PushModeRemoteWrite}},
+		{name: "url without scheme", input: MetricsPush{URL: "pushgateway:9091"}, wantErr: true},
+		{name: "invalid mode", input: MetricsPush{URL: "http://pushgateway:9091", Mode: "otlp"}, wantErr: true},
+		{name: "invalid label name", input: MetricsPush{URL: "http://pushgateway:9091", Labels: map[string]string{"1cluster": "a"}}, wantErr: true},
+		{name: "reserved label name", input: MetricsPush{URL: "http://pushgateway:9091", Labels: map[string]string{"__name__": "a"}}, wantErr: true},
+		{name: "negative timeout", input: MetricsPush{URL: "http://pushgateway:9091", TimeoutSeconds: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.input.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPrecheckValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   Precheck
+		wantErr bool
+	}{
+		{name: "empty", input: Precheck{}},
+		{name: "all set", input: Precheck{PCIe: PCIeCheck{Speed: 32, Width: 16, NUMANodes: map[string]int{"mlx5_0": 0}, RelaxedOrdering: PCIeEnabled, ACS: PCIeDisabled}}},
+		{name: "negative speed", input: Precheck{PCIe: PCIeCheck{Speed: -1}}, wantErr: true},
+		{name: "invalid width", input: Precheck{PCIe: PCIeCheck{Width: 12}}, wantErr: true},
+		{name: "negative numa node", input: Precheck{PCIe: PCIeCheck{NUMANodes: map[string]int{"mlx5_0": -1}}}, wantErr: true},
+		{name: "invalid relaxed ordering", input: Precheck{PCIe: PCIeCheck{RelaxedOrdering: "on"}}, wantErr: true},
+		{name: "invalid acs", input: Precheck{PCIe: PCIeCheck{ACS: "off"}}, wantErr: true},
+		{name: "roce", input: Precheck{RoCE: RoCECheck{MTU: 9000, Trust: TrustDSCP, PFCPriorities: []int{3}, ECNPriorities: []int{3, 4}}}},
+		{name: "negative mtu", input: Precheck{RoCE: RoCECheck{MTU: -1}}, wantErr: true},
+		{name: "invalid trust", input: Precheck{RoCE: RoCECheck{Trust: "l2"}}, wantErr: true},
+		{name: "priority out of range", input: Precheck{RoCE: RoCECheck{PFCPriorities: []int{8}}}, wantErr: true},
+		{name: "duplicate priority", input: Precheck{RoCE: RoCECheck{ECNPriorities: []int{3, 3}}}, wantErr: true},
+		{name: "perftest", input: Precheck{Perftest: PerftestCheck{BundleDir: "perftest", InstallDir: "/opt/perftest/bin"}}},
+		{name: "relative install dir", input: Precheck{Perftest: PerftestCheck{InstallDir: "bin"}}, wantErr: true},
+		{name: "host tuning", input: Precheck{HostTuning: HostTuningCheck{Enabled: true, CPUGovernor: "performance", MaxCState: 1, IOMMU: IOMMUPassthrough, MinHugePages: 1024, Memlock: MemlockUnlimited, IRQAffinity: true}}},
+		{name: "memlock in KB", input: Precheck{HostTuning: HostTuningCheck{Memlock: "65536"}}},
+		{name: "invalid memlock", input: Precheck{HostTuning: HostTuningCheck{Memlock: "64M"}}, wantErr: true},
+		{name: "invalid iommu", input: Precheck{HostTuning: HostTuningCheck{IOMMU: "pt"}}, wantErr: true},
+		{name: "negative hugepages", input: Precheck{HostTuning: HostTuningCheck{MinHugePages: -1}}, wantErr: true},
+		{name: "fix", input: Precheck{Fix: PrecheckFix{Actions: []string{FixPortUp, FixMTU}, Modules: []string{"mlx5_ib"}}}},
+		{name: "invalid fix action", input: Precheck{Fix: PrecheckFix{Actions: []string{"reboot"}}}, wantErr: true},
+		{name: "firmware pins", input: Precheck{Firmware: FirmwareCheck{Pinned: map[string]string{"MT_0000000838": "28.39.1002"}}}},
+		{name: "empty firmware pin", input: Precheck{Firmware: FirmwareCheck{Pinned: map[string]string{"MT_0000000838": ""}}}, wantErr: true},
+		{name: "cache ttl", input: Precheck{CacheTTLSeconds: 300}},
+		{name: "negative cache ttl", input: Precheck{CacheTTLSeconds: -1}, wantErr: true},
+		{name: "exclude unhealthy hcas", input: Precheck{UnhealthyHCA: UnhealthyHCAExclude}},
+		{name: "invalid unhealthy hca policy", input: Precheck{UnhealthyHCA: "skip"}, wantErr: true},
+		{name: "invalid fix module", input: Precheck{Fix: PrecheckFix{Modules: []string{"mlx5_ib; reboot"}}}, wantErr: true},
+		{name: "fix module with shell expansion", input: Precheck{Fix: PrecheckFix{Modules: []string{"$(reboot)"}}}, wantErr: true},
+		{name: "fix module with newline", input: Precheck{Fix: PrecheckFix{Modules: []string{"mlx5_ib\nreboot"}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.input.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPrecheckFixEnabled(t *testing.T) {
+	all := PrecheckFix{}
+	for _, action := range FixActions {
+		if want := action != FixMemlock; all.Enabled(action) != want {
+			t.Errorf("Enabled(%q) = %v without actions, want %v", action, !want, want)
+		}
+	}
+
+	memlock := PrecheckFix{Actions: []string{FixMemlock}}
+	if !memlock.Enabled(FixMemlock) {
+		t.Errorf("Enabled(%q) = false with actions %v, want true", FixMemlock, memlock.Actions)
+	}
+
+	some := PrecheckFix{Actions: []string{FixMTU}}
+	if !some.Enabled(FixMTU) || some.Enabled(FixPortUp) {
+		t.Errorf("Enabled() with actions %v = %v/%v, want true/false", some.Actions, some.Enabled(FixMTU), some.Enabled(FixPortUp))
+	}
+}
+
+func TestLoadConfigValidatesPrecheck(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{name: "valid policy", yaml: "precheck:\n  unhealthy_hca: exclude\n"},
+		{name: "misspelled policy", yaml: "precheck:\n  unhealthy_hca: exlude\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "_")+".yaml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadConfig(path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHostHCAs(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{Hostname: []string{"node-01", "node-02"}, Hca: []string{"mlx5_0", "mlx5_1"}},
+		Client: ClientConfig{Hostname: []string{"node-02", "node-03"}, Hca: []string{"mlx5_1", "mlx5_2"}},
+	}
+	want := map[string][]string{
+		"node-01": {"mlx5_0", "mlx5_1"},
+		"node-02": {"mlx5_0", "mlx5_1", "mlx5_2"},
+		"node-03": {"mlx5_1", "mlx5_2"},
+	}
+	if got := cfg.HostHCAs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("HostHCAs() = %v, want %v", got, want)
+	}
+}
+
+func TestHCAPort(t *testing.T) {
+	if got := (&Config{}).HCAPort(); got != 1 {
+		t.Errorf("HCAPort() without ib_port = %d, want 1", got)
+	}
+	if got := (&Config{IBPort: 2}).HCAPort(); got != 2 {
+		t.Errorf("HCAPort() with ib_port 2 = %d, want 2", got)
+	}
+}
@@ -0,0 +1,298 @@
+// Package audit records every command dispatched to a remote host, together
+// with who dispatched it.
+//
+// The dispatcher travels in the context: the server stores the API caller or
+// the job's initiator with WithActor, and services pass their context down to
+// Run, Output, CombinedOutput and Start, which read it back with ActorFrom.
+// Commands whose context carries no actor are attributed to the local OS
+// user, which is what CLI runs want.
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultPath is where the CLI and the server append audit entries
+	DefaultPath = "audit/audit.log"
+	// DefaultMaxSize is the size at which the log is rotated
+	DefaultMaxSize = 50 * 1024 * 1024
+	// DefaultMaxBackups is the number of rotated files kept
+	DefaultMaxBackups = 10
+	// backupTimeFormat names rotated files; it sorts chronologically
+	backupTimeFormat = "20060102-150405.000"
+)
+
+// Actor identifies who dispatched a remote command
+type Actor struct {
+	User   string `json:"user"`             // authenticated API caller, or the OS user of CLI runs
+	Auth   string `json:"auth,omitempty"`   // token, basic, oidc, cert or local
+	Client string `json:"client,omitempty"` // address of the API caller
+	JobID  string `json:"job_id,omitempty"` // server job the command belongs to
+}
+
+// actorKey is the context key of the Actor
+type actorKey struct{}
+
+// WithActor returns a copy of ctx whose remote commands are attributed to actor
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor stored in ctx, or LocalActor when there is none
+func ActorFrom(ctx context.Context) Actor {
+	if actor, ok := ctx.Value(actorKey{}).(Actor); ok {
+		return actor
+	}
+	return LocalActor()
+}
+
+// LocalActor returns the OS user running this process; under sudo it is the
+// user who invoked sudo
+var LocalActor = sync.OnceValue(func() Actor {
+	name := os.Getenv("SUDO_USER")
+	if name == "" {
+		if u, err := user.Current(); err == nil {
+			name = u.Username
+		} else {
+			name = os.Getenv("USER")
+		}
+	}
+	return Actor{User: name, Auth: "local"}
+})
+
+// Entry is one remote command in the audit log
+type Entry struct {
+	Time time.Time `json:"time"`
+	Actor
+	Host       string `json:"host"`
+	Command    string `json:"command"`
+	ExitCode   int    `json:"exit_code"` // -1 when the command could not be run
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	Background bool   `json:"background,omitempty"` // started without waiting; exit code is that of the start
+}
+
+// Filter selects audit entries; zero fields match everything
+type Filter struct {
+	Host  string
+	User  string
+	JobID string
+	Since time.Time
+	Until time.Time
+	Limit int // newest entries returned when more match
+}
+
+func (f Filter) match(e Entry) bool {
+	return (f.Host == "" || e.Host == f.Host) &&
+		(f.User == "" || e.User == f.User) &&
+		(f.JobID == "" || e.JobID == f.JobID) &&
+		(f.Since.IsZero() || !e.Time.Before(f.Since)) &&
+		(f.Until.IsZero() || e.Time.Before(f.Until))
+}
+
+// Log is an append-only audit log of JSON lines. When the file grows past the
+// maximum size it is renamed with a timestamp suffix and a new file is started;
+// only the newest backups are kept.
+type Log struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// Open opens or creates the audit log at path
+func Open(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("create audit log directory: %w", err)
+	}
+	l := &Log{path: path, maxSize: DefaultMaxSize, maxBackups: DefaultMaxBackups}
+	if err := l.openFile(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// WithMaxSize sets the size in bytes at which the log is rotated
+func (l *Log) WithMaxSize(bytes int64) *Log {
+	l.maxSize = bytes
+	return l
+}
+
+// WithMaxBackups sets the number of rotated files kept
+func (l *Log) WithMaxBackups(n int) *Log {
+	l.maxBackups = n
+	return l
+}
+
+// Path returns the path of the current log file
+func (l *Log) Path() string {
+	return l.path
+}
+
+func (l *Log) openFile() error {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0640)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	l.file = file
+	l.size = info.Size()
+	return nil
+}
+
+// Record appends an entry, rotating the file first when it is full
+func (l *Log) Record(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return fmt.Errorf("audit log %s is closed", l.path)
+	}
+	if l.maxSize > 0 && l.size > 0 && l.size+int64(len(data)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(data)
+	l.size += int64(n)
+	return err
+}
+
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	l.file = nil
+	backup := l.path + "." + time.Now().Format(backupTimeFormat)
+	// Several rotations within a millisecond must not overwrite each other
+	for i := 1; fileExists(backup); i++ {
+		backup = fmt.Sprintf("%s.%s-%03d", l.path, time.Now().Format(backupTimeFormat), i)
+	}
+	if err := os.Rename(l.path, backup); err != nil {
+		return fmt.Errorf("rotate audit log: %w", err)
+	}
+	if err := l.openFile(); err != nil {
+		return err
+	}
+
+	backups, err := l.backups()
+	if err != nil {
+		return err
+	}
+	if l.maxBackups > 0 && len(backups) > l.maxBackups {
+		for _, old := range backups[:len(backups)-l.maxBackups] {
+			os.Remove(old)
+		}
+	}
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// backups returns the rotated files, oldest first
+func (l *Log) backups() ([]string, error) {
+	files, err := filepath.Glob(l.path + ".*")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Query returns the matching entries of the current and rotated files, newest
+// first
+func (l *Log) Query(f Filter) ([]Entry, error) {
+	l.mu.Lock()
+	files, err := l.backups()
+	l.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, l.path)
+
+	var entries []Entry
+	for _, path := range files {
+		if err := readEntries(path, f, &entries); err != nil {
+			return nil, err
+		}
+	}
+
+	// Files are read oldest first, so reversing yields newest first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	if f.Limit > 0 && len(entries) > f.Limit {
+		entries = entries[:f.Limit]
+	}
+	return entries, nil
+}
+
+func readEntries(path string, f Filter, entries *[]Entry) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		// Rotated away between listing and reading
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			// A line torn by a crash must not hide the rest of the log
+			continue
+		}
+		if f.match(e) {
+			*entries = append(*entries, e)
+			// Only the newest Limit entries are returned; drop older ones early
+			if f.Limit > 0 && len(*entries) >= 2*f.Limit {
+				*entries = append([]Entry(nil), (*entries)[len(*entries)-f.Limit:]...)
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// Close closes the log file
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
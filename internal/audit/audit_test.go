@@ -0,0 +1,140 @@
+package audit_test
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"xnetperf/internal/audit"
+)
+
+func openLog(t *testing.T) *audit.Log {
+	t.Helper()
+	l, err := audit.Open(filepath.Join(t.TempDir(), "audit", "audit.log"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+func TestQuery(t *testing.T) {
+	l := openLog(t)
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	entries := []audit.Entry{
+		{Time: base, Actor: audit.Actor{User: "alice", JobID: "job-1"}, Host: "node1", Command: "ibstat"},
+		{Time: base.Add(time.Minute), Actor: audit.Actor{User: "bob"}, Host: "node2", Command: "ibstat"},
+		{Time: base.Add(2 * time.Minute), Actor: audit.Actor{User: "alice", JobID: "job-2"}, Host: "node2", Command: "killall ib_write_bw"},
+	}
+	for _, e := range entries {
+		if err := l.Record(e); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter audit.Filter
+		want   []string // commands, newest first
+	}{
+		{name: "all", filter: audit.Filter{}, want: []string{"killall ib_write_bw", "ibstat", "ibstat"}},
+		{name: "host", filter: audit.Filter{Host: "node1"}, want: []string{"ibstat"}},
+		{name: "user", filter: audit.Filter{User: "alice"}, want: []string{"killall ib_write_bw", "ibstat"}},
+		{name: "job", filter: audit.Filter{JobID: "job-2"}, want: []string{"killall ib_write_bw"}},
+		{name: "since", filter: audit.Filter{Since: base.Add(time.Minute)}, want: []string{"killall ib_write_bw", "ibstat"}},
+		{name: "until", filter: audit.Filter{Until: base.Add(time.Minute)}, want: []string{"ibstat"}},
+		{name: "limit", filter: audit.Filter{Limit: 1}, want: []string{"killall ib_write_bw"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := l.Query(tt.filter)
+			if err != nil {
+				t.Fatalf("Query: %v", err)
+			}
+			var commands []string
+			for _, e := range got {
+				commands = append(commands, e.Command)
+			}
+			if strings.Join(commands, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("Query() = %v, want %v", commands, tt.want)
+			}
+		})
+	}
+}
+
+func TestRotation(t *testing.T) {
+	l := openLog(t)
+	l.WithMaxSize(400).WithMaxBackups(2)
+
+	for i := 0; i < 20; i++ {
+		if err := l.Record(audit.Entry{Actor: audit.Actor{User: "alice"}, Host: "node1", Command: "ibstat"}); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	backups, err := filepath.Glob(l.Path() + ".*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Errorf("Got %d backups, want 2: %v", len(backups), backups)
+	}
+
+	// Entries in the kept backups are still returned, the pruned ones are gone
+	got, err := l.Query(audit.Filter{})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(got) == 0 || len(got) >= 20 {
+		t.Errorf("Query() returned %d entries, want between 1 and 19", len(got))
+	}
+}
+
+func TestCommandAttribution(t *testing.T) {
+	l := openLog(t)
+	audit.SetDefault(l)
+	defer audit.SetDefault(nil)
+
+	job := audit.Actor{User: "carol", Auth: "token", JobID: "job-7"}
+	if err := audit.Run(audit.WithActor(context.Background(), job), "node1", "true", exec.Command("true")); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	audit.Run(context.Background(), "node2", "exit 3", exec.Command("sh", "-c", "exit 3"))
+
+	got, err := l.Query(audit.Filter{})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Got %d entries, want 2", len(got))
+	}
+	if e := got[1]; e.User != "carol" || e.JobID != "job-7" || e.Host != "node1" || e.ExitCode != 0 {
+		t.Errorf("Job entry = %+v", e)
+	}
+	if e := got[0]; e.User != audit.LocalActor().User || e.Auth != "local" || e.ExitCode != 3 {
+		t.Errorf("Local entry = %+v", e)
+	}
+}
+
+func TestRunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := audit.Run(ctx, "node1", "sleep 10", exec.Command("sleep", "10"))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Run() returned after %s, want the command to be killed", elapsed)
+	}
+
+	if _, err := audit.Output(ctx, "node1", "true", exec.Command("true")); !errors.Is(err, context.Canceled) {
+		t.Errorf("Output() with cancelled ctx error = %v, want context.Canceled", err)
+	}
+}
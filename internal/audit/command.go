@@ -0,0 +1,133 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"xnetperf/pkg/tools/logger"
+)
+
+var (
+	defaultLog atomic.Pointer[Log]
+
+	observersMu sync.Mutex
+	observers   []func(Entry)
+)
+
+// SetDefault sets the log that remote commands are recorded in; nil disables
+// recording
+func SetDefault(l *Log) {
+	defaultLog.Store(l)
+}
+
+// Default returns the log that remote commands are recorded in, or nil
+func Default() *Log {
+	return defaultLog.Load()
+}
+
+// AddObserver registers fn to be called with every remote command, whether or
+// not a log is set; the server uses it for SSH metrics
+func AddObserver(fn func(Entry)) {
+	observersMu.Lock()
+	defer observersMu.Unlock()
+	observers = append(observers, fn)
+}
+
+// Run runs cmd, a command dispatched to host, and records it in the default
+// log under the actor of ctx. The command is killed when ctx is cancelled.
+func Run(ctx context.Context, host, command string, cmd *exec.Cmd) error {
+	start := time.Now()
+	err := wait(ctx, cmd)
+	record(ActorFrom(ctx), host, command, start, err, false)
+	return err
+}
+
+// Output is like Run and returns the standard output of cmd
+func Output(ctx context.Context, host, command string, cmd *exec.Cmd) ([]byte, error) {
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	start := time.Now()
+	err := wait(ctx, cmd)
+	record(ActorFrom(ctx), host, command, start, err, false)
+	return stdout.Bytes(), err
+}
+
+// CombinedOutput is like Run and returns the combined output of cmd
+func CombinedOutput(ctx context.Context, host, command string, cmd *exec.Cmd) ([]byte, error) {
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	start := time.Now()
+	err := wait(ctx, cmd)
+	record(ActorFrom(ctx), host, command, start, err, false)
+	return output.Bytes(), err
+}
+
+// Start starts cmd without waiting for it and records the start under the
+// actor of ctx; cancelling ctx does not kill the command
+func Start(ctx context.Context, host, command string, cmd *exec.Cmd) error {
+	start := time.Now()
+	err := cmd.Start()
+	record(ActorFrom(ctx), host, command, start, err, true)
+	return err
+}
+
+// wait runs cmd to completion, killing it when ctx is cancelled first
+func wait(ctx context.Context, cmd *exec.Cmd) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { cmd.Process.Kill() })
+	defer stop()
+	err := cmd.Wait()
+	if ctxErr := ctx.Err(); ctxErr != nil && err != nil {
+		return fmt.Errorf("%w: %v", ctxErr, err)
+	}
+	return err
+}
+
+func record(actor Actor, host, command string, start time.Time, err error, background bool) {
+	l := Default()
+	observersMu.Lock()
+	notify := observers
+	observersMu.Unlock()
+	if l == nil && len(notify) == 0 {
+		return
+	}
+
+	entry := Entry{
+		Time:       start,
+		Actor:      actor,
+		Host:       host,
+		Command:    command,
+		DurationMs: time.Since(start).Milliseconds(),
+		Background: background,
+	}
+	if err != nil {
+		entry.ExitCode = -1
+		entry.Error = err.Error()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			entry.ExitCode = exitErr.ExitCode()
+		}
+	}
+
+	for _, fn := range notify {
+		fn(entry)
+	}
+	if l == nil {
+		return
+	}
+	if err := l.Record(entry); err != nil {
+		logger.GetLogger().With("module", "AUDIT").Warn("Failed to record remote command", "host", host, "error", err)
+	}
+}
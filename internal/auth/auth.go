@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrNoCredentials means the request carries no credentials the
+	// authenticator understands; the next authenticator of a chain is tried
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials means the request carries credentials that are
+	// unknown, expired or wrongly signed
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Role is the access level of an authenticated identity. Higher roles include
+// the permissions of all lower ones.
+type Role int
+
+const (
+	// RoleNone grants nothing; it is the zero value of unmapped identities
+	RoleNone Role = iota
+	// RoleViewer may read configs, jobs, runs and reports
+	RoleViewer
+	// RoleOperator may additionally run prechecks, tests and collections
+	RoleOperator
+	// RoleAdmin may additionally edit configs and dictionaries and delete runs
+	RoleAdmin
+)
+
+// String returns the role name used in files, flags and token claims
+func (r Role) String() string {
+	switch r {
+	case RoleViewer:
+		return "viewer"
+	case RoleOperator:
+		return "operator"
+	case RoleAdmin:
+		return "admin"
+	default:
+		return "none"
+	}
+}
+
+// Allows reports whether the role includes the required one
+func (r Role) Allows(required Role) bool {
+	return r >= required && r != RoleNone
+}
+
+// MarshalText makes roles render by name in JSON responses
+func (r Role) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// ParseRole parses a role name (viewer, operator or admin)
+func ParseRole(name string) (Role, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "viewer":
+		return RoleViewer, nil
+	case "operator":
+		return RoleOperator, nil
+	case "admin":
+		return RoleAdmin, nil
+	default:
+		return RoleNone, fmt.Errorf("invalid role '%s', must be one of: viewer, operator, admin", name)
+	}
+}
+
+// Identity is the authenticated caller of a request
+type Identity struct {
+	Subject string `json:"subject"`
+	Role    Role   `json:"role"`
+	Method  string `json:"method"` // token, basic or oidc
+}
+
+// Authenticator verifies the credentials of an HTTP request
+type Authenticator interface {
+	// Authenticate returns the caller identity, ErrNoCredentials when the
+	// request has no credentials for this authenticator, or an error
+	// wrapping ErrInvalidCredentials when they are rejected
+	Authenticate(r *http.Request) (*Identity, error)
+	// Challenge returns the WWW-Authenticate header value sent with 401
+	// responses
+	Challenge() string
+}
+
+// Chain tries authenticators in order until one recognizes the credentials
+type Chain []Authenticator
+
+// Authenticate returns the identity of the first authenticator that accepts
+// or rejects the request
+func (c Chain) Authenticate(r *http.Request) (*Identity, error) {
+	for _, a := range c {
+		identity, err := a.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return identity, err
+	}
+	return nil, ErrNoCredentials
+}
+
+// Challenge returns the challenge of the first authenticator
+func (c Chain) Challenge() string {
+	if len(c) == 0 {
+		return ""
+	}
+	return c[0].Challenge()
+}
+
+// Challenges returns the challenges of all chained authenticators, so that a
+// 401 response can advertise every accepted scheme
+func (c Chain) Challenges() []string {
+	var challenges []string
+	for _, a := range c {
+		if challenge := a.Challenge(); challenge != "" {
+			challenges = append(challenges, challenge)
+		}
+	}
+	return challenges
+}
+
+// bearerToken returns the token of an "Authorization: Bearer" header
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -0,0 +1,229 @@
+package auth_test
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"xnetperf/internal/auth"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestStaticAuthenticators(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users, err := auth.LoadUsersFile(writeFile(t, "users", "# users\nalice:"+string(hash)+":admin\nbob:"+string(hash)+"\n"))
+	if err != nil {
+		t.Fatalf("LoadUsersFile failed: %v", err)
+	}
+	tokens, err := auth.LoadTokenFile(writeFile(t, "tokens", "ci:operator:tok:with:colons\n\n"))
+	if err != nil {
+		t.Fatalf("LoadTokenFile failed: %v", err)
+	}
+	chain := auth.Chain{tokens, users}
+
+	tests := []struct {
+		name        string
+		setup       func(r *http.Request)
+		wantSubject string
+		wantRole    auth.Role
+		wantErr     error
+	}{
+		{name: "no credentials", setup: func(r *http.Request) {}, wantErr: auth.ErrNoCredentials},
+		{name: "token", setup: func(r *http.Request) { r.Header.Set("Authorization", "Bearer tok:with:colons") }, wantSubject: "ci", wantRole: auth.RoleOperator},
+		{name: "unknown token", setup: func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") }, wantErr: auth.ErrInvalidCredentials},
+		{name: "basic admin", setup: func(r *http.Request) { r.SetBasicAuth("alice", "secret") }, wantSubject: "alice", wantRole: auth.RoleAdmin},
+		{name: "basic default role", setup: func(r *http.Request) { r.SetBasicAuth("bob", "secret") }, wantSubject: "bob", wantRole: auth.RoleViewer},
+		{name: "wrong password", setup: func(r *http.Request) { r.SetBasicAuth("alice", "wrong") }, wantErr: auth.ErrInvalidCredentials},
+		{name: "unknown user", setup: func(r *http.Request) { r.SetBasicAuth("eve", "secret") }, wantErr: auth.ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/configs", nil)
+			tt.setup(r)
+			identity, err := chain.Authenticate(r)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate failed: %v", err)
+			}
+			if identity.Subject != tt.wantSubject || identity.Role != tt.wantRole {
+				t.Errorf("Expected %s/%s, got %s/%s", tt.wantSubject, tt.wantRole, identity.Subject, identity.Role)
+			}
+		})
+	}
+
+	if _, err := auth.LoadUsersFile(writeFile(t, "bad", "carol:plaintext\n")); err == nil {
+		t.Error("Expected plain-text password to be rejected")
+	}
+}
+
+// encodeJWT joins the encoded header and claims with the signature returned
+// by sign for the signing input
+func encodeJWT(t *testing.T, header map[string]string, claims map[string]any, sign func(signed []byte) []byte) string {
+	t.Helper()
+	segment := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := segment(header) + "." + segment(claims)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(signed)))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+	return encodeJWT(t, map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"}, claims, func(signed []byte) []byte {
+		digest := sha256.Sum256(signed)
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signature
+	})
+}
+
+// signHS256 signs with an HMAC secret, as an attacker would with a public key
+func signHS256(t *testing.T, secret []byte, kid string, claims map[string]any) string {
+	t.Helper()
+	return encodeJWT(t, map[string]string{"alg": "HS256", "kid": kid, "typ": "JWT"}, claims, func(signed []byte) []byte {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signed)
+		return mac.Sum(nil)
+	})
+}
+
+func TestOIDCAuthenticator(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var issuer string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": issuer, "jwks_uri": issuer + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	idp := httptest.NewServer(mux)
+	defer idp.Close()
+	issuer = idp.URL
+
+	authenticator, err := auth.NewOIDCAuthenticator(auth.OIDCConfig{
+		Issuer:    issuer,
+		Audience:  "xnetperf",
+		RoleClaim: "groups",
+		RoleMap:   map[string]string{"netops": "operator", "netops-admins": "admin"},
+	})
+	if err != nil {
+		t.Fatalf("NewOIDCAuthenticator failed: %v", err)
+	}
+
+	now := time.Now()
+	claims := func(overrides map[string]any) map[string]any {
+		c := map[string]any{
+			"iss":                issuer,
+			"sub":                "u-1",
+			"preferred_username": "alice",
+			"aud":                []string{"xnetperf", "other"},
+			"exp":                now.Add(time.Hour).Unix(),
+			"groups":             []string{"staff", "netops"},
+		}
+		for k, v := range overrides {
+			c[k] = v
+		}
+		return c
+	}
+
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	unsigned := encodeJWT(t, map[string]string{"alg": "none", "kid": "k1", "typ": "JWT"}, claims(nil), func([]byte) []byte { return nil })
+
+	tests := []struct {
+		name     string
+		token    string
+		wantRole auth.Role
+		wantErr  bool
+	}{
+		{name: "valid", token: signRS256(t, key, "k1", claims(nil)), wantRole: auth.RoleOperator},
+		{name: "highest mapped role", token: signRS256(t, key, "k1", claims(map[string]any{"groups": []string{"netops", "netops-admins"}})), wantRole: auth.RoleAdmin},
+		{name: "unmapped groups", token: signRS256(t, key, "k1", claims(map[string]any{"groups": "staff"})), wantRole: auth.RoleNone},
+		{name: "expired", token: signRS256(t, key, "k1", claims(map[string]any{"exp": now.Add(-time.Hour).Unix()})), wantErr: true},
+		{name: "wrong issuer", token: signRS256(t, key, "k1", claims(map[string]any{"iss": "https://evil.example"})), wantErr: true},
+		{name: "wrong audience", token: signRS256(t, key, "k1", claims(map[string]any{"aud": "other"})), wantErr: true},
+		{name: "wrong key", token: signRS256(t, otherKey, "k1", claims(nil)), wantErr: true},
+		{name: "unknown kid", token: signRS256(t, key, "k2", claims(nil)), wantErr: true},
+		{name: "alg none", token: unsigned, wantErr: true},
+		{name: "HS256 with public key PEM", token: signHS256(t, publicPEM, "k1", claims(nil)), wantErr: true},
+		{name: "HS256 with public key DER", token: signHS256(t, publicDER, "k1", claims(nil)), wantErr: true},
+		{name: "HS256 with modulus", token: signHS256(t, key.N.Bytes(), "k1", claims(nil)), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/configs", nil)
+			r.Header.Set("Authorization", "Bearer "+tt.token)
+			identity, err := authenticator.Authenticate(r)
+			if tt.wantErr {
+				if !errors.Is(err, auth.ErrInvalidCredentials) {
+					t.Fatalf("Expected ErrInvalidCredentials, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate failed: %v", err)
+			}
+			if identity.Subject != "alice" || identity.Role != tt.wantRole {
+				t.Errorf("Expected alice/%s, got %s/%s", tt.wantRole, identity.Subject, identity.Role)
+			}
+		})
+	}
+}
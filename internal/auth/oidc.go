@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+)
+
+// DefaultRoleClaim is the token claim that carries the role
+const DefaultRoleClaim = "role"
+
+// signingAlgs are the accepted token signing algorithms; only asymmetric
+// algorithms are listed so that a public key can never be used as an HMAC
+// secret, and unsigned tokens (alg "none") are rejected
+var signingAlgs = []string{oidc.RS256, oidc.RS384, oidc.RS512, oidc.ES256, oidc.ES384, oidc.ES512}
+
+// OIDCConfig configures verification of OIDC ID tokens and JWT access tokens
+type OIDCConfig struct {
+	Issuer   string // expected "iss" claim; also used for discovery
+	JWKSURL  string // signing keys; discovered from the issuer when empty
+	Audience string // required "aud" value; not checked when empty
+	// RoleClaim names the claim holding the caller's role or groups. Nested
+	// claims use dots, e.g. "realm_access.roles".
+	RoleClaim string
+	// RoleMap maps claim values (e.g. group names) to role names. When empty
+	// the claim values must be role names themselves.
+	RoleMap map[string]string
+}
+
+// OIDCAuthenticator verifies signed JWTs sent as "Authorization: Bearer"
+// against the JSON Web Key Set of an OIDC issuer
+type OIDCAuthenticator struct {
+	config  OIDCConfig
+	roleMap map[string]Role
+	client  *http.Client
+	now     func() time.Time
+
+	mu       sync.Mutex
+	verifier *oidc.IDTokenVerifier
+}
+
+// NewOIDCAuthenticator creates an authenticator for the issuer. Discovery and
+// signing keys are fetched on first use, so the issuer need not be reachable
+// at startup.
+func NewOIDCAuthenticator(config OIDCConfig) (*OIDCAuthenticator, error) {
+	if config.Issuer == "" {
+		return nil, errors.New("OIDC issuer is required")
+	}
+	if config.RoleClaim == "" {
+		config.RoleClaim = DefaultRoleClaim
+	}
+
+	roleMap := make(map[string]Role, len(config.RoleMap))
+	for value, name := range config.RoleMap {
+		role, err := ParseRole(name)
+		if err != nil {
+			return nil, fmt.Errorf("role mapping for '%s': %w", value, err)
+		}
+		roleMap[value] = role
+	}
+
+	return &OIDCAuthenticator{
+		config:  config,
+		roleMap: roleMap,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		now:     time.Now,
+	}, nil
+}
+
+// WithHTTPClient sets the client used for discovery and JWKS requests
+func (a *OIDCAuthenticator) WithHTTPClient(client *http.Client) *OIDCAuthenticator {
+	a.client = client
+	return a
+}
+
+// WithClock sets the time source used to check token expiry
+func (a *OIDCAuthenticator) WithClock(now func() time.Time) *OIDCAuthenticator {
+	a.now = now
+	return a
+}
+
+// Authenticate implements Authenticator
+func (a *OIDCAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	token, ok := bearerToken(r)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+
+	claims, err := a.Verify(r.Context(), token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	identity := &Identity{Method: "oidc", Role: a.role(claims)}
+	for _, name := range []string{"preferred_username", "email", "sub"} {
+		if value, ok := claims[name].(string); ok && value != "" {
+			identity.Subject = value
+			break
+		}
+	}
+	return identity, nil
+}
+
+// Challenge implements Authenticator
+func (a *OIDCAuthenticator) Challenge() string {
+	return `Bearer realm="xnetperf"`
+}
+
+// Verify checks the signature, issuer, audience and validity period of a
+// token and returns its claims
+func (a *OIDCAuthenticator) Verify(ctx context.Context, token string) (map[string]any, error) {
+	verifier, err := a.tokenVerifier()
+	if err != nil {
+		return nil, err
+	}
+	idToken, err := verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("token claims: %w", err)
+	}
+	return claims, nil
+}
+
+// tokenVerifier returns the verifier of the issuer, discovering its key set on
+// first use; a failed discovery is retried by the next request
+func (a *OIDCAuthenticator) tokenVerifier() (*oidc.IDTokenVerifier, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.verifier != nil {
+		return a.verifier, nil
+	}
+
+	// The key set keeps this context to fetch rotated keys later, so it must
+	// outlive the request that triggered discovery
+	ctx := oidc.ClientContext(context.Background(), a.client)
+	config := &oidc.Config{
+		ClientID:             a.config.Audience,
+		SkipClientIDCheck:    a.config.Audience == "",
+		SupportedSigningAlgs: signingAlgs,
+		Now:                  a.now,
+	}
+	if a.config.JWKSURL != "" {
+		a.verifier = oidc.NewVerifier(a.config.Issuer, oidc.NewRemoteKeySet(ctx, a.config.JWKSURL), config)
+		return a.verifier, nil
+	}
+	provider, err := oidc.NewProvider(ctx, a.config.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("discover signing keys: %w", err)
+	}
+	a.verifier = provider.Verifier(config)
+	return a.verifier, nil
+}
+
+// role returns the highest role granted by the role claim
+func (a *OIDCAuthenticator) role(claims map[string]any) Role {
+	var value any = claims
+	for _, name := range strings.Split(a.config.RoleClaim, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return RoleNone
+		}
+		value = object[name]
+	}
+
+	best := RoleNone
+	for _, v := range claimStrings(value) {
+		role, ok := a.roleMap[v]
+		if !ok && len(a.roleMap) == 0 {
+			role, _ = ParseRole(v)
+		}
+		if role > best {
+			best = role
+		}
+	}
+	return best
+}
+
+// claimStrings returns a string or array-of-strings claim as a slice
+func claimStrings(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TokenAuthenticator accepts static API tokens sent as
+// "Authorization: Bearer <token>"
+type TokenAuthenticator struct {
+	tokens []staticToken
+}
+
+type staticToken struct {
+	name string
+	role Role
+	hash [sha256.Size]byte
+}
+
+// NewTokenAuthenticator creates an authenticator without tokens; add them
+// with WithToken or load them with LoadTokenFile
+func NewTokenAuthenticator() *TokenAuthenticator {
+	return &TokenAuthenticator{}
+}
+
+// WithToken adds a token; name identifies its holder in logs and audit
+func (a *TokenAuthenticator) WithToken(name string, role Role, token string) *TokenAuthenticator {
+	a.tokens = append(a.tokens, staticToken{name: name, role: role, hash: sha256.Sum256([]byte(token))})
+	return a
+}
+
+// LoadTokenFile reads a token file with one "name:role:token" entry per line.
+// Empty lines and lines starting with # are ignored.
+func LoadTokenFile(path string) (*TokenAuthenticator, error) {
+	a := NewTokenAuthenticator()
+	err := readEntries(path, func(lineNo int, fields []string) error {
+		parts := strings.SplitN(strings.Join(fields, ":"), ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+			return fmt.Errorf("%s:%d: expected name:role:token", path, lineNo)
+		}
+		role, err := ParseRole(parts[1])
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+		a.WithToken(parts[0], role, parts[2])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Authenticate implements Authenticator
+func (a *TokenAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	token, ok := bearerToken(r)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+
+	hash := sha256.Sum256([]byte(token))
+	for _, t := range a.tokens {
+		if subtle.ConstantTimeCompare(hash[:], t.hash[:]) == 1 {
+			return &Identity{Subject: t.name, Role: t.role, Method: "token"}, nil
+		}
+	}
+
+	// Leave tokens shaped like a JWT to the OIDC authenticator of the chain
+	if strings.Count(token, ".") == 2 {
+		return nil, ErrNoCredentials
+	}
+	return nil, fmt.Errorf("%w: unknown API token", ErrInvalidCredentials)
+}
+
+// Challenge implements Authenticator
+func (a *TokenAuthenticator) Challenge() string {
+	return `Bearer realm="xnetperf"`
+}
+
+// BasicAuthenticator accepts HTTP basic credentials of users with
+// bcrypt-hashed passwords
+type BasicAuthenticator struct {
+	users map[string]basicUser
+}
+
+type basicUser struct {
+	hash []byte
+	role Role
+}
+
+// LoadUsersFile reads a users file with one "username:bcrypt-hash[:role]"
+// entry per line, as written by "htpasswd -nB" with an optional role appended.
+// Users without a role are viewers.
+func LoadUsersFile(path string) (*BasicAuthenticator, error) {
+	a := &BasicAuthenticator{users: make(map[string]basicUser)}
+	err := readEntries(path, func(lineNo int, fields []string) error {
+		if len(fields) < 2 || len(fields) > 3 || fields[0] == "" {
+			return fmt.Errorf("%s:%d: expected username:bcrypt-hash[:role]", path, lineNo)
+		}
+		if _, err := bcrypt.Cost([]byte(fields[1])); err != nil {
+			return fmt.Errorf("%s:%d: user %s: password is not a bcrypt hash: %w", path, lineNo, fields[0], err)
+		}
+		role := RoleViewer
+		if len(fields) == 3 {
+			var err error
+			if role, err = ParseRole(fields[2]); err != nil {
+				return fmt.Errorf("%s:%d: %w", path, lineNo, err)
+			}
+		}
+		a.users[fields[0]] = basicUser{hash: []byte(fields[1]), role: role}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Authenticate implements Authenticator
+func (a *BasicAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+
+	user, found := a.users[username]
+	if !found {
+		return nil, fmt.Errorf("%w: unknown user %s", ErrInvalidCredentials, username)
+	}
+	if err := bcrypt.CompareHashAndPassword(user.hash, []byte(password)); err != nil {
+		return nil, fmt.Errorf("%w: wrong password for user %s", ErrInvalidCredentials, username)
+	}
+	return &Identity{Subject: username, Role: user.role, Method: "basic"}, nil
+}
+
+// Challenge implements Authenticator
+func (a *BasicAuthenticator) Challenge() string {
+	return `Basic realm="xnetperf", charset="UTF-8"`
+}
+
+// readEntries calls fn with the colon-separated fields of every non-comment
+// line of a file
+func readEntries(path string, fn func(lineNo int, fields []string) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := fn(lineNo, strings.Split(line, ":")); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// CertAuthenticator accepts clients that presented a TLS certificate verified
+// against the server's client CA
+type CertAuthenticator struct {
+	role Role
+}
+
+// NewCertAuthenticator creates an authenticator granting role to every
+// client with a verified certificate
+func NewCertAuthenticator(role Role) *CertAuthenticator {
+	return &CertAuthenticator{role: role}
+}
+
+// Authenticate implements Authenticator
+func (a *CertAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, ErrNoCredentials
+	}
+
+	cert := r.TLS.VerifiedChains[0][0]
+	subject := cert.Subject.CommonName
+	if subject == "" && len(cert.DNSNames) > 0 {
+		subject = cert.DNSNames[0]
+	}
+	return &Identity{Subject: subject, Role: a.role, Method: "cert"}, nil
+}
+
+// Challenge implements Authenticator; client certificates are requested
+// during the TLS handshake, not with a header
+func (a *CertAuthenticator) Challenge() string {
+	return ""
+}
@@ -0,0 +1,154 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultHistorySize is the number of events a bus keeps for late subscribers
+const DefaultHistorySize = 2000
+
+// subscriberBuffer is the number of events a subscriber may lag behind before
+// it is dropped
+const subscriberBuffer = 256
+
+// Type identifies what an event reports
+type Type string
+
+const (
+	// TypeJobState reports a job state change (queued, running, succeeded, ...)
+	TypeJobState Type = "job_state"
+	// TypeProgress reports the progress percentage and current step of a job
+	TypeProgress Type = "progress"
+	// TypeLog is a free-form log line
+	TypeLog Type = "log"
+	// TypeStep reports that a workflow moved on to its next step
+	TypeStep Type = "step"
+	// TypeHostStarted reports that all expected server processes run on a host
+	TypeHostStarted Type = "host_started"
+	// TypeProcessCount reports a changed number of test processes on a host
+	TypeProcessCount Type = "process_count"
+	// TypeReportCollected reports the report files collected from a host
+	TypeReportCollected Type = "report_collected"
+	// TypeAnalysisDone reports that collected reports have been analyzed
+	TypeAnalysisDone Type = "analysis_done"
+)
+
+// Event is a structured progress event published by services
+type Event struct {
+	Seq     int64          `json:"seq"`
+	Time    time.Time      `json:"time"`
+	Type    Type           `json:"type"`
+	Host    string         `json:"host,omitempty"`
+	Message string         `json:"message,omitempty"`
+	Data    map[string]any `json:"data,omitempty"`
+}
+
+// Publisher receives events from services
+type Publisher interface {
+	Publish(Event)
+}
+
+// Discard is a Publisher that drops all events; services use it by default
+var Discard Publisher = discard{}
+
+type discard struct{}
+
+func (discard) Publish(Event) {}
+
+// Bus fans events out to subscribers and keeps a bounded history so that
+// subscribers joining late can replay what they missed
+type Bus struct {
+	mu          sync.Mutex
+	seq         int64
+	history     []Event
+	historySize int
+	subs        map[chan Event]struct{}
+	closed      bool
+}
+
+// NewBus creates a bus keeping up to historySize events
+func NewBus(historySize int) *Bus {
+	if historySize <= 0 {
+		historySize = DefaultHistorySize
+	}
+	return &Bus{
+		historySize: historySize,
+		subs:        make(map[chan Event]struct{}),
+	}
+}
+
+// Publish stamps the event with a sequence number and time and delivers it.
+// Subscribers that cannot keep up are dropped instead of blocking publishers.
+func (b *Bus) Publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+
+	b.seq++
+	e.Seq = b.seq
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	b.history = append(b.history, e)
+	if over := len(b.history) - b.historySize; over > 0 {
+		b.history = b.history[over:]
+	}
+
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe returns the kept events with a sequence number greater than
+// afterSeq and a channel of the events published from now on. The channel is
+// closed when the bus is closed, the subscriber lags too far behind, or cancel
+// is called.
+func (b *Bus) Subscribe(afterSeq int64) (replay []Event, ch <-chan Event, cancel func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, e := range b.history {
+		if e.Seq > afterSeq {
+			replay = append(replay, e)
+		}
+	}
+
+	sub := make(chan Event, subscriberBuffer)
+	if b.closed {
+		close(sub)
+		return replay, sub, func() {}
+	}
+	b.subs[sub] = struct{}{}
+
+	cancel = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[sub]; ok {
+			delete(b.subs, sub)
+			close(sub)
+		}
+	}
+	return replay, sub, cancel
+}
+
+// Close ends all subscriptions; later events are dropped
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for ch := range b.subs {
+		delete(b.subs, ch)
+		close(ch)
+	}
+}
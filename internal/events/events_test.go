@@ -0,0 +1,66 @@
+package events_test
+
+import (
+	"testing"
+
+	"xnetperf/internal/events"
+)
+
+func TestBusReplayAndLive(t *testing.T) {
+	bus := events.NewBus(2)
+	bus.Publish(events.Event{Type: events.TypeLog, Message: "one"})
+	bus.Publish(events.Event{Type: events.TypeLog, Message: "two"})
+	bus.Publish(events.Event{Type: events.TypeLog, Message: "three"})
+
+	// Only the last two events are kept
+	replay, ch, cancel := bus.Subscribe(0)
+	defer cancel()
+	if len(replay) != 2 || replay[0].Message != "two" || replay[1].Seq != 3 {
+		t.Fatalf("Unexpected replay: %+v", replay)
+	}
+	if replay[0].Time.IsZero() {
+		t.Error("Expected events to be timestamped")
+	}
+
+	// Resuming after an event only replays newer ones
+	if resumed, _, cancelResumed := bus.Subscribe(2); len(resumed) != 1 || resumed[0].Message != "three" {
+		t.Errorf("Unexpected resumed replay: %+v", resumed)
+	} else {
+		cancelResumed()
+	}
+
+	bus.Publish(events.Event{Type: events.TypeHostStarted, Host: "host1"})
+	if e := <-ch; e.Type != events.TypeHostStarted || e.Host != "host1" || e.Seq != 4 {
+		t.Errorf("Unexpected live event: %+v", e)
+	}
+
+	bus.Close()
+	if _, ok := <-ch; ok {
+		t.Error("Expected channel to be closed with the bus")
+	}
+	bus.Publish(events.Event{Type: events.TypeLog})
+	if replay, ch, _ := bus.Subscribe(0); len(replay) != 2 {
+		t.Errorf("Expected events published after Close to be dropped, got %d", len(replay))
+	} else if _, ok := <-ch; ok {
+		t.Error("Expected subscription to a closed bus to be closed")
+	}
+}
+
+func TestBusDropsSlowSubscriber(t *testing.T) {
+	bus := events.NewBus(0)
+	_, ch, cancel := bus.Subscribe(0)
+	defer cancel()
+
+	// Never reading from ch must not block publishers
+	for i := 0; i < 1000; i++ {
+		bus.Publish(events.Event{Type: events.TypeLog})
+	}
+
+	received := 0
+	for range ch {
+		received++
+	}
+	if received == 0 || received >= 1000 {
+		t.Errorf("Expected the slow subscriber to be dropped after its buffer filled, received %d", received)
+	}
+}
@@ -0,0 +1,186 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"xnetperf/config"
+	"xnetperf/pkg/tools/logger"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+const (
+	// DefaultJob is the job label of pushed metrics
+	DefaultJob = "xnetperf"
+	// DefaultTimeout is the time allowed for one push request
+	DefaultTimeout = 10 * time.Second
+)
+
+// Pusher sends the results of a CLI run to a Prometheus Pushgateway or a
+// remote-write endpoint, since short-lived runs from cron cannot be scraped
+type Pusher struct {
+	cfg    config.MetricsPush
+	client *http.Client
+	logger *slog.Logger
+}
+
+// NewPusher creates a pusher for the metrics_push config section
+func NewPusher(cfg config.MetricsPush) *Pusher {
+	timeout := DefaultTimeout
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	if cfg.Job == "" {
+		cfg.Job = DefaultJob
+	}
+	return &Pusher{
+		cfg:    cfg,
+		client: &http.Client{Timeout: timeout},
+		logger: logger.GetLogger().With("module", "PUSH"),
+	}
+}
+
+// WithHTTPClient sets the client used for push requests
+func (p *Pusher) WithHTTPClient(client *http.Client) *Pusher {
+	p.client = client
+	return p
+}
+
+// Push converts results (see Results.Observe) of a config into gauges and
+// sends them. Results of other types are ignored; nothing is sent when none
+// are left.
+func (p *Pusher) Push(ctx context.Context, configName string, results ...any) error {
+	registry := prometheus.NewRegistry()
+	r := NewResults(registry)
+	observed := false
+	for _, result := range results {
+		observed = r.Observe(configName, result) || observed
+	}
+	if !observed {
+		return nil
+	}
+
+	// Gather leaves out the gauges without samples
+	families, err := registry.Gather()
+	if err != nil {
+		return fmt.Errorf("gather metrics: %w", err)
+	}
+
+	var req *http.Request
+	switch p.cfg.Mode {
+	case config.PushModeRemoteWrite:
+		req, err = p.remoteWriteRequest(ctx, families, time.Now())
+	default:
+		req, err = p.pushgatewayRequest(ctx, configName, families)
+	}
+	if err != nil {
+		return err
+	}
+	for name, value := range p.cfg.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("push metrics: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("push metrics: %s returned %s: %s", req.URL.Redacted(), resp.Status, strings.TrimSpace(string(body)))
+	}
+	p.logger.Info("Pushed metrics", "url", req.URL.Redacted(), "mode", p.mode(), "families", len(families))
+	return nil
+}
+
+func (p *Pusher) mode() string {
+	if p.cfg.Mode == "" {
+		return config.PushModePushgateway
+	}
+	return p.cfg.Mode
+}
+
+// pushgatewayRequest POSTs the text format to the group of the job, the
+// config and the configured labels. POST only replaces metrics with the same
+// names, so precheck, bandwidth and latency pushes do not remove each other.
+func (p *Pusher) pushgatewayRequest(ctx context.Context, configName string, families []*dto.MetricFamily) (*http.Request, error) {
+	path := "/metrics/" + groupingValue("job", p.cfg.Job)
+	path += "/" + groupingValue("config", configName)
+	for _, name := range sortedKeys(p.cfg.Labels) {
+		path += "/" + groupingValue(name, p.cfg.Labels[name])
+	}
+
+	format := expfmt.NewFormat(expfmt.TypeTextPlain)
+	var body bytes.Buffer
+	encoder := expfmt.NewEncoder(&body, format)
+	for _, f := range families {
+		if err := encoder.Encode(f); err != nil {
+			return nil, fmt.Errorf("encode metrics: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(p.cfg.URL, "/")+path, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", string(format))
+	return req, nil
+}
+
+// groupingValue encodes one name/value pair of a Pushgateway grouping key;
+// values that cannot appear in a path segment use the base64 form
+func groupingValue(name, value string) string {
+	switch {
+	case value == "":
+		return name + "@base64/="
+	case strings.Contains(value, "/"):
+		return name + "@base64/" + base64.RawURLEncoding.EncodeToString([]byte(value))
+	}
+	return name + "/" + url.PathEscape(value)
+}
+
+// remoteWriteRequest sends every sample as a series with the job and the
+// configured labels added
+func (p *Pusher) remoteWriteRequest(ctx context.Context, families []*dto.MetricFamily, now time.Time) (*http.Request, error) {
+	extra := []prompb.Label{{Name: "job", Value: p.cfg.Job}}
+	for _, name := range sortedKeys(p.cfg.Labels) {
+		extra = append(extra, prompb.Label{Name: name, Value: p.cfg.Labels[name]})
+	}
+
+	data, err := writeRequest(families, extra, now.UnixMilli()).Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("encode remote-write request: %w", err)
+	}
+	body := snappy.Encode(nil, data)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	return req, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
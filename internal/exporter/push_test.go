@@ -0,0 +1,184 @@
+package exporter_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"xnetperf/config"
+	"xnetperf/internal/exporter"
+	"xnetperf/internal/service/analyze"
+	"xnetperf/internal/service/lat"
+	"xnetperf/internal/service/precheck"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// pushRequest is what the local stand-in of the Pushgateway or remote-write
+// endpoint received
+type pushRequest struct {
+	method  string
+	path    string
+	headers http.Header
+	body    []byte
+}
+
+func standIn(t *testing.T, status int) (*httptest.Server, *[]pushRequest) {
+	t.Helper()
+	var received []pushRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, pushRequest{method: r.Method, path: r.URL.EscapedPath(), headers: r.Header, body: body})
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &received
+}
+
+var (
+	precheckSummary = precheck.Summarize([]precheck.PrecheckResult{
+		{Hostname: "node1", HCA: "mlx5_0", Speed: "400 Gb/sec (4X NDR)", IsHealthy: true, SerialNumber: "SN001"},
+		{Hostname: "node2", HCA: "mlx5_0", Speed: "200 Gb/sec (4X HDR)", IsHealthy: false, SerialNumber: "SN002"},
+	})
+	bandwidthReport = &analyze.ReportData{
+		ClientData: map[string]map[string]*analyze.ClientDeviceData{
+			"node1": {"mlx5_0": {Hostname: "node1", Device: "mlx5_0", SerialNumber: "SN001", ActualBW: 391.5, TheoreticalBW: 400}},
+		},
+		ServerData: map[string]map[string]*analyze.ServerDeviceData{
+			"node2": {"mlx5_0": {Hostname: "node2", Device: "mlx5_0", SerialNumber: "SN002", RxBW: 388, TheoreticalBW: 400}},
+		},
+	}
+	latencySummary = &lat.LatencySummary{
+		Matrix: map[string]map[string]float64{"node1:mlx5_0": {"node2:mlx5_0": 2.5}},
+	}
+)
+
+func TestPushgateway(t *testing.T) {
+	srv, received := standIn(t, http.StatusOK)
+	pusher := exporter.NewPusher(config.MetricsPush{
+		URL:     srv.URL + "/",
+		Job:     "nightly",
+		Labels:  map[string]string{"cluster": "a1", "site": "bj/dc2"},
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+	})
+
+	if err := pusher.Push(context.Background(), "config.yaml", precheckSummary, bandwidthReport, latencySummary, "ignored"); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if len(*received) != 1 {
+		t.Fatalf("Got %d requests, want 1", len(*received))
+	}
+	req := (*received)[0]
+
+	if req.method != http.MethodPost {
+		t.Errorf("Method = %s, want POST", req.method)
+	}
+	if want := "/metrics/job/nightly/config/config.yaml/cluster/a1/site@base64/YmovZGMy"; req.path != want {
+		t.Errorf("Path = %s, want %s", req.path, want)
+	}
+	if got := req.headers.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization = %q", got)
+	}
+
+	body := string(req.body)
+	for _, line := range []string{
+		`xnetperf_hca_healthy{config="config.yaml",hca="mlx5_0",host="node1",serial="SN001"} 1`,
+		`xnetperf_hca_healthy{config="config.yaml",hca="mlx5_0",host="node2",serial="SN002"} 0`,
+		`xnetperf_hca_link_speed_gbps{config="config.yaml",hca="mlx5_0",host="node2",serial="SN002"} 200`,
+		`xnetperf_hca_bandwidth_gbps{config="config.yaml",direction="tx",hca="mlx5_0",host="node1",serial="SN001"} 391.5`,
+		`xnetperf_hca_bandwidth_gbps{config="config.yaml",direction="rx",hca="mlx5_0",host="node2",serial="SN002"} 388`,
+		`xnetperf_latency_microseconds{config="config.yaml",source_hca="mlx5_0",source_host="node1",source_serial="SN001",target_hca="mlx5_0",target_host="node2",target_serial="SN002"} 2.5`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("Body is missing %s\n%s", line, body)
+		}
+	}
+}
+
+func TestPushNothing(t *testing.T) {
+	srv, received := standIn(t, http.StatusOK)
+	pusher := exporter.NewPusher(config.MetricsPush{URL: srv.URL})
+	if err := pusher.Push(context.Background(), "config.yaml", "not a result"); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if len(*received) != 0 {
+		t.Errorf("Got %d requests, want none", len(*received))
+	}
+}
+
+func TestPushError(t *testing.T) {
+	srv, _ := standIn(t, http.StatusBadRequest)
+	pusher := exporter.NewPusher(config.MetricsPush{URL: srv.URL})
+	err := pusher.Push(context.Background(), "config.yaml", precheckSummary)
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("Push() error = %v, want status 400", err)
+	}
+}
+
+func TestRemoteWrite(t *testing.T) {
+	srv, received := standIn(t, http.StatusNoContent)
+	pusher := exporter.NewPusher(config.MetricsPush{
+		URL:    srv.URL + "/api/v1/push",
+		Mode:   config.PushModeRemoteWrite,
+		Labels: map[string]string{"cluster": "a1", "host": "overridden"},
+	})
+
+	if err := pusher.Push(context.Background(), "config.yaml", precheckSummary); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if len(*received) != 1 {
+		t.Fatalf("Got %d requests, want 1", len(*received))
+	}
+	req := (*received)[0]
+	if req.path != "/api/v1/push" {
+		t.Errorf("Path = %s", req.path)
+	}
+	if req.headers.Get("Content-Encoding") != "snappy" || req.headers.Get("Content-Type") != "application/x-protobuf" {
+		t.Errorf("Headers = %v", req.headers)
+	}
+
+	series := decodeWriteRequest(t, req.body)
+	want := map[string]float64{
+		`__name__=xnetperf_hca_healthy,cluster=a1,config=config.yaml,hca=mlx5_0,host=node1,job=xnetperf,serial=SN001`: 1,
+		`__name__=xnetperf_hca_healthy,cluster=a1,config=config.yaml,hca=mlx5_0,host=node2,job=xnetperf,serial=SN002`: 0,
+	}
+	for key, value := range want {
+		got, ok := series[key]
+		if !ok {
+			t.Errorf("Missing series %s in %v", key, series)
+			continue
+		}
+		if got != value {
+			t.Errorf("Series %s = %v, want %v", key, got, value)
+		}
+	}
+}
+
+// decodeWriteRequest returns the value of every series keyed by its labels
+func decodeWriteRequest(t *testing.T, body []byte) map[string]float64 {
+	t.Helper()
+	data, err := snappy.Decode(nil, body)
+	if err != nil {
+		t.Fatalf("Decode snappy: %v", err)
+	}
+	var req prompb.WriteRequest
+	if err := req.Unmarshal(data); err != nil {
+		t.Fatalf("Decode WriteRequest: %v", err)
+	}
+	series := make(map[string]float64)
+	for _, ts := range req.Timeseries {
+		var labels []string
+		for _, l := range ts.Labels {
+			labels = append(labels, l.Name+"="+l.Value)
+		}
+		if len(ts.Samples) != 1 {
+			t.Fatalf("Series %v has %d samples, want 1", labels, len(ts.Samples))
+		}
+		series[strings.Join(labels, ",")] = ts.Samples[0].Value
+	}
+	return series
+}
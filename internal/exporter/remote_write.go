@@ -0,0 +1,63 @@
+package exporter
+
+import (
+	"sort"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// writeRequest builds a remote-write request with one sample per metric,
+// adding the extra labels to every series
+func writeRequest(families []*dto.MetricFamily, extra []prompb.Label, timestampMs int64) *prompb.WriteRequest {
+	req := &prompb.WriteRequest{}
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			value, ok := sampleValue(f.GetType(), m)
+			if !ok {
+				continue
+			}
+			// Sample labels win over configured labels of the same name
+			labels := []prompb.Label{{Name: "__name__", Value: f.GetName()}}
+			for _, l := range m.GetLabel() {
+				labels = append(labels, prompb.Label{Name: l.GetName(), Value: l.GetValue()})
+			}
+			labels = append(labels, extra...)
+			req.Timeseries = append(req.Timeseries, prompb.TimeSeries{
+				Labels:  sortLabels(labels),
+				Samples: []prompb.Sample{{Value: value, Timestamp: timestampMs}},
+			})
+		}
+	}
+	return req
+}
+
+// sortLabels sorts labels by name, as remote write requires, keeping the
+// first of duplicate names
+func sortLabels(labels []prompb.Label) []prompb.Label {
+	sort.SliceStable(labels, func(i, j int) bool {
+		return labels[i].Name < labels[j].Name
+	})
+	unique := labels[:0]
+	for i, l := range labels {
+		if i > 0 && l.Name == labels[i-1].Name {
+			continue
+		}
+		unique = append(unique, l)
+	}
+	return unique
+}
+
+// sampleValue returns the value of a gauge, counter or untyped metric; the
+// exporter only pushes gauges
+func sampleValue(metricType dto.MetricType, m *dto.Metric) (float64, bool) {
+	switch metricType {
+	case dto.MetricType_GAUGE:
+		return m.GetGauge().GetValue(), true
+	case dto.MetricType_COUNTER:
+		return m.GetCounter().GetValue(), true
+	case dto.MetricType_UNTYPED:
+		return m.GetUntyped().GetValue(), true
+	}
+	return 0, false
+}
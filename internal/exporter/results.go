@@ -0,0 +1,195 @@
+package exporter
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"xnetperf/internal/service/analyze"
+	"xnetperf/internal/service/lat"
+	"xnetperf/internal/service/precheck"
+	"xnetperf/internal/service/workflow"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Results turns precheck summaries, bandwidth reports and latency reports into
+// gauges labelled by host, HCA and serial number. Each config keeps only its
+// latest result of every kind.
+type Results struct {
+	bandwidth   *prometheus.GaugeVec
+	theoretical *prometheus.GaugeVec
+	latency     *prometheus.GaugeVec
+	healthy     *prometheus.GaugeVec
+	linkSpeed   *prometheus.GaugeVec
+	resultTime  *prometheus.GaugeVec
+
+	mu      sync.Mutex
+	serials map[string]string // host -> serial number seen in precheck and bandwidth results
+}
+
+// NewResults creates the result gauges and registers them with registerer
+func NewResults(registerer prometheus.Registerer) *Results {
+	factory := promauto.With(registerer)
+	gauge := func(name, help string, labelNames ...string) *prometheus.GaugeVec {
+		return factory.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: help}, labelNames)
+	}
+	return &Results{
+		bandwidth: gauge("xnetperf_hca_bandwidth_gbps",
+			"Latest measured bandwidth of an HCA; direction is tx for clients, rx for servers and p2p.", "config", "host", "hca", "serial", "direction"),
+		theoretical: gauge("xnetperf_hca_bandwidth_theoretical_gbps",
+			"Expected bandwidth of an HCA in the latest bandwidth report.", "config", "host", "hca", "serial", "direction"),
+		latency: gauge("xnetperf_latency_microseconds",
+			"Latest measured average latency between two HCAs.", "config", "source_host", "source_hca", "source_serial", "target_host", "target_hca", "target_serial"),
+		healthy: gauge("xnetperf_hca_healthy",
+			"Whether the HCA passed the latest precheck (1) or not (0).", "config", "host", "hca", "serial"),
+		linkSpeed: gauge("xnetperf_hca_link_speed_gbps",
+			"Link speed of the HCA reported by the latest precheck.", "config", "host", "hca", "serial"),
+		resultTime: gauge("xnetperf_result_timestamp_seconds",
+			"Time of the latest bandwidth, latency or precheck result of a config.", "config", "kind"),
+		serials: make(map[string]string),
+	}
+}
+
+// Observe updates the gauges from a result, replacing the previous result of
+// the same kind for the config. It accepts *precheck.PrecheckSummary,
+// *analyze.ReportData, *lat.LatencySummary and *workflow.Result and reports
+// whether the result was one of them.
+func (r *Results) Observe(configName string, result any) bool {
+	switch v := result.(type) {
+	case *precheck.PrecheckSummary:
+		return r.observePrecheck(configName, v)
+	case *analyze.ReportData:
+		return r.observeBandwidth(configName, v)
+	case *lat.LatencySummary:
+		return r.observeLatency(configName, v)
+	case *workflow.Result:
+		if v == nil {
+			return false
+		}
+		observed := false
+		if v.Precheck != nil {
+			observed = r.observePrecheck(configName, v.Precheck)
+		}
+		if v.Report != nil {
+			observed = r.Observe(configName, v.Report) || observed
+		}
+		return observed
+	}
+	return false
+}
+
+func (r *Results) observePrecheck(configName string, summary *precheck.PrecheckSummary) bool {
+	if summary == nil {
+		return false
+	}
+	r.healthy.DeletePartialMatch(prometheus.Labels{"config": configName})
+	r.linkSpeed.DeletePartialMatch(prometheus.Labels{"config": configName})
+	for _, result := range summary.Results {
+		r.learnSerial(result.Hostname, result.SerialNumber)
+		healthy := 0.0
+		if result.IsHealthy && result.Error == "" {
+			healthy = 1
+		}
+		r.healthy.WithLabelValues(configName, result.Hostname, result.HCA, result.SerialNumber).Set(healthy)
+		if speed, ok := parseLinkSpeed(result.Speed); ok {
+			r.linkSpeed.WithLabelValues(configName, result.Hostname, result.HCA, result.SerialNumber).Set(speed)
+		}
+	}
+	r.resultTime.WithLabelValues(configName, "precheck").Set(nowSeconds())
+	return true
+}
+
+func (r *Results) observeBandwidth(configName string, report *analyze.ReportData) bool {
+	if report == nil {
+		return false
+	}
+	r.bandwidth.DeletePartialMatch(prometheus.Labels{"config": configName})
+	r.theoretical.DeletePartialMatch(prometheus.Labels{"config": configName})
+	for _, devices := range report.ClientData {
+		for _, d := range devices {
+			r.learnSerial(d.Hostname, d.SerialNumber)
+			if d.Status == analyze.StatusExcluded {
+				continue // not tested, a bandwidth of 0 would look like a dead link
+			}
+			r.bandwidth.WithLabelValues(configName, d.Hostname, d.Device, d.SerialNumber, "tx").Set(d.ActualBW)
+			r.theoretical.WithLabelValues(configName, d.Hostname, d.Device, d.SerialNumber, "tx").Set(d.TheoreticalBW)
+		}
+	}
+	for _, devices := range report.ServerData {
+		for _, d := range devices {
+			r.learnSerial(d.Hostname, d.SerialNumber)
+			if d.Status == analyze.StatusExcluded {
+				continue // not tested, a bandwidth of 0 would look like a dead link
+			}
+			r.bandwidth.WithLabelValues(configName, d.Hostname, d.Device, d.SerialNumber, "rx").Set(d.RxBW)
+			r.theoretical.WithLabelValues(configName, d.Hostname, d.Device, d.SerialNumber, "rx").Set(d.TheoreticalBW)
+		}
+	}
+	for _, devices := range report.P2PData {
+		for _, d := range devices {
+			r.bandwidth.WithLabelValues(configName, d.Hostname, d.Device, r.serial(d.Hostname), "p2p").Set(d.AvgSpeed)
+		}
+	}
+	r.resultTime.WithLabelValues(configName, "bandwidth").Set(nowSeconds())
+	return true
+}
+
+func (r *Results) observeLatency(configName string, summary *lat.LatencySummary) bool {
+	if summary == nil {
+		return false
+	}
+	r.latency.DeletePartialMatch(prometheus.Labels{"config": configName})
+	for source, targets := range summary.Matrix {
+		sourceHost, sourceHCA := splitEndpoint(source)
+		for target, latency := range targets {
+			targetHost, targetHCA := splitEndpoint(target)
+			r.latency.WithLabelValues(configName,
+				sourceHost, sourceHCA, r.serial(sourceHost),
+				targetHost, targetHCA, r.serial(targetHost)).Set(latency)
+		}
+	}
+	r.resultTime.WithLabelValues(configName, "latency").Set(nowSeconds())
+	return true
+}
+
+// learnSerial remembers the serial number of a host; latency reports carry
+// none, so they use the one seen in earlier results
+func (r *Results) learnSerial(host, serial string) {
+	if serial == "" || serial == "N/A" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.serials[host] = serial
+}
+
+func (r *Results) serial(host string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.serials[host]
+}
+
+// splitEndpoint splits a "host:hca" key of the latency matrix
+func splitEndpoint(endpoint string) (host, hca string) {
+	if i := strings.LastIndex(endpoint, ":"); i >= 0 {
+		return endpoint[:i], endpoint[i+1:]
+	}
+	return endpoint, ""
+}
+
+// parseLinkSpeed parses a port rate such as "400 Gb/sec (4X NDR)"
+func parseLinkSpeed(speed string) (float64, bool) {
+	fields := strings.Fields(speed)
+	if len(fields) == 0 {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	return value, err == nil
+}
+
+func nowSeconds() float64 {
+	return float64(time.Now().UnixNano()) / 1e9
+}
@@ -0,0 +1,30 @@
+package exporter_test
+
+import (
+	"testing"
+
+	"xnetperf/internal/exporter"
+	"xnetperf/internal/service/precheck"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestResultsReplacePreviousResult(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	results := exporter.NewResults(registry)
+
+	results.Observe("a.yaml", precheckSummary)
+	results.Observe("b.yaml", precheckSummary)
+	if got := testutil.CollectAndCount(registry, "xnetperf_hca_healthy"); got != 4 {
+		t.Fatalf("Got %d healthy series, want 4", got)
+	}
+
+	// a.yaml's new precheck only has node1; b.yaml keeps both hosts
+	results.Observe("a.yaml", precheck.Summarize([]precheck.PrecheckResult{
+		{Hostname: "node1", HCA: "mlx5_0", IsHealthy: true, SerialNumber: "SN001"},
+	}))
+	if got := testutil.CollectAndCount(registry, "xnetperf_hca_healthy"); got != 3 {
+		t.Errorf("Got %d healthy series, want 3", got)
+	}
+}
@@ -0,0 +1,567 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"xnetperf/internal/audit"
+	"xnetperf/internal/events"
+	"xnetperf/pkg/tools/logger"
+
+	"github.com/samber/lo"
+)
+
+const (
+	// DefaultWorkers is the number of jobs that run at the same time
+	DefaultWorkers = 4
+	// DefaultQueueSize is the number of jobs that can wait for a worker
+	DefaultQueueSize = 64
+	// DefaultMaxFinished is the number of finished jobs kept in memory
+	DefaultMaxFinished = 500
+	// maxLogLines is the number of log lines kept per job
+	maxLogLines = 1000
+)
+
+// State is the lifecycle state of a job
+type State string
+
+const (
+	StateQueued    State = "queued"
+	StateRunning   State = "running"
+	StateSucceeded State = "succeeded"
+	StateFailed    State = "failed"
+	StateCancelled State = "cancelled"
+)
+
+// Finished reports whether the state is terminal
+func (s State) Finished() bool {
+	return s == StateSucceeded || s == StateFailed || s == StateCancelled
+}
+
+var (
+	// ErrQueueFull is returned by Submit when no more jobs can be queued
+	ErrQueueFull = errors.New("job queue is full")
+	// ErrNotFound is returned for unknown job IDs
+	ErrNotFound = errors.New("job not found")
+	// ErrFinished is returned when cancelling a job that already finished
+	ErrFinished = errors.New("job already finished")
+	// ErrShutdown is returned by Submit after the manager was shut down
+	ErrShutdown = errors.New("job manager is shut down")
+)
+
+// LogEntry is one line of a job log
+type LogEntry struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+// Info is a point-in-time copy of a job, safe to serialize
+type Info struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	ConfigName string      `json:"config_name"`
+	Initiator  audit.Actor `json:"initiator"`
+	State      State       `json:"state"`
+	Progress   int         `json:"progress"` // 0-100
+	Step       string      `json:"step,omitempty"`
+	RunID      string      `json:"run_id,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	StartedAt  *time.Time  `json:"started_at,omitempty"`
+	FinishedAt *time.Time  `json:"finished_at,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	Result     any         `json:"result,omitempty"`
+	Logs       []LogEntry  `json:"logs,omitempty"`
+}
+
+// Func is the work of a job. It should return early once ctx is cancelled.
+// ctx carries the job's initiator as its audit actor, so the remote commands
+// of services given ctx are attributed to the job.
+type Func func(ctx context.Context, job *Job) (any, error)
+
+// Job is a unit of work executed by the Manager. It is also the event
+// publisher handed to the services the job runs.
+type Job struct {
+	mu     sync.Mutex
+	info   Info
+	fn     Func
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	bus    *events.Bus
+}
+
+// Publish publishes a service event to the job's subscribers
+func (j *Job) Publish(e events.Event) {
+	j.bus.Publish(e)
+}
+
+// auditActor returns the job's initiator tagged with the job ID
+func (j *Job) auditActor() audit.Actor {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	actor := j.info.Initiator
+	actor.JobID = j.info.ID
+	return actor
+}
+
+// Events returns the event bus of the job; it is closed when the job finishes
+func (j *Job) Events() *events.Bus {
+	return j.bus
+}
+
+// SetProgress records the progress (0-100) and the current step of the job
+func (j *Job) SetProgress(percent int, step string) {
+	percent = max(0, min(100, percent))
+	j.mu.Lock()
+	j.info.Progress = percent
+	j.info.Step = step
+	j.appendLogLocked(fmt.Sprintf("[%d%%] %s", percent, step))
+	j.mu.Unlock()
+	j.bus.Publish(events.Event{
+		Type:    events.TypeProgress,
+		Message: step,
+		Data:    map[string]any{"progress": percent},
+	})
+}
+
+// SetResult records an intermediate result, e.g. the step statuses of a
+// workflow; the value returned by the job function replaces it
+func (j *Job) SetResult(result any) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.info.Result = result
+}
+
+// SetRunID links the job to a recorded run
+func (j *Job) SetRunID(id string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.info.RunID = id
+}
+
+// Logf appends a line to the job log
+func (j *Job) Logf(format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	j.mu.Lock()
+	j.appendLogLocked(message)
+	j.mu.Unlock()
+	j.bus.Publish(events.Event{Type: events.TypeLog, Message: message})
+}
+
+func (j *Job) appendLogLocked(message string) {
+	j.info.Logs = append(j.info.Logs, LogEntry{Time: time.Now(), Message: message})
+	if over := len(j.info.Logs) - maxLogLines; over > 0 {
+		j.info.Logs = j.info.Logs[over:]
+	}
+}
+
+// publishState publishes the current state of the job
+func (j *Job) publishState() {
+	info := j.Info()
+	data := map[string]any{"state": info.State, "progress": info.Progress}
+	if info.Error != "" {
+		data["error"] = info.Error
+	}
+	if info.RunID != "" {
+		data["run_id"] = info.RunID
+	}
+	j.bus.Publish(events.Event{Type: events.TypeJobState, Message: string(info.State), Data: data})
+}
+
+// Info returns a copy of the job state
+func (j *Job) Info() Info {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	info := j.info
+	info.Logs = append([]LogEntry(nil), j.info.Logs...)
+	return info
+}
+
+func (j *Job) state() State {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.info.State
+}
+
+// Done is closed once the job reaches a terminal state
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// Filter narrows down the jobs returned by List; empty fields match everything
+type Filter struct {
+	ConfigName string
+	Type       string
+	State      State
+}
+
+// Manager queues jobs and runs them on a bounded pool of workers. Jobs with
+// the same config name never run at the same time, because the services of a
+// config share its hosts and report directories. Each config has its own FIFO
+// and only its head is handed to the workers, so a backlog for one config
+// never holds a worker slot that another config could use.
+type Manager struct {
+	logger      *slog.Logger
+	queueSize   int
+	maxFinished int
+
+	mu      sync.Mutex
+	ready   *sync.Cond
+	jobs    map[string]*Job
+	order   []string // job IDs in submission order
+	runq    []*Job   // jobs whose config is free, waiting for a worker
+	pending map[string][]*Job
+	active  map[string]bool // configs with a job in runq or running
+	queued  int             // jobs in runq or pending
+	closed  bool
+	nextID  int
+
+	onFinish []func(Info)
+
+	wg sync.WaitGroup
+}
+
+// New starts a manager with the given number of workers and queue size
+func New(workers, queueSize int) *Manager {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+	m := &Manager{
+		logger:      logger.GetLogger().With("module", "JOBS"),
+		queueSize:   queueSize,
+		maxFinished: DefaultMaxFinished,
+		jobs:        make(map[string]*Job),
+		pending:     make(map[string][]*Job),
+		active:      make(map[string]bool),
+	}
+	m.ready = sync.NewCond(&m.mu)
+	for i := 0; i < workers; i++ {
+		m.wg.Add(1)
+		go m.worker()
+	}
+	return m
+}
+
+// OnFinish registers fn to be called with the final state of every job, e.g.
+// to record job metrics
+func (m *Manager) OnFinish(fn func(Info)) *Manager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onFinish = append(m.onFinish, fn)
+	return m
+}
+
+// Submit queues a job of the given type for a config on behalf of the local
+// OS user
+func (m *Manager) Submit(jobType, configName string, fn Func) (*Job, error) {
+	return m.SubmitAs(audit.LocalActor(), jobType, configName, fn)
+}
+
+// SubmitAs queues a job on behalf of actor; the remote commands of the job are
+// attributed to it in the audit log
+func (m *Manager) SubmitAs(actor audit.Actor, jobType, configName string, fn Func) (*Job, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m.mu.Lock()
+	m.nextID++
+	job := &Job{
+		info: Info{
+			ID:         fmt.Sprintf("%s-%04d", time.Now().Format("20060102-150405"), m.nextID),
+			Type:       jobType,
+			ConfigName: configName,
+			Initiator:  actor,
+			State:      StateQueued,
+			CreatedAt:  time.Now(),
+		},
+		fn:     fn,
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		bus:    events.NewBus(events.DefaultHistorySize),
+	}
+
+	if m.closed || m.queued >= m.queueSize {
+		m.mu.Unlock()
+		cancel()
+		if m.closed {
+			return nil, ErrShutdown
+		}
+		return nil, ErrQueueFull
+	}
+	m.enqueueLocked(job)
+	m.jobs[job.info.ID] = job
+	m.order = append(m.order, job.info.ID)
+	m.pruneLocked()
+	m.mu.Unlock()
+
+	job.publishState()
+	job.Logf("Job queued")
+	m.logger.Info("Job queued", "id", job.info.ID, "type", jobType, "config", configName)
+	return job, nil
+}
+
+// Get returns a job by ID
+func (m *Manager) Get(id string) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return job, nil
+}
+
+// List returns the jobs matching the filter, newest first
+func (m *Manager) List(filter Filter) []Info {
+	m.mu.Lock()
+	jobs := make([]*Job, 0, len(m.order))
+	for _, id := range m.order {
+		jobs = append(jobs, m.jobs[id])
+	}
+	m.mu.Unlock()
+
+	var infos []Info
+	for _, job := range jobs {
+		info := job.Info()
+		if filter.ConfigName != "" && info.ConfigName != filter.ConfigName {
+			continue
+		}
+		if filter.Type != "" && info.Type != filter.Type {
+			continue
+		}
+		if filter.State != "" && info.State != filter.State {
+			continue
+		}
+		info.Logs = nil
+		infos = append(infos, info)
+	}
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].CreatedAt.After(infos[j].CreatedAt)
+	})
+	return infos
+}
+
+// Cancel cancels a queued or running job. Queued jobs are cancelled at once;
+// running jobs get their context cancelled and finish as cancelled when their
+// function returns.
+func (m *Manager) Cancel(id string) error {
+	job, err := m.Get(id)
+	if err != nil {
+		return err
+	}
+
+	state := job.state()
+	if state.Finished() {
+		return ErrFinished
+	}
+
+	job.Logf("Cancellation requested")
+	job.cancel()
+	m.finish(job, nil, context.Canceled, StateQueued)
+	m.dropPending(job)
+	m.logger.Info("Job cancellation requested", "id", id, "state", state)
+	return nil
+}
+
+// Shutdown cancels all jobs and waits for the workers to exit
+func (m *Manager) Shutdown() {
+	m.mu.Lock()
+	for _, job := range m.jobs {
+		job.cancel()
+	}
+	m.closed = true
+	m.ready.Broadcast()
+	m.mu.Unlock()
+	m.wg.Wait()
+}
+
+// enqueueLocked hands the job to the workers when its config is free and
+// appends it to the config's FIFO otherwise
+func (m *Manager) enqueueLocked(job *Job) {
+	m.queued++
+	name := job.info.ConfigName
+	if m.active[name] {
+		m.pending[name] = append(m.pending[name], job)
+		return
+	}
+	m.active[name] = true
+	m.runq = append(m.runq, job)
+	m.ready.Signal()
+}
+
+// release hands the next job of the config to the workers once the previous
+// one is done
+func (m *Manager) release(configName string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if next := m.pending[configName]; len(next) > 0 {
+		m.pending[configName] = next[1:]
+		m.runq = append(m.runq, next[0])
+		m.ready.Signal()
+		return
+	}
+	delete(m.pending, configName)
+	delete(m.active, configName)
+	if m.closed && len(m.active) == 0 {
+		m.ready.Broadcast()
+	}
+}
+
+// dropPending removes a cancelled job from its config's FIFO so it no longer
+// takes a queue slot
+func (m *Manager) dropPending(job *Job) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name := job.info.ConfigName
+	for i, pending := range m.pending[name] {
+		if pending == job {
+			m.pending[name] = append(m.pending[name][:i:i], m.pending[name][i+1:]...)
+			m.queued--
+			return
+		}
+	}
+}
+
+// next blocks until a job is ready; it returns nil once the manager is shut
+// down and every queued job was handed out
+func (m *Manager) next() *Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for len(m.runq) == 0 && !(m.closed && len(m.active) == 0) {
+		m.ready.Wait()
+	}
+	if len(m.runq) == 0 {
+		return nil
+	}
+	job := m.runq[0]
+	m.runq = m.runq[1:]
+	m.queued--
+	return job
+}
+
+func (m *Manager) worker() {
+	defer m.wg.Done()
+	for {
+		job := m.next()
+		if job == nil {
+			return
+		}
+		m.run(job)
+		m.release(job.info.ConfigName)
+	}
+}
+
+func (m *Manager) run(job *Job) {
+	if job.ctx.Err() != nil {
+		m.finish(job, nil, job.ctx.Err())
+		return
+	}
+
+	now := time.Now()
+	job.mu.Lock()
+	if job.info.State != StateQueued {
+		// Cancelled while waiting for the config
+		job.mu.Unlock()
+		return
+	}
+	job.info.State = StateRunning
+	job.info.StartedAt = &now
+	job.mu.Unlock()
+	job.publishState()
+	job.Logf("Job started")
+	m.logger.Info("Job started", "id", job.info.ID)
+
+	result, err := m.call(job)
+	m.finish(job, result, err)
+}
+
+// call runs the job function, turning panics into errors so a faulty job
+// cannot take down a worker
+func (m *Manager) call(job *Job) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return job.fn(audit.WithActor(job.ctx, job.auditActor()), job)
+}
+
+// finish moves the job to its terminal state. When from states are given,
+// the job is only finished if it is currently in one of them.
+func (m *Manager) finish(job *Job, result any, err error, from ...State) {
+	now := time.Now()
+	job.mu.Lock()
+	if job.info.State.Finished() || (len(from) > 0 && !lo.Contains(from, job.info.State)) {
+		job.mu.Unlock()
+		return
+	}
+	job.info.FinishedAt = &now
+	switch {
+	case job.ctx.Err() != nil:
+		job.info.State = StateCancelled
+		job.info.Error = "job cancelled"
+		if result != nil {
+			job.info.Result = result
+		}
+	case err != nil:
+		job.info.State = StateFailed
+		job.info.Error = err.Error()
+		job.info.Result = result
+	default:
+		job.info.State = StateSucceeded
+		job.info.Progress = 100
+		job.info.Result = result
+	}
+	state := job.info.State
+	job.mu.Unlock()
+
+	job.Logf("Job %s", state)
+	job.publishState()
+	job.bus.Close()
+	job.cancel()
+	close(job.done)
+	m.logger.Info("Job finished", "id", job.info.ID, "state", state)
+
+	m.mu.Lock()
+	hooks := m.onFinish
+	m.mu.Unlock()
+	if len(hooks) > 0 {
+		info := job.Info()
+		info.Logs = nil
+		for _, fn := range hooks {
+			fn(info)
+		}
+	}
+}
+
+// pruneLocked drops the oldest finished jobs beyond maxFinished
+func (m *Manager) pruneLocked() {
+	finished := 0
+	for _, id := range m.order {
+		if m.jobs[id].state().Finished() {
+			finished++
+		}
+	}
+	if finished <= m.maxFinished {
+		return
+	}
+
+	kept := m.order[:0]
+	for _, id := range m.order {
+		if finished > m.maxFinished && m.jobs[id].state().Finished() {
+			delete(m.jobs, id)
+			finished--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	m.order = kept
+}
@@ -0,0 +1,227 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"xnetperf/internal/audit"
+	"xnetperf/internal/jobs"
+)
+
+func waitDone(t *testing.T, job *jobs.Job) jobs.Info {
+	t.Helper()
+	select {
+	case <-job.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Job %s did not finish", job.Info().ID)
+	}
+	return job.Info()
+}
+
+func TestJobLifecycle(t *testing.T) {
+	m := jobs.New(2, 8)
+	defer m.Shutdown()
+
+	tests := []struct {
+		name      string
+		fn        jobs.Func
+		wantState jobs.State
+		wantError string
+	}{
+		{
+			name: "succeeded",
+			fn: func(ctx context.Context, job *jobs.Job) (any, error) {
+				job.SetProgress(50, "halfway")
+				return "ok", nil
+			},
+			wantState: jobs.StateSucceeded,
+		},
+		{
+			name: "failed",
+			fn: func(ctx context.Context, job *jobs.Job) (any, error) {
+				return nil, errors.New("boom")
+			},
+			wantState: jobs.StateFailed,
+			wantError: "boom",
+		},
+		{
+			name: "panicked",
+			fn: func(ctx context.Context, job *jobs.Job) (any, error) {
+				panic("oops")
+			},
+			wantState: jobs.StateFailed,
+			wantError: "job panicked: oops",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job, err := m.Submit("test", tt.name+".yaml", tt.fn)
+			if err != nil {
+				t.Fatalf("Submit failed: %v", err)
+			}
+			info := waitDone(t, job)
+			if info.State != tt.wantState {
+				t.Errorf("Expected state %s, got %s", tt.wantState, info.State)
+			}
+			if info.Error != tt.wantError {
+				t.Errorf("Expected error %q, got %q", tt.wantError, info.Error)
+			}
+			if info.StartedAt == nil || info.FinishedAt == nil {
+				t.Error("Expected start and finish times")
+			}
+			if len(info.Logs) == 0 {
+				t.Error("Expected job logs")
+			}
+			if tt.wantState == jobs.StateSucceeded && (info.Progress != 100 || info.Result != "ok") {
+				t.Errorf("Unexpected progress/result: %d/%v", info.Progress, info.Result)
+			}
+		})
+	}
+}
+
+func TestJobCancel(t *testing.T) {
+	m := jobs.New(1, 8)
+	defer m.Shutdown()
+
+	started := make(chan struct{})
+	running, err := m.Submit("test", "a.yaml", func(ctx context.Context, job *jobs.Job) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	<-started
+
+	// The only worker is busy, so this job stays queued
+	queued, err := m.Submit("test", "b.yaml", func(ctx context.Context, job *jobs.Job) (any, error) {
+		t.Error("Cancelled job must not run")
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if state := queued.Info().State; state != jobs.StateQueued {
+		t.Fatalf("Expected queued job, got %s", state)
+	}
+
+	if err := m.Cancel(queued.Info().ID); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if info := waitDone(t, queued); info.State != jobs.StateCancelled || info.StartedAt != nil {
+		t.Errorf("Expected queued job to be cancelled without starting, got %+v", info)
+	}
+
+	if err := m.Cancel(running.Info().ID); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if info := waitDone(t, running); info.State != jobs.StateCancelled {
+		t.Errorf("Expected running job to be cancelled, got %s", info.State)
+	}
+
+	if err := m.Cancel(running.Info().ID); !errors.Is(err, jobs.ErrFinished) {
+		t.Errorf("Expected ErrFinished, got %v", err)
+	}
+	if err := m.Cancel("missing"); !errors.Is(err, jobs.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestJobsOfSameConfigRunSerially(t *testing.T) {
+	m := jobs.New(4, 8)
+	defer m.Shutdown()
+
+	active := make(chan struct{}, 4)
+	fn := func(ctx context.Context, job *jobs.Job) (any, error) {
+		select {
+		case active <- struct{}{}:
+		default:
+		}
+		if len(active) > 1 {
+			return nil, errors.New("jobs of the same config overlapped")
+		}
+		time.Sleep(20 * time.Millisecond)
+		<-active
+		return nil, nil
+	}
+
+	var submitted []*jobs.Job
+	for i := 0; i < 3; i++ {
+		job, err := m.Submit("test", "same.yaml", fn)
+		if err != nil {
+			t.Fatalf("Submit failed: %v", err)
+		}
+		submitted = append(submitted, job)
+	}
+	for _, job := range submitted {
+		if info := waitDone(t, job); info.State != jobs.StateSucceeded {
+			t.Errorf("Job %s: expected succeeded, got %s (%s)", info.ID, info.State, info.Error)
+		}
+	}
+
+	if got := len(m.List(jobs.Filter{ConfigName: "same.yaml"})); got != 3 {
+		t.Errorf("Expected 3 listed jobs, got %d", got)
+	}
+}
+
+func TestBusyConfigDoesNotStarveOthers(t *testing.T) {
+	m := jobs.New(2, 8)
+	defer m.Shutdown()
+
+	release := make(chan struct{})
+	busy := func(ctx context.Context, job *jobs.Job) (any, error) {
+		<-release
+		return nil, nil
+	}
+
+	// One running and two waiting jobs for the same config must leave the
+	// second worker free
+	var busyJobs []*jobs.Job
+	for i := 0; i < 3; i++ {
+		job, err := m.Submit("test", "busy.yaml", busy)
+		if err != nil {
+			t.Fatalf("Submit failed: %v", err)
+		}
+		busyJobs = append(busyJobs, job)
+	}
+
+	other, err := m.Submit("test", "other.yaml", func(ctx context.Context, job *jobs.Job) (any, error) {
+		return "ok", nil
+	})
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if info := waitDone(t, other); info.State != jobs.StateSucceeded {
+		t.Errorf("Expected other config's job to succeed, got %s", info.State)
+	}
+
+	close(release)
+	for _, job := range busyJobs {
+		if info := waitDone(t, job); info.State != jobs.StateSucceeded {
+			t.Errorf("Job %s: expected succeeded, got %s", info.ID, info.State)
+		}
+	}
+}
+
+func TestJobContextCarriesInitiator(t *testing.T) {
+	m := jobs.New(1, 8)
+	defer m.Shutdown()
+
+	actor := audit.Actor{User: "carol", Auth: "token"}
+	job, err := m.SubmitAs(actor, "test", "config.yaml", func(ctx context.Context, job *jobs.Job) (any, error) {
+		return audit.ActorFrom(ctx), nil
+	})
+	if err != nil {
+		t.Fatalf("SubmitAs failed: %v", err)
+	}
+	info := waitDone(t, job)
+
+	got, ok := info.Result.(audit.Actor)
+	if !ok || got.User != "carol" || got.Auth != "token" || got.JobID != info.ID {
+		t.Errorf("Job actor = %+v, want carol/token tagged with job %s", info.Result, info.ID)
+	}
+}
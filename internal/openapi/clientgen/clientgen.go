@@ -0,0 +1,500 @@
+// Package clientgen generates the typed Go client of the server API from its
+// OpenAPI document.
+//
+// Component schemas of the packages matched by Options.Copy are copied as
+// standalone structs with the JSON layout of the Go types they were generated
+// from, so the client does not depend on the packages of the server. Other
+// component schemas become type aliases. Every operation answering with the
+// {code, message, data} envelope becomes a method of Client.
+package clientgen
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"go/format"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"xnetperf/internal/openapi"
+)
+
+var textMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// Options controls the generated code
+type Options struct {
+	Package string   // package name of the generated file
+	Copy    []string // import paths whose types are copied instead of aliased; a trailing / matches a path prefix
+	Skip    []string // component schemas not to generate, e.g. the envelope
+}
+
+type generator struct {
+	doc     *openapi.Document
+	opts    Options
+	imports map[string]string // import path -> name
+	names   map[reflect.Type]string
+	body    bytes.Buffer
+}
+
+// Generate renders the client code of doc
+func Generate(doc *openapi.Document, opts Options) ([]byte, error) {
+	g := &generator{
+		doc:     doc,
+		opts:    opts,
+		imports: map[string]string{"context": "context", "net/url": "url"},
+		names:   make(map[reflect.Type]string),
+	}
+	for _, name := range sortedKeys(doc.Components.Schemas) {
+		if t, ok := doc.GoType(name); ok {
+			g.names[t] = name
+		}
+	}
+
+	if err := g.types(); err != nil {
+		return nil, err
+	}
+	if err := g.operations(); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by xnetperf/pkg/client/internal/gen; DO NOT EDIT.\n\npackage %s\n\nimport (\n", opts.Package)
+	std := true
+	for _, importPath := range sortedKeys(g.imports) {
+		// Standard library first, then the packages of the module
+		if std && strings.Contains(importPath, "xnetperf") {
+			out.WriteString("\n")
+			std = false
+		}
+		if name := g.imports[importPath]; name != path.Base(importPath) {
+			fmt.Fprintf(&out, "\t%s %q\n", name, importPath)
+		} else {
+			fmt.Fprintf(&out, "\t%q\n", importPath)
+		}
+	}
+	out.WriteString(")\n")
+	out.Write(g.body.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w\n%s", err, out.Bytes())
+	}
+	return src, nil
+}
+
+// types writes an alias or a struct for every component schema
+func (g *generator) types() error {
+	for _, name := range sortedKeys(g.doc.Components.Schemas) {
+		if contains(g.opts.Skip, name) {
+			continue
+		}
+		t, ok := g.doc.GoType(name)
+		if !ok {
+			return fmt.Errorf("schema %s has no Go type", name)
+		}
+		if g.copied(t.PkgPath()) {
+			if err := g.copyStruct(name, t); err != nil {
+				return err
+			}
+			continue
+		}
+		fmt.Fprintf(&g.body, "\n// %s is %s.%s\ntype %s = %s\n", name, path.Base(t.PkgPath()), t.Name(), name, g.qualified(t))
+	}
+	return nil
+}
+
+// copyStruct writes a struct with the JSON layout of t
+func (g *generator) copyStruct(name string, t reflect.Type) error {
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("schema %s: cannot copy %s", name, t)
+	}
+	fields, err := g.structFields(t, "\t")
+	if err != nil {
+		return fmt.Errorf("schema %s: %w", name, err)
+	}
+	fmt.Fprintf(&g.body, "\n// %s is %s.%s\ntype %s struct {\n%s}\n", name, path.Base(t.PkgPath()), t.Name(), name, fields)
+	return nil
+}
+
+// structFields returns the field lines of a copied struct. Embedded structs
+// without a JSON name are flattened as encoding/json does, since they are not
+// component schemas of their own.
+func (g *generator) structFields(t reflect.Type, indent string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, hasTag := f.Tag.Lookup("json")
+		if tag == "-" {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && !hasTag && ft.Kind() == reflect.Struct && g.copied(ft.PkgPath()) {
+			fields, err := g.structFields(ft, indent)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(fields)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		expr, err := g.typeExpr(f.Type)
+		if err != nil {
+			return "", fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		if hasTag {
+			tag = fmt.Sprintf(" `json:%q`", tag)
+		}
+		if f.Anonymous {
+			fmt.Fprintf(&b, "%s%s%s\n", indent, expr, tag)
+		} else {
+			fmt.Fprintf(&b, "%s%s %s%s\n", indent, f.Name, expr, tag)
+		}
+	}
+	return b.String(), nil
+}
+
+// typeExpr returns the Go expression of a field type, naming component
+// types by their schema name. Other named types of copied packages are
+// replaced by the type they encode as.
+func (g *generator) typeExpr(t reflect.Type) (string, error) {
+	if name, ok := g.names[t]; ok {
+		return name, nil
+	}
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name(), nil
+		}
+		if !g.copied(t.PkgPath()) {
+			return g.qualified(t), nil
+		}
+		// Types such as roles encode themselves as JSON strings
+		if t.Implements(textMarshaler) || reflect.PointerTo(t).Implements(textMarshaler) {
+			return "string", nil
+		}
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return t.Kind().String(), nil
+	case reflect.Pointer:
+		elem, err := g.typeExpr(t.Elem())
+		return "*" + elem, err
+	case reflect.Slice:
+		elem, err := g.typeExpr(t.Elem())
+		return "[]" + elem, err
+	case reflect.Map:
+		key, err := g.typeExpr(t.Key())
+		if err != nil {
+			return "", err
+		}
+		elem, err := g.typeExpr(t.Elem())
+		return "map[" + key + "]" + elem, err
+	case reflect.Struct:
+		if t.Name() != "" {
+			return "", fmt.Errorf("%s is not a component schema", t)
+		}
+		fields, err := g.structFields(t, "")
+		if err != nil {
+			return "", err
+		}
+		return "struct {\n" + fields + "}", nil
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "any", nil
+		}
+	}
+	return "", fmt.Errorf("unsupported type %s", t)
+}
+
+// copied reports whether the types of a package are copied
+func (g *generator) copied(importPath string) bool {
+	for _, c := range g.opts.Copy {
+		if importPath == c || (strings.HasSuffix(c, "/") && strings.HasPrefix(importPath, c)) {
+			return true
+		}
+	}
+	return false
+}
+
+// qualified returns pkg.Name of a named type, adding its import
+func (g *generator) qualified(t reflect.Type) string {
+	return g.importName(t.PkgPath()) + "." + t.Name()
+}
+
+func (g *generator) importName(importPath string) string {
+	if name, ok := g.imports[importPath]; ok {
+		return name
+	}
+	name := path.Base(importPath)
+	for taken := true; taken; {
+		taken = false
+		for _, existing := range g.imports {
+			if existing == name {
+				name = path.Base(path.Dir(importPath)) + name
+				taken = true
+				break
+			}
+		}
+	}
+	g.imports[importPath] = name
+	return name
+}
+
+// operations writes a method for every operation answering with the envelope
+func (g *generator) operations() error {
+	var err error
+	g.doc.Operations(func(method, p string, op *openapi.Operation) {
+		if err == nil {
+			err = g.operation(strings.ToUpper(method), p, op)
+		}
+	})
+	return err
+}
+
+var pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+func (g *generator) operation(method, p string, op *openapi.Operation) error {
+	response, status := successResponse(op)
+	if response == nil {
+		return nil
+	}
+	name := goName(op.OperationID)
+
+	args := []string{"ctx context.Context"}
+	var pathExpr []string
+	last := 0
+	for _, m := range pathParam.FindAllStringSubmatchIndex(p, -1) {
+		param := p[m[2]:m[3]]
+		args = append(args, param+" string")
+		pathExpr = append(pathExpr, fmt.Sprintf("%q", p[last:m[0]]), "url.PathEscape("+param+")")
+		last = m[1]
+	}
+	if last < len(p) {
+		pathExpr = append(pathExpr, fmt.Sprintf("%q", p[last:]))
+	}
+
+	bodyArg := "nil"
+	if op.RequestBody != nil {
+		bodyType, err := g.schemaType(op.RequestBody.Content["application/json"].Schema)
+		if err != nil {
+			return fmt.Errorf("%s request body: %w", op.OperationID, err)
+		}
+		args = append(args, "body "+bodyType)
+		bodyArg = "body"
+	}
+
+	queryArg := "nil"
+	var query []*openapi.Parameter
+	for _, param := range op.Parameters {
+		// wait=true is the synchronous variant; the client waits for jobs itself
+		if param.In == "query" && param.Name != "wait" {
+			query = append(query, param)
+		}
+	}
+	if len(query) > 0 {
+		if err := g.params(name+"Params", op.OperationID, query); err != nil {
+			return err
+		}
+		args = append(args, "params *"+name+"Params")
+		queryArg = "params.values()"
+	}
+
+	var dataType string
+	if data := dataSchema(response); data != nil {
+		var err error
+		if dataType, err = g.schemaType(data); err != nil {
+			return fmt.Errorf("%s response: %w", op.OperationID, err)
+		}
+	}
+
+	fmt.Fprintf(&g.body, "\n// %s %s\n//\n// %s %s", name, summaryOf(op), method, p)
+	if op.Role != "" {
+		fmt.Fprintf(&g.body, " (%s)", op.Role)
+	}
+	if status == "202" {
+		g.body.WriteString("\n//\n// The server runs it as a job; wait for the result with WaitJob.")
+	}
+	fmt.Fprintf(&g.body, "\nfunc (c *Client) %s(%s) ", name, strings.Join(args, ", "))
+	call := fmt.Sprintf("c.do(ctx, %q, %s, %s, %s", method, strings.Join(pathExpr, " + "), queryArg, bodyArg)
+	switch {
+	case dataType == "":
+		fmt.Fprintf(&g.body, "error {\n\treturn %s, nil)\n}\n", call)
+	case g.isStruct(dataType):
+		fmt.Fprintf(&g.body, "(*%s, error) {\n\tvar out %s\n\tif err := %s, &out); err != nil {\n\t\treturn nil, err\n\t}\n\treturn &out, nil\n}\n", dataType, dataType, call)
+	default:
+		fmt.Fprintf(&g.body, "(%s, error) {\n\tvar out %s\n\terr := %s, &out)\n\treturn out, err\n}\n", dataType, dataType, call)
+	}
+	return nil
+}
+
+// params writes the struct of the query parameters of an operation
+func (g *generator) params(name, operationID string, query []*openapi.Parameter) error {
+	fmt.Fprintf(&g.body, "\n// %s are the optional query parameters of %s\ntype %s struct {\n", name, goName(operationID), name)
+	for _, param := range query {
+		typ, err := g.schemaType(param.Schema)
+		if err != nil {
+			return fmt.Errorf("%s parameter %s: %w", operationID, param.Name, err)
+		}
+		if param.Description != "" {
+			fmt.Fprintf(&g.body, "\t// %s\n", param.Description)
+		}
+		fmt.Fprintf(&g.body, "\t%s %s\n", goName(param.Name), typ)
+	}
+	g.body.WriteString("}\n")
+
+	g.imports["strconv"] = "strconv"
+	fmt.Fprintf(&g.body, "\nfunc (p *%s) values() url.Values {\n\tif p == nil {\n\t\treturn nil\n\t}\n\tv := url.Values{}\n", name)
+	for _, param := range query {
+		field := "p." + goName(param.Name)
+		switch param.Schema.Type {
+		case "integer":
+			fmt.Fprintf(&g.body, "\tif %s != 0 {\n\t\tv.Set(%q, strconv.FormatInt(int64(%s), 10))\n\t}\n", field, param.Name, field)
+		case "boolean":
+			fmt.Fprintf(&g.body, "\tif %s {\n\t\tv.Set(%q, strconv.FormatBool(%s))\n\t}\n", field, param.Name, field)
+		default:
+			fmt.Fprintf(&g.body, "\tif %s != \"\" {\n\t\tv.Set(%q, %s)\n\t}\n", field, param.Name, field)
+		}
+	}
+	g.body.WriteString("\treturn v\n}\n")
+	return nil
+}
+
+// schemaType returns the Go type of a schema used by an operation
+func (g *generator) schemaType(s *openapi.Schema) (string, error) {
+	if name := s.RefName(); name != "" {
+		if _, ok := g.doc.Components.Schemas[name]; !ok {
+			return "", fmt.Errorf("unknown schema %s", name)
+		}
+		return name, nil
+	}
+	switch s.Type {
+	case "string":
+		if s.Format == "date-time" {
+			g.imports["time"] = "time"
+			return "time.Time", nil
+		}
+		return "string", nil
+	case "integer":
+		return "int", nil
+	case "number":
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		elem, err := g.schemaType(s.Items)
+		return "[]" + elem, err
+	case "object":
+		if s.AdditionalProperties != nil {
+			elem, err := g.schemaType(s.AdditionalProperties)
+			return "map[string]" + elem, err
+		}
+	case "":
+		g.imports["encoding/json"] = "json"
+		return "json.RawMessage", nil
+	}
+	return "", fmt.Errorf("unsupported schema %+v", *s)
+}
+
+func (g *generator) isStruct(typ string) bool {
+	_, ok := g.doc.Components.Schemas[typ]
+	return ok
+}
+
+// successResponse returns the JSON envelope response of an operation,
+// preferring the 202 of asynchronous jobs
+func successResponse(op *openapi.Operation) (*openapi.Schema, string) {
+	for _, status := range []string{"202", "200"} {
+		response, ok := op.Responses[status]
+		if !ok {
+			continue
+		}
+		media, ok := response.Content["application/json"]
+		if !ok || media.Schema == nil {
+			return nil, ""
+		}
+		if media.Schema.RefName() == "Response" ||
+			(len(media.Schema.AllOf) > 0 && media.Schema.AllOf[0].RefName() == "Response") {
+			return media.Schema, status
+		}
+		return nil, ""
+	}
+	return nil, ""
+}
+
+// dataSchema returns the schema of the data field of an envelope
+func dataSchema(envelope *openapi.Schema) *openapi.Schema {
+	for _, s := range envelope.AllOf {
+		if data, ok := s.Properties["data"]; ok {
+			return data
+		}
+	}
+	return nil
+}
+
+// initialisms are written in upper case in Go names
+var initialisms = map[string]bool{"id": true, "url": true, "hca": true, "hcas": true, "json": true, "api": true}
+
+// goName converts snake_case and camelCase names to exported Go names,
+// e.g. job_id -> JobID, getHCAs -> GetHCAs
+func goName(s string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(s, "_") {
+		if initialisms[strings.ToLower(part)] {
+			if strings.ToLower(part) == "hcas" {
+				b.WriteString("HCAs")
+			} else {
+				b.WriteString(strings.ToUpper(part))
+			}
+			continue
+		}
+		if part != "" {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
+
+// summaryOf returns the summary of an operation for its method comment
+func summaryOf(op *openapi.Operation) string {
+	if op.Summary == "" {
+		return "calls " + op.OperationID
+	}
+	return op.Summary
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ClientOptions generate pkg/client: all types of the module are copied, so
+// the client depends neither on gin nor on the internal packages, and the
+// envelope is handled by Client itself
+var ClientOptions = Options{
+	Package: "client",
+	Copy:    []string{"xnetperf/"},
+	Skip:    []string{"Response"},
+}
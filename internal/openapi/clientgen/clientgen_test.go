@@ -0,0 +1,94 @@
+package clientgen_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+	"testing"
+
+	"xnetperf/config"
+	"xnetperf/internal/openapi"
+	"xnetperf/internal/openapi/clientgen"
+	"xnetperf/server"
+
+	"github.com/gin-gonic/gin"
+)
+
+func serverDocument(t *testing.T) *openapi.Document {
+	t.Helper()
+	return server.NewServer(config.HTTPServer{}, nil, nil).OpenAPI()
+}
+
+func routeCount(t *testing.T) int {
+	t.Helper()
+	engine, ok := server.NewServer(config.HTTPServer{}, nil, nil).Handler().(*gin.Engine)
+	if !ok {
+		t.Fatal("Server handler is not a gin engine")
+	}
+	return len(engine.Routes())
+}
+
+// TestOpenAPICoversRoutes fails when a route was added without describing it
+// in the apiOperations table of the server
+func TestOpenAPICoversRoutes(t *testing.T) {
+	doc := serverDocument(t)
+
+	count := 0
+	doc.Operations(func(method, path string, op *openapi.Operation) {
+		count++
+		if op.Summary == "" || len(op.Tags) == 0 {
+			t.Errorf("%s %s is not described in apiOperations", strings.ToUpper(method), path)
+		}
+	})
+	if want := routeCount(t); count != want {
+		t.Errorf("Document has %d operations, want one per route (%d)", count, want)
+	}
+
+	// Every reference must point to a component
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, part := range strings.Split(string(data), `"$ref":"#/components/schemas/`)[1:] {
+		name := part[:strings.IndexByte(part, '"')]
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("Reference to missing schema %s", name)
+		}
+	}
+	for _, name := range []string{"Response", "ReportData", "LatencySummary", "ConnectivitySummary", "ProbeSummary", "PrecheckSummary", "Job"} {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("Schema %s missing", name)
+		}
+	}
+}
+
+// TestGeneratedClientUpToDate fails when pkg/client was not regenerated
+// after changing routes or response types; run go generate ./pkg/client
+func TestGeneratedClientUpToDate(t *testing.T) {
+	want, err := clientgen.Generate(serverDocument(t), clientgen.ClientOptions)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	got, err := os.ReadFile("../../../pkg/client/zz_generated.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("pkg/client/zz_generated.go is out of date; run go generate ./pkg/client")
+	}
+
+	// The client must be usable outside the module, so it copies the types
+	// instead of referring to internal packages
+	file, err := parser.ParseFile(token.NewFileSet(), "zz_generated.go", want, parser.ImportsOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, spec := range file.Imports {
+		if strings.HasPrefix(strings.Trim(spec.Path.Value, `"`), "xnetperf/") {
+			t.Errorf("Generated client imports %s", spec.Path.Value)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// Package openapi builds OpenAPI 3.0 documents, deriving the schemas of
+// request and response bodies from Go types by reflection.
+package openapi
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Version is the OpenAPI version of generated documents
+const Version = "3.0.3"
+
+// Document is an OpenAPI document. Only the parts used by the server are
+// modelled.
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Paths      map[string]*PathItem  `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []map[string][]string `json:"security,omitempty"`
+	Tags       []Tag                 `json:"tags,omitempty"`
+
+	goTypes  map[string]reflect.Type // component name -> Go type
+	names    map[reflect.Type]string
+	reserved map[string]reflect.Type // names set with SetName
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Tag groups operations
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// Components holds the schemas referenced by operations and the ways
+// callers authenticate
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme is an authentication method
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations of one path, keyed by lower-case method
+type PathItem map[string]*Operation
+
+// Operation is one method of a path
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	// Role is the minimum role a caller needs when authentication is enabled
+	Role string `json:"x-required-role,omitempty"`
+}
+
+// Parameter is a path, query or header parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody is the body of an operation
+type RequestBody struct {
+	Description string                `json:"description,omitempty"`
+	Required    bool                  `json:"required,omitempty"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+// Response is one response of an operation
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the schema of one content type
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Schema is a JSON schema in the OpenAPI 3.0 dialect
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+}
+
+// RefName returns the component name of a $ref schema, or "" if the schema
+// is not a reference
+func (s *Schema) RefName() string {
+	if s == nil {
+		return ""
+	}
+	return strings.TrimPrefix(s.Ref, refPrefix)
+}
+
+// NewDocument creates an empty document
+func NewDocument(info Info) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			Schemas:         make(map[string]*Schema),
+			SecuritySchemes: make(map[string]*SecurityScheme),
+		},
+		goTypes:  make(map[string]reflect.Type),
+		names:    make(map[reflect.Type]string),
+		reserved: make(map[string]reflect.Type),
+	}
+}
+
+// AddOperation adds an operation to a gin route; path parameters such as
+// /:name are converted to /{name}
+func (d *Document) AddOperation(method, ginPath string, op *Operation) {
+	path := ConvertPath(ginPath)
+	item, ok := d.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+	(*item)[strings.ToLower(method)] = op
+}
+
+// Operations calls fn for every operation, ordered by path and method
+func (d *Document) Operations(fn func(method, path string, op *Operation)) {
+	for _, path := range sortedKeys(d.Paths) {
+		item := *d.Paths[path]
+		for _, method := range sortedKeys(item) {
+			fn(method, path, item[method])
+		}
+	}
+}
+
+// GoType returns the Go type a component schema was generated from
+func (d *Document) GoType(schemaName string) (reflect.Type, bool) {
+	t, ok := d.goTypes[schemaName]
+	return t, ok
+}
+
+var ginParam = regexp.MustCompile(`[:*]([A-Za-z_][A-Za-z0-9_]*)`)
+
+// ConvertPath turns a gin route path into an OpenAPI path template
+func ConvertPath(ginPath string) string {
+	return ginParam.ReplaceAllString(ginPath, "{$1}")
+}
+
+// PathParams returns the parameter names of a gin route path in order
+func PathParams(ginPath string) []string {
+	var names []string
+	for _, m := range ginParam.FindAllStringSubmatch(ginPath, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
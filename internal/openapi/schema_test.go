@@ -0,0 +1,131 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"xnetperf/internal/openapi"
+)
+
+type level int
+
+func (l level) MarshalText() ([]byte, error) { return []byte("high"), nil }
+
+type Base struct {
+	ID string `json:"id"`
+}
+
+type Item struct {
+	Base
+	Name     string            `json:"name"`
+	Note     string            `json:"note,omitempty"`
+	Tags     []string          `json:"tags"`
+	Counts   map[string]int    `json:"counts,omitempty"`
+	Created  time.Time         `json:"created"`
+	Finished *time.Time        `json:"finished,omitempty"`
+	Level    level             `json:"level"`
+	Raw      json.RawMessage   `json:"raw,omitempty"`
+	Any      any               `json:"any,omitempty"`
+	Children map[string]*Item  `json:"children,omitempty"`
+	Options  struct{ On bool } `json:"options"`
+	Secret   string            `json:"-"`
+	Required string            `json:"required,omitempty" binding:"required"`
+	internal string
+}
+
+func TestSchema(t *testing.T) {
+	doc := openapi.NewDocument(openapi.Info{Title: "test", Version: "v1"})
+
+	if got := doc.Schema([]Item{}); got.Type != "array" || got.Items.Ref != "#/components/schemas/Item" {
+		t.Fatalf("Schema([]Item) = %+v", got)
+	}
+
+	item := doc.Components.Schemas["Item"]
+	if item == nil {
+		t.Fatalf("Item was not registered: %v", doc.Components.Schemas)
+	}
+	if goType, ok := doc.GoType("Item"); !ok || goType != reflect.TypeOf(Item{}) {
+		t.Errorf("GoType(Item) = %v, %v", goType, ok)
+	}
+
+	tests := []struct {
+		property string
+		want     openapi.Schema
+	}{
+		{"id", openapi.Schema{Type: "string"}},
+		{"name", openapi.Schema{Type: "string"}},
+		{"tags", openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string"}}},
+		{"counts", openapi.Schema{Type: "object", AdditionalProperties: &openapi.Schema{Type: "integer", Format: "int64"}}},
+		{"created", openapi.Schema{Type: "string", Format: "date-time"}},
+		{"finished", openapi.Schema{Type: "string", Format: "date-time"}},
+		{"level", openapi.Schema{Type: "string"}},
+		{"raw", openapi.Schema{}},
+		{"any", openapi.Schema{}},
+		{"children", openapi.Schema{Type: "object", AdditionalProperties: openapi.Ref("Item")}},
+		{"options", openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{"On": {Type: "boolean"}}, Required: []string{"On"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.property, func(t *testing.T) {
+			got, ok := item.Properties[tt.property]
+			if !ok {
+				t.Fatalf("Property %s missing", tt.property)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Property %s = %+v, want %+v", tt.property, *got, tt.want)
+			}
+		})
+	}
+
+	for _, name := range []string{"Secret", "internal", "Base"} {
+		if _, ok := item.Properties[name]; ok {
+			t.Errorf("Property %s should not be present", name)
+		}
+	}
+	wantRequired := []string{"created", "id", "level", "name", "options", "required", "tags"}
+	if !reflect.DeepEqual(item.Required, wantRequired) {
+		t.Errorf("Required = %v, want %v", item.Required, wantRequired)
+	}
+}
+
+type Result struct {
+	OK bool `json:"ok"`
+}
+
+func TestComponentNames(t *testing.T) {
+	doc := openapi.NewDocument(openapi.Info{Title: "test", Version: "v1"})
+	doc.SetName(Result{}, "Outcome")
+	doc.SetName(Base{}, "Item")
+
+	if got := doc.Schema(&Result{}).Ref; got != "#/components/schemas/Outcome" {
+		t.Errorf("Schema(Result) = %s, want the name set with SetName", got)
+	}
+	// Item is reserved for Base, so the type Item gets its package prefixed
+	if got := doc.Schema(Item{}).Ref; got != "#/components/schemas/Openapi_testItem" {
+		t.Errorf("Schema(Item) = %s", got)
+	}
+	if got := doc.Schema(Base{}).Ref; got != "#/components/schemas/Item" {
+		t.Errorf("Schema(Base) = %s", got)
+	}
+}
+
+func TestPaths(t *testing.T) {
+	tests := []struct {
+		ginPath string
+		want    string
+		params  []string
+	}{
+		{"/api/configs", "/api/configs", nil},
+		{"/api/configs/:name/report", "/api/configs/{name}/report", []string{"name"}},
+		{"/api/runs/:id/results/:result", "/api/runs/{id}/results/{result}", []string{"id", "result"}},
+	}
+	for _, tt := range tests {
+		if got := openapi.ConvertPath(tt.ginPath); got != tt.want {
+			t.Errorf("ConvertPath(%s) = %s, want %s", tt.ginPath, got, tt.want)
+		}
+		if got := openapi.PathParams(tt.ginPath); !reflect.DeepEqual(got, tt.params) {
+			t.Errorf("PathParams(%s) = %v, want %v", tt.ginPath, got, tt.params)
+		}
+	}
+}
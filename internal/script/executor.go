@@ -1,15 +1,22 @@
 package script
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"xnetperf/config"
+	"xnetperf/internal/audit"
+	"xnetperf/internal/events"
 	"xnetperf/internal/script/generator"
 	"xnetperf/internal/tools"
 
+	"github.com/samber/lo"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -52,10 +59,14 @@ func (testType TestType) Command() string {
 
 // Executor 负责脚本的生成和执行编排
 type Executor struct {
-	cfg      *config.Config
-	mode     TestMode
-	TestType TestType
-	timeout  time.Duration // TODO
+	cfg        *config.Config
+	mode       TestMode
+	TestType   TestType
+	timeout    time.Duration // TODO
+	scriptsDir string        // 生成脚本的保存目录，为空时不保存
+	events     events.Publisher
+	excluded   map[string][]string // 从测试计划中排除的 主机 -> HCA，见 WithExcludedHCAs
+	ctx        context.Context
 }
 
 // NewExecutor 创建执行器
@@ -74,9 +85,72 @@ func NewExecutor(cfg *config.Config, testType TestType) *Executor {
 		mode:     mode,
 		TestType: testType,
 		timeout:  10 * time.Minute,
+		events:   events.Discard,
+		ctx:      context.Background(),
 	}
 }
 
+// WithScriptsDir 设置生成脚本的保存目录，执行前会把每台主机的脚本写入该目录
+func (e *Executor) WithScriptsDir(dir string) *Executor {
+	e.scriptsDir = dir
+	return e
+}
+
+// WithEvents 设置事件发布者，等待服务端启动时发布进程数变化和主机就绪事件
+func (e *Executor) WithEvents(publisher events.Publisher) *Executor {
+	e.events = publisher
+	return e
+}
+
+// WithContext 设置取消执行的上下文：取消后不再启动新的脚本，正在执行的 SSH 命令被终止
+func (e *Executor) WithContext(ctx context.Context) *Executor {
+	e.ctx = ctx
+	return e
+}
+
+// WithExcludedHCAs 从测试计划中去掉所有涉及这些 主机 -> HCA 的流，
+// 所有 HCA 都被排除的主机不再查询 IP 和下发脚本
+func (e *Executor) WithExcludedHCAs(hostHCAs map[string][]string) *Executor {
+	if len(hostHCAs) == 0 {
+		return e
+	}
+	e.excluded = hostHCAs
+	e.cfg = excludeHosts(e.cfg, e.mode, hostHCAs)
+	return e
+}
+
+// excludeHosts 返回去掉了所有 HCA 都被排除的主机的配置副本；
+// P2P 按索引配对，两端任一主机被去掉时整对去掉
+func excludeHosts(cfg *config.Config, mode TestMode, hostHCAs map[string][]string) *config.Config {
+	allExcluded := func(host string, hcas []string) bool {
+		return len(hcas) > 0 && lo.Every(hostHCAs[host], hcas)
+	}
+
+	planned := *cfg
+	planned.Server.Hostname, planned.Client.Hostname = nil, nil
+	for i, host := range cfg.Server.Hostname {
+		if mode == ModeBwP2P || mode == ModeLatP2P {
+			if i < len(cfg.Client.Hostname) && (allExcluded(host, cfg.Server.Hca) || allExcluded(cfg.Client.Hostname[i], cfg.Client.Hca)) {
+				continue
+			}
+		} else if allExcluded(host, cfg.Server.Hca) {
+			continue
+		}
+		planned.Server.Hostname = append(planned.Server.Hostname, host)
+	}
+	for i, host := range cfg.Client.Hostname {
+		if mode == ModeBwP2P || mode == ModeLatP2P {
+			if i < len(cfg.Server.Hostname) && (allExcluded(cfg.Server.Hostname[i], cfg.Server.Hca) || allExcluded(host, cfg.Client.Hca)) {
+				continue
+			}
+		} else if allExcluded(host, cfg.Client.Hca) {
+			continue
+		}
+		planned.Client.Hostname = append(planned.Client.Hostname, host)
+	}
+	return &planned
+}
+
 func parseTestMode(testType TestType, cfg *config.Config) TestMode {
 	switch testType {
 	case TestTypeBandwidth:
@@ -114,25 +188,34 @@ func (e *Executor) GenerateScripts() (*generator.ScriptResult, error) {
 	}
 
 	// 2. 根据模式创建对应的generator
+	if len(e.excluded) > 0 && (e.mode == ModeLatP2P || e.mode == ModeLatLocaltest) {
+		return nil, fmt.Errorf("%s mode cannot exclude unhealthy HCAs from the test plan; set precheck.unhealthy_hca to abort or warn", e.mode)
+	}
 	var result *generator.ScriptResult
 	switch e.mode {
 	case ModeBwFullmesh:
 		gen := generator.NewBwFullmeshScriptGenerator(e.cfg, hostIPs)
+		gen.ExcludeHCAs(e.excluded)
 		result, err = gen.GenerateScripts()
 	case ModeBwIncast:
 		gen := generator.NewBwIncastScriptGenerator(e.cfg, hostIPs)
+		gen.ExcludeHCAs(e.excluded)
 		result, err = gen.GenerateScripts()
 	case ModeBwP2P:
 		gen := generator.NewBwP2PScriptGenerator(e.cfg, hostIPs)
+		gen.ExcludeHCAs(e.excluded)
 		result, err = gen.GenerateScripts()
 	case ModeBwLocaltest:
 		gen := generator.NewBwLocaltestScriptGenerator(e.cfg, hostIPs)
+		gen.ExcludeHCAs(e.excluded)
 		result, err = gen.GenerateScripts()
 	case ModeLatFullmesh:
 		gen := generator.NewLatFullmeshScriptGenerator(e.cfg, hostIPs)
+		gen.ExcludeHCAs(e.excluded)
 		result, err = gen.GenerateScripts()
 	case ModeLatIncast:
 		gen := generator.NewLatIncastScriptGenerator(e.cfg, hostIPs)
+		gen.ExcludeHCAs(e.excluded)
 		result, err = gen.GenerateScripts()
 	case ModeLatP2P:
 		// TODO: 实现lat p2p generator
@@ -158,9 +241,15 @@ func (e *Executor) Execute() error {
 	if err != nil {
 		return err
 	}
+	if e.scriptsDir != "" {
+		if err := e.saveScripts(result); err != nil {
+			return err
+		}
+	}
 
 	// 2. 执行服务端脚本
 	fmt.Println("Starting server processes...")
+	e.events.Publish(events.Event{Type: events.TypeStep, Message: "Starting server processes"})
 	var eg errgroup.Group
 	for _, script := range result.ServerScripts {
 		script := script // capture loop variable
@@ -177,9 +266,13 @@ func (e *Executor) Execute() error {
 
 	// 3. 等待服务端启动
 	e.waitingForServerStart(result.ServerScripts)
+	if err := e.ctx.Err(); err != nil {
+		return err
+	}
 
 	// 4. 执行客户端脚本
 	fmt.Println("Starting client processes...")
+	e.events.Publish(events.Event{Type: events.TypeStep, Message: "Starting client processes"})
 	var clientEg errgroup.Group
 	for _, script := range result.ClientScripts {
 		script := script // capture loop variable
@@ -195,6 +288,34 @@ func (e *Executor) Execute() error {
 	}
 
 	fmt.Println("All scripts executed successfully")
+	e.events.Publish(events.Event{Type: events.TypeStep, Message: "All scripts executed"})
+	return nil
+}
+
+// saveScripts 把生成的脚本写入 scriptsDir，文件名为 <test_type>_<role>_<host>.sh
+func (e *Executor) saveScripts(result *generator.ScriptResult) error {
+	if err := os.MkdirAll(e.scriptsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create scripts directory: %v", err)
+	}
+
+	write := func(role string, scripts []*generator.HostScript) error {
+		for _, hs := range scripts {
+			name := fmt.Sprintf("%s_%s_%s.sh", e.TestType, role, hs.Host)
+			content := "#!/bin/bash\n" + hs.Command + "\n"
+			if err := os.WriteFile(filepath.Join(e.scriptsDir, name), []byte(content), 0755); err != nil {
+				return fmt.Errorf("failed to save script %s: %v", name, err)
+			}
+		}
+		return nil
+	}
+	if err := write("server", result.ServerScripts); err != nil {
+		return err
+	}
+	if err := write("client", result.ClientScripts); err != nil {
+		return err
+	}
+
+	fmt.Printf("Scripts saved to %s\n", e.scriptsDir)
 	return nil
 }
 
@@ -212,6 +333,9 @@ func (e *Executor) waitingForServerStart(sHosts []*generator.HostScript) {
 	fmt.Println("Waiting for server processes to start...")
 	fmt.Printf("Expected processes: %v\n\n", expectedProcesses)
 
+	lastCounts := make(map[string]int)
+	started := make(map[string]bool)
+
 	startTime := time.Now()
 	probeInterval := 1 * time.Second
 	ticker := time.NewTicker(probeInterval)
@@ -241,6 +365,24 @@ func (e *Executor) waitingForServerStart(sHosts []*generator.HostScript) {
 				allReady = false
 			}
 
+			if last, seen := lastCounts[script.Host]; !seen || last != count {
+				lastCounts[script.Host] = count
+				e.events.Publish(events.Event{
+					Type: events.TypeProcessCount,
+					Host: script.Host,
+					Data: map[string]any{"process": e.TestType.Command(), "count": count, "expected": expected},
+				})
+			}
+			if count >= expected && !started[script.Host] {
+				started[script.Host] = true
+				e.events.Publish(events.Event{
+					Type:    events.TypeHostStarted,
+					Host:    script.Host,
+					Message: fmt.Sprintf("%d/%d server processes started", count, expected),
+					Data:    map[string]any{"count": count, "expected": expected},
+				})
+			}
+
 			fmt.Printf("%s %s: %d/%d processes\n", status, script.Host, count, expected)
 		}
 		fmt.Println()
@@ -252,14 +394,19 @@ func (e *Executor) waitingForServerStart(sHosts []*generator.HostScript) {
 		}
 
 		// 等待下一次探测
-		<-ticker.C
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+		}
 	}
 }
 
 // probeProcessCount 探测指定主机上的 ib_write_bw 进程数量
-func (e *Executor) probeProcessCount(hostname string) int {
+func (e *Executor) probeProcessCount(host string) int {
 	sshKeyPath := e.cfg.SSH.PrivateKey
 
+	hostname := host
 	if !strings.Contains(hostname, "@") && e.cfg.SSH.User != "" {
 		hostname = fmt.Sprintf("%s@%s", e.cfg.SSH.User, hostname)
 	}
@@ -284,7 +431,7 @@ func (e *Executor) probeProcessCount(hostname string) int {
 			command)
 	}
 
-	output, err := cmd.Output()
+	output, err := audit.Output(e.ctx, host, command, cmd)
 	if err != nil {
 		return 0
 	}
@@ -310,11 +457,11 @@ func (e *Executor) lookupIPs() (map[string]string, error) {
 		}
 
 		// 查询所有主机的IP
-		serverIPs, err := e.cfg.LookupServerHostsIP()
+		serverIPs, err := e.lookupHostsIP(e.cfg.Server.Hostname)
 		if err != nil {
 			return nil, fmt.Errorf("failed to lookup server IPs: %v", err)
 		}
-		clientIPs, err := e.cfg.LookupClientHostsIP()
+		clientIPs, err := e.lookupHostsIP(e.cfg.Client.Hostname)
 		if err != nil {
 			return nil, fmt.Errorf("failed to lookup client IPs: %v", err)
 		}
@@ -329,7 +476,7 @@ func (e *Executor) lookupIPs() (map[string]string, error) {
 
 	case ModeBwIncast, ModeLatIncast:
 		// Incast只需要server的IP
-		serverIPs, err := e.cfg.LookupServerHostsIP()
+		serverIPs, err := e.lookupHostsIP(e.cfg.Server.Hostname)
 		if err != nil {
 			return nil, fmt.Errorf("failed to lookup server IPs: %v", err)
 		}
@@ -337,11 +484,11 @@ func (e *Executor) lookupIPs() (map[string]string, error) {
 
 	case ModeBwP2P, ModeLatP2P:
 		// P2P需要所有server和client的IP
-		serverIPs, err := e.cfg.LookupServerHostsIP()
+		serverIPs, err := e.lookupHostsIP(e.cfg.Server.Hostname)
 		if err != nil {
 			return nil, fmt.Errorf("failed to lookup server IPs: %v", err)
 		}
-		clientIPs, err := e.cfg.LookupClientHostsIP()
+		clientIPs, err := e.lookupHostsIP(e.cfg.Client.Hostname)
 		if err != nil {
 			return nil, fmt.Errorf("failed to lookup client IPs: %v", err)
 		}
@@ -356,7 +503,7 @@ func (e *Executor) lookupIPs() (map[string]string, error) {
 
 	case ModeBwLocaltest, ModeLatLocaltest:
 		// Localtest只需要server的IP（都是本地测试）
-		serverIPs, err := e.cfg.LookupServerHostsIP()
+		serverIPs, err := e.lookupHostsIP(e.cfg.Server.Hostname)
 		if err != nil {
 			return nil, fmt.Errorf("failed to lookup server IPs: %v", err)
 		}
@@ -369,6 +516,57 @@ func (e *Executor) lookupIPs() (map[string]string, error) {
 	return hostIPs, nil
 }
 
+// lookupHostsIP 通过 SSH 查询主机在 network_interface 上的 IPv4 地址，已经是 IP 的主机直接使用
+func (e *Executor) lookupHostsIP(hosts []string) (map[string]string, error) {
+	ipMap := make(map[string]string)
+	var mu sync.Mutex
+
+	g, _ := errgroup.WithContext(e.ctx)
+	for _, host := range hosts {
+		g.Go(func() error {
+			ip := host
+			if !tools.IsValidIP(host) {
+				var err error
+				if ip, err = e.lookupHostIP(host); err != nil {
+					return err
+				}
+			}
+			mu.Lock()
+			ipMap[host] = ip
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return ipMap, nil
+}
+
+// lookupHostIP 查询一台主机在 network_interface 上的 IPv4 地址
+func (e *Executor) lookupHostIP(hostname string) (string, error) {
+	command := fmt.Sprintf(`ip -4 addr show %s | grep -oP '(?<=inet\s)\d+(\.\d+){3}'`, e.cfg.NetworkInterface)
+
+	sshWrapper := tools.NewSSHWrapper(hostname).
+		User(e.cfg.SSH.User).
+		PrivateKey(e.cfg.SSH.PrivateKey).
+		Command(command)
+
+	fmt.Println(sshWrapper.String())
+
+	cmd := exec.Command("bash", "-c", sshWrapper.String())
+	output, err := audit.CombinedOutput(e.ctx, hostname, command, cmd)
+	if err != nil {
+		return "", fmt.Errorf("SSH command failed on %s: %v, output: %s", hostname, err, string(output))
+	}
+
+	ip := strings.TrimSpace(string(output))
+	if ip == "" {
+		return "", fmt.Errorf("no IP address found for %s on %s interface", hostname, e.cfg.NetworkInterface)
+	}
+	return ip, nil
+}
+
 // executeRemote 使用SSH执行远程命令
 func (e *Executor) executeRemote(script *generator.HostScript) error {
 	// 打印执行信息
@@ -379,7 +577,7 @@ func (e *Executor) executeRemote(script *generator.HostScript) error {
 		User(e.cfg.SSH.User).
 		Command(script.Command)
 	cmd := exec.Command("bash", "-c", sshWrapper.String())
-	err := cmd.Run()
+	err := audit.Run(e.ctx, script.Host, script.Command, cmd)
 	if err != nil {
 		return fmt.Errorf("SSH command execution failed: %v", err)
 	}
@@ -16,6 +16,24 @@ type ScriptGeneratorIface interface {
 }
 
 type ScriptGenerator struct {
+	excluded map[string]map[string]bool // host -> hca -> excluded, see ExcludeHCAs
+}
+
+// ExcludeHCAs drops every flow touching one of the given host -> HCAs from
+// the generated scripts, e.g. HCAs precheck found unhealthy
+func (sg *ScriptGenerator) ExcludeHCAs(hostHCAs map[string][]string) {
+	sg.excluded = make(map[string]map[string]bool)
+	for host, hcas := range hostHCAs {
+		sg.excluded[host] = make(map[string]bool)
+		for _, hca := range hcas {
+			sg.excluded[host][hca] = true
+		}
+	}
+}
+
+// excludedFlow returns true if either end of a flow is an excluded HCA
+func (sg *ScriptGenerator) excludedFlow(hostA, hcaA, hostB, hcaB string) bool {
+	return sg.excluded[hostA][hcaA] || sg.excluded[hostB][hcaB]
 }
 
 func (sg *ScriptGenerator) GenerateScripts() (*ScriptResult, error) {
@@ -31,7 +49,8 @@ func (sg *ScriptGenerator) buildIbWriteBwCommand(cfg *config.Config, hca string,
 		RunInfinitely(cfg.Run.Infinitely).
 		Duration(cfg.Run.DurationSeconds).
 		RdmaCm(cfg.RdmaCm).
-		GidIndex(cfg.GidIndex)
+		GidIndex(cfg.GidIndex).
+		IBPort(cfg.IBPort)
 	if cfg.Report.Enable {
 		cmd = cmd.EnableReport(rFileName)
 	}
@@ -50,7 +69,8 @@ func (sg *ScriptGenerator) buildIbWriteLatCommand(cfg *config.Config, hca string
 		RunInfinitely(false).
 		Duration(5).
 		RdmaCm(cfg.RdmaCm).
-		GidIndex(cfg.GidIndex)
+		GidIndex(cfg.GidIndex).
+		IBPort(cfg.IBPort)
 
 	if targetIP != "" {
 		cmd = cmd.AsClient(targetIP)
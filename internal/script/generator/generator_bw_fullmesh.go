@@ -34,6 +34,9 @@ func (g *bwFullmeshScriptGenerator) GenerateScripts() (*ScriptResult, error) {
 		for _, sHca := range g.cfg.Server.Hca {
 			for _, cHost := range g.cfg.Client.Hostname {
 				for _, cHca := range g.cfg.Client.Hca {
+					if g.excludedFlow(sHost, sHca, cHost, cHca) {
+						continue
+					}
 					sasFile := fmt.Sprintf("%s/report_s_%s_%s_%d.json",
 						g.cfg.Report.Dir, sHost, sHca, port)
 					sasCmd := g.buildIbWriteBwCommand(g.cfg, sHca, port, "", sasFile)
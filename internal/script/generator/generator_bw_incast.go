@@ -36,6 +36,9 @@ func (g *bwIncastScriptGenerator) GenerateScripts() (*ScriptResult, error) {
 		for _, sHca := range g.cfg.Server.Hca {
 			for _, cHost := range g.cfg.Client.Hostname {
 				for _, cHca := range g.cfg.Client.Hca {
+					if g.excludedFlow(sHost, sHca, cHost, cHca) {
+						continue
+					}
 					serverCmd := tools.NewIBWriteBwCommand().
 						Device(sHca).
 						QueuePairs(g.cfg.QpNum).
@@ -44,7 +47,8 @@ func (g *bwIncastScriptGenerator) GenerateScripts() (*ScriptResult, error) {
 						RunInfinitely(g.cfg.Run.Infinitely).
 						Duration(g.cfg.Run.DurationSeconds).
 						RdmaCm(g.cfg.RdmaCm).
-						GidIndex(g.cfg.GidIndex)
+						GidIndex(g.cfg.GidIndex).
+						IBPort(g.cfg.IBPort)
 					if g.cfg.Report.Enable {
 						serverCmd = serverCmd.EnableReport(fmt.Sprintf("%s/report_s_%s_%s_%d.json", g.cfg.Report.Dir, sHost, sHca, port))
 					}
@@ -59,7 +63,8 @@ func (g *bwIncastScriptGenerator) GenerateScripts() (*ScriptResult, error) {
 						RunInfinitely(g.cfg.Run.Infinitely).
 						Duration(g.cfg.Run.DurationSeconds).
 						RdmaCm(g.cfg.RdmaCm).
-						GidIndex(g.cfg.GidIndex)
+						GidIndex(g.cfg.GidIndex).
+						IBPort(g.cfg.IBPort)
 
 					if g.cfg.Report.Enable {
 						clientCmd = clientCmd.EnableReport(
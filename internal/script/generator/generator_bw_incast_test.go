@@ -370,3 +370,39 @@ func TestBwIncastScriptGenerator_CommandFormat(t *testing.T) {
 		t.Error("Client command should wrap ib_write_bw in parentheses")
 	}
 }
+
+func TestBwIncastScriptGenerator_ExcludeHCAs(t *testing.T) {
+	cfg := &config.Config{
+		StartPort: 20000,
+		QpNum:     8,
+		Server: config.ServerConfig{
+			Hostname: []string{"server1"},
+			Hca:      []string{"mlx5_0", "mlx5_1"},
+		},
+		Client: config.ClientConfig{
+			Hostname: []string{"client1", "client2"},
+			Hca:      []string{"mlx5_0"},
+		},
+	}
+
+	gen := generator.NewBwIncastScriptGenerator(cfg, map[string]string{"server1": "192.168.1.10"})
+	gen.ExcludeHCAs(map[string][]string{"server1": {"mlx5_1"}, "client2": {"mlx5_0"}})
+	result, err := gen.GenerateScripts()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Only server1/mlx5_0 <- client1/mlx5_0 touches no excluded HCA
+	if len(result.ServerScripts) != 1 || result.ServerScripts[0].CommandCount != 1 {
+		t.Fatalf("Expected 1 server command, got %+v", result.ServerScripts)
+	}
+	if strings.Contains(result.ServerScripts[0].Command, "mlx5_1") {
+		t.Error("Server command should not use excluded HCA mlx5_1")
+	}
+	if len(result.ClientScripts) != 1 || result.ClientScripts[0].Host != "client1" {
+		t.Fatalf("Expected only a client1 script, got %+v", result.ClientScripts)
+	}
+	if !strings.Contains(result.ClientScripts[0].Command, "-p 20000") {
+		t.Error("Client command should keep the port of its flow")
+	}
+}
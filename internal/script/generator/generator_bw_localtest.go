@@ -44,6 +44,10 @@ func (g *bwLocaltestScriptGenerator) GenerateScripts() (*ScriptResult, error) {
 			// 内层循环：与所有"client"角色的host_hca组合建立连接（包括自己）
 			for _, clientHost := range hosts {
 				for _, clientHca := range hcas {
+					if g.excludedFlow(serverHost, serverHca, clientHost, clientHca) {
+						continue
+					}
+
 					// Server command (在serverHost上执行)
 					serverCmd := tools.NewIBWriteBwCommand().
 						Device(serverHca).
@@ -53,7 +57,8 @@ func (g *bwLocaltestScriptGenerator) GenerateScripts() (*ScriptResult, error) {
 						RunInfinitely(g.cfg.Run.Infinitely).
 						Duration(g.cfg.Run.DurationSeconds).
 						RdmaCm(g.cfg.RdmaCm).
-						GidIndex(g.cfg.GidIndex)
+						GidIndex(g.cfg.GidIndex).
+						IBPort(g.cfg.IBPort)
 
 					if g.cfg.Report.Enable {
 						serverCmd = serverCmd.EnableReport(
@@ -73,7 +78,8 @@ func (g *bwLocaltestScriptGenerator) GenerateScripts() (*ScriptResult, error) {
 						RunInfinitely(g.cfg.Run.Infinitely).
 						Duration(g.cfg.Run.DurationSeconds).
 						RdmaCm(g.cfg.RdmaCm).
-						GidIndex(g.cfg.GidIndex)
+						GidIndex(g.cfg.GidIndex).
+						IBPort(g.cfg.IBPort)
 
 					if g.cfg.Report.Enable {
 						clientCmd = clientCmd.EnableReport(
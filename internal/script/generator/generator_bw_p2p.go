@@ -42,6 +42,10 @@ func (g *bwP2PScriptGenerator) GenerateScripts() (*ScriptResult, error) {
 			clientHcaIndex := (hcaIndex + 1) % len(g.cfg.Client.Hca)
 			clientHca := g.cfg.Client.Hca[clientHcaIndex]
 
+			if g.excludedFlow(serverHost, serverHca, clientHost, clientHca) {
+				continue
+			}
+
 			// Server command
 			serverCmd := tools.NewIBWriteBwCommand().
 				Device(serverHca).
@@ -51,7 +55,8 @@ func (g *bwP2PScriptGenerator) GenerateScripts() (*ScriptResult, error) {
 				RunInfinitely(g.cfg.Run.Infinitely).
 				Duration(g.cfg.Run.DurationSeconds).
 				RdmaCm(g.cfg.RdmaCm).
-				GidIndex(g.cfg.GidIndex)
+				GidIndex(g.cfg.GidIndex).
+				IBPort(g.cfg.IBPort)
 
 			if g.cfg.Report.Enable {
 				serverCmd = serverCmd.EnableReport(
@@ -71,7 +76,8 @@ func (g *bwP2PScriptGenerator) GenerateScripts() (*ScriptResult, error) {
 				RunInfinitely(g.cfg.Run.Infinitely).
 				Duration(g.cfg.Run.DurationSeconds).
 				RdmaCm(g.cfg.RdmaCm).
-				GidIndex(g.cfg.GidIndex)
+				GidIndex(g.cfg.GidIndex).
+				IBPort(g.cfg.IBPort)
 
 			if g.cfg.Report.Enable {
 				clientCmd = clientCmd.EnableReport(
@@ -69,6 +69,9 @@ func (g *latFullmeshScriptGenerator) GenerateScripts() (*ScriptResult, error) {
 			if combo1.host == combo2.host && combo1.hca == combo2.hca {
 				continue
 			}
+			if g.excludedFlow(combo1.host, combo1.hca, combo2.host, combo2.hca) {
+				continue
+			}
 			// combo1 -> combo2
 			serverFile := fmt.Sprintf("%s/latency_fullmesh_s_%s_%s_from_%s_%s_p%d.json",
 				g.cfg.Report.Dir, combo1.host, combo1.hca, combo2.host, combo2.hca, port)
@@ -34,13 +34,17 @@ func (g *latIncastScriptGenerator) GenerateScripts() (*ScriptResult, error) {
 		for _, sHca := range g.cfg.Server.Hca {
 			for _, cHost := range g.cfg.Client.Hostname {
 				for _, cHca := range g.cfg.Client.Hca {
+					if g.excludedFlow(sHost, sHca, cHost, cHca) {
+						continue
+					}
 					serverCmd := tools.NewIBWriteLatCommand().
 						Device(sHca).
 						Port(port).
 						RunInfinitely(false).
 						Duration(5).
 						RdmaCm(g.cfg.RdmaCm).
-						GidIndex(g.cfg.GidIndex)
+						GidIndex(g.cfg.GidIndex).
+						IBPort(g.cfg.IBPort)
 					if g.cfg.Report.Enable {
 						serverCmd = serverCmd.EnableReport(fmt.Sprintf("%s/latency_incast_s_%s_%s_from_%s_%s_p%d.json",
 							g.cfg.Report.Dir, sHost, sHca, cHost, cHca, port))
@@ -54,7 +58,8 @@ func (g *latIncastScriptGenerator) GenerateScripts() (*ScriptResult, error) {
 						RunInfinitely(false).
 						Duration(5).
 						RdmaCm(g.cfg.RdmaCm).
-						GidIndex(g.cfg.GidIndex)
+						GidIndex(g.cfg.GidIndex).
+						IBPort(g.cfg.IBPort)
 
 					if g.cfg.Report.Enable {
 						clientCmd = clientCmd.EnableReport(
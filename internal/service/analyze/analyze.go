@@ -1,6 +1,7 @@
 package analyze
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -8,10 +9,17 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"xnetperf/config"
+	"xnetperf/internal/audit"
+	"xnetperf/internal/events"
+	"xnetperf/internal/service/counters"
+	"xnetperf/internal/service/neighbor"
+	"xnetperf/internal/service/precheck"
+	"xnetperf/internal/store"
 	"xnetperf/internal/tools"
 	"xnetperf/pkg/tools/logger"
 )
@@ -32,61 +40,120 @@ type DeviceData struct {
 	Hostname     string
 	Device       string
 	SerialNumber string
+	Switch       string // switch the device is cabled to, empty if not discovered
+	SwitchPort   string
 	BWSum        float64
 	Count        int
 	IsClient     bool
+	Excluded     bool // excluded from the test plan because precheck found it unhealthy
 }
 
 type Analyzer struct {
 	cfg    *config.Config
 	logger *slog.Logger
+	events events.Publisher
+	ctx    context.Context
 }
 
 func New(cfg *config.Config) *Analyzer {
 	return &Analyzer{
 		cfg:    cfg,
 		logger: logger.GetLogger().With("module", "ANALYZE"),
+		events: events.Discard,
+		ctx:    context.Background(),
 	}
 }
 
-func (a *Analyzer) DoAnalyze(reportsDir string, generateMD bool) {
+// WithEvents sets the publisher an analysis_done event is sent to after GenerateReport
+func (a *Analyzer) WithEvents(publisher events.Publisher) *Analyzer {
+	a.events = publisher
+	return a
+}
+
+// WithContext sets the context of the serial number commands; they are killed
+// when it is cancelled
+func (a *Analyzer) WithContext(ctx context.Context) *Analyzer {
+	a.ctx = ctx
+	return a
+}
+
+// DoAnalyze prints the analysis of the reports in reportsDir and returns the
+// report data it was built from, the same GenerateReport returns, or nil when
+// the reports cannot be analyzed
+func (a *Analyzer) DoAnalyze(reportsDir string, generateMD bool) *ReportData {
 	// Check if reports directory exists
 	if _, err := os.Stat(reportsDir); os.IsNotExist(err) {
 		fmt.Printf("Reports directory not found: %s\n", reportsDir)
-		return
+		return nil
 	}
 
 	// Handle different stream types with separate functions
+	var clientData, serverData map[string]map[string]*DeviceData
 	switch a.cfg.StreamType {
 	case config.P2P:
 		runP2PAnalyze(reportsDir, a.cfg, generateMD)
 	default:
 		// Handle fullmesh and incast with existing logic
-		runTraditionalAnalyze(reportsDir, a.cfg, generateMD)
+		var err error
+		clientData, serverData, err = runTraditionalAnalyze(a.ctx, reportsDir, a.cfg, generateMD)
+		if err != nil {
+			return nil
+		}
 	}
+	displayExcludedHCAs(reportsDir)
+	displayCounterDeltas(reportsDir)
+	return a.buildReport(reportsDir, clientData, serverData)
 }
 
-// runTraditionalAnalyze handles fullmesh and incast analysis with existing logic
-func runTraditionalAnalyze(reportsDir string, cfg *config.Config, generateMD bool) {
+// displayExcludedHCAs lists the HCAs excluded from the test plan, if any
+func displayExcludedHCAs(reportsDir string) {
+	excluded, err := precheck.LoadExcluded(reportsDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("Error loading excluded HCAs: %v\n", err)
+		}
+		return
+	}
+	precheck.DisplayExcluded(excluded)
+}
+
+// displayCounterDeltas shows the port counter deltas recorded with the reports, if any
+func displayCounterDeltas(reportsDir string) {
+	deltas, err := counters.Load(reportsDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("Error loading counter deltas: %v\n", err)
+		}
+		return
+	}
+	fmt.Println()
+	counters.Display(deltas)
+}
+
+// runTraditionalAnalyze handles fullmesh and incast analysis with existing
+// logic and returns the collected client and server data
+func runTraditionalAnalyze(ctx context.Context, reportsDir string, cfg *config.Config, generateMD bool) (map[string]map[string]*DeviceData, map[string]map[string]*DeviceData, error) {
 	// Collect all report data using existing function
-	clientData, serverData, err := collectReportData(reportsDir, cfg)
+	clientData, serverData, err := collectReportData(ctx, reportsDir, cfg)
 	if err != nil {
 		fmt.Printf("Error collecting report data: %v\n", err)
-		return
+		return nil, nil, err
 	}
 
 	// Display results using existing function
 	displayResults(clientData, serverData, cfg.Speed)
 
-	// Generate markdown file if requested
+	// Generate markdown file next to the reports directory if requested
 	if generateMD {
-		err := generateMarkdownTable(clientData, serverData, cfg.Speed)
+		mdPath := filepath.Join(filepath.Dir(reportsDir), "network_performance_analysis.md")
+		err := generateMarkdownTable(mdPath, clientData, serverData, cfg.Speed)
 		if err != nil {
 			fmt.Printf("Error generating markdown file: %v\n", err)
 		} else {
-			fmt.Println("\nMarkdown table generated: network_performance_analysis.md")
+			fmt.Printf("\nMarkdown table generated: %s\n", mdPath)
 		}
 	}
+	return clientData, serverData, nil
 }
 
 // runP2PAnalyze handles P2P-specific analysis
@@ -97,22 +164,31 @@ func runP2PAnalyze(reportsDir string, cfg *config.Config, generateMD bool) {
 		fmt.Printf("Error collecting P2P report data: %v\n", err)
 		return
 	}
+	neighbors := loadNeighbors(reportsDir)
+	for hostname, devices := range p2pData {
+		for device, data := range devices {
+			n := neighbors.Get(hostname, device)
+			data.Switch, data.SwitchPort = n.SwitchName(), n.Port
+		}
+	}
 
 	// Display P2P results
 	displayP2PResults(p2pData)
 
-	// Generate P2P markdown file if requested
+	// Generate P2P markdown file next to the reports directory if requested
 	if generateMD {
-		err := generateP2PMarkdownTable(p2pData)
+		mdPath := filepath.Join(filepath.Dir(reportsDir), "p2p_performance_analysis.md")
+		err := generateP2PMarkdownTable(mdPath, p2pData)
 		if err != nil {
 			fmt.Printf("Error generating P2P markdown file: %v\n", err)
 		} else {
-			fmt.Println("\nP2P Markdown table generated: p2p_performance_analysis.md")
+			fmt.Printf("\nP2P Markdown table generated: %s\n", mdPath)
 		}
 	}
 }
 
-func AllSerialNumbers(cfg *config.Config) map[string]string {
+// AllSerialNumbers reads the system serial number of every host
+func AllSerialNumbers(ctx context.Context, cfg *config.Config) map[string]string {
 	allHosts := cfg.ALLHosts()
 	serialNumbers := make(map[string]string)
 
@@ -125,7 +201,7 @@ func AllSerialNumbers(cfg *config.Config) map[string]string {
 
 	for host := range allHosts {
 		go func(h string) {
-			serial := getSerialNumberForHost(h, cfg.SSH.PrivateKey, cfg.SSH.User)
+			serial := getSerialNumberForHost(ctx, h, cfg.SSH.PrivateKey, cfg.SSH.User)
 			resultChan <- result{host: h, serial: serial}
 		}(host)
 	}
@@ -139,17 +215,31 @@ func AllSerialNumbers(cfg *config.Config) map[string]string {
 	return serialNumbers
 }
 
+// loadNeighbors reads the switch ports saved with the reports when they were
+// collected; reports collected without them show no switch ports
+func loadNeighbors(reportsDir string) neighbor.Map {
+	neighbors, err := neighbor.Load(reportsDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("Error loading neighbors: %v\n", err)
+		}
+		return neighbor.Map{}
+	}
+	return neighbors
+}
+
 // getSerialNumberForHost 获取指定主机的序列号
-func getSerialNumberForHost(hostname string, sshKeyPath string, user string) string {
+func getSerialNumberForHost(ctx context.Context, host string, sshKeyPath string, user string) string {
 	// 尝试通过SSH获取系统序列号
 	var cmd *exec.Cmd
+	hostname := host
 	if user != "" && !strings.Contains(hostname, "@") {
 		hostname = fmt.Sprintf("%s@%s", user, hostname)
 	}
 	command := "cat /sys/class/dmi/id/product_serial"
 	sshWrapper := tools.NewSSHWrapper(hostname).Command(command).PrivateKey(sshKeyPath)
 	cmd = exec.Command("bash", "-c", sshWrapper.String())
-	output, err := cmd.CombinedOutput()
+	output, err := audit.CombinedOutput(ctx, host, command, cmd)
 	if err != nil {
 		return "N/A"
 	}
@@ -170,16 +260,33 @@ func getSerialNumberForHost(hostname string, sshKeyPath string, user string) str
 	return serialNumber
 }
 
-func collectReportData(reportsDir string, cfg *config.Config) (map[string]map[string]*DeviceData, map[string]map[string]*DeviceData, error) {
+func collectReportData(ctx context.Context, reportsDir string, cfg *config.Config) (map[string]map[string]*DeviceData, map[string]map[string]*DeviceData, error) {
 	clientData := make(map[string]map[string]*DeviceData)
 	serverData := make(map[string]map[string]*DeviceData)
 
-	allSerialNumbers := AllSerialNumbers(cfg)
+	allSerialNumbers := AllSerialNumbers(ctx, cfg)
+	neighbors := loadNeighbors(reportsDir)
+	newDevice := func(hostname, device string, isClient bool) *DeviceData {
+		n := neighbors.Get(hostname, device)
+		return &DeviceData{
+			Hostname:     hostname,
+			Device:       device,
+			SerialNumber: allSerialNumbers[hostname],
+			Switch:       n.SwitchName(),
+			SwitchPort:   n.Port,
+			IsClient:     isClient,
+		}
+	}
 
 	err := filepath.Walk(reportsDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		// A repeated run keeps each iteration in its own subdirectory;
+		// reading them all would add up the iterations
+		if info.IsDir() && path != reportsDir && store.IsIterationDir(info.Name()) {
+			return filepath.SkipDir
+		}
 
 		if !strings.HasSuffix(info.Name(), ".json") {
 			return nil
@@ -226,17 +333,7 @@ func collectReportData(reportsDir string, cfg *config.Config) (map[string]map[st
 
 		// Initialize or update device data
 		if dataMap[hostname][device] == nil {
-			// Get serial number for this hostname
-			serialNumber := allSerialNumbers[hostname]
-
-			dataMap[hostname][device] = &DeviceData{
-				Hostname:     hostname,
-				Device:       device,
-				SerialNumber: serialNumber,
-				BWSum:        0,
-				Count:        0,
-				IsClient:     isClient,
-			}
+			dataMap[hostname][device] = newDevice(hostname, device, isClient)
 		}
 
 		dataMap[hostname][device].BWSum += report.Results.BWAverage
@@ -244,8 +341,49 @@ func collectReportData(reportsDir string, cfg *config.Config) (map[string]map[st
 
 		return nil
 	})
+	if err != nil {
+		return clientData, serverData, err
+	}
+
+	addExcludedDevices(reportsDir, cfg, clientData, serverData, newDevice)
+	return clientData, serverData, nil
+}
 
-	return clientData, serverData, err
+// addExcludedDevices adds the HCAs excluded from the test plan as Excluded
+// devices: to the table of their role in incast, to both tables otherwise as
+// every HCA sends and receives
+func addExcludedDevices(reportsDir string, cfg *config.Config, clientData, serverData map[string]map[string]*DeviceData,
+	newDevice func(hostname, device string, isClient bool) *DeviceData) {
+	excluded, err := precheck.LoadExcluded(reportsDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("Error loading excluded HCAs: %v\n", err)
+		}
+		return
+	}
+
+	add := func(dataMap map[string]map[string]*DeviceData, e precheck.ExcludedHCA, isClient bool) {
+		if dataMap[e.Hostname] == nil {
+			dataMap[e.Hostname] = make(map[string]*DeviceData)
+		}
+		if dataMap[e.Hostname][e.HCA] == nil {
+			dataMap[e.Hostname][e.HCA] = newDevice(e.Hostname, e.HCA, isClient)
+		}
+		dataMap[e.Hostname][e.HCA].Excluded = true
+	}
+	for _, e := range excluded {
+		isClient := slices.Contains(cfg.Client.Hostname, e.Hostname) && slices.Contains(cfg.Client.Hca, e.HCA)
+		isServer := slices.Contains(cfg.Server.Hostname, e.Hostname) && slices.Contains(cfg.Server.Hca, e.HCA)
+		if cfg.StreamType != config.InCast {
+			isClient, isServer = true, true
+		}
+		if isClient {
+			add(clientData, e, true)
+		}
+		if isServer {
+			add(serverData, e, false)
+		}
+	}
 }
 
 // calculateMaxSerialNumberLength 计算数据中最长的序列号长度
@@ -300,36 +438,88 @@ func calculateMaxP2PDeviceNameLength(dataMap map[string]map[string]*P2PDeviceDat
 	return maxLen
 }
 
+// calculateMaxSwitchPortLength 计算交换机端口列宽度，没有发现交换机端口时返回 0
+func calculateMaxSwitchPortLength(dataMap map[string]map[string]*DeviceData) int {
+	maxLen := 0
+	for _, devices := range dataMap {
+		for _, data := range devices {
+			maxLen = max(maxLen, len(switchPortLabel(data.Switch, data.SwitchPort)))
+		}
+	}
+	if maxLen > 0 {
+		maxLen = max(maxLen, len("Switch Port"))
+	}
+	return maxLen
+}
+
+// calculateMaxP2PSwitchPortLength 计算P2P数据的交换机端口列宽度，没有发现交换机端口时返回 0
+func calculateMaxP2PSwitchPortLength(dataMap map[string]map[string]*P2PDeviceData) int {
+	maxLen := 0
+	for _, devices := range dataMap {
+		for _, data := range devices {
+			maxLen = max(maxLen, len(switchPortLabel(data.Switch, data.SwitchPort)))
+		}
+	}
+	if maxLen > 0 {
+		maxLen = max(maxLen, len("Switch Port"))
+	}
+	return maxLen
+}
+
+// switchPortLabel 返回 "交换机:端口"，未发现时为空
+func switchPortLabel(switchName, port string) string {
+	return neighbor.Neighbor{Switch: switchName, Port: port}.Label()
+}
+
+// switchCell 返回交换机端口列的单元格，switchWidth 为 0 时不显示该列
+func switchCell(switchWidth int, value string) string {
+	if switchWidth == 0 {
+		return ""
+	}
+	if value == "" {
+		value = "-"
+	}
+	return fmt.Sprintf(" %-*s │", switchWidth, value)
+}
+
+// switchDashes 返回交换机端口列的横线及其右侧的连接符
+func switchDashes(switchWidth int, junction string) string {
+	if switchWidth == 0 {
+		return ""
+	}
+	return strings.Repeat("─", switchWidth+2) + junction
+}
+
 // displayClientTableHeader 显示客户端表格头部（动态列宽）
-func displayClientTableHeader(serialNumberWidth, deviceWidth int) {
+func displayClientTableHeader(serialNumberWidth, deviceWidth, switchWidth int) {
 	serialNumberDashes := strings.Repeat("─", serialNumberWidth)
 	deviceDashes := strings.Repeat("─", deviceWidth)
-	fmt.Printf("┌─%s─┬─────────────────────┬─%s─┬─────────────┬──────────────┬─────────────────┬──────────┐\n", serialNumberDashes, deviceDashes)
-	fmt.Printf("│ %-*s │ Hostname            │ %-*s │ TX (Gbps)   │ SPEC (Gbps)  │ DELTA           │ Status   │\n", serialNumberWidth, "Serial Number", deviceWidth, "Device")
-	fmt.Printf("├─%s─┼─────────────────────┼─%s─┼─────────────┼──────────────┼─────────────────┼──────────┤\n", serialNumberDashes, deviceDashes)
+	fmt.Printf("┌─%s─┬─────────────────────┬─%s─┬%s─────────────┬──────────────┬─────────────────┬──────────┐\n", serialNumberDashes, deviceDashes, switchDashes(switchWidth, "┬"))
+	fmt.Printf("│ %-*s │ Hostname            │ %-*s │%s TX (Gbps)   │ SPEC (Gbps)  │ DELTA           │ Status   │\n", serialNumberWidth, "Serial Number", deviceWidth, "Device", switchCell(switchWidth, "Switch Port"))
+	fmt.Printf("├─%s─┼─────────────────────┼─%s─┼%s─────────────┼──────────────┼─────────────────┼──────────┤\n", serialNumberDashes, deviceDashes, switchDashes(switchWidth, "┼"))
 }
 
 // displayClientTableFooter 显示客户端表格尾部（动态列宽）
-func displayClientTableFooter(serialNumberWidth, deviceWidth int) {
+func displayClientTableFooter(serialNumberWidth, deviceWidth, switchWidth int) {
 	serialNumberDashes := strings.Repeat("─", serialNumberWidth)
 	deviceDashes := strings.Repeat("─", deviceWidth)
-	fmt.Printf("└─%s─┴─────────────────────┴─%s─┴─────────────┴──────────────┴─────────────────┴──────────┘\n", serialNumberDashes, deviceDashes)
+	fmt.Printf("└─%s─┴─────────────────────┴─%s─┴%s─────────────┴──────────────┴─────────────────┴──────────┘\n", serialNumberDashes, deviceDashes, switchDashes(switchWidth, "┴"))
 }
 
 // displayServerTableHeader 显示服务端表格头部（动态列宽）
-func displayServerTableHeader(serialNumberWidth, deviceWidth int) {
+func displayServerTableHeader(serialNumberWidth, deviceWidth, switchWidth int) {
 	serialNumberDashes := strings.Repeat("─", serialNumberWidth)
 	deviceDashes := strings.Repeat("─", deviceWidth)
-	fmt.Printf("┌─%s─┬─────────────────────┬─%s─┬─────────────┬──────────────┬─────────────────┬──────────┐\n", serialNumberDashes, deviceDashes)
-	fmt.Printf("│ %-*s │ Hostname            │ %-*s │ RX (Gbps)   │ SPEC (Gbps)  │ DELTA           │ Status   │\n", serialNumberWidth, "Serial Number", deviceWidth, "Device")
-	fmt.Printf("├─%s─┼─────────────────────┼─%s─┼─────────────┼──────────────┼─────────────────┼──────────┤\n", serialNumberDashes, deviceDashes)
+	fmt.Printf("┌─%s─┬─────────────────────┬─%s─┬%s─────────────┬──────────────┬─────────────────┬──────────┐\n", serialNumberDashes, deviceDashes, switchDashes(switchWidth, "┬"))
+	fmt.Printf("│ %-*s │ Hostname            │ %-*s │%s RX (Gbps)   │ SPEC (Gbps)  │ DELTA           │ Status   │\n", serialNumberWidth, "Serial Number", deviceWidth, "Device", switchCell(switchWidth, "Switch Port"))
+	fmt.Printf("├─%s─┼─────────────────────┼─%s─┼%s─────────────┼──────────────┼─────────────────┼──────────┤\n", serialNumberDashes, deviceDashes, switchDashes(switchWidth, "┼"))
 }
 
 // displayServerTableFooter 显示服务端表格尾部（动态列宽）
-func displayServerTableFooter(serialNumberWidth, deviceWidth int) {
+func displayServerTableFooter(serialNumberWidth, deviceWidth, switchWidth int) {
 	serialNumberDashes := strings.Repeat("─", serialNumberWidth)
 	deviceDashes := strings.Repeat("─", deviceWidth)
-	fmt.Printf("└─%s─┴─────────────────────┴─%s─┴─────────────┴──────────────┴─────────────────┴──────────┘\n", serialNumberDashes, deviceDashes)
+	fmt.Printf("└─%s─┴─────────────────────┴─%s─┴%s─────────────┴──────────────┴─────────────────┴──────────┘\n", serialNumberDashes, deviceDashes, switchDashes(switchWidth, "┴"))
 }
 
 func displayResults(clientData, serverData map[string]map[string]*DeviceData, specSpeed float64) {
@@ -360,12 +550,15 @@ func displayResults(clientData, serverData map[string]map[string]*DeviceData, sp
 		maxSerialNumberLen = serverMaxSerialNumberLen
 	}
 
+	// 计算交换机端口列宽度，没有发现交换机端口时不显示该列
+	maxSwitchLen := max(calculateMaxSwitchPortLength(clientData), calculateMaxSwitchPortLength(serverData))
+
 	// Display client data with enhanced table
 	fmt.Println("CLIENT DATA (TX)")
-	displayClientTableHeader(maxSerialNumberLen, maxDeviceLen)
+	displayClientTableHeader(maxSerialNumberLen, maxDeviceLen, maxSwitchLen)
 
-	displayEnhancedClientTable(clientData, theoreticalBWPerClient, maxSerialNumberLen, maxDeviceLen)
-	displayClientTableFooter(maxSerialNumberLen, maxDeviceLen)
+	displayEnhancedClientTable(clientData, theoreticalBWPerClient, maxSerialNumberLen, maxDeviceLen, maxSwitchLen)
+	displayClientTableFooter(maxSerialNumberLen, maxDeviceLen, maxSwitchLen)
 
 	fmt.Printf("\nTheoretical BW per client: %.2f Gbps (Total server BW: %.2f Gbps ÷ %d clients)\n",
 		theoreticalBWPerClient, totalServerBW, clientCount)
@@ -374,13 +567,13 @@ func displayResults(clientData, serverData map[string]map[string]*DeviceData, sp
 
 	// Display server data with enhanced table
 	fmt.Println("SERVER DATA (RX)")
-	displayServerTableHeader(maxSerialNumberLen, maxDeviceLen)
+	displayServerTableHeader(maxSerialNumberLen, maxDeviceLen, maxSwitchLen)
 
-	displayEnhancedServerTable(serverData, specSpeed, maxSerialNumberLen, maxDeviceLen)
-	displayServerTableFooter(maxSerialNumberLen, maxDeviceLen)
+	displayEnhancedServerTable(serverData, specSpeed, maxSerialNumberLen, maxDeviceLen, maxSwitchLen)
+	displayServerTableFooter(maxSerialNumberLen, maxDeviceLen, maxSwitchLen)
 }
 
-func generateMarkdownTable(clientData, serverData map[string]map[string]*DeviceData, specSpeed float64) error {
+func generateMarkdownTable(path string, clientData, serverData map[string]map[string]*DeviceData, specSpeed float64) error {
 	content := "# Network Performance Analysis\n\n"
 
 	// 计算理论带宽
@@ -395,20 +588,41 @@ func generateMarkdownTable(clientData, serverData map[string]map[string]*DeviceD
 	content += "## Client Data (TX)\n\n"
 	content += fmt.Sprintf("Theoretical BW per client: %.2f Gbps (Total server BW: %.2f Gbps ÷ %d clients)\n\n",
 		theoreticalBWPerClient, totalServerBW, clientCount)
-	content += "| Hostname | Device | TX (Gbps) | SPEC (Gbps) | DELTA | Status |\n"
-	content += "|----------|--------|-----------|-------------|-------|--------|\n"
+	showSwitch := calculateMaxSwitchPortLength(clientData) > 0 || calculateMaxSwitchPortLength(serverData) > 0
+	content += markdownHeader(showSwitch, "TX (Gbps)")
 
-	content += generateEnhancedMarkdownClientContent(clientData, theoreticalBWPerClient)
+	content += generateEnhancedMarkdownClientContent(clientData, theoreticalBWPerClient, showSwitch)
 	content += "\n"
 
 	// Server data table with enhanced columns
 	content += "## Server Data (RX)\n\n"
-	content += "| Hostname | Device | RX (Gbps) | SPEC (Gbps) | DELTA | Status |\n"
-	content += "|----------|--------|-----------|-------------|-------|--------|\n"
+	content += markdownHeader(showSwitch, "RX (Gbps)")
 
-	content += generateEnhancedMarkdownServerContent(serverData, specSpeed)
+	content += generateEnhancedMarkdownServerContent(serverData, specSpeed, showSwitch)
 
-	return os.WriteFile("network_performance_analysis.md", []byte(content), 0644)
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+// markdownHeader 生成带宽 Markdown 表格的表头，showSwitch 时包含交换机端口列
+func markdownHeader(showSwitch bool, bwColumn string) string {
+	if showSwitch {
+		return fmt.Sprintf("| Hostname | Device | Switch Port | %s | SPEC (Gbps) | DELTA | Status |\n", bwColumn) +
+			"|----------|--------|-------------|-----------|-------------|-------|--------|\n"
+	}
+	return fmt.Sprintf("| Hostname | Device | %s | SPEC (Gbps) | DELTA | Status |\n", bwColumn) +
+		"|----------|--------|-----------|-------------|-------|--------|\n"
+}
+
+// markdownSwitchCell 返回 Markdown 表格的交换机端口单元格，不显示该列时为空
+func markdownSwitchCell(showSwitch bool, switchName, port string) string {
+	if !showSwitch {
+		return ""
+	}
+	label := switchPortLabel(switchName, port)
+	if label == "" {
+		label = "-"
+	}
+	return " " + label + " |"
 }
 
 func generateMarkdownTableContent(dataMap map[string]map[string]*DeviceData) string {
@@ -453,8 +667,10 @@ func generateMarkdownTableContent(dataMap map[string]map[string]*DeviceData) str
 func calculateTotalServerBandwidth(serverData map[string]map[string]*DeviceData, specSpeed float64) float64 {
 	total := float64(0)
 	for _, devices := range serverData {
-		for range devices {
-			total += specSpeed
+		for _, data := range devices {
+			if !data.Excluded {
+				total += specSpeed
+			}
 		}
 	}
 	return total
@@ -464,13 +680,17 @@ func calculateTotalServerBandwidth(serverData map[string]map[string]*DeviceData,
 func calculateClientCount(clientData map[string]map[string]*DeviceData) int {
 	count := 0
 	for _, devices := range clientData {
-		count += len(devices)
+		for _, data := range devices {
+			if !data.Excluded {
+				count++
+			}
+		}
 	}
 	return count
 }
 
 // displayEnhancedClientTable 显示增强的客户端表格
-func displayEnhancedClientTable(clientData map[string]map[string]*DeviceData, theoreticalBW float64, serialNumberWidth, deviceWidth int) {
+func displayEnhancedClientTable(clientData map[string]map[string]*DeviceData, theoreticalBW float64, serialNumberWidth, deviceWidth, switchWidth int) {
 	// Get sorted hostnames
 	var hostnames []string
 	for hostname := range clientData {
@@ -492,6 +712,20 @@ func displayEnhancedClientTable(clientData map[string]map[string]*DeviceData, th
 
 		for j, device := range deviceNames {
 			data := devices[device]
+
+			// Format serial number and hostname (only show for first device of each host)
+			serialNumberStr := ""
+			hostnameStr := ""
+			if j == 0 {
+				serialNumberStr = data.SerialNumber
+				hostnameStr = hostname
+			}
+
+			if data.Excluded {
+				displayExcludedRow(data, serialNumberStr, hostnameStr, serialNumberWidth, deviceWidth, switchWidth)
+				continue
+			}
+
 			actualBW := data.BWSum
 			delta := actualBW - theoreticalBW
 			deltaPercent := float64(0)
@@ -508,28 +742,21 @@ func displayEnhancedClientTable(clientData map[string]map[string]*DeviceData, th
 				status = "NOT OK"
 			}
 
-			// Format serial number and hostname (only show for first device of each host)
-			serialNumberStr := ""
-			hostnameStr := ""
-			if j == 0 {
-				serialNumberStr = data.SerialNumber
-				hostnameStr = hostname
-			}
-
-			fmt.Printf("│ %-*s │ %-19s │ %-*s │ %11.2f │ %12.2f │ %15s │ %-8s │\n",
-				serialNumberWidth, serialNumberStr, hostnameStr, deviceWidth, device, actualBW, theoreticalBW, deltaStr, status)
+			fmt.Printf("│ %-*s │ %-19s │ %-*s │%s %11.2f │ %12.2f │ %15s │ %-8s │\n",
+				serialNumberWidth, serialNumberStr, hostnameStr, deviceWidth, device,
+				switchCell(switchWidth, switchPortLabel(data.Switch, data.SwitchPort)), actualBW, theoreticalBW, deltaStr, status)
 		}
 
 		// Add separator between different hostnames (except for the last one)
 		if i < len(hostnames)-1 && len(clientData[hostname]) > 0 {
 			serialNumberDashes := strings.Repeat("─", serialNumberWidth)
-			fmt.Printf("├─%s─┼─────────────────────┼─%s─┼─────────────┼──────────────┼─────────────────┼──────────┤\n", serialNumberDashes, deviceDashes)
+			fmt.Printf("├─%s─┼─────────────────────┼─%s─┼%s─────────────┼──────────────┼─────────────────┼──────────┤\n", serialNumberDashes, deviceDashes, switchDashes(switchWidth, "┼"))
 		}
 	}
 }
 
 // displayEnhancedServerTable 显示增强的服务端表格（包含SPEC、DELTA和Status列）
-func displayEnhancedServerTable(serverData map[string]map[string]*DeviceData, specSpeed float64, serialNumberWidth, deviceWidth int) {
+func displayEnhancedServerTable(serverData map[string]map[string]*DeviceData, specSpeed float64, serialNumberWidth, deviceWidth, switchWidth int) {
 	// Get sorted hostnames
 	var hostnames []string
 	for hostname := range serverData {
@@ -551,6 +778,20 @@ func displayEnhancedServerTable(serverData map[string]map[string]*DeviceData, sp
 
 		for j, device := range deviceNames {
 			data := devices[device]
+
+			// Format serial number and hostname (only show for first device of each host)
+			serialNumberStr := ""
+			hostnameStr := ""
+			if j == 0 {
+				serialNumberStr = data.SerialNumber
+				hostnameStr = hostname
+			}
+
+			if data.Excluded {
+				displayExcludedRow(data, serialNumberStr, hostnameStr, serialNumberWidth, deviceWidth, switchWidth)
+				continue
+			}
+
 			actualBW := data.BWSum        // RX 带宽
 			delta := specSpeed - actualBW // DELTA = SPEC - RX
 			deltaPercent := float64(0)
@@ -567,26 +808,26 @@ func displayEnhancedServerTable(serverData map[string]map[string]*DeviceData, sp
 				status = "NOT OK"
 			}
 
-			// Format serial number and hostname (only show for first device of each host)
-			serialNumberStr := ""
-			hostnameStr := ""
-			if j == 0 {
-				serialNumberStr = data.SerialNumber
-				hostnameStr = hostname
-			}
-
-			fmt.Printf("│ %-*s │ %-19s │ %-*s │ %11.2f │ %12.2f │ %15s │ %-8s │\n",
-				serialNumberWidth, serialNumberStr, hostnameStr, deviceWidth, device, actualBW, specSpeed, deltaStr, status)
+			fmt.Printf("│ %-*s │ %-19s │ %-*s │%s %11.2f │ %12.2f │ %15s │ %-8s │\n",
+				serialNumberWidth, serialNumberStr, hostnameStr, deviceWidth, device,
+				switchCell(switchWidth, switchPortLabel(data.Switch, data.SwitchPort)), actualBW, specSpeed, deltaStr, status)
 		}
 
 		// Add separator between different hostnames (except for the last one)
 		if i < len(hostnames)-1 && len(serverData[hostname]) > 0 {
 			serialNumberDashes := strings.Repeat("─", serialNumberWidth)
-			fmt.Printf("├─%s─┼─────────────────────┼─%s─┼─────────────┼──────────────┼─────────────────┼──────────┤\n", serialNumberDashes, deviceDashes)
+			fmt.Printf("├─%s─┼─────────────────────┼─%s─┼%s─────────────┼──────────────┼─────────────────┼──────────┤\n", serialNumberDashes, deviceDashes, switchDashes(switchWidth, "┼"))
 		}
 	}
 }
 
+// displayExcludedRow 显示从测试计划中排除的设备，没有带宽数据
+func displayExcludedRow(data *DeviceData, serialNumberStr, hostnameStr string, serialNumberWidth, deviceWidth, switchWidth int) {
+	fmt.Printf("│ %-*s │ %-19s │ %-*s │%s %11s │ %12s │ %15s │ %-8s │\n",
+		serialNumberWidth, serialNumberStr, hostnameStr, deviceWidth, data.Device,
+		switchCell(switchWidth, switchPortLabel(data.Switch, data.SwitchPort)), "-", "-", "-", StatusExcluded)
+}
+
 // abs 返回浮点数的绝对值
 func abs(x float64) float64 {
 	if x < 0 {
@@ -596,7 +837,7 @@ func abs(x float64) float64 {
 }
 
 // generateEnhancedMarkdownClientContent 生成增强的客户端Markdown表格内容
-func generateEnhancedMarkdownClientContent(clientData map[string]map[string]*DeviceData, theoreticalBW float64) string {
+func generateEnhancedMarkdownClientContent(clientData map[string]map[string]*DeviceData, theoreticalBW float64, showSwitch bool) string {
 	var content strings.Builder
 
 	// Get sorted hostnames
@@ -618,6 +859,10 @@ func generateEnhancedMarkdownClientContent(clientData map[string]map[string]*Dev
 
 		for j, device := range deviceNames {
 			data := devices[device]
+			if data.Excluded {
+				content.WriteString(markdownExcludedRow(data, j == 0, showSwitch))
+				continue
+			}
 			actualBW := data.BWSum
 			delta := actualBW - theoreticalBW
 			deltaPercent := float64(0)
@@ -640,8 +885,8 @@ func generateEnhancedMarkdownClientContent(clientData map[string]map[string]*Dev
 				hostnameStr = hostname
 			}
 
-			content.WriteString(fmt.Sprintf("| %s | %s | %.2f | %.2f | %s | %s |\n",
-				hostnameStr, device, actualBW, theoreticalBW, deltaStr, status))
+			content.WriteString(fmt.Sprintf("| %s | %s |%s %.2f | %.2f | %s | %s |\n",
+				hostnameStr, device, markdownSwitchCell(showSwitch, data.Switch, data.SwitchPort), actualBW, theoreticalBW, deltaStr, status))
 		}
 	}
 
@@ -649,7 +894,7 @@ func generateEnhancedMarkdownClientContent(clientData map[string]map[string]*Dev
 }
 
 // generateEnhancedMarkdownServerContent 生成增强的服务端Markdown表格内容
-func generateEnhancedMarkdownServerContent(serverData map[string]map[string]*DeviceData, specSpeed float64) string {
+func generateEnhancedMarkdownServerContent(serverData map[string]map[string]*DeviceData, specSpeed float64, showSwitch bool) string {
 	var content strings.Builder
 
 	// Get sorted hostnames
@@ -671,6 +916,10 @@ func generateEnhancedMarkdownServerContent(serverData map[string]map[string]*Dev
 
 		for j, device := range deviceNames {
 			data := devices[device]
+			if data.Excluded {
+				content.WriteString(markdownExcludedRow(data, j == 0, showSwitch))
+				continue
+			}
 			actualBW := data.BWSum        // RX 带宽
 			delta := specSpeed - actualBW // DELTA = SPEC - RX
 			deltaPercent := float64(0)
@@ -693,19 +942,31 @@ func generateEnhancedMarkdownServerContent(serverData map[string]map[string]*Dev
 				hostnameStr = hostname
 			}
 
-			content.WriteString(fmt.Sprintf("| %s | %s | %.2f | %.2f | %s | %s |\n",
-				hostnameStr, device, actualBW, specSpeed, deltaStr, status))
+			content.WriteString(fmt.Sprintf("| %s | %s |%s %.2f | %.2f | %s | %s |\n",
+				hostnameStr, device, markdownSwitchCell(showSwitch, data.Switch, data.SwitchPort), actualBW, specSpeed, deltaStr, status))
 		}
 	}
 
 	return content.String()
 }
 
+// markdownExcludedRow 生成从测试计划中排除的设备的 Markdown 表格行
+func markdownExcludedRow(data *DeviceData, showHostname, showSwitch bool) string {
+	hostnameStr := ""
+	if showHostname {
+		hostnameStr = data.Hostname
+	}
+	return fmt.Sprintf("| %s | %s |%s - | - | - | %s |\n",
+		hostnameStr, data.Device, markdownSwitchCell(showSwitch, data.Switch, data.SwitchPort), StatusExcluded)
+}
+
 // P2PDeviceData represents aggregated data for a P2P device
 type P2PDeviceData struct {
 	Hostname     string
 	Device       string
 	SerialNumber string
+	Switch       string // switch the device is cabled to, empty if not discovered
+	SwitchPort   string
 	BWSum        float64
 	Count        int
 }
@@ -770,6 +1031,11 @@ func collectP2PReportData(reportsDir string, sshKeyPath string, user string) (ma
 		if err != nil {
 			return err
 		}
+		// A repeated run keeps each iteration in its own subdirectory;
+		// reading them all would add up the iterations
+		if info.IsDir() && path != reportsDir && store.IsIterationDir(info.Name()) {
+			return filepath.SkipDir
+		}
 
 		if !strings.HasSuffix(info.Name(), ".json") {
 			return nil
@@ -856,12 +1122,15 @@ func displayP2PResults(p2pData map[string]map[string]*P2PDeviceData) {
 
 	maxSerialNumberLen := calculateMaxP2PSerialNumberLength(p2pData)
 
+	// 计算交换机端口列宽度，没有发现交换机端口时不显示该列
+	maxSwitchLen := calculateMaxP2PSwitchPortLength(p2pData)
+
 	// 显示表格头部
 	serialNumberDashes := strings.Repeat("─", maxSerialNumberLen)
 	deviceDashes := strings.Repeat("─", maxDeviceLen)
-	fmt.Printf("┌─%s─┬─────────────────────┬─%s─┬─────────────┐\n", serialNumberDashes, deviceDashes)
-	fmt.Printf("│ %-*s │ Hostname            │ %-*s │ Speed (Gbps)│\n", maxSerialNumberLen, "Serial Number", maxDeviceLen, "Device")
-	fmt.Printf("├─%s─┼─────────────────────┼─%s─┼─────────────┤\n", serialNumberDashes, deviceDashes)
+	fmt.Printf("┌─%s─┬─────────────────────┬─%s─┬%s─────────────┐\n", serialNumberDashes, deviceDashes, switchDashes(maxSwitchLen, "┬"))
+	fmt.Printf("│ %-*s │ Hostname            │ %-*s │%s Speed (Gbps)│\n", maxSerialNumberLen, "Serial Number", maxDeviceLen, "Device", switchCell(maxSwitchLen, "Switch Port"))
+	fmt.Printf("├─%s─┼─────────────────────┼─%s─┼%s─────────────┤\n", serialNumberDashes, deviceDashes, switchDashes(maxSwitchLen, "┼"))
 
 	// Get sorted hostnames
 	var hostnames []string
@@ -892,18 +1161,19 @@ func displayP2PResults(p2pData map[string]map[string]*P2PDeviceData) {
 				hostnameStr = hostname
 			}
 
-			fmt.Printf("│ %-*s │ %-19s │ %-*s │ %11.2f │\n",
-				maxSerialNumberLen, serialNumberStr, hostnameStr, maxDeviceLen, device, avgSpeed)
+			fmt.Printf("│ %-*s │ %-19s │ %-*s │%s %11.2f │\n",
+				maxSerialNumberLen, serialNumberStr, hostnameStr, maxDeviceLen, device,
+				switchCell(maxSwitchLen, switchPortLabel(data.Switch, data.SwitchPort)), avgSpeed)
 
 			// Add separator between different hosts (except for the last host)
 			if j == len(deviceNames)-1 && i < len(hostnames)-1 {
-				fmt.Printf("├─%s─┼─────────────────────┼─%s─┼─────────────┤\n", serialNumberDashes, deviceDashes)
+				fmt.Printf("├─%s─┼─────────────────────┼─%s─┼%s─────────────┤\n", serialNumberDashes, deviceDashes, switchDashes(maxSwitchLen, "┼"))
 			}
 		}
 	}
 
 	// 显示表格尾部
-	fmt.Printf("└─%s─┴─────────────────────┴─%s─┴─────────────┘\n", serialNumberDashes, deviceDashes)
+	fmt.Printf("└─%s─┴─────────────────────┴─%s─┴%s─────────────┘\n", serialNumberDashes, deviceDashes, switchDashes(maxSwitchLen, "┴"))
 
 	// Calculate and display summary
 	totalPairs := 0
@@ -922,12 +1192,18 @@ func displayP2PResults(p2pData map[string]map[string]*P2PDeviceData) {
 }
 
 // generateP2PMarkdownTable generates markdown table for P2P results
-func generateP2PMarkdownTable(p2pData map[string]map[string]*P2PDeviceData) error {
+func generateP2PMarkdownTable(path string, p2pData map[string]map[string]*P2PDeviceData) error {
 	var content strings.Builder
 
 	content.WriteString("# P2P Performance Analysis Report\n\n")
-	content.WriteString("| Hostname | Device | Speed (Gbps) |\n")
-	content.WriteString("|----------|--------|-------------|\n")
+	showSwitch := calculateMaxP2PSwitchPortLength(p2pData) > 0
+	if showSwitch {
+		content.WriteString("| Hostname | Device | Switch Port | Speed (Gbps) |\n")
+		content.WriteString("|----------|--------|-------------|-------------|\n")
+	} else {
+		content.WriteString("| Hostname | Device | Speed (Gbps) |\n")
+		content.WriteString("|----------|--------|-------------|\n")
+	}
 
 	// Get sorted hostnames
 	var hostnames []string
@@ -956,8 +1232,8 @@ func generateP2PMarkdownTable(p2pData map[string]map[string]*P2PDeviceData) erro
 				hostnameStr = hostname
 			}
 
-			content.WriteString(fmt.Sprintf("| %s | %s | %.2f |\n",
-				hostnameStr, device, avgSpeed))
+			content.WriteString(fmt.Sprintf("| %s | %s |%s %.2f |\n",
+				hostnameStr, device, markdownSwitchCell(showSwitch, data.Switch, data.SwitchPort), avgSpeed))
 		}
 	}
 
@@ -978,8 +1254,7 @@ func generateP2PMarkdownTable(p2pData map[string]map[string]*P2PDeviceData) erro
 	}
 
 	// Write to file
-	filename := "p2p_performance_analysis.md"
-	err := os.WriteFile(filename, []byte(content.String()), 0644)
+	err := os.WriteFile(path, []byte(content.String()), 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write P2P markdown file: %w", err)
 	}
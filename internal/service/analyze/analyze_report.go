@@ -3,9 +3,16 @@ package analyze
 import (
 	"fmt"
 	"os"
+	"sort"
 	"xnetperf/config"
+	"xnetperf/internal/events"
+	"xnetperf/internal/service/counters"
+	"xnetperf/internal/service/precheck"
 )
 
+// StatusExcluded 是从测试计划中排除的设备的状态，见 config.Precheck.UnhealthyHCA
+const StatusExcluded = "EXCLUDED"
+
 // ReportData 报告数据结构
 type ReportData struct {
 	StreamType             string                                   `json:"stream_type"`
@@ -16,28 +23,36 @@ type ReportData struct {
 	ServerData             map[string]map[string]*ServerDeviceData  `json:"server_data,omitempty"`
 	P2PData                map[string]map[string]*P2PDeviceDataInfo `json:"p2p_data,omitempty"`
 	P2PSummary             *P2PSummary                              `json:"p2p_summary,omitempty"`
+	CounterDeltas          []counters.Delta                         `json:"counter_deltas,omitempty"` // 测试期间端口计数器的增量
+	Excluded               []precheck.ExcludedHCA                   `json:"excluded,omitempty"`       // precheck 发现不健康、从测试计划中排除的 HCA
 }
 
 // ClientDeviceData 客户端设备数据
 type ClientDeviceData struct {
 	Hostname      string  `json:"hostname"`
 	Device        string  `json:"device"`
+	SerialNumber  string  `json:"serial_number,omitempty"`
+	Switch        string  `json:"switch,omitempty"`      // 所连的交换机，未发现时为空
+	SwitchPort    string  `json:"switch_port,omitempty"` // 所连的交换机端口
 	ActualBW      float64 `json:"actual_bw"`
 	TheoreticalBW float64 `json:"theoretical_bw"`
 	Delta         float64 `json:"delta"`
 	DeltaPercent  float64 `json:"delta_percent"`
-	Status        string  `json:"status"` // OK, NOT OK
+	Status        string  `json:"status"` // OK, NOT OK, EXCLUDED
 }
 
 // ServerDeviceData 服务端设备数据
 type ServerDeviceData struct {
 	Hostname      string  `json:"hostname"`
 	Device        string  `json:"device"`
+	SerialNumber  string  `json:"serial_number,omitempty"`
+	Switch        string  `json:"switch,omitempty"`      // 所连的交换机，未发现时为空
+	SwitchPort    string  `json:"switch_port,omitempty"` // 所连的交换机端口
 	RxBW          float64 `json:"rx_bw"`
 	TheoreticalBW float64 `json:"theoretical_bw"`
 	Delta         float64 `json:"delta"`
 	DeltaPercent  float64 `json:"delta_percent"`
-	Status        string  `json:"status"` // OK, NOT OK
+	Status        string  `json:"status"` // OK, NOT OK, EXCLUDED
 }
 
 // P2PDeviceData P2P设备数据
@@ -54,14 +69,27 @@ type P2PSummary struct {
 	AvgSpeed   float64 `json:"avg_speed"`
 }
 
-// GenerateReport 生成报告数据
-func (a *Analyzer) GenerateReport() (*ReportData, error) {
-	reportsDir := "reports"
-
+// GenerateReport 根据 reportsDir 中的报告文件生成报告数据
+func (a *Analyzer) GenerateReport(reportsDir string) (*ReportData, error) {
 	// 检查 reports 目录是否存在
 	if _, err := os.Stat(reportsDir); os.IsNotExist(err) {
 		return nil, fmt.Errorf("reports directory not found: %s", reportsDir)
 	}
+
+	var clientData, serverData map[string]map[string]*DeviceData
+	if a.cfg.StreamType != config.P2P {
+		// FullMesh 和 InCast 分析
+		var err error
+		clientData, serverData, err = collectReportData(a.ctx, reportsDir, a.cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect report data: %v", err)
+		}
+	}
+	return a.buildReport(reportsDir, clientData, serverData), nil
+}
+
+// buildReport 由收集到的客户端和服务端数据以及 reportsDir 中保存的排除 HCA 和计数器增量生成报告数据
+func (a *Analyzer) buildReport(reportsDir string, clientData, serverData map[string]map[string]*DeviceData) *ReportData {
 	cfg := a.cfg
 	report := &ReportData{
 		StreamType: string(cfg.StreamType),
@@ -72,12 +100,6 @@ func (a *Analyzer) GenerateReport() (*ReportData, error) {
 		// P2P 分析 TODO
 
 	default:
-		// FullMesh 和 InCast 分析
-		clientData, serverData, err := collectReportData(reportsDir, cfg)
-		if err != nil {
-			return nil, fmt.Errorf("failed to collect report data: %v", err)
-		}
-
 		// 计算理论带宽
 		report.TotalServerBW = calculateTotalServerBandwidth(serverData, cfg.Speed)
 		report.ClientCount = calculateClientCount(clientData)
@@ -87,10 +109,76 @@ func (a *Analyzer) GenerateReport() (*ReportData, error) {
 
 		// 转换为 API 响应格式
 		report.ClientData = convertClientData(clientData, report.TheoreticalBWPerClient)
-		report.ServerData = convertServerData(serverData, a.cfg.Speed)
+		report.ServerData = convertServerData(serverData, cfg.Speed)
+	}
+
+	// 从测试计划中排除的 HCA（如有）
+	if excluded, err := precheck.LoadExcluded(reportsDir); err == nil {
+		report.Excluded = excluded
+	} else if !os.IsNotExist(err) {
+		a.logger.Warn("Failed to load excluded HCAs", "dir", reportsDir, "error", err)
+	}
+
+	// 测试前后记录的端口计数器增量（如有）
+	if deltas, err := counters.Load(reportsDir); err == nil {
+		report.CounterDeltas = deltas
+	} else if !os.IsNotExist(err) {
+		a.logger.Warn("Failed to load counter deltas", "dir", reportsDir, "error", err)
+	}
+
+	a.events.Publish(events.Event{
+		Type:    events.TypeAnalysisDone,
+		Message: "Bandwidth report generated",
+		Data:    map[string]any{"clients": len(report.ClientData), "servers": len(report.ServerData)},
+	})
+	return report
+}
+
+// DisplayReport renders a report generated elsewhere, e.g. by the server, with
+// the same tables DoAnalyze prints for local reports
+func (a *Analyzer) DisplayReport(report *ReportData) {
+	if len(report.ClientData) == 0 && len(report.ServerData) == 0 {
+		fmt.Printf("⚠️  No bandwidth data to display (stream_type: %s)\n", report.StreamType)
+		return
 	}
 
-	return report, nil
+	clientData := make(map[string]map[string]*DeviceData)
+	for hostname, devices := range report.ClientData {
+		clientData[hostname] = make(map[string]*DeviceData)
+		for device, data := range devices {
+			clientData[hostname][device] = &DeviceData{
+				Hostname:     hostname,
+				Device:       device,
+				SerialNumber: data.SerialNumber,
+				Switch:       data.Switch,
+				SwitchPort:   data.SwitchPort,
+				BWSum:        data.ActualBW,
+				IsClient:     true,
+				Excluded:     data.Status == StatusExcluded,
+			}
+		}
+	}
+	serverData := make(map[string]map[string]*DeviceData)
+	for hostname, devices := range report.ServerData {
+		serverData[hostname] = make(map[string]*DeviceData)
+		for device, data := range devices {
+			serverData[hostname][device] = &DeviceData{
+				Hostname:     hostname,
+				Device:       device,
+				SerialNumber: data.SerialNumber,
+				Switch:       data.Switch,
+				SwitchPort:   data.SwitchPort,
+				BWSum:        data.RxBW,
+				Excluded:     data.Status == StatusExcluded,
+			}
+		}
+	}
+	displayResults(clientData, serverData, a.cfg.Speed)
+	precheck.DisplayExcluded(report.Excluded)
+	if len(report.CounterDeltas) > 0 {
+		fmt.Println()
+		counters.Display(report.CounterDeltas)
+	}
 }
 
 func convertP2PData(p2pData map[string]map[string]*DeviceData) map[string]map[string]*P2PDeviceDataInfo {
@@ -139,6 +227,17 @@ func convertClientData(clientData map[string]map[string]*DeviceData, theoretical
 	for hostname, devices := range clientData {
 		result[hostname] = make(map[string]*ClientDeviceData)
 		for device, data := range devices {
+			if data.Excluded {
+				result[hostname][device] = &ClientDeviceData{
+					Hostname:     hostname,
+					Device:       device,
+					SerialNumber: data.SerialNumber,
+					Switch:       data.Switch,
+					SwitchPort:   data.SwitchPort,
+					Status:       StatusExcluded,
+				}
+				continue
+			}
 			actualBW := data.BWSum
 			delta := actualBW - theoreticalBW
 			deltaPercent := float64(0)
@@ -154,6 +253,9 @@ func convertClientData(clientData map[string]map[string]*DeviceData, theoretical
 			result[hostname][device] = &ClientDeviceData{
 				Hostname:      hostname,
 				Device:        device,
+				SerialNumber:  data.SerialNumber,
+				Switch:        data.Switch,
+				SwitchPort:    data.SwitchPort,
 				ActualBW:      actualBW,
 				TheoreticalBW: theoreticalBW,
 				Delta:         delta,
@@ -172,6 +274,17 @@ func convertServerData(serverData map[string]map[string]*DeviceData, theoretical
 	for hostname, devices := range serverData {
 		result[hostname] = make(map[string]*ServerDeviceData)
 		for device, data := range devices {
+			if data.Excluded {
+				result[hostname][device] = &ServerDeviceData{
+					Hostname:     hostname,
+					Device:       device,
+					SerialNumber: data.SerialNumber,
+					Switch:       data.Switch,
+					SwitchPort:   data.SwitchPort,
+					Status:       StatusExcluded,
+				}
+				continue
+			}
 
 			delta := data.BWSum - theoreticalBW
 			deltaPercent := float64(0)
@@ -186,6 +299,9 @@ func convertServerData(serverData map[string]map[string]*DeviceData, theoretical
 			result[hostname][device] = &ServerDeviceData{
 				Hostname:      hostname,
 				Device:        device,
+				SerialNumber:  data.SerialNumber,
+				Switch:        data.Switch,
+				SwitchPort:    data.SwitchPort,
 				RxBW:          data.BWSum,
 				TheoreticalBW: theoreticalBW,
 				Delta:         delta,
@@ -197,3 +313,77 @@ func convertServerData(serverData map[string]map[string]*DeviceData, theoretical
 
 	return result
 }
+
+// DeviceBandwidth 单个设备的带宽汇总（用于基线保存和回归对比）
+type DeviceBandwidth struct {
+	Hostname      string  `json:"hostname"`
+	Device        string  `json:"device"`
+	Role          string  `json:"role"` // tx, rx, p2p
+	BandwidthGbps float64 `json:"bandwidth_gbps"`
+}
+
+// Bandwidth roles used by DeviceBandwidth
+const (
+	RoleTX  = "tx"
+	RoleRX  = "rx"
+	RoleP2P = "p2p"
+)
+
+// CollectDeviceBandwidth 汇总 reportsDir 中每个 host/device 的带宽，结果按 role/host/device 排序
+func (a *Analyzer) CollectDeviceBandwidth(reportsDir string) ([]DeviceBandwidth, error) {
+	if _, err := os.Stat(reportsDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("reports directory not found: %s", reportsDir)
+	}
+
+	var result []DeviceBandwidth
+	switch a.cfg.StreamType {
+	case config.P2P:
+		p2pData, err := collectP2PReportData(reportsDir, a.cfg.SSH.PrivateKey, a.cfg.SSH.User)
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect P2P report data: %v", err)
+		}
+		for hostname, devices := range p2pData {
+			for device, data := range devices {
+				if data.Count == 0 {
+					continue
+				}
+				result = append(result, DeviceBandwidth{
+					Hostname:      hostname,
+					Device:        device,
+					Role:          RoleP2P,
+					BandwidthGbps: data.BWSum / float64(data.Count),
+				})
+			}
+		}
+	default:
+		clientData, serverData, err := collectReportData(a.ctx, reportsDir, a.cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect report data: %v", err)
+		}
+		for hostname, devices := range clientData {
+			for device, data := range devices {
+				if !data.Excluded {
+					result = append(result, DeviceBandwidth{Hostname: hostname, Device: device, Role: RoleTX, BandwidthGbps: data.BWSum})
+				}
+			}
+		}
+		for hostname, devices := range serverData {
+			for device, data := range devices {
+				if !data.Excluded {
+					result = append(result, DeviceBandwidth{Hostname: hostname, Device: device, Role: RoleRX, BandwidthGbps: data.BWSum})
+				}
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Role != result[j].Role {
+			return result[i].Role < result[j].Role
+		}
+		if result[i].Hostname != result[j].Hostname {
+			return result[i].Hostname < result[j].Hostname
+		}
+		return result[i].Device < result[j].Device
+	})
+	return result, nil
+}
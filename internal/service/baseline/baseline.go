@@ -0,0 +1,188 @@
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"xnetperf/config"
+	"xnetperf/internal/service/analyze"
+	"xnetperf/internal/service/lat"
+	"xnetperf/pkg/tools/logger"
+)
+
+// DefaultDir is the directory baselines are stored in when none is given
+const DefaultDir = "baselines"
+
+// Baseline is a named snapshot of per-HCA bandwidth and per-pair latency
+type Baseline struct {
+	Name       string                    `json:"name"`
+	CreatedAt  time.Time                 `json:"created_at"`
+	StreamType string                    `json:"stream_type"`
+	Bandwidth  []analyze.DeviceBandwidth `json:"bandwidth,omitempty"`
+	Latency    []LatencyPair             `json:"latency,omitempty"`
+}
+
+// LatencyPair is the average latency measured from one HCA to another
+type LatencyPair struct {
+	SourceHost   string  `json:"source_host"`
+	SourceHCA    string  `json:"source_hca"`
+	TargetHost   string  `json:"target_host"`
+	TargetHCA    string  `json:"target_hca"`
+	AvgLatencyUs float64 `json:"avg_latency_us"`
+}
+
+// Manager captures, stores and loads baselines
+type Manager struct {
+	cfg    *config.Config
+	dir    string
+	logger *slog.Logger
+}
+
+// New creates a baseline manager storing baselines in dir
+func New(cfg *config.Config, dir string) *Manager {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	return &Manager{
+		cfg:    cfg,
+		dir:    dir,
+		logger: logger.GetLogger().With("module", "BASELINE"),
+	}
+}
+
+// Capture builds a baseline from the bandwidth and latency reports found in reportsDir
+func (m *Manager) Capture(name, reportsDir string) (*Baseline, error) {
+	if _, err := os.Stat(reportsDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("reports directory not found: %s", reportsDir)
+	}
+
+	b := &Baseline{
+		Name:       name,
+		CreatedAt:  time.Now(),
+		StreamType: m.cfg.StreamType,
+	}
+
+	bandwidth, err := analyze.New(m.cfg).CollectDeviceBandwidth(reportsDir)
+	if err != nil {
+		return nil, err
+	}
+	b.Bandwidth = bandwidth
+
+	// ParseLatencyReportsFromDir returns an error when no latency reports exist,
+	// which is expected for bandwidth-only runs
+	latencyData, err := lat.New(m.cfg).ParseLatencyReportsFromDir(reportsDir)
+	if err != nil {
+		m.logger.Debug("No latency data captured", "reports_dir", reportsDir, "error", err)
+	}
+	for _, data := range latencyData {
+		b.Latency = append(b.Latency, LatencyPair{
+			SourceHost:   data.SourceHost,
+			SourceHCA:    data.SourceHCA,
+			TargetHost:   data.TargetHost,
+			TargetHCA:    data.TargetHCA,
+			AvgLatencyUs: data.AvgLatencyUs,
+		})
+	}
+	sort.Slice(b.Latency, func(i, j int) bool {
+		return b.Latency[i].key() < b.Latency[j].key()
+	})
+
+	if len(b.Bandwidth) == 0 && len(b.Latency) == 0 {
+		return nil, fmt.Errorf("no bandwidth or latency reports found in %s", reportsDir)
+	}
+	return b, nil
+}
+
+// Save writes the baseline to <dir>/<name>.json, refusing to overwrite unless force is set
+func (m *Manager) Save(b *Baseline, force bool) (string, error) {
+	if err := validateName(b.Name); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create baseline directory: %w", err)
+	}
+
+	path := m.path(b.Name)
+	if _, err := os.Stat(path); err == nil && !force {
+		return "", fmt.Errorf("baseline %q already exists (use --force to overwrite)", b.Name)
+	}
+
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal baseline: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write baseline file '%s': %w", path, err)
+	}
+
+	m.logger.Info("Baseline saved", "name", b.Name, "path", path,
+		"bandwidth_entries", len(b.Bandwidth), "latency_entries", len(b.Latency))
+	return path, nil
+}
+
+// Load reads a baseline by name
+func (m *Manager) Load(name string) (*Baseline, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(m.path(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("baseline %q not found in %s", name, m.dir)
+		}
+		return nil, fmt.Errorf("failed to read baseline %q: %w", name, err)
+	}
+
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %q: %w", name, err)
+	}
+	return &b, nil
+}
+
+// List returns all stored baselines sorted by creation time
+func (m *Manager) List() ([]*Baseline, error) {
+	files, err := filepath.Glob(filepath.Join(m.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var baselines []*Baseline
+	for _, file := range files {
+		b, err := m.Load(strings.TrimSuffix(filepath.Base(file), ".json"))
+		if err != nil {
+			m.logger.Warn("Skipping unreadable baseline", "file", file, "error", err)
+			continue
+		}
+		baselines = append(baselines, b)
+	}
+	sort.Slice(baselines, func(i, j int) bool {
+		return baselines[i].CreatedAt.Before(baselines[j].CreatedAt)
+	})
+	return baselines, nil
+}
+
+func (m *Manager) path(name string) string {
+	return filepath.Join(m.dir, name+".json")
+}
+
+// validateName rejects names that would escape the baseline directory
+func validateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("baseline name cannot be empty")
+	}
+	if strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return fmt.Errorf("invalid baseline name %q", name)
+	}
+	return nil
+}
+
+func (p LatencyPair) key() string {
+	return fmt.Sprintf("%s:%s->%s:%s", p.SourceHost, p.SourceHCA, p.TargetHost, p.TargetHCA)
+}
@@ -0,0 +1,48 @@
+package baseline_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"xnetperf/config"
+	"xnetperf/internal/service/baseline"
+	"xnetperf/internal/store"
+)
+
+func writeReport(t *testing.T, dir, name string, bw float64) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := fmt.Sprintf(`{"test_info": {"test": "BW", "Device": "mlx5_0"}, "results": {"BW_average": %g}}`, bw)
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestCaptureRepeatedRun checks that the iterations of a --repeat run are not
+// added up when its reports directory is captured
+func TestCaptureRepeatedRun(t *testing.T) {
+	reportsDir := t.TempDir()
+	for i := 1; i <= 3; i++ {
+		writeReport(t, store.IterationDir(reportsDir, i), "report_c_host1_mlx5_0_20000.json", 100)
+	}
+	if n := store.Iterations(reportsDir); n != 3 {
+		t.Fatalf("Iterations() = %d, want 3", n)
+	}
+
+	manager := baseline.New(config.NewDefaultConfig(), t.TempDir())
+	if b, err := manager.Capture("all", reportsDir); err == nil {
+		t.Errorf("Capture() of the run reports = %+v, want an error as no iteration is chosen", b.Bandwidth)
+	}
+
+	b, err := manager.Capture("second", store.IterationDir(reportsDir, 2))
+	if err != nil {
+		t.Fatalf("Capture() of one iteration: %v", err)
+	}
+	if len(b.Bandwidth) != 1 || b.Bandwidth[0].BandwidthGbps != 100 {
+		t.Errorf("Bandwidth = %+v, want one device at 100 Gbps", b.Bandwidth)
+	}
+}
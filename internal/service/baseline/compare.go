@@ -0,0 +1,196 @@
+package baseline
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Comparison statuses
+const (
+	StatusOK        = "OK"
+	StatusRegressed = "REGRESSED"
+	StatusImproved  = "IMPROVED"
+	StatusMissing   = "MISSING" // present in the baseline, absent from the current run
+	StatusNew       = "NEW"     // present in the current run, absent from the baseline
+)
+
+// Comparison kinds
+const (
+	KindBandwidth = "bandwidth"
+	KindLatency   = "latency"
+)
+
+// DefaultTolerancePercent is the allowed deviation before a device is flagged
+const DefaultTolerancePercent = 5.0
+
+// ComparisonRow is the comparison of a single device (bandwidth) or HCA pair (latency)
+type ComparisonRow struct {
+	Kind         string  `json:"kind"`
+	Role         string  `json:"role,omitempty"` // tx, rx, p2p (bandwidth only)
+	Hostname     string  `json:"hostname"`
+	Device       string  `json:"device"`
+	Target       string  `json:"target,omitempty"` // host:hca (latency only)
+	Baseline     float64 `json:"baseline"`
+	Current      float64 `json:"current"`
+	DeltaPercent float64 `json:"delta_percent"`
+	Status       string  `json:"status"`
+}
+
+// CompareResult holds all comparison rows and their summary counts
+type CompareResult struct {
+	BaselineName     string          `json:"baseline_name"`
+	TolerancePercent float64         `json:"tolerance_percent"`
+	Rows             []ComparisonRow `json:"rows"`
+	RegressedCount   int             `json:"regressed_count"`
+	ImprovedCount    int             `json:"improved_count"`
+	MissingCount     int             `json:"missing_count"`
+	NewCount         int             `json:"new_count"`
+}
+
+// HasRegressions reports whether any device regressed or disappeared
+func (r *CompareResult) HasRegressions() bool {
+	return r.RegressedCount > 0 || r.MissingCount > 0
+}
+
+// Compare compares the current snapshot against the baseline.
+// Bandwidth regresses when it drops more than tolerancePercent below the baseline;
+// latency regresses when it rises more than tolerancePercent above it.
+func Compare(base, current *Baseline, tolerancePercent float64) *CompareResult {
+	result := &CompareResult{
+		BaselineName:     base.Name,
+		TolerancePercent: tolerancePercent,
+	}
+
+	// Bandwidth: higher is better
+	baseBW := make(map[string]float64)
+	for _, bw := range base.Bandwidth {
+		baseBW[bandwidthKey(bw.Role, bw.Hostname, bw.Device)] = bw.BandwidthGbps
+	}
+	seen := make(map[string]bool)
+	for _, bw := range current.Bandwidth {
+		key := bandwidthKey(bw.Role, bw.Hostname, bw.Device)
+		seen[key] = true
+		row := ComparisonRow{
+			Kind:     KindBandwidth,
+			Role:     bw.Role,
+			Hostname: bw.Hostname,
+			Device:   bw.Device,
+			Current:  bw.BandwidthGbps,
+		}
+		if baseValue, ok := baseBW[key]; ok {
+			row.Baseline = baseValue
+			row.DeltaPercent = deltaPercent(baseValue, bw.BandwidthGbps)
+			row.Status = classify(row.DeltaPercent, tolerancePercent, true)
+		} else {
+			row.Status = StatusNew
+		}
+		result.add(row)
+	}
+	for _, bw := range base.Bandwidth {
+		if !seen[bandwidthKey(bw.Role, bw.Hostname, bw.Device)] {
+			result.add(ComparisonRow{
+				Kind:     KindBandwidth,
+				Role:     bw.Role,
+				Hostname: bw.Hostname,
+				Device:   bw.Device,
+				Baseline: bw.BandwidthGbps,
+				Status:   StatusMissing,
+			})
+		}
+	}
+
+	// Latency: lower is better
+	baseLat := make(map[string]float64)
+	for _, pair := range base.Latency {
+		baseLat[pair.key()] = pair.AvgLatencyUs
+	}
+	seen = make(map[string]bool)
+	for _, pair := range current.Latency {
+		seen[pair.key()] = true
+		row := latencyRow(pair)
+		row.Current = pair.AvgLatencyUs
+		if baseValue, ok := baseLat[pair.key()]; ok {
+			row.Baseline = baseValue
+			row.DeltaPercent = deltaPercent(baseValue, pair.AvgLatencyUs)
+			row.Status = classify(row.DeltaPercent, tolerancePercent, false)
+		} else {
+			row.Status = StatusNew
+		}
+		result.add(row)
+	}
+	for _, pair := range base.Latency {
+		if !seen[pair.key()] {
+			row := latencyRow(pair)
+			row.Baseline = pair.AvgLatencyUs
+			row.Status = StatusMissing
+			result.add(row)
+		}
+	}
+
+	sort.SliceStable(result.Rows, func(i, j int) bool {
+		a, b := result.Rows[i], result.Rows[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Role != b.Role {
+			return a.Role < b.Role
+		}
+		if a.Hostname != b.Hostname {
+			return a.Hostname < b.Hostname
+		}
+		if a.Device != b.Device {
+			return a.Device < b.Device
+		}
+		return a.Target < b.Target
+	})
+	return result
+}
+
+func (r *CompareResult) add(row ComparisonRow) {
+	switch row.Status {
+	case StatusRegressed:
+		r.RegressedCount++
+	case StatusImproved:
+		r.ImprovedCount++
+	case StatusMissing:
+		r.MissingCount++
+	case StatusNew:
+		r.NewCount++
+	}
+	r.Rows = append(r.Rows, row)
+}
+
+// classify maps a delta to a status; higherIsBetter selects the regression direction
+func classify(delta, tolerance float64, higherIsBetter bool) string {
+	if !higherIsBetter {
+		delta = -delta
+	}
+	switch {
+	case delta < -tolerance:
+		return StatusRegressed
+	case delta > tolerance:
+		return StatusImproved
+	default:
+		return StatusOK
+	}
+}
+
+func deltaPercent(base, current float64) float64 {
+	if base == 0 {
+		return 0
+	}
+	return (current - base) / base * 100
+}
+
+func bandwidthKey(role, hostname, device string) string {
+	return fmt.Sprintf("%s|%s|%s", role, hostname, device)
+}
+
+func latencyRow(pair LatencyPair) ComparisonRow {
+	return ComparisonRow{
+		Kind:     KindLatency,
+		Hostname: pair.SourceHost,
+		Device:   pair.SourceHCA,
+		Target:   fmt.Sprintf("%s:%s", pair.TargetHost, pair.TargetHCA),
+	}
+}
@@ -0,0 +1,111 @@
+package baseline
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// ANSI color codes
+const (
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorReset  = "\033[0m"
+)
+
+// DisplayComparison prints the comparison as a table, highlighting regressions in red
+func DisplayComparison(result *CompareResult) {
+	fmt.Printf("=== Baseline Comparison: %s (tolerance ±%.1f%%) ===\n\n", result.BaselineName, result.TolerancePercent)
+
+	bandwidthRows := filterRows(result.Rows, KindBandwidth)
+	if len(bandwidthRows) > 0 {
+		fmt.Println("BANDWIDTH (Gbps)")
+		t := newTable()
+		t.AppendHeader(table.Row{"Role", "Hostname", "Device", "Baseline", "Current", "Delta", "Status"})
+		for _, row := range bandwidthRows {
+			t.AppendRow(table.Row{
+				row.Role,
+				row.Hostname,
+				row.Device,
+				formatValue(row.Baseline, row.Status == StatusNew),
+				formatValue(row.Current, row.Status == StatusMissing),
+				formatDelta(row),
+				colorStatus(row.Status),
+			})
+		}
+		t.Render()
+		fmt.Println()
+	}
+
+	latencyRows := filterRows(result.Rows, KindLatency)
+	if len(latencyRows) > 0 {
+		fmt.Println("LATENCY (μs)")
+		t := newTable()
+		t.AppendHeader(table.Row{"Source Host", "Source HCA", "Target", "Baseline", "Current", "Delta", "Status"})
+		for _, row := range latencyRows {
+			t.AppendRow(table.Row{
+				row.Hostname,
+				row.Device,
+				row.Target,
+				formatValue(row.Baseline, row.Status == StatusNew),
+				formatValue(row.Current, row.Status == StatusMissing),
+				formatDelta(row),
+				colorStatus(row.Status),
+			})
+		}
+		t.Render()
+		fmt.Println()
+	}
+
+	fmt.Printf("Summary: %s%d regressed%s, %s%d missing%s, %s%d improved%s, %d new (Total: %d)\n",
+		colorRed, result.RegressedCount, colorReset,
+		colorRed, result.MissingCount, colorReset,
+		colorGreen, result.ImprovedCount, colorReset,
+		result.NewCount, len(result.Rows))
+}
+
+func newTable() table.Writer {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleRounded)
+	return t
+}
+
+func filterRows(rows []ComparisonRow, kind string) []ComparisonRow {
+	var filtered []ComparisonRow
+	for _, row := range rows {
+		if row.Kind == kind {
+			filtered = append(filtered, row)
+		}
+	}
+	return filtered
+}
+
+func formatValue(value float64, absent bool) string {
+	if absent {
+		return "N/A"
+	}
+	return fmt.Sprintf("%.2f", value)
+}
+
+func formatDelta(row ComparisonRow) string {
+	if row.Status == StatusNew || row.Status == StatusMissing {
+		return "N/A"
+	}
+	return fmt.Sprintf("%+.2f%%", row.DeltaPercent)
+}
+
+func colorStatus(status string) string {
+	switch status {
+	case StatusRegressed, StatusMissing:
+		return colorRed + status + colorReset
+	case StatusImproved:
+		return colorGreen + status + colorReset
+	case StatusNew:
+		return colorYellow + status + colorReset
+	default:
+		return status
+	}
+}
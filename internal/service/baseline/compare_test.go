@@ -0,0 +1,100 @@
+package baseline_test
+
+import (
+	"testing"
+
+	"xnetperf/internal/service/analyze"
+	"xnetperf/internal/service/baseline"
+)
+
+func TestCompare(t *testing.T) {
+	base := &baseline.Baseline{
+		Name: "before",
+		Bandwidth: []analyze.DeviceBandwidth{
+			{Hostname: "host1", Device: "mlx5_0", Role: analyze.RoleTX, BandwidthGbps: 400},
+			{Hostname: "host1", Device: "mlx5_1", Role: analyze.RoleTX, BandwidthGbps: 400},
+			{Hostname: "host2", Device: "mlx5_0", Role: analyze.RoleRX, BandwidthGbps: 400},
+			{Hostname: "host3", Device: "mlx5_0", Role: analyze.RoleRX, BandwidthGbps: 400},
+		},
+		Latency: []baseline.LatencyPair{
+			{SourceHost: "host1", SourceHCA: "mlx5_0", TargetHost: "host2", TargetHCA: "mlx5_0", AvgLatencyUs: 2.0},
+			{SourceHost: "host2", SourceHCA: "mlx5_0", TargetHost: "host1", TargetHCA: "mlx5_0", AvgLatencyUs: 2.0},
+		},
+	}
+	current := &baseline.Baseline{
+		Name: "current",
+		Bandwidth: []analyze.DeviceBandwidth{
+			{Hostname: "host1", Device: "mlx5_0", Role: analyze.RoleTX, BandwidthGbps: 390}, // -2.5%: OK
+			{Hostname: "host1", Device: "mlx5_1", Role: analyze.RoleTX, BandwidthGbps: 300}, // -25%: regressed
+			{Hostname: "host2", Device: "mlx5_0", Role: analyze.RoleRX, BandwidthGbps: 450}, // +12.5%: improved
+			{Hostname: "host4", Device: "mlx5_0", Role: analyze.RoleRX, BandwidthGbps: 400}, // new
+		},
+		Latency: []baseline.LatencyPair{
+			{SourceHost: "host1", SourceHCA: "mlx5_0", TargetHost: "host2", TargetHCA: "mlx5_0", AvgLatencyUs: 3.0}, // +50%: regressed
+			{SourceHost: "host2", SourceHCA: "mlx5_0", TargetHost: "host1", TargetHCA: "mlx5_0", AvgLatencyUs: 1.5}, // -25%: improved
+		},
+	}
+
+	result := baseline.Compare(base, current, 5)
+
+	want := map[string]string{
+		"bandwidth|tx|host1|mlx5_0|":         baseline.StatusOK,
+		"bandwidth|tx|host1|mlx5_1|":         baseline.StatusRegressed,
+		"bandwidth|rx|host2|mlx5_0|":         baseline.StatusImproved,
+		"bandwidth|rx|host3|mlx5_0|":         baseline.StatusMissing,
+		"bandwidth|rx|host4|mlx5_0|":         baseline.StatusNew,
+		"latency||host1|mlx5_0|host2:mlx5_0": baseline.StatusRegressed,
+		"latency||host2|mlx5_0|host1:mlx5_0": baseline.StatusImproved,
+	}
+
+	if len(result.Rows) != len(want) {
+		t.Fatalf("Expected %d rows, got %d", len(want), len(result.Rows))
+	}
+	for _, row := range result.Rows {
+		key := row.Kind + "|" + row.Role + "|" + row.Hostname + "|" + row.Device + "|" + row.Target
+		status, ok := want[key]
+		if !ok {
+			t.Errorf("Unexpected row %s", key)
+			continue
+		}
+		if row.Status != status {
+			t.Errorf("Row %s: expected status %s, got %s (delta %.2f%%)", key, status, row.Status, row.DeltaPercent)
+		}
+	}
+
+	if result.RegressedCount != 2 {
+		t.Errorf("Expected 2 regressions, got %d", result.RegressedCount)
+	}
+	if result.MissingCount != 1 {
+		t.Errorf("Expected 1 missing, got %d", result.MissingCount)
+	}
+	if result.ImprovedCount != 2 {
+		t.Errorf("Expected 2 improvements, got %d", result.ImprovedCount)
+	}
+	if result.NewCount != 1 {
+		t.Errorf("Expected 1 new, got %d", result.NewCount)
+	}
+	if !result.HasRegressions() {
+		t.Error("Expected HasRegressions to be true")
+	}
+}
+
+func TestCompareWithinTolerance(t *testing.T) {
+	base := &baseline.Baseline{
+		Bandwidth: []analyze.DeviceBandwidth{
+			{Hostname: "host1", Device: "mlx5_0", Role: analyze.RoleP2P, BandwidthGbps: 100},
+		},
+	}
+	current := &baseline.Baseline{
+		Bandwidth: []analyze.DeviceBandwidth{
+			{Hostname: "host1", Device: "mlx5_0", Role: analyze.RoleP2P, BandwidthGbps: 91},
+		},
+	}
+
+	if result := baseline.Compare(base, current, 10); result.HasRegressions() {
+		t.Errorf("Expected no regressions within 10%% tolerance, got %+v", result.Rows)
+	}
+	if result := baseline.Compare(base, current, 5); !result.HasRegressions() {
+		t.Error("Expected a regression with 5% tolerance")
+	}
+}
@@ -1,6 +1,7 @@
 package collect
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
@@ -9,25 +10,62 @@ import (
 	"strings"
 	"sync"
 	"xnetperf/config"
+	"xnetperf/internal/audit"
+	"xnetperf/internal/events"
+	"xnetperf/internal/service/neighbor"
 	"xnetperf/pkg/tools"
 )
 
 type Collector struct {
 	cfg    *config.Config
 	logger *slog.Logger
+	events events.Publisher
+	ctx    context.Context
 }
 
 func New(cfg *config.Config) *Collector {
 	return &Collector{
 		cfg:    cfg,
 		logger: slog.Default().With("module", "COLLECT"),
+		events: events.Discard,
+		ctx:    context.Background(),
 	}
 }
 
-func (c *Collector) DoCollect(cleanupRemote bool) error {
-	c.logger.Info("Starting collection of report files", "cleanup_remote", cleanupRemote)
-	// 创建本地reports目录
-	reportsDir := "reports"
+// WithEvents sets the publisher a report_collected event is sent to for every host
+func (c *Collector) WithEvents(publisher events.Publisher) *Collector {
+	c.events = publisher
+	return c
+}
+
+// WithContext sets the context that cancels the collection; the scp and ssh
+// commands in flight are killed
+func (c *Collector) WithContext(ctx context.Context) *Collector {
+	c.ctx = ctx
+	return c
+}
+
+func (c *Collector) publishCollected(host string, count int) {
+	c.events.Publish(events.Event{
+		Type:    events.TypeReportCollected,
+		Host:    host,
+		Message: fmt.Sprintf("Collected %d report files", count),
+		Data:    map[string]any{"files": count},
+	})
+}
+
+// saveNeighbors discovers the switch port of every HCA once, when the reports
+// are taken, and saves them next to the reports for analyze and lat to render
+func (c *Collector) saveNeighbors(reportsDir string) {
+	neighbors := neighbor.New(c.cfg).WithContext(c.ctx).Discover()
+	if err := neighbor.Save(reportsDir, neighbors); err != nil {
+		c.logger.Warn("Failed to save neighbors", "error", err)
+	}
+}
+
+// DoCollect collects report files from all hosts into reportsDir, replacing its contents
+func (c *Collector) DoCollect(reportsDir string, cleanupRemote bool) error {
+	c.logger.Info("Starting collection of report files", "dir", reportsDir, "cleanup_remote", cleanupRemote)
 
 	// Remove existing reports directory if it exists
 	if _, err := os.Stat(reportsDir); err == nil {
@@ -56,17 +94,22 @@ func (c *Collector) DoCollect(cleanupRemote bool) error {
 		wg.Add(1)
 		go func(host string) {
 			defer wg.Done()
-			collectFromHost(host, c.cfg.Report.Dir, reportsDir, c.cfg.SSH.PrivateKey, c.cfg.SSH.User, cleanupRemote)
+			count := collectFromHost(c.ctx, host, c.cfg.Report.Dir, reportsDir, c.cfg.SSH.PrivateKey, c.cfg.SSH.User, cleanupRemote)
+			c.publishCollected(host, count)
 		}(hostname)
 	}
 
 	wg.Wait()
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	c.saveNeighbors(reportsDir)
 	fmt.Printf("Report collection completed. Files saved to '%s' directory.\n", reportsDir)
 	c.logger.Info("Collection process completed successfully")
 	return nil
 }
 
-func collectFromHost(hostname, remoteDir, localBaseDir, sshKeyPath, user string, cleanupRemote bool) int {
+func collectFromHost(ctx context.Context, hostname, remoteDir, localBaseDir, sshKeyPath, user string, cleanupRemote bool) int {
 	// 为每个主机创建本地子目录
 	hostDir := filepath.Join(localBaseDir, hostname)
 	err := os.MkdirAll(hostDir, 0755)
@@ -89,7 +132,7 @@ func collectFromHost(hostname, remoteDir, localBaseDir, sshKeyPath, user string,
 		cmd = exec.Command("scp", "-i", sshKeyPath, fmt.Sprintf("%s:%s", tmpHost, scpCmd), hostDir+"/")
 	}
 
-	output, err := cmd.CombinedOutput()
+	output, err := audit.CombinedOutput(ctx, hostname, "scp "+scpCmd, cmd)
 	if err != nil {
 		// 检查是否是因为没有匹配的文件
 		if string(output) != "" {
@@ -112,7 +155,7 @@ func collectFromHost(hostname, remoteDir, localBaseDir, sshKeyPath, user string,
 
 		// 仅在启用cleanup标志时清理远程主机上的报告文件
 		if cleanupRemote {
-			cleanupRemoteFiles(hostname, remoteDir, sshKeyPath, user)
+			cleanupRemoteFiles(ctx, hostname, remoteDir, sshKeyPath, user)
 		}
 	} else {
 		fmt.Printf("   [INFO] ℹ️  %s: No report files found\n", hostname)
@@ -121,14 +164,14 @@ func collectFromHost(hostname, remoteDir, localBaseDir, sshKeyPath, user string,
 	return len(files)
 }
 
-func cleanupRemoteFiles(hostname, remoteDir, sshKeyPath, user string) {
+func cleanupRemoteFiles(ctx context.Context, hostname, remoteDir, sshKeyPath, user string) {
 	fmt.Printf("   [CLEANUP] 🧹 %s: Cleaning up remote report files...\n", hostname)
 
 	// 首先检查远程目录中是否还有属于当前主机的JSON文件
 	checkCmd := fmt.Sprintf("ls %s/*%s*.json 2>/dev/null | wc -l", remoteDir, hostname)
 	checkExec := tools.BuildSSHCommand(hostname, checkCmd, sshKeyPath, user)
 
-	checkOutput, err := checkExec.CombinedOutput()
+	checkOutput, err := audit.CombinedOutput(ctx, hostname, checkCmd, checkExec)
 	if err != nil {
 		fmt.Printf("   [WARNING] ⚠️  %s: Failed to check remote files: %v\n", hostname, err)
 		return
@@ -144,7 +187,7 @@ func cleanupRemoteFiles(hostname, remoteDir, sshKeyPath, user string) {
 	rmCmd := fmt.Sprintf("rm -f %s/*%s*.json", remoteDir, hostname)
 	cmd := tools.BuildSSHCommand(hostname, rmCmd, sshKeyPath, user)
 
-	output, err := cmd.CombinedOutput()
+	output, err := audit.CombinedOutput(ctx, hostname, rmCmd, cmd)
 	if err != nil {
 		fmt.Printf("   [WARNING] ⚠️  %s: Failed to cleanup remote files: %v\n", hostname, err)
 		if len(output) > 0 {
@@ -157,7 +200,7 @@ func cleanupRemoteFiles(hostname, remoteDir, sshKeyPath, user string) {
 	verifyCmd := fmt.Sprintf("ls %s/*%s*.json 2>/dev/null | wc -l", remoteDir, hostname)
 	verifyExec := tools.BuildSSHCommand(hostname, verifyCmd, sshKeyPath, user)
 
-	verifyOutput, err := verifyExec.CombinedOutput()
+	verifyOutput, err := audit.CombinedOutput(ctx, hostname, verifyCmd, verifyExec)
 	if err == nil && string(verifyOutput) == "0\n" {
 		fmt.Printf("   [CLEANUP] ✅ %s: Remote files cleaned up successfully\n", hostname)
 	} else {
@@ -171,9 +214,11 @@ type CollectResult struct {
 	Message        string         `json:"message"`
 	CollectedFiles map[string]int `json:"collected_files"` // hostname -> file count
 	Error          string         `json:"error,omitempty"`
+	RunID          string         `json:"run_id,omitempty"` // 运行记录 ID，由调用方填写
 }
 
-func (c *Collector) CollectAndGetResult(cfg *config.Config) (*CollectResult, error) {
+// CollectAndGetResult collects report files into reportsDir and returns per-host counts
+func (c *Collector) CollectAndGetResult(cfg *config.Config, reportsDir string) (*CollectResult, error) {
 	result := &CollectResult{
 		CollectedFiles: make(map[string]int),
 	}
@@ -184,9 +229,6 @@ func (c *Collector) CollectAndGetResult(cfg *config.Config) (*CollectResult, err
 		return result, fmt.Errorf("report is not enabled in config")
 	}
 
-	// 创建本地reports目录
-	reportsDir := "reports"
-
 	// 删除已存在的reports目录
 	if _, err := os.Stat(reportsDir); err == nil {
 		err = os.RemoveAll(reportsDir)
@@ -223,7 +265,8 @@ func (c *Collector) CollectAndGetResult(cfg *config.Config) (*CollectResult, err
 		wg.Add(1)
 		go func(host string) {
 			defer wg.Done()
-			count := collectFromHost(host, cfg.Report.Dir, reportsDir, cfg.SSH.PrivateKey, cfg.SSH.User, true)
+			count := collectFromHost(c.ctx, host, cfg.Report.Dir, reportsDir, cfg.SSH.PrivateKey, cfg.SSH.User, true)
+			c.publishCollected(host, count)
 			mu.Lock()
 			result.CollectedFiles[host] = count
 			mu.Unlock()
@@ -231,6 +274,11 @@ func (c *Collector) CollectAndGetResult(cfg *config.Config) (*CollectResult, err
 	}
 
 	wg.Wait()
+	if err := c.ctx.Err(); err != nil {
+		result.Error = err.Error()
+		return result, err
+	}
+	c.saveNeighbors(reportsDir)
 
 	result.Success = true
 	result.Message = fmt.Sprintf("Report collection completed from %d hosts", len(allHosts))
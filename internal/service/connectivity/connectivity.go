@@ -1,14 +1,18 @@
 package connectivity
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
 
 	"xnetperf/config"
+	"xnetperf/internal/events"
 	"xnetperf/internal/script"
 	"xnetperf/internal/service/collect"
 	"xnetperf/internal/service/lat"
+	"xnetperf/internal/store"
 )
 
 const (
@@ -24,6 +28,9 @@ func GetConnectivityTestTimeout() int {
 type Checker struct {
 	cfg    *config.Config
 	logger *slog.Logger
+	runDir string // per-run directory; each test direction gets its own reports/ and scripts/ subdirectory
+	events events.Publisher
+	ctx    context.Context
 }
 
 // New creates a new connectivity checker instance
@@ -31,9 +38,34 @@ func New(cfg *config.Config) *Checker {
 	return &Checker{
 		cfg:    cfg,
 		logger: slog.Default().With("module", "CONNECTIVITY"),
+		events: events.Discard,
+		ctx:    context.Background(),
 	}
 }
 
+// WithEvents sets the publisher the checker and the services it drives report progress to
+func (c *Checker) WithEvents(publisher events.Publisher) *Checker {
+	c.events = publisher
+	return c
+}
+
+// WithContext sets the context that cancels the check; the executor, monitor
+// and collector it drives stop with it
+func (c *Checker) WithContext(ctx context.Context) *Checker {
+	c.ctx = ctx
+	return c
+}
+
+func (c *Checker) publishStep(message string) {
+	c.events.Publish(events.Event{Type: events.TypeStep, Message: message})
+}
+
+// WithRunDir sets the per-run directory that scripts and collected reports are written to
+func (c *Checker) WithRunDir(dir string) *Checker {
+	c.runDir = dir
+	return c
+}
+
 // CheckConnectivity performs bidirectional connectivity testing using 2 incast latency tests
 // This tests all client->server and server->client HCA connectivity
 func (c *Checker) CheckConnectivity() (*ConnectivitySummary, error) {
@@ -58,6 +90,7 @@ func (c *Checker) CheckConnectivity() (*ConnectivitySummary, error) {
 
 	// Step 1: Run client->server connectivity test (normal incast)
 	c.logger.Info("Running client->server connectivity test")
+	c.publishStep("Running client->server connectivity test")
 	if err := c.runConnectivityTest("client-to-server"); err != nil {
 		return nil, fmt.Errorf("client->server test failed: %w", err)
 	}
@@ -68,18 +101,20 @@ func (c *Checker) CheckConnectivity() (*ConnectivitySummary, error) {
 	}
 
 	// Collect reports
-	if err := c.collectReports(); err != nil {
+	clientToServerDir := c.reportsDir("client-to-server")
+	if err := c.collectReports(clientToServerDir); err != nil {
 		return nil, fmt.Errorf("failed to collect client->server reports: %w", err)
 	}
 
 	// Parse client->server results
-	clientToServerResults, err := c.parseConnectivityResults("reports")
+	clientToServerResults, err := c.parseConnectivityResults(clientToServerDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse client->server results: %w", err)
 	}
 
 	// Step 2: Swap client/server roles for server->client connectivity test
 	c.logger.Info("Running server->client connectivity test")
+	c.publishStep("Running server->client connectivity test")
 	c.swapClientServer()
 
 	if err := c.runConnectivityTest("server-to-client"); err != nil {
@@ -94,13 +129,14 @@ func (c *Checker) CheckConnectivity() (*ConnectivitySummary, error) {
 	}
 
 	// Collect reports
-	if err := c.collectReports(); err != nil {
+	serverToClientDir := c.reportsDir("server-to-client")
+	if err := c.collectReports(serverToClientDir); err != nil {
 		c.swapClientServer() // Restore roles
 		return nil, fmt.Errorf("failed to collect server->client reports: %w", err)
 	}
 
 	// Parse server->client results
-	serverToClientResults, err := c.parseConnectivityResults("reports")
+	serverToClientResults, err := c.parseConnectivityResults(serverToClientDir)
 	if err != nil {
 		c.swapClientServer() // Restore roles
 		return nil, fmt.Errorf("failed to parse server->client results: %w", err)
@@ -114,6 +150,16 @@ func (c *Checker) CheckConnectivity() (*ConnectivitySummary, error) {
 
 	// Build summary
 	summary := c.buildSummary(allResults)
+	c.events.Publish(events.Event{
+		Type:    events.TypeAnalysisDone,
+		Message: "Connectivity results analyzed",
+		Data: map[string]any{
+			"total_pairs":        summary.TotalPairs,
+			"connected_pairs":    summary.ConnectedPairs,
+			"disconnected_pairs": summary.DisconnectedPairs,
+			"error_pairs":        summary.ErrorPairs,
+		},
+	})
 
 	c.logger.Info("Connectivity check completed",
 		"total_pairs", summary.TotalPairs,
@@ -133,7 +179,11 @@ func (c *Checker) runConnectivityTest(testName string) error {
 		return fmt.Errorf("failed to create executor for connectivity test")
 	}
 
-	if err := executor.Execute(); err != nil {
+	scriptsDir := store.ScriptsPath(c.runDir)
+	if scriptsDir != "" {
+		scriptsDir = filepath.Join(scriptsDir, testName)
+	}
+	if err := executor.WithScriptsDir(scriptsDir).WithEvents(c.events).WithContext(c.ctx).Execute(); err != nil {
 		return fmt.Errorf("executor failed: %w", err)
 	}
 
@@ -144,7 +194,7 @@ func (c *Checker) runConnectivityTest(testName string) error {
 func (c *Checker) monitorTestProgress(timeoutSeconds int) error {
 	c.logger.Info("Monitoring test progress", "timeout_seconds", timeoutSeconds)
 
-	latRunner := lat.New(c.cfg)
+	latRunner := lat.New(c.cfg).WithEvents(c.events).WithContext(c.ctx)
 	if err := latRunner.MonitorProgressWithTimeout(timeoutSeconds); err != nil {
 		return fmt.Errorf("monitor progress failed: %w", err)
 	}
@@ -152,8 +202,17 @@ func (c *Checker) monitorTestProgress(timeoutSeconds int) error {
 	return nil
 }
 
-// collectReports collects latency report files
-func (c *Checker) collectReports() error {
+// reportsDir returns the local reports directory of a test direction; without a
+// run directory both directions share the legacy reports directory
+func (c *Checker) reportsDir(testName string) string {
+	if c.runDir == "" {
+		return store.ReportsPath("")
+	}
+	return filepath.Join(store.ReportsPath(c.runDir), testName)
+}
+
+// collectReports collects latency report files into reportsDir
+func (c *Checker) collectReports(reportsDir string) error {
 	if !c.cfg.Report.Enable {
 		c.logger.Warn("Report generation is disabled, skipping collect")
 		return fmt.Errorf("report generation must be enabled for connectivity testing")
@@ -161,9 +220,9 @@ func (c *Checker) collectReports() error {
 
 	c.logger.Info("Collecting connectivity test reports")
 
-	collector := collect.New(c.cfg)
+	collector := collect.New(c.cfg).WithEvents(c.events).WithContext(c.ctx)
 	var cleanupRemote = true
-	if err := collector.DoCollect(cleanupRemote); err != nil {
+	if err := collector.DoCollect(reportsDir, cleanupRemote); err != nil {
 		return fmt.Errorf("error during report collection: %w", err)
 	}
 
@@ -0,0 +1,272 @@
+package counters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"xnetperf/config"
+	"xnetperf/internal/audit"
+	"xnetperf/pkg/tools"
+	"xnetperf/pkg/tools/logger"
+
+	"github.com/samber/lo"
+)
+
+// FileName is the file the counter deltas of a test run are saved to in its reports directory
+const FileName = "counter_deltas.json"
+
+// Kinds of watched counters
+const (
+	KindError      = "error"
+	KindCongestion = "congestion"
+)
+
+// watched are the counters (ports/<n>/counters and ports/<n>/hw_counters of
+// the configured ib_port n) whose deltas are reported; other counters such as
+// traffic counters are ignored
+var watched = map[string]string{
+	// counters
+	"symbol_error":                    KindError,
+	"link_error_recovery":             KindError,
+	"link_downed":                     KindError,
+	"port_rcv_errors":                 KindError,
+	"port_rcv_remote_physical_errors": KindError,
+	"port_rcv_switch_relay_errors":    KindError,
+	"port_rcv_constraint_errors":      KindError,
+	"port_xmit_discards":              KindError,
+	"port_xmit_constraint_errors":     KindError,
+	"local_link_integrity_errors":     KindError,
+	"excessive_buffer_overrun_errors": KindError,
+	"VL15_dropped":                    KindError,
+	"port_xmit_wait":                  KindCongestion,
+	// hw_counters
+	"out_of_buffer":              KindError,
+	"out_of_sequence":            KindError,
+	"packet_seq_err":             KindError,
+	"duplicate_request":          KindError,
+	"rnr_nak_retry_err":          KindError,
+	"local_ack_timeout_err":      KindError,
+	"implied_nak_seq_err":        KindError,
+	"req_cqe_error":              KindError,
+	"resp_cqe_error":             KindError,
+	"req_remote_access_errors":   KindError,
+	"req_remote_invalid_request": KindError,
+	"resp_local_length_error":    KindError,
+	"resp_remote_access_errors":  KindError,
+	"rx_icrc_encapsulated":       KindError,
+	"np_cnp_sent":                KindCongestion,
+	"np_ecn_marked_roce_packets": KindCongestion,
+	"rp_cnp_handled":             KindCongestion,
+	"rp_cnp_ignored":             KindCongestion,
+}
+
+// Kind returns the kind of a watched counter, or "" if the counter is not watched
+func Kind(counter string) string {
+	return watched[counter]
+}
+
+// Snapshot is a reading of the counters of all HCAs
+type Snapshot struct {
+	Time   time.Time
+	Values map[string]map[string]map[string]uint64 // host -> hca -> counter -> value
+	Errors map[string]string                       // host -> error of hosts that could not be read
+}
+
+// Delta is the increase of the watched counters of one HCA during a test run
+type Delta struct {
+	Hostname   string            `json:"hostname"`
+	HCA        string            `json:"hca"`
+	Counters   map[string]uint64 `json:"counters,omitempty"` // counters that increased -> increase
+	Errors     uint64            `json:"errors"`             // sum of the error counter increases
+	Congestion uint64            `json:"congestion"`         // sum of the congestion counter increases
+	Error      string            `json:"error,omitempty"`    // why the counters could not be compared
+}
+
+// Reader reads the port counters of every host over SSH
+type Reader struct {
+	cfg      *config.Config
+	logger   *slog.Logger
+	hostHCAs map[string][]string
+	ctx      context.Context
+}
+
+// New creates a reader for all hosts and HCAs in the config
+func New(cfg *config.Config) *Reader {
+	return &Reader{
+		cfg:      cfg,
+		logger:   logger.GetLogger().With("module", "COUNTERS"),
+		hostHCAs: cfg.HostHCAs(),
+		ctx:      context.Background(),
+	}
+}
+
+// WithContext sets the context that cancels the counter reads
+func (r *Reader) WithContext(ctx context.Context) *Reader {
+	r.ctx = ctx
+	return r
+}
+
+// Snapshot reads the counters of all hosts in parallel. Hosts that cannot be
+// read are recorded in Errors and do not fail the snapshot.
+func (r *Reader) Snapshot() *Snapshot {
+	snapshot := &Snapshot{
+		Time:   time.Now(),
+		Values: make(map[string]map[string]map[string]uint64),
+		Errors: make(map[string]string),
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for host, hcas := range r.hostHCAs {
+		wg.Add(1)
+		go func(host string, hcas []string) {
+			defer wg.Done()
+			values, err := r.readHost(host, hcas)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				snapshot.Errors[host] = err.Error()
+				return
+			}
+			snapshot.Values[host] = values
+		}(host, hcas)
+	}
+
+	wg.Wait()
+	return snapshot
+}
+
+func (r *Reader) readHost(host string, hcas []string) (map[string]map[string]uint64, error) {
+	command := buildCounterCommand(hcas, r.cfg.HCAPort())
+	cmd := tools.BuildSSHCommand(host, command, r.cfg.SSH.PrivateKey, r.cfg.SSH.User)
+	output, err := audit.Output(r.ctx, host, command, cmd)
+	if err != nil {
+		r.logger.Warn("Failed to read counters", "host", host, "error", err)
+		return nil, fmt.Errorf("SSH error: %v", err)
+	}
+	return ParseCounters(string(output)), nil
+}
+
+// buildCounterCommand prints one "<path>:<value>" line per counter file of the HCAs
+func buildCounterCommand(hcas []string, ibPort int) string {
+	var b strings.Builder
+	b.WriteString("grep -H .")
+	for _, hca := range hcas {
+		port := fmt.Sprintf("/sys/class/infiniband/%s/ports/%d", hca, ibPort)
+		b.WriteString(fmt.Sprintf(" %s/counters/* %s/hw_counters/*", port, port))
+	}
+	// Missing counter directories are expected, e.g. hw_counters on older drivers
+	b.WriteString(" 2>/dev/null || true")
+	return b.String()
+}
+
+// ParseCounters parses the output of the remote counter command, lines like
+// "/sys/class/infiniband/mlx5_0/ports/1/counters/symbol_error:0", into
+// hca -> counter -> value. Lines that are not counters are skipped.
+func ParseCounters(output string) map[string]map[string]uint64 {
+	values := make(map[string]map[string]uint64)
+	for _, line := range strings.Split(output, "\n") {
+		path, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		parts := strings.Split(path, "/")
+		i := lo.IndexOf(parts, "infiniband")
+		if i < 0 || i+1 >= len(parts) {
+			continue
+		}
+		n, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			continue
+		}
+		hca, counter := parts[i+1], parts[len(parts)-1]
+		if values[hca] == nil {
+			values[hca] = make(map[string]uint64)
+		}
+		values[hca][counter] = n
+	}
+	return values
+}
+
+// Diff returns the increase of the watched counters of every HCA between two
+// snapshots, sorted by host and HCA. Counters that went backwards were reset
+// during the run and are skipped.
+func Diff(before, after *Snapshot) []Delta {
+	var deltas []Delta
+	hosts := lo.Uniq(append(lo.Keys(before.Values), lo.Keys(before.Errors)...))
+	for _, host := range hosts {
+		if err, ok := lo.Coalesce(before.Errors[host], after.Errors[host]); ok {
+			deltas = append(deltas, Delta{Hostname: host, Error: err})
+			continue
+		}
+		for hca, beforeValues := range before.Values[host] {
+			delta := Delta{Hostname: host, HCA: hca}
+			afterValues, ok := after.Values[host][hca]
+			if !ok {
+				delta.Error = "counters not read after the test"
+				deltas = append(deltas, delta)
+				continue
+			}
+			for counter, kind := range watched {
+				b, okBefore := beforeValues[counter]
+				a, okAfter := afterValues[counter]
+				if !okBefore || !okAfter || a <= b {
+					continue
+				}
+				if delta.Counters == nil {
+					delta.Counters = make(map[string]uint64)
+				}
+				delta.Counters[counter] = a - b
+				if kind == KindError {
+					delta.Errors += a - b
+				} else {
+					delta.Congestion += a - b
+				}
+			}
+			deltas = append(deltas, delta)
+		}
+	}
+
+	sort.Slice(deltas, func(i, j int) bool {
+		if deltas[i].Hostname != deltas[j].Hostname {
+			return deltas[i].Hostname < deltas[j].Hostname
+		}
+		return deltas[i].HCA < deltas[j].HCA
+	})
+	return deltas
+}
+
+// Save writes the counter deltas into a reports directory
+func Save(reportsDir string, deltas []Delta) error {
+	data, err := json.MarshalIndent(deltas, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(reportsDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(reportsDir, FileName), data, 0644)
+}
+
+// Load reads the counter deltas saved into a reports directory; reports of
+// runs without counters return an error satisfying os.IsNotExist
+func Load(reportsDir string) ([]Delta, error) {
+	data, err := os.ReadFile(filepath.Join(reportsDir, FileName))
+	if err != nil {
+		return nil, err
+	}
+	var deltas []Delta
+	if err := json.Unmarshal(data, &deltas); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", FileName, err)
+	}
+	return deltas, nil
+}
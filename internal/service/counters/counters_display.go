@@ -0,0 +1,75 @@
+package counters
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// ANSI color codes
+const (
+	colorRed   = "\033[31m"
+	colorReset = "\033[0m"
+)
+
+// Display prints the counter deltas of a test run, one row per HCA, followed
+// by the number of HCAs whose error counters increased
+func Display(deltas []Delta) {
+	if len(deltas) == 0 {
+		return
+	}
+
+	fmt.Println("=== Port Counter Deltas ===")
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleRounded)
+	t.AppendHeader(table.Row{"Hostname", "HCA", "Errors", "Congestion", "Details"})
+
+	withErrors := 0
+	for _, d := range deltas {
+		if d.Error != "" {
+			t.AppendRow(table.Row{d.Hostname, d.HCA, "N/A", "N/A", d.Error})
+			continue
+		}
+		errors := fmt.Sprintf("%d", d.Errors)
+		if d.Errors > 0 {
+			withErrors++
+			errors = colorRed + errors + colorReset
+		}
+		t.AppendRow(table.Row{d.Hostname, d.HCA, errors, d.Congestion, FormatCounters(d.Counters)})
+	}
+	t.Render()
+
+	if withErrors > 0 {
+		fmt.Printf("⚠️  Error counters increased on %d HCAs during the test\n", withErrors)
+	} else {
+		fmt.Println("✅ No error counters increased during the test")
+	}
+}
+
+// FormatCounters formats counter increases as "link_downed +1, symbol_error +12",
+// error counters first, or "-" if none increased
+func FormatCounters(counters map[string]uint64) string {
+	if len(counters) == 0 {
+		return "-"
+	}
+	names := make([]string, 0, len(counters))
+	for name := range counters {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if Kind(names[i]) != Kind(names[j]) {
+			return Kind(names[i]) == KindError
+		}
+		return names[i] < names[j]
+	})
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s +%d", name, counters[name])
+	}
+	return strings.Join(parts, ", ")
+}
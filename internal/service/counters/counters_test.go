@@ -0,0 +1,92 @@
+package counters_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"xnetperf/internal/service/counters"
+)
+
+func TestParseCounters(t *testing.T) {
+	output := "/sys/class/infiniband/mlx5_0/ports/1/counters/symbol_error:3\n" +
+		"/sys/class/infiniband/mlx5_0/ports/1/hw_counters/out_of_buffer:120\n" +
+		"/sys/class/infiniband/mlx5_bond_0/ports/1/counters/link_downed:1\n" +
+		"/sys/class/infiniband/mlx5_0/ports/1/hw_counters/lifespan:N/A\n" +
+		"unrelated output\n"
+
+	want := map[string]map[string]uint64{
+		"mlx5_0":      {"symbol_error": 3, "out_of_buffer": 120},
+		"mlx5_bond_0": {"link_downed": 1},
+	}
+	if got := counters.ParseCounters(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCounters() = %v, want %v", got, want)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before := &counters.Snapshot{
+		Values: map[string]map[string]map[string]uint64{
+			"node-01": {
+				"mlx5_0": {"symbol_error": 3, "np_cnp_sent": 10, "port_xmit_data": 100, "link_downed": 5},
+				"mlx5_1": {"symbol_error": 0},
+			},
+			"node-02": {"mlx5_0": {"symbol_error": 0}},
+		},
+		Errors: map[string]string{"node-03": "SSH error: exit status 255"},
+	}
+	after := &counters.Snapshot{
+		Values: map[string]map[string]map[string]uint64{
+			"node-01": {
+				// link_downed went backwards: the counters were reset and are skipped
+				"mlx5_0": {"symbol_error": 15, "np_cnp_sent": 30, "port_xmit_data": 900, "link_downed": 0},
+			},
+			"node-03": {"mlx5_0": {"symbol_error": 0}},
+		},
+		Errors: map[string]string{"node-02": "SSH error: exit status 255"},
+	}
+
+	want := []counters.Delta{
+		{Hostname: "node-01", HCA: "mlx5_0", Counters: map[string]uint64{"symbol_error": 12, "np_cnp_sent": 20}, Errors: 12, Congestion: 20},
+		{Hostname: "node-01", HCA: "mlx5_1", Error: "counters not read after the test"},
+		{Hostname: "node-02", Error: "SSH error: exit status 255"},
+		{Hostname: "node-03", Error: "SSH error: exit status 255"},
+	}
+	if got := counters.Diff(before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFormatCounters(t *testing.T) {
+	tests := []struct {
+		counters map[string]uint64
+		want     string
+	}{
+		{nil, "-"},
+		{map[string]uint64{"np_cnp_sent": 20, "symbol_error": 12, "link_downed": 1}, "link_downed +1, symbol_error +12, np_cnp_sent +20"},
+	}
+	for _, tt := range tests {
+		if got := counters.FormatCounters(tt.counters); got != tt.want {
+			t.Errorf("FormatCounters(%v) = %q, want %q", tt.counters, got, tt.want)
+		}
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := counters.Load(dir); !os.IsNotExist(err) {
+		t.Fatalf("Load() of a directory without deltas = %v, want not exist", err)
+	}
+
+	deltas := []counters.Delta{{Hostname: "node-01", HCA: "mlx5_0", Counters: map[string]uint64{"symbol_error": 12}, Errors: 12}}
+	if err := counters.Save(dir, deltas); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	got, err := counters.Load(dir)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, deltas) {
+		t.Errorf("Load() = %+v, want %+v", got, deltas)
+	}
+}
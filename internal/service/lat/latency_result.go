@@ -1,5 +1,11 @@
 package lat
 
+import (
+	"xnetperf/internal/service/counters"
+	"xnetperf/internal/service/neighbor"
+	"xnetperf/internal/service/precheck"
+)
+
 // LatencyData represents a single latency measurement
 type LatencyData struct {
 	SourceHost   string  `json:"source_host"`
@@ -18,6 +24,10 @@ type LatencySummary struct {
 	Statistics  LatencyStatistics             `json:"statistics"`
 	ClientStats map[string]LatencyStats       `json:"client_stats,omitempty"` // Only for incast mode
 	ServerStats map[string]LatencyStats       `json:"server_stats,omitempty"` // Only for incast mode
+
+	CounterDeltas []counters.Delta       `json:"counter_deltas,omitempty"` // Port counter increases during the test
+	Neighbors     []neighbor.Neighbor    `json:"neighbors,omitempty"`      // Switch port every HCA is cabled to
+	Excluded      []precheck.ExcludedHCA `json:"excluded,omitempty"`       // Unhealthy HCAs excluded from the test plan
 }
 
 // LatencyStatistics contains global latency statistics
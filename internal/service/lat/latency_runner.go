@@ -1,6 +1,7 @@
 package lat
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -11,27 +12,72 @@ import (
 	"time"
 
 	"xnetperf/config"
+	"xnetperf/internal/audit"
+	"xnetperf/internal/events"
 	"xnetperf/internal/script"
 	"xnetperf/internal/service/collect"
+	"xnetperf/internal/service/counters"
+	"xnetperf/internal/service/neighbor"
 	"xnetperf/internal/service/precheck"
+	"xnetperf/internal/store"
 	"xnetperf/pkg/tools"
 	"xnetperf/stream"
 )
 
 // latRunner manages latency testing workflow
 type latRunner struct {
-	cfg    *config.Config
-	logger *slog.Logger
+	cfg        *config.Config
+	logger     *slog.Logger
+	reportsDir string // local directory reports are collected into
+	scriptsDir string // directory generated scripts are saved to; empty does not save them
+	events     events.Publisher
+	cache      *precheck.Cache        // cached precheck results; nil checks every host
+	excluded   []precheck.ExcludedHCA // unhealthy HCAs excluded from the test plan
+	ctx        context.Context
 }
 
 // New creates a new latency runner instance
 func New(cfg *config.Config) *latRunner {
 	return &latRunner{
-		cfg:    cfg,
-		logger: slog.Default(),
+		cfg:        cfg,
+		logger:     slog.Default(),
+		reportsDir: store.ReportsPath(""),
+		events:     events.Discard,
+		ctx:        context.Background(),
 	}
 }
 
+// WithEvents sets the publisher process count changes and analysis results are sent to
+func (r *latRunner) WithEvents(publisher events.Publisher) *latRunner {
+	r.events = publisher
+	return r
+}
+
+// WithContext sets the context that cancels probing and monitoring; the SSH
+// commands in flight are killed
+func (r *latRunner) WithContext(ctx context.Context) *latRunner {
+	r.ctx = ctx
+	return r
+}
+
+// WithRunDir sets the per-run directory that scripts and collected reports are written to
+func (r *latRunner) WithRunDir(dir string) *latRunner {
+	return r.WithDirs(store.ReportsPath(dir), store.ScriptsPath(dir))
+}
+
+// WithDirs sets the reports and scripts directories explicitly
+func (r *latRunner) WithDirs(reportsDir, scriptsDir string) *latRunner {
+	r.reportsDir = reportsDir
+	r.scriptsDir = scriptsDir
+	return r
+}
+
+// WithPrecheckCache reuses the cached precheck results of recently checked hosts
+func (r *latRunner) WithPrecheckCache(cache *precheck.Cache) *latRunner {
+	r.cache = cache
+	return r
+}
+
 // Execute runs the complete latency testing workflow (for CLI)
 func (r *latRunner) Execute() error {
 	fmt.Println("🚀 Starting xnetperf latency testing workflow...")
@@ -39,9 +85,38 @@ func (r *latRunner) Execute() error {
 
 	// Step 0: Precheck - Verify network card status before starting tests
 	fmt.Println("\n🔍 Step 0/5: Performing network card precheck...")
-	checker := precheck.New(r.cfg)
-	checker.Display(checker.DoCheck())
-	fmt.Println("✅ Precheck passed! All network cards are healthy. Proceeding with latency tests...")
+	checker := precheck.New(r.cfg).WithTestType(script.TestTypeLatency).WithContext(r.ctx).WithCache(r.cache, false)
+	results := checker.DoCheck()
+	checker.Display(results)
+	perftest := checker.CheckPerftest()
+	checker.DisplayPerftest(perftest)
+	hostTuning := checker.CheckHostTuning()
+	checker.DisplayHostTuning(hostTuning)
+	checker.SaveCache(results, perftest, hostTuning)
+	firmware := checker.CheckFirmware(results)
+	precheck.DisplayFirmware(firmware)
+	if precheck.PerftestFailedHosts(perftest) > 0 {
+		return fmt.Errorf("ib_write_lat is missing or too old on some hosts")
+	}
+	if firmware.PinViolationCount > 0 {
+		return fmt.Errorf("some HCAs do not run the pinned firmware version")
+	}
+	decision, err := checker.ApplyUnhealthyHCAPolicy(results, config.UnhealthyHCAWarn)
+	if err != nil {
+		return err
+	}
+	if len(decision.Excluded) > 0 && r.cfg.Version != "v1" {
+		return fmt.Errorf("excluding unhealthy HCAs requires a v1 config")
+	}
+	r.excluded = decision.Excluded
+	precheck.DisplayUnhealthyHCADecision(decision)
+	if precheck.Summarize(results).AllHealthy {
+		fmt.Println("✅ Precheck passed! All network cards are healthy. Proceeding with latency tests...")
+	}
+
+	// Snapshot the port counters so errors accumulated during the test can be reported
+	counterReader := counters.New(r.cfg).WithContext(r.ctx)
+	before := counterReader.Snapshot()
 
 	if r.cfg.Version == "v1" {
 		executor := script.NewExecutor(r.cfg, script.TestTypeLatency)
@@ -49,7 +124,7 @@ func (r *latRunner) Execute() error {
 			return fmt.Errorf("unsupported stream type for v1 execute workflow")
 		}
 		fmt.Println("\n📋 Step 1/5: Running network tests...")
-		err := executor.Execute()
+		err := executor.WithScriptsDir(r.scriptsDir).WithContext(r.ctx).WithExcludedHCAs(precheck.ExcludedHostHCAs(r.excluded)).Execute()
 		if err != nil {
 			return fmt.Errorf("run step failed: %w", err)
 		}
@@ -72,6 +147,7 @@ func (r *latRunner) Execute() error {
 	if err := r.monitorProgress(); err != nil {
 		return fmt.Errorf("probe step failed: %w", err)
 	}
+	deltas := counters.Diff(before, counterReader.Snapshot())
 
 	// Short delay before collection
 	fmt.Println("⏳ Waiting for 2 seconds before collecting reports...")
@@ -82,6 +158,8 @@ func (r *latRunner) Execute() error {
 	if err := r.collectReports(); err != nil {
 		return fmt.Errorf("report collection failed: %w", err)
 	}
+	r.saveCounterDeltas(deltas)
+	r.saveExcluded()
 
 	// Step 5: Analyze and display latency matrix
 	fmt.Println("\n📊 Step 5/5: Analyzing latency results...")
@@ -95,10 +173,8 @@ func (r *latRunner) Execute() error {
 	return nil
 }
 
-// GenerateLatencyReport generates latency report data for API responses
-func (r *latRunner) GenerateLatencyReport() (*LatencySummary, error) {
-	reportsDir := "reports"
-
+// GenerateLatencyReport generates latency report data for API responses from the reports in reportsDir
+func (r *latRunner) GenerateLatencyReport(reportsDir string) (*LatencySummary, error) {
 	// Check if reports directory exists
 	if _, err := os.Stat(reportsDir); os.IsNotExist(err) {
 		return nil, fmt.Errorf("reports directory not found: %s", reportsDir)
@@ -118,6 +194,7 @@ func (r *latRunner) GenerateLatencyReport() (*LatencySummary, error) {
 	summary := &LatencySummary{
 		StreamType: string(r.cfg.StreamType),
 		Matrix:     make(map[string]map[string]float64),
+		Neighbors:  loadNeighbors(reportsDir).List(),
 	}
 
 	// Build matrix
@@ -184,6 +261,20 @@ func (r *latRunner) GenerateLatencyReport() (*LatencySummary, error) {
 		}
 	}
 
+	// Port counter increases recorded around the test, if any
+	if deltas, err := counters.Load(reportsDir); err == nil {
+		summary.CounterDeltas = deltas
+	}
+	// HCAs excluded from the test plan, if any
+	if excluded, err := precheck.LoadExcluded(reportsDir); err == nil {
+		summary.Excluded = excluded
+	}
+
+	r.events.Publish(events.Event{
+		Type:    events.TypeAnalysisDone,
+		Message: "Latency report generated",
+		Data:    map[string]any{"pairs": len(latencyData)},
+	})
 	return summary, nil
 }
 
@@ -203,7 +294,7 @@ func (r *latRunner) generateScripts() error {
 func (r *latRunner) runTests() error {
 	fmt.Println("Executing latency tests...")
 
-	if err := stream.RunLatencyScripts(r.cfg); err != nil {
+	if err := stream.RunLatencyScripts(r.ctx, r.cfg); err != nil {
 		return fmt.Errorf("error running latency scripts: %w", err)
 	}
 
@@ -239,10 +330,25 @@ func (r *latRunner) MonitorProgressWithTimeout(timeoutSeconds int) error {
 
 	probeIntervalSec := 5
 	startTime := time.Now()
+	lastCounts := make(map[string]int)
 
 	for {
 		results := r.probeLatencyAllHosts(allHosts)
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
 		r.displayLatencyProbeResults(results)
+		for _, result := range results {
+			if last, seen := lastCounts[result.Hostname]; seen && last == result.ProcessCount {
+				continue
+			}
+			lastCounts[result.Hostname] = result.ProcessCount
+			r.events.Publish(events.Event{
+				Type: events.TypeProcessCount,
+				Host: result.Hostname,
+				Data: map[string]any{"process": script.TestTypeLatency.Command(), "count": result.ProcessCount},
+			})
+		}
 
 		// Check if all processes have completed
 		allCompleted := true
@@ -269,7 +375,11 @@ func (r *latRunner) MonitorProgressWithTimeout(timeoutSeconds int) error {
 
 		// Wait for next probe
 		fmt.Printf("Waiting %d seconds for next probe...\n\n", probeIntervalSec)
-		time.Sleep(time.Duration(probeIntervalSec) * time.Second)
+		select {
+		case <-r.ctx.Done():
+			return r.ctx.Err()
+		case <-time.After(time.Duration(probeIntervalSec) * time.Second):
+		}
 	}
 
 	return nil
@@ -285,8 +395,8 @@ func (r *latRunner) collectReports() error {
 	var cleanupRemote = true
 	fmt.Println("Collecting latency report files from remote hosts...")
 
-	collector := collect.New(r.cfg)
-	if err := collector.DoCollect(cleanupRemote); err != nil {
+	collector := collect.New(r.cfg).WithContext(r.ctx)
+	if err := collector.DoCollect(r.reportsDir, cleanupRemote); err != nil {
 		return fmt.Errorf("error during report collection: %w", err)
 	}
 
@@ -294,6 +404,28 @@ func (r *latRunner) collectReports() error {
 	return nil
 }
 
+// saveCounterDeltas saves the counter deltas next to the collected reports, or
+// displays them right away when no reports are collected
+func (r *latRunner) saveCounterDeltas(deltas []counters.Delta) {
+	if !r.cfg.Report.Enable {
+		counters.Display(deltas)
+		return
+	}
+	if err := counters.Save(r.reportsDir, deltas); err != nil {
+		fmt.Printf("⚠️  Failed to save counter deltas: %v\n", err)
+	}
+}
+
+// saveExcluded saves the HCAs excluded from the test plan next to the collected reports
+func (r *latRunner) saveExcluded() {
+	if !r.cfg.Report.Enable || len(r.excluded) == 0 {
+		return
+	}
+	if err := precheck.SaveExcluded(r.reportsDir, r.excluded); err != nil {
+		fmt.Printf("⚠️  Failed to save excluded HCAs: %v\n", err)
+	}
+}
+
 // analyzeAndDisplay analyzes latency results and displays N×N matrix
 func (r *latRunner) analyzeAndDisplay() error {
 	if !r.cfg.Report.Enable {
@@ -303,7 +435,7 @@ func (r *latRunner) analyzeAndDisplay() error {
 
 	fmt.Println("Analyzing latency results...")
 
-	reportsDir := "reports"
+	reportsDir := r.reportsDir
 
 	// Check if reports directory exists
 	if _, err := os.Stat(reportsDir); os.IsNotExist(err) {
@@ -323,11 +455,77 @@ func (r *latRunner) analyzeAndDisplay() error {
 		// Default to fullmesh display
 		displayLatencyMatrix(latencyMatrix)
 	}
+	if excluded, err := precheck.LoadExcluded(reportsDir); err == nil {
+		precheck.DisplayExcluded(excluded)
+	}
+	fmt.Println()
+	neighbor.Display(loadNeighbors(reportsDir).List())
+
+	if deltas, err := counters.Load(reportsDir); err == nil {
+		fmt.Println()
+		counters.Display(deltas)
+	}
 
 	fmt.Println("✅ Latency analysis completed successfully")
 	return nil
 }
 
+// DisplayReport renders a latency report generated elsewhere, e.g. by the
+// server, with the same matrix analyzeAndDisplay prints for local reports
+func (r *latRunner) DisplayReport(summary *LatencySummary) {
+	var latencyData []LatencyData
+	for source, targets := range summary.Matrix {
+		sourceHost, sourceHCA := splitHostHCA(source)
+		for target, latency := range targets {
+			targetHost, targetHCA := splitHostHCA(target)
+			latencyData = append(latencyData, LatencyData{
+				SourceHost:   sourceHost,
+				SourceHCA:    sourceHCA,
+				TargetHost:   targetHost,
+				TargetHCA:    targetHCA,
+				AvgLatencyUs: latency,
+			})
+		}
+	}
+
+	if r.cfg.StreamType == config.InCast {
+		displayLatencyMatrixIncast(latencyData, r.cfg)
+	} else {
+		displayLatencyMatrix(latencyData)
+	}
+	precheck.DisplayExcluded(summary.Excluded)
+	if len(summary.Neighbors) > 0 {
+		fmt.Println()
+		neighbor.Display(summary.Neighbors)
+	}
+	if len(summary.CounterDeltas) > 0 {
+		fmt.Println()
+		counters.Display(summary.CounterDeltas)
+	}
+}
+
+// loadNeighbors reads the switch ports saved with the reports when they were
+// collected; reports collected without them show no switch ports
+func loadNeighbors(reportsDir string) neighbor.Map {
+	neighbors, err := neighbor.Load(reportsDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("⚠️  Failed to load neighbors: %v\n", err)
+		}
+		return neighbor.Map{}
+	}
+	return neighbors
+}
+
+// splitHostHCA splits a "host:hca" key of the latency matrix
+func splitHostHCA(key string) (string, string) {
+	i := strings.LastIndex(key, ":")
+	if i < 0 {
+		return key, ""
+	}
+	return key[:i], key[i+1:]
+}
+
 // collectLatencyReportData parses all latency JSON reports
 func (r *latRunner) collectLatencyReportData(reportsDir string) ([]LatencyData, error) {
 	return r.ParseLatencyReportsFromDir(reportsDir)
@@ -342,6 +540,11 @@ func (r *latRunner) ParseLatencyReportsFromDir(reportsDir string) ([]LatencyData
 		if err != nil {
 			return err
 		}
+		// A repeated run keeps each iteration in its own subdirectory;
+		// reading them all would add up the iterations
+		if info.IsDir() && path != reportsDir && store.IsIterationDir(info.Name()) {
+			return filepath.SkipDir
+		}
 
 		// Only process latency JSON files (named like latency_c_*.json or latency_s_*.json)
 		if !info.IsDir() && strings.HasPrefix(info.Name(), "latency_") && strings.HasSuffix(info.Name(), ".json") {
@@ -469,6 +672,9 @@ func (r *latRunner) DoLatencyProbe() ([]LatencyProbeResult, error) {
 	}
 
 	ret := r.probeLatencyAllHosts(allHosts)
+	if err := r.ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	r.logger.Info("Latency probe operation completed successfully")
 	return ret, nil
@@ -534,8 +740,9 @@ func (r *latRunner) probeLatencyHost(hostname string) LatencyProbeResult {
 	}
 
 	// Use SSH to execute ps command to find ib_write_lat processes
-	cmd := tools.BuildSSHCommand(hostname, "ps aux | grep ib_write_lat | grep -v grep", r.cfg.SSH.PrivateKey, r.cfg.SSH.User)
-	output, err := cmd.CombinedOutput()
+	command := "ps aux | grep ib_write_lat | grep -v grep"
+	cmd := tools.BuildSSHCommand(hostname, command, r.cfg.SSH.PrivateKey, r.cfg.SSH.User)
+	output, err := audit.CombinedOutput(r.ctx, hostname, command, cmd)
 
 	if err != nil {
 		// If no processes found or SSH connection failed
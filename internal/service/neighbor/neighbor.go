@@ -0,0 +1,326 @@
+package neighbor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"xnetperf/config"
+	"xnetperf/internal/audit"
+	"xnetperf/pkg/tools"
+	"xnetperf/pkg/tools/logger"
+
+	"github.com/samber/lo"
+)
+
+// Sources the neighbor of an HCA port was discovered from
+const (
+	SourceLLDPCtl  = "lldpctl"  // lldpd
+	SourceLLDPTool = "lldptool" // lldpad
+	SourceSMP      = "smpquery" // InfiniBand directed route query of the peer port
+)
+
+// FileName is the file the neighbors discovered for a test run are saved to in its reports directory
+const FileName = "neighbors.json"
+
+// Neighbor is the switch and port an HCA port is cabled to
+type Neighbor struct {
+	Hostname string `json:"hostname"`
+	HCA      string `json:"hca"`
+	Switch   string `json:"switch,omitempty"`    // LLDP system name, or node description on InfiniBand
+	SwitchID string `json:"switch_id,omitempty"` // LLDP chassis ID, or node GUID on InfiniBand
+	Port     string `json:"port,omitempty"`      // LLDP port ID, or port number on InfiniBand
+	Source   string `json:"source,omitempty"`    // SourceLLDPCtl, SourceLLDPTool or SourceSMP
+	Error    string `json:"error,omitempty"`     // why the neighbor could not be discovered
+}
+
+// Found reports whether the switch port of the HCA was discovered
+func (n Neighbor) Found() bool {
+	return (n.Switch != "" || n.SwitchID != "") && n.Port != ""
+}
+
+// SwitchName returns the switch name, or the switch ID when the switch has no name
+func (n Neighbor) SwitchName() string {
+	return lo.Ternary(n.Switch != "", n.Switch, n.SwitchID)
+}
+
+// Label returns "switch:port" as named by SwitchName, or "" if the neighbor
+// was not discovered
+func (n Neighbor) Label() string {
+	if !n.Found() {
+		return ""
+	}
+	return n.SwitchName() + ":" + n.Port
+}
+
+// Map holds the discovered neighbors as host -> hca -> neighbor
+type Map map[string]map[string]Neighbor
+
+// Get returns the neighbor of an HCA, or a zero Neighbor if it was not discovered
+func (m Map) Get(host, hca string) Neighbor {
+	return m[host][hca]
+}
+
+// Label returns the "switch:port" label of an HCA, or "" if it was not discovered
+func (m Map) Label(host, hca string) string {
+	return m.Get(host, hca).Label()
+}
+
+// List returns all neighbors sorted by host and HCA
+func (m Map) List() []Neighbor {
+	var list []Neighbor
+	for _, hcas := range m {
+		for _, n := range hcas {
+			list = append(list, n)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Hostname != list[j].Hostname {
+			return list[i].Hostname < list[j].Hostname
+		}
+		return list[i].HCA < list[j].HCA
+	})
+	return list
+}
+
+// Save writes the neighbors into a reports directory, so the reports can be
+// rendered later without discovering them again
+func Save(reportsDir string, neighbors Map) error {
+	data, err := json.MarshalIndent(neighbors.List(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(reportsDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(reportsDir, FileName), data, 0644)
+}
+
+// Load reads the neighbors saved into a reports directory; reports collected
+// without neighbors return an error satisfying os.IsNotExist
+func Load(reportsDir string) (Map, error) {
+	data, err := os.ReadFile(filepath.Join(reportsDir, FileName))
+	if err != nil {
+		return nil, err
+	}
+	var list []Neighbor
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", FileName, err)
+	}
+	neighbors := make(Map)
+	for _, n := range list {
+		if neighbors[n.Hostname] == nil {
+			neighbors[n.Hostname] = make(map[string]Neighbor)
+		}
+		neighbors[n.Hostname][n.HCA] = n
+	}
+	return neighbors, nil
+}
+
+// Discoverer finds the switch port of every HCA over SSH
+type Discoverer struct {
+	cfg      *config.Config
+	logger   *slog.Logger
+	hostHCAs map[string][]string
+	ctx      context.Context
+}
+
+// New creates a discoverer for all hosts and HCAs in the config
+func New(cfg *config.Config) *Discoverer {
+	return &Discoverer{
+		cfg:      cfg,
+		logger:   logger.GetLogger().With("module", "NEIGHBOR"),
+		hostHCAs: cfg.HostHCAs(),
+		ctx:      context.Background(),
+	}
+}
+
+// WithContext sets the context that cancels the discovery commands
+func (d *Discoverer) WithContext(ctx context.Context) *Discoverer {
+	d.ctx = ctx
+	return d
+}
+
+// WithHostHCAs only discovers the neighbors of the given host -> HCAs
+func (d *Discoverer) WithHostHCAs(hostHCAs map[string][]string) *Discoverer {
+	d.hostHCAs = hostHCAs
+	return d
+}
+
+// Discover queries the neighbors of all hosts in parallel. HCAs whose
+// neighbor cannot be discovered are returned with Error set.
+func (d *Discoverer) Discover() Map {
+	neighbors := make(Map)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for host, hcas := range d.hostHCAs {
+		wg.Add(1)
+		go func(host string, hcas []string) {
+			defer wg.Done()
+			found := d.discoverHost(host, hcas)
+			mu.Lock()
+			defer mu.Unlock()
+			neighbors[host] = found
+		}(host, hcas)
+	}
+
+	wg.Wait()
+	return neighbors
+}
+
+func (d *Discoverer) discoverHost(host string, hcas []string) map[string]Neighbor {
+	command := buildCommand(hcas, d.cfg.HCAPort())
+	cmd := tools.BuildSSHCommand(host, command, d.cfg.SSH.PrivateKey, d.cfg.SSH.User)
+	output, err := audit.Output(d.ctx, host, command, cmd)
+	if err != nil {
+		d.logger.Warn("Failed to discover neighbors", "host", host, "error", err)
+		found := make(map[string]Neighbor)
+		for _, hca := range hcas {
+			found[hca] = Neighbor{Hostname: host, HCA: hca, Error: fmt.Sprintf("SSH error: %v", err)}
+		}
+		return found
+	}
+	return Parse(host, hcas, string(output))
+}
+
+// buildCommand prints "<hca> <key> <value>" lines for every HCA: the peer
+// node description and node info over a directed route out of ibPort on
+// InfiniBand, the LLDP neighbor of the HCA's network interface on Ethernet
+func buildCommand(hcas []string, ibPort int) string {
+	return fmt.Sprintf(`for h in %s; do `+
+		`d=/sys/class/infiniband/$h; `+
+		`[ -d $d ] || { echo "$h error HCA not found"; continue; }; `+
+		`if [ "$(cat $d/ports/%[2]d/link_layer 2>/dev/null)" = InfiniBand ]; then `+
+		`command -v smpquery >/dev/null || { echo "$h error smpquery not found"; continue; }; `+
+		`echo "$h source %[3]s"; `+
+		`smpquery -C $h -P %[2]d -D nodedesc 0,%[2]d 2>&1 | sed "s/^/$h smp /"; `+
+		`smpquery -C $h -P %[2]d -D nodeinfo 0,%[2]d 2>&1 | sed "s/^/$h smp /"; `+
+		`continue; fi; `+
+		`n=$(ls $d/device/net 2>/dev/null | head -1); `+
+		`[ -n "$n" ] || { echo "$h error no netdev found"; continue; }; `+
+		`if command -v lldpctl >/dev/null; then `+
+		`echo "$h source %[4]s"; lldpctl -f keyvalue $n 2>&1 | sed "s/^/$h lldp /"; `+
+		`elif command -v lldptool >/dev/null; then `+
+		`echo "$h source %[5]s"; `+
+		`for t in sysName chassisID portID; do echo "$h $t $(lldptool -t -n -i $n -V $t 2>/dev/null | tail -1)"; done; `+
+		`else echo "$h error no LLDP agent (lldpctl or lldptool) found"; fi; `+
+		`done`, strings.Join(hcas, " "), ibPort, SourceSMP, SourceLLDPCtl, SourceLLDPTool)
+}
+
+// Parse parses the output of the remote discovery command into hca -> neighbor.
+// HCAs without a discovered switch port get an Error explaining why.
+func Parse(host string, hcas []string, output string) map[string]Neighbor {
+	found := make(map[string]Neighbor)
+	for _, hca := range hcas {
+		found[hca] = Neighbor{Hostname: host, HCA: hca}
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 3)
+		if len(fields) < 2 {
+			continue
+		}
+		n, ok := found[fields[0]]
+		if !ok {
+			continue
+		}
+		value := ""
+		if len(fields) == 3 {
+			value = strings.TrimSpace(fields[2])
+		}
+
+		switch fields[1] {
+		case "error":
+			n.Error = value
+		case "source":
+			n.Source = value
+		case "smp":
+			parseSMP(&n, value)
+		case "lldp":
+			parseLLDPCtl(&n, value)
+		case "sysName":
+			n.Switch = value
+		case "chassisID":
+			n.SwitchID = lldpToolValue(value)
+		case "portID":
+			n.Port = lldpToolValue(value)
+		}
+		found[fields[0]] = n
+	}
+
+	for hca, n := range found {
+		switch {
+		case n.Found():
+			n.Error = ""
+		case n.Error != "":
+		case n.Source == "":
+			n.Error = "no discovery output"
+		default:
+			n.Error = "no neighbor reported"
+		}
+		found[hca] = n
+	}
+	return found
+}
+
+// parseSMP parses a "Key:.....value" line of smpquery nodedesc or nodeinfo;
+// smpquery errors are kept in case the query fails
+func parseSMP(n *Neighbor, line string) {
+	key, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return
+	}
+	value = strings.TrimSpace(strings.TrimLeft(value, "."))
+	switch key {
+	case "smpquery", "ibwarn":
+		n.Error = line
+	case "Node Description":
+		n.Switch = value
+	case "Guid", "NodeGuid":
+		n.SwitchID = value
+	case "LocalPort":
+		n.Port = value
+	}
+}
+
+// parseLLDPCtl parses a "lldp.<netdev>.<key>=<value>" line of lldpctl -f keyvalue.
+// The port name prefers the interface name over a MAC or local port ID.
+func parseLLDPCtl(n *Neighbor, line string) {
+	key, value, ok := strings.Cut(line, "=")
+	if !ok {
+		return
+	}
+	parts := strings.SplitN(key, ".", 3)
+	if len(parts) < 3 || parts[0] != "lldp" {
+		return
+	}
+	switch parts[2] {
+	case "chassis.name":
+		n.Switch = value
+	case "chassis.mac", "chassis.local":
+		if n.SwitchID == "" {
+			n.SwitchID = value
+		}
+	case "port.ifname":
+		n.Port = value
+	case "port.local", "port.mac":
+		if n.Port == "" {
+			n.Port = value
+		}
+	}
+}
+
+// lldpToolValue strips the subtype prefix of an lldptool TLV value, e.g. "Ifname: Ethernet12"
+func lldpToolValue(value string) string {
+	if _, v, ok := strings.Cut(value, ": "); ok {
+		return strings.TrimSpace(v)
+	}
+	return value
+}
@@ -0,0 +1,39 @@
+package neighbor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/samber/lo"
+)
+
+// Display prints the switch port of every HCA, followed by the number of
+// HCAs whose neighbor could not be discovered
+func Display(neighbors []Neighbor) {
+	if len(neighbors) == 0 {
+		return
+	}
+
+	fmt.Println("=== Switch Ports ===")
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleRounded)
+	t.AppendHeader(table.Row{"Hostname", "HCA", "Switch", "Switch ID", "Port", "Source / Error"})
+
+	missing := 0
+	for _, n := range neighbors {
+		if !n.Found() {
+			missing++
+			t.AppendRow(table.Row{n.Hostname, n.HCA, "N/A", "N/A", "N/A", n.Error})
+			continue
+		}
+		t.AppendRow(table.Row{n.Hostname, n.HCA, lo.Ternary(n.Switch == "", "-", n.Switch),
+			lo.Ternary(n.SwitchID == "", "-", n.SwitchID), n.Port, n.Source})
+	}
+	t.Render()
+
+	if missing > 0 {
+		fmt.Printf("⚠️  Switch port not discovered for %d of %d HCAs\n", missing, len(neighbors))
+	}
+}
@@ -0,0 +1,99 @@
+package neighbor_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"xnetperf/internal/service/neighbor"
+)
+
+func TestParse(t *testing.T) {
+	hcas := []string{"mlx5_0", "mlx5_1", "mlx5_2", "mlx5_3", "mlx5_4", "mlx5_5", "mlx5_6"}
+	output := "mlx5_0 source smpquery\n" +
+		"mlx5_0 smp Node Description:.......MF0;ib-leaf-01:MQM8700/U1\n" +
+		"mlx5_0 smp # Node info: DR path slid 65535; dlid 65535; 0,1\n" +
+		"mlx5_0 smp NodeType:.........................Switch\n" +
+		"mlx5_0 smp Guid:.............................0x98039b0300a1b2c3\n" +
+		"mlx5_0 smp LocalPort:........................17\n" +
+		"mlx5_1 source lldpctl\n" +
+		"mlx5_1 lldp lldp.eth2.via=LLDP\n" +
+		"mlx5_1 lldp lldp.eth2.chassis.mac=50:6b:4b:12:34:56\n" +
+		"mlx5_1 lldp lldp.eth2.chassis.name=roce-leaf-02\n" +
+		"mlx5_1 lldp lldp.eth2.port.local=12\n" +
+		"mlx5_1 lldp lldp.eth2.port.ifname=Ethernet12\n" +
+		"mlx5_2 source lldptool\n" +
+		"mlx5_2 sysName roce-leaf-03\n" +
+		"mlx5_2 chassisID MAC: 50:6b:4b:ab:cd:ef\n" +
+		"mlx5_2 portID Ifname: Ethernet7\n" +
+		"mlx5_3 source lldpctl\n" +
+		"mlx5_4 error no LLDP agent (lldpctl or lldptool) found\n" +
+		"mlx5_5 source smpquery\n" +
+		"mlx5_5 smp smpquery: iberror: failed: operation NodeDesc: node desc query failed\n" +
+		"mlx9_9 error HCA not found\n"
+
+	want := map[string]neighbor.Neighbor{
+		"mlx5_0": {Hostname: "node-01", HCA: "mlx5_0", Switch: "MF0;ib-leaf-01:MQM8700/U1", SwitchID: "0x98039b0300a1b2c3", Port: "17", Source: neighbor.SourceSMP},
+		"mlx5_1": {Hostname: "node-01", HCA: "mlx5_1", Switch: "roce-leaf-02", SwitchID: "50:6b:4b:12:34:56", Port: "Ethernet12", Source: neighbor.SourceLLDPCtl},
+		"mlx5_2": {Hostname: "node-01", HCA: "mlx5_2", Switch: "roce-leaf-03", SwitchID: "50:6b:4b:ab:cd:ef", Port: "Ethernet7", Source: neighbor.SourceLLDPTool},
+		"mlx5_3": {Hostname: "node-01", HCA: "mlx5_3", Source: neighbor.SourceLLDPCtl, Error: "no neighbor reported"},
+		"mlx5_4": {Hostname: "node-01", HCA: "mlx5_4", Error: "no LLDP agent (lldpctl or lldptool) found"},
+		"mlx5_5": {Hostname: "node-01", HCA: "mlx5_5", Source: neighbor.SourceSMP, Error: "smpquery: iberror: failed: operation NodeDesc: node desc query failed"},
+		"mlx5_6": {Hostname: "node-01", HCA: "mlx5_6", Error: "no discovery output"},
+	}
+	got := neighbor.Parse("node-01", hcas, output)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLabel(t *testing.T) {
+	tests := []struct {
+		name     string
+		neighbor neighbor.Neighbor
+		want     string
+	}{
+		{"switch name", neighbor.Neighbor{Switch: "leaf-01", SwitchID: "50:6b:4b:12:34:56", Port: "Ethernet12"}, "leaf-01:Ethernet12"},
+		{"switch ID only", neighbor.Neighbor{SwitchID: "0x98039b0300a1b2c3", Port: "17"}, "0x98039b0300a1b2c3:17"},
+		{"no port", neighbor.Neighbor{Switch: "leaf-01"}, ""},
+		{"not discovered", neighbor.Neighbor{Error: "no neighbor reported"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.neighbor.Label(); got != tt.want {
+				t.Errorf("Label() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	neighbors := neighbor.Map{"node-01": {"mlx5_0": {Hostname: "node-01", HCA: "mlx5_0", Switch: "leaf-01", Port: "Ethernet12"}}}
+	if got := neighbors.Label("node-01", "mlx5_0"); got != "leaf-01:Ethernet12" {
+		t.Errorf("Map.Label() = %q", got)
+	}
+	if got := neighbors.Label("node-02", "mlx5_0"); got != "" {
+		t.Errorf("Map.Label() of unknown host = %q, want empty", got)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := neighbor.Load(dir); !os.IsNotExist(err) {
+		t.Fatalf("Load() of a directory without neighbors = %v, want not exist", err)
+	}
+
+	neighbors := neighbor.Map{
+		"node-01": {"mlx5_0": {Hostname: "node-01", HCA: "mlx5_0", Switch: "leaf-01", Port: "Ethernet12", Source: neighbor.SourceLLDPCtl}},
+		"node-02": {"mlx5_0": {Hostname: "node-02", HCA: "mlx5_0", Error: "no neighbor reported"}},
+	}
+	if err := neighbor.Save(dir, neighbors); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	got, err := neighbor.Load(dir)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, neighbors) {
+		t.Errorf("Load() = %+v, want %+v", got, neighbors)
+	}
+}
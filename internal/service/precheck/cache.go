@@ -0,0 +1,222 @@
+package precheck
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"xnetperf/config"
+	"xnetperf/internal/store"
+	"xnetperf/pkg/tools/logger"
+
+	"github.com/samber/lo"
+)
+
+// CacheName 是 precheck 缓存在 run store 中的名称
+const CacheName = "precheck"
+
+// Cache 按主机保存最近一次 precheck 的结果，之后的 precheck 可以跳过最近检查通过的主机
+type Cache struct {
+	Hosts   map[string]*CachedHost `json:"hosts"`
+	store   *store.Store
+	updated map[string]*CachedHost // 本次更新的主机，Save 时合并到 run store 中最新的缓存
+}
+
+// CachedHost 是一台主机最近一次的检查结果
+type CachedHost struct {
+	CheckedAt  time.Time         `json:"checked_at"`  // 检查时间，部分结果复用自更早的检查时为更早的时间
+	ConfigHash string            `json:"config_hash"` // 影响检查结果的配置摘要，配置改变后缓存失效
+	Results    []PrecheckResult  `json:"results"`     // 每个 HCA 的检查结果
+	Perftest   *PerftestResult   `json:"perftest,omitempty"`
+	HostTuning *HostTuningResult `json:"host_tuning,omitempty"`
+}
+
+// LoadCache 读取 run store 中的 precheck 缓存；没有缓存或读取失败时返回空缓存
+func LoadCache(s *store.Store) *Cache {
+	cache := &Cache{store: s}
+	if _, err := s.ReadCache(CacheName, cache); err != nil {
+		logger.GetLogger().With("module", "PRECHECK").Warn("Ignoring unreadable precheck cache", "error", err)
+		cache.Hosts = nil
+	}
+	if cache.Hosts == nil {
+		cache.Hosts = make(map[string]*CachedHost)
+	}
+	return cache
+}
+
+// Save 把本次更新的主机合并到 run store 中最新的缓存并写回。合并在缓存锁内进行，
+// 同时运行的其他 precheck 更新的主机不会被覆盖
+func (c *Cache) Save() error {
+	if c.store == nil {
+		return fmt.Errorf("precheck cache is not backed by a run store")
+	}
+	latest := &Cache{}
+	err := c.store.UpdateCache(CacheName, latest, func() error {
+		if latest.Hosts == nil {
+			latest.Hosts = make(map[string]*CachedHost)
+		}
+		for host, entry := range c.updated {
+			latest.Hosts[host] = entry
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	c.Hosts = latest.Hosts
+	c.updated = nil
+	return nil
+}
+
+// ConfigHash 返回影响 precheck 结果的配置摘要：precheck 的期望值（不含 cache_ttl_seconds 和 unhealthy_hca）、
+// GID index 以及决定 perftest 所需参数的配置
+func ConfigHash(cfg *config.Config) string {
+	expected := cfg.Precheck
+	expected.CacheTTLSeconds = 0
+	expected.UnhealthyHCA = ""
+	data, _ := json.Marshal(struct {
+		Precheck   config.Precheck
+		GidIndex   int
+		Report     bool
+		Infinitely bool
+	}{expected, cfg.GidIndex, cfg.Report.Enable, cfg.Run.Infinitely})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// resultPassed 判断 HCA 的检查结果是否无需重新检查：健康、读取成功且没有与期望不符的项
+func resultPassed(r PrecheckResult) bool {
+	return r.Error == "" && r.IsHealthy && len(r.PCIeIssues) == 0 && len(r.RoCEIssues) == 0
+}
+
+// Reusable 返回主机可以复用的缓存结果，没有可复用的结果时返回 nil。缓存的配置必须与 cfg 相同。
+// 默认只在主机上次的所有检查都通过、覆盖 hcas 且距今不到 cache_ttl_seconds 时复用全部结果；
+// recheckFailed 时不看 ttl，复用上次通过的 HCA、perftest 和主机调优结果，只重新检查失败的项
+func (c *Cache) Reusable(cfg *config.Config, host string, hcas []string, recheckFailed bool, now time.Time) *CachedHost {
+	entry := c.Hosts[host]
+	if entry == nil || entry.ConfigHash != ConfigHash(cfg) {
+		return nil
+	}
+
+	reused := &CachedHost{CheckedAt: entry.CheckedAt, ConfigHash: entry.ConfigHash}
+	for _, hca := range hcas {
+		if r, ok := lo.Find(entry.Results, func(r PrecheckResult) bool { return r.HCA == hca }); ok && resultPassed(r) {
+			reused.Results = append(reused.Results, r)
+		}
+	}
+	if entry.Perftest != nil && entry.Perftest.OK() {
+		reused.Perftest = entry.Perftest
+	}
+	if t := entry.HostTuning; t != nil && t.Error == "" && len(t.Issues) == 0 {
+		reused.HostTuning = t
+	}
+
+	if recheckFailed {
+		if len(reused.Results) == 0 && reused.Perftest == nil && reused.HostTuning == nil {
+			return nil
+		}
+		return reused
+	}
+
+	ttl := time.Duration(cfg.Precheck.CacheTTLSeconds) * time.Second
+	passed := len(reused.Results) == len(hcas) && reused.Perftest != nil &&
+		(reused.HostTuning != nil || !cfg.Precheck.HostTuning.Enabled)
+	if !passed || ttl <= 0 || now.Sub(entry.CheckedAt) >= ttl {
+		return nil
+	}
+	return reused
+}
+
+// Update 记录一台主机的检查结果
+func (c *Cache) Update(cfg *config.Config, host string, checkedAt time.Time, results []PrecheckResult, perftest *PerftestResult, hostTuning *HostTuningResult) {
+	results = lo.Map(results, func(r PrecheckResult, _ int) PrecheckResult {
+		r.Cached = false
+		return r
+	})
+	entry := &CachedHost{
+		CheckedAt:  checkedAt,
+		ConfigHash: ConfigHash(cfg),
+		Results:    results,
+		Perftest:   perftest,
+		HostTuning: hostTuning,
+	}
+	c.Hosts[host] = entry
+	if c.updated == nil {
+		c.updated = make(map[string]*CachedHost)
+	}
+	c.updated[host] = entry
+}
+
+// WithCache 复用 cache 中最近检查通过的主机的结果，只检查其余的主机；recheckFailed 时只重新检查
+// 上次失败的主机和 HCA。cache 为 nil 时检查所有主机
+func (c *checker) WithCache(cache *Cache, recheckFailed bool) *checker {
+	c.cache = cache
+	c.reused = make(map[string]*CachedHost)
+	if cache == nil {
+		return c
+	}
+	now := time.Now()
+	for host, hcas := range c.cfg.HostHCAs() {
+		if reused := cache.Reusable(c.cfg, host, hcas, recheckFailed, now); reused != nil {
+			c.reused[host] = reused
+		}
+	}
+	return c
+}
+
+// skipCachedHCAs 去掉可以复用缓存结果的 HCA，返回仍需检查的主机和 HCA 以及复用的结果
+func (c *checker) skipCachedHCAs(hostHCAs map[string][]string) (map[string][]string, []PrecheckResult) {
+	toCheck := make(map[string][]string)
+	var cached []PrecheckResult
+	for host, hcas := range hostHCAs {
+		reused := c.reused[host]
+		if reused == nil {
+			toCheck[host] = hcas
+			continue
+		}
+		for _, r := range reused.Results {
+			r.Cached = true
+			cached = append(cached, r)
+		}
+		reusedHCAs := lo.Map(reused.Results, func(r PrecheckResult, _ int) string { return r.HCA })
+		if rest := lo.Without(hcas, reusedHCAs...); len(rest) > 0 {
+			toCheck[host] = rest
+		}
+	}
+	return toCheck, cached
+}
+
+// SaveCache 把本次的检查结果写入缓存。复用了缓存结果的主机保留原来的检查时间，
+// 使 ttl 从实际检查时开始计算
+func (c *checker) SaveCache(results []PrecheckResult, perftest []PerftestResult, hostTuning []HostTuningResult) {
+	if c.cache == nil {
+		return
+	}
+	now := time.Now()
+	for host := range c.cfg.HostHCAs() {
+		checkedAt := now
+		if reused := c.reused[host]; reused != nil {
+			checkedAt = reused.CheckedAt
+		}
+		hostResults := lo.Filter(results, func(r PrecheckResult, _ int) bool { return r.Hostname == host })
+		var hostPerftest *PerftestResult
+		if r, ok := lo.Find(perftest, func(r PerftestResult) bool { return r.Hostname == host }); ok {
+			hostPerftest = &r
+		}
+		var hostTuningResult *HostTuningResult
+		if r, ok := lo.Find(hostTuning, func(r HostTuningResult) bool { return r.Hostname == host }); ok {
+			hostTuningResult = &r
+		}
+		c.cache.Update(c.cfg, host, checkedAt, hostResults, hostPerftest, hostTuningResult)
+	}
+	if err := c.cache.Save(); err != nil {
+		c.logger.Warn("Failed to save precheck cache", "error", err)
+	}
+}
+
+// cachedHosts 返回复用了缓存结果的主机
+func cachedHosts(results []PrecheckResult) []string {
+	return lo.Uniq(lo.FilterMap(results, func(r PrecheckResult, _ int) (string, bool) { return r.Hostname, r.Cached }))
+}
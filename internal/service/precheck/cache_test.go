@@ -0,0 +1,130 @@
+package precheck_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"xnetperf/config"
+	"xnetperf/internal/service/precheck"
+	"xnetperf/internal/store"
+)
+
+func TestCacheReusable(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.Precheck.CacheTTLSeconds = 300
+	noTTL := config.NewDefaultConfig()
+	changed := config.NewDefaultConfig()
+	changed.Precheck.CacheTTLSeconds = 300
+	changed.Precheck.RoCE.MTU = 4200
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	healthy := func(hca string) precheck.PrecheckResult {
+		return precheck.PrecheckResult{Hostname: "node-01", HCA: hca, IsHealthy: true}
+	}
+	perftestOK := &precheck.PerftestResult{Hostname: "node-01", Binaries: []precheck.PerftestBinary{{Name: "ib_write_bw", Path: "/usr/bin/ib_write_bw"}}}
+	perftestMissing := &precheck.PerftestResult{Hostname: "node-01", Binaries: []precheck.PerftestBinary{{Name: "ib_write_bw"}}}
+	cached := func(age time.Duration, perftest *precheck.PerftestResult, results ...precheck.PrecheckResult) *precheck.CachedHost {
+		return &precheck.CachedHost{CheckedAt: now.Add(-age), ConfigHash: precheck.ConfigHash(cfg), Results: results, Perftest: perftest}
+	}
+	unhealthy := precheck.PrecheckResult{Hostname: "node-01", HCA: "mlx5_1", PhysState: "Disabled"}
+	roceIssue := healthy("mlx5_1")
+	roceIssue.RoCEIssues = []string{"MTU 1500, expected 4200"}
+
+	tests := []struct {
+		name          string
+		cfg           *config.Config
+		entry         *precheck.CachedHost
+		recheckFailed bool
+		wantHCAs      []string // nil: nothing reused
+		wantPerftest  bool
+	}{
+		{name: "fresh and passed", cfg: cfg, entry: cached(time.Minute, perftestOK, healthy("mlx5_0"), healthy("mlx5_1")), wantHCAs: []string{"mlx5_0", "mlx5_1"}, wantPerftest: true},
+		{name: "not cached", cfg: cfg},
+		{name: "expired", cfg: cfg, entry: cached(10*time.Minute, perftestOK, healthy("mlx5_0"), healthy("mlx5_1"))},
+		{name: "ttl disabled", cfg: noTTL, entry: cached(time.Minute, perftestOK, healthy("mlx5_0"), healthy("mlx5_1"))},
+		{name: "config changed", cfg: changed, entry: cached(time.Minute, perftestOK, healthy("mlx5_0"), healthy("mlx5_1"))},
+		{name: "HCA not cached", cfg: cfg, entry: cached(time.Minute, perftestOK, healthy("mlx5_0"))},
+		{name: "unhealthy HCA", cfg: cfg, entry: cached(time.Minute, perftestOK, healthy("mlx5_0"), unhealthy)},
+		{name: "RoCE issue", cfg: cfg, entry: cached(time.Minute, perftestOK, healthy("mlx5_0"), roceIssue)},
+		{name: "perftest missing", cfg: cfg, entry: cached(time.Minute, perftestMissing, healthy("mlx5_0"), healthy("mlx5_1"))},
+		{name: "recheck failed HCA", cfg: cfg, entry: cached(time.Hour, perftestOK, healthy("mlx5_0"), unhealthy), recheckFailed: true, wantHCAs: []string{"mlx5_0"}, wantPerftest: true},
+		{name: "recheck failed perftest", cfg: cfg, entry: cached(time.Hour, perftestMissing, healthy("mlx5_0"), healthy("mlx5_1")), recheckFailed: true, wantHCAs: []string{"mlx5_0", "mlx5_1"}},
+		{name: "recheck SSH failure", cfg: cfg, entry: cached(time.Hour, &precheck.PerftestResult{Hostname: "node-01", Error: "SSH execution failed"},
+			precheck.PrecheckResult{Hostname: "node-01", Error: "SSH execution failed"}), recheckFailed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := &precheck.Cache{Hosts: map[string]*precheck.CachedHost{}}
+			if tt.entry != nil {
+				cache.Hosts["node-01"] = tt.entry
+			}
+			reused := cache.Reusable(tt.cfg, "node-01", []string{"mlx5_0", "mlx5_1"}, tt.recheckFailed, now)
+			if tt.wantHCAs == nil {
+				if reused != nil {
+					t.Fatalf("Reusable() = %+v, want nil", reused)
+				}
+				return
+			}
+			if reused == nil {
+				t.Fatal("Reusable() = nil")
+			}
+			var hcas []string
+			for _, r := range reused.Results {
+				hcas = append(hcas, r.HCA)
+			}
+			if !reflect.DeepEqual(hcas, tt.wantHCAs) {
+				t.Errorf("reused HCAs = %v, want %v", hcas, tt.wantHCAs)
+			}
+			if (reused.Perftest != nil) != tt.wantPerftest {
+				t.Errorf("reused perftest = %v, want %v", reused.Perftest != nil, tt.wantPerftest)
+			}
+		})
+	}
+}
+
+func TestCacheSaveLoad(t *testing.T) {
+	s, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.NewDefaultConfig()
+	checkedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	cache := precheck.LoadCache(s)
+	if len(cache.Hosts) != 0 {
+		t.Fatalf("expected an empty cache, got %v", cache.Hosts)
+	}
+	cache.Update(cfg, "node-01", checkedAt, []precheck.PrecheckResult{{Hostname: "node-01", HCA: "mlx5_0", IsHealthy: true, Cached: true}}, nil, nil)
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	want := &precheck.CachedHost{
+		CheckedAt:  checkedAt,
+		ConfigHash: precheck.ConfigHash(cfg),
+		Results:    []precheck.PrecheckResult{{Hostname: "node-01", HCA: "mlx5_0", IsHealthy: true}},
+	}
+	if got := precheck.LoadCache(s).Hosts["node-01"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded %+v, want %+v", got, want)
+	}
+
+	// a cache loaded before another precheck saved keeps that precheck's hosts
+	stale := precheck.LoadCache(s)
+	other := precheck.LoadCache(s)
+	other.Update(cfg, "node-02", checkedAt, nil, nil, nil)
+	if err := other.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	stale.Update(cfg, "node-03", checkedAt, nil, nil, nil)
+	if err := stale.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded := precheck.LoadCache(s)
+	for _, host := range []string{"node-01", "node-02", "node-03"} {
+		if loaded.Hosts[host] == nil {
+			t.Errorf("expected %s in the saved cache, got %v", host, loaded.Hosts)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package precheck
 
-import "sort"
+import (
+	"sort"
+
+	"xnetperf/internal/service/neighbor"
+)
 
 // ColorStyle 颜色样式枚举
 type ColorStyle int
@@ -56,7 +60,13 @@ type PrecheckDisplayItem struct {
 	Speed        FieldColorInfo // 速度（可能着色）
 	FwVer        FieldColorInfo // 固件版本（可能着色）
 	BoardId      FieldColorInfo // 板卡ID（可能着色）
+	PCIeLink     FieldColorInfo // PCIe 链路（与期望不符时着色）
+	NUMANode     string         // NUMA 节点（不着色）
+	RoCE         FieldColorInfo // RoCE 网络接口（与期望不符时着色）
+	SwitchPort   string         // 所连的交换机端口（不着色）
 	Status       FieldColorInfo // 状态（着色）
+	PCIeIssues   []string       // 与期望不符的 PCIe 项
+	RoCEIssues   []string       // 与期望不符的 RoCE 项
 }
 
 // PrecheckDisplayData 预检查展示数据集合（包含统计信息和着色规则）
@@ -68,6 +78,8 @@ type PrecheckDisplayData struct {
 	UnhealthyCount int
 	ErrorCount     int
 	TotalCount     int
+	PCIeIssueCount int // PCIe 与期望不符的 HCA 数量
+	RoCEIssueCount int // RoCE 配置与期望不符的 HCA 数量
 }
 
 // NewPrecheckDisplayData 从 PrecheckResult 创建展示数据（应用着色规则）
@@ -126,12 +138,19 @@ func NewPrecheckDisplayData(results []PrecheckResult) *PrecheckDisplayData {
 			SerialNumber: result.SerialNumber,
 			PhysState:    result.PhysState,
 			State:        result.State,
+			NUMANode:     result.NUMANode,
+			SwitchPort:   switchPortLabel(result),
+			PCIeIssues:   result.PCIeIssues,
+			RoCEIssues:   result.RoCEIssues,
 		}
 
 		// 处理 N/A 值
 		if item.SerialNumber == "" {
 			item.SerialNumber = "N/A"
 		}
+		if item.NUMANode == "" {
+			item.NUMANode = "N/A"
+		}
 
 		// 处理错误情况
 		if result.Error != "" {
@@ -142,6 +161,10 @@ func NewPrecheckDisplayData(results []PrecheckResult) *PrecheckDisplayData {
 			item.Speed = FieldColorInfo{Value: "N/A", ColorStyle: ColorStyleNormal}
 			item.FwVer = FieldColorInfo{Value: "N/A", ColorStyle: ColorStyleNormal}
 			item.BoardId = FieldColorInfo{Value: "N/A", ColorStyle: ColorStyleNormal}
+			item.PCIeLink = FieldColorInfo{Value: "N/A", ColorStyle: ColorStyleNormal}
+			item.NUMANode = "N/A"
+			item.RoCE = FieldColorInfo{Value: "N/A", ColorStyle: ColorStyleNormal}
+			item.SwitchPort = "N/A"
 			item.Status = FieldColorInfo{Value: "[!] ERROR", ColorStyle: ColorStyleWarning}
 
 			display.ErrorCount++
@@ -155,6 +178,12 @@ func NewPrecheckDisplayData(results []PrecheckResult) *PrecheckDisplayData {
 			// 应用 BoardId 着色规则
 			item.BoardId = display.applyBoardIdColor(result.BoardId, boardIdCounts[result.BoardId], maxBoardIdCount)
 
+			// 应用 PCIe 着色规则
+			item.PCIeLink = display.applyPCIeColor(result.PCIeLink, result.PCIeIssues)
+
+			// 应用 RoCE 着色规则
+			item.RoCE = display.applyRoCEColor(result)
+
 			// 应用 Status 着色规则
 			if result.IsHealthy {
 				item.Status = FieldColorInfo{Value: "[+] HEALTHY", ColorStyle: ColorStyleSuccess}
@@ -171,6 +200,15 @@ func NewPrecheckDisplayData(results []PrecheckResult) *PrecheckDisplayData {
 	return display
 }
 
+// switchPortLabel 返回 "交换机:端口"，未发现时为 "-"
+func switchPortLabel(result PrecheckResult) string {
+	label := neighbor.Neighbor{Switch: result.Switch, SwitchID: result.SwitchID, Port: result.SwitchPort}.Label()
+	if label == "" {
+		return "-"
+	}
+	return label
+}
+
 // applySpeedColor 应用速度字段着色规则
 func (d *PrecheckDisplayData) applySpeedColor(speed string, count, maxCount int) FieldColorInfo {
 	if speed == "" {
@@ -215,6 +253,40 @@ func (d *PrecheckDisplayData) applyBoardIdColor(boardId string, count, maxCount
 	return FieldColorInfo{Value: boardId, ColorStyle: ColorStyleNormal}
 }
 
+// applyPCIeColor 应用 PCIe 链路字段着色规则
+func (d *PrecheckDisplayData) applyPCIeColor(link string, issues []string) FieldColorInfo {
+	if len(issues) > 0 {
+		d.PCIeIssueCount++
+		if link == "" {
+			link = "N/A"
+		}
+		// 规则：与期望值不符（降速、降宽、NUMA、relaxed ordering、ACS）标红色
+		return FieldColorInfo{Value: link, ColorStyle: ColorStyleError}
+	}
+	if link == "" {
+		return FieldColorInfo{Value: "N/A", ColorStyle: ColorStyleNormal}
+	}
+	return FieldColorInfo{Value: link, ColorStyle: ColorStyleNormal}
+}
+
+// applyRoCEColor 应用 RoCE 字段着色规则，显示 GID 所属的网络接口
+func (d *PrecheckDisplayData) applyRoCEColor(result PrecheckResult) FieldColorInfo {
+	if result.LinkLayer != LinkLayerEthernet {
+		// InfiniBand 不检查 RoCE 配置
+		return FieldColorInfo{Value: "-", ColorStyle: ColorStyleNormal}
+	}
+	value := result.NetDev
+	if value == "" {
+		value = "N/A"
+	}
+	// 规则：与期望值不符（GID、MTU、trust、PFC、ECN）标红色，否则标绿色
+	if len(result.RoCEIssues) > 0 {
+		d.RoCEIssueCount++
+		return FieldColorInfo{Value: value, ColorStyle: ColorStyleError}
+	}
+	return FieldColorInfo{Value: value, ColorStyle: ColorStyleSuccess}
+}
+
 // SortByHostAndHCA 按主机名和HCA排序
 func (d *PrecheckDisplayData) SortByHostAndHCA() {
 	sort.Slice(d.Items, func(i, j int) bool {
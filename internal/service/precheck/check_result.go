@@ -20,4 +20,30 @@ type PrecheckResult struct {
 	IsHealthy    bool   `json:"is_healthy"`
 	SerialNumber string `json:"serial_number"`
 	Error        string `json:"error"`
+
+	// PCIe 链路与设备设置，读取失败时为空
+	PCIeLink        string   `json:"pcie_link,omitempty"`        // 当前链路，例如 "16.0 GT/s x16"
+	PCIeMaxLink     string   `json:"pcie_max_link,omitempty"`    // 设备支持的最大链路
+	NUMANode        string   `json:"numa_node,omitempty"`        // -1 表示 BIOS 未提供 NUMA 信息
+	RelaxedOrdering string   `json:"relaxed_ordering,omitempty"` // enabled 或 disabled
+	ACS             string   `json:"acs,omitempty"`              // 上游桥的 ACS：enabled 或 disabled
+	PCIeIssues      []string `json:"pcie_issues,omitempty"`      // 与期望值不符的项，见 config.PCIeCheck
+
+	// RoCE 配置，仅 link_layer 为 Ethernet 的 HCA 填写
+	LinkLayer  string   `json:"link_layer,omitempty"`  // InfiniBand 或 Ethernet
+	GID        string   `json:"gid,omitempty"`         // config.GidIndex 处 GID 映射的 IPv4 地址
+	GIDType    string   `json:"gid_type,omitempty"`    // 例如 "RoCE v2"
+	NetDev     string   `json:"netdev,omitempty"`      // GID 所属的网络接口
+	MTU        string   `json:"mtu,omitempty"`         // 网络接口的 MTU
+	Trust      string   `json:"trust,omitempty"`       // pcp 或 dscp
+	PFC        string   `json:"pfc,omitempty"`         // 开启 PFC 的优先级，例如 "3"，none 表示全部关闭
+	ECN        string   `json:"ecn,omitempty"`         // 开启 ECN 的优先级
+	RoCEIssues []string `json:"roce_issues,omitempty"` // 与期望值不符的项，见 config.RoCECheck
+
+	// 端口所连的交换机，通过 LLDP 或 InfiniBand SMP 查询，未发现时为空
+	Switch     string `json:"switch,omitempty"`      // 交换机名称
+	SwitchID   string `json:"switch_id,omitempty"`   // LLDP chassis ID 或 InfiniBand node GUID
+	SwitchPort string `json:"switch_port,omitempty"` // 交换机端口
+
+	Cached bool `json:"cached,omitempty"` // 复用自最近一次检查的缓存结果，见 precheck.cache_ttl_seconds
 }
@@ -1,15 +1,20 @@
 package precheck
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 	"xnetperf/config"
+	"xnetperf/internal/audit"
+	"xnetperf/internal/script"
+	"xnetperf/internal/service/neighbor"
 	"xnetperf/internal/tools"
 	"xnetperf/pkg/tools/logger"
 
@@ -18,59 +23,74 @@ import (
 )
 
 type checker struct {
-	cfg    *config.Config
-	logger *slog.Logger
+	cfg      *config.Config
+	logger   *slog.Logger
+	testType script.TestType // 决定检查哪些 perftest 工具，为空时全部检查
+	cache    *Cache
+	reused   map[string]*CachedHost // 主机 -> 复用的缓存结果，见 WithCache
+	ctx      context.Context
 }
 
 func New(cfg *config.Config) *checker {
 	return &checker{
 		cfg:    cfg,
 		logger: logger.GetLogger().With("module", "PRECHECK"),
+		ctx:    context.Background(),
 	}
 }
 
+// WithContext 设置取消检查的上下文，取消后正在执行的 SSH 命令被终止
+func (c *checker) WithContext(ctx context.Context) *checker {
+	c.ctx = ctx
+	return c
+}
+
 func (c *checker) DoCheck() []PrecheckResult {
 	// 1. 解析配置文件，获取所有主机和HCA信息
 	// 收集所有需要检查的主机和HCA，支持去重
-	hostHCAs := c.buildHostHCAs()
+	hostHCAs := c.cfg.HostHCAs()
 	if len(hostHCAs) == 0 {
 		return []PrecheckResult{{Error: "No hosts configured in config file"}}
 	}
 
-	// 2. 生成每个host上要执行的命令
+	// 2. 跳过可以复用缓存结果的 HCA
+	hostHCAs, cached := c.skipCachedHCAs(hostHCAs)
+
+	return append(c.checkHCAs(hostHCAs), cached...)
+}
+
+// checkHCAs 检查给定主机上的 HCA，DoCheck 和修复后的重新检查共用
+func (c *checker) checkHCAs(hostHCAs map[string][]string) []PrecheckResult {
+	// 3. 生成每个host上要执行的命令
 	hostCommands := c.buildHostCommands(hostHCAs)
 
-	// 3. 并发执行命令，收集结果（DTO层）
+	// 4. 并发执行命令，收集结果（DTO层）
 	hostDataList := c.execPrecheckCommands(hostCommands)
 
-	// 4. 转换为展示层数据
+	// 5. 转换为展示层数据
 	results := c.convertHostDataToResults(hostDataList)
 
+	// 6. 附加每个 HCA 所连的交换机端口
+	if c.ctx.Err() != nil {
+		return results
+	}
+	c.attachNeighbors(results, hostHCAs)
+
 	return results
 }
 
-// 1. 收集所有需要检查的主机和HCA
-func (c *checker) buildHostHCAs() map[string][]string {
-	hostHCAs := make(map[string][]string)
-	// 添加服务器端的主机和HCA
-	for _, hostname := range c.cfg.Server.Hostname {
-		if _, exists := hostHCAs[hostname]; !exists {
-			hostHCAs[hostname] = make([]string, 0)
-		}
-		// 合并HCA列表并去重
-		hostHCAs[hostname] = lo.Union(hostHCAs[hostname], c.cfg.Server.Hca)
+// attachNeighbors 查询检查的 HCA 所连的交换机端口并写入检查结果
+func (c *checker) attachNeighbors(results []PrecheckResult, hostHCAs map[string][]string) {
+	if len(hostHCAs) == 0 {
+		return
 	}
-
-	// 添加客户端的主机和HCA
-	for _, hostname := range c.cfg.Client.Hostname {
-		if _, exists := hostHCAs[hostname]; !exists {
-			hostHCAs[hostname] = make([]string, 0)
+	neighbors := neighbor.New(c.cfg).WithHostHCAs(hostHCAs).WithContext(c.ctx).Discover()
+	for i := range results {
+		n := neighbors.Get(results[i].Hostname, results[i].HCA)
+		if n.Found() {
+			results[i].Switch, results[i].SwitchID, results[i].SwitchPort = n.Switch, n.SwitchID, n.Port
 		}
-		// 合并HCA列表并去重
-		hostHCAs[hostname] = lo.Union(hostHCAs[hostname], c.cfg.Client.Hca)
 	}
-
-	return hostHCAs
 }
 
 // 2. 生成每个host上要执行的命令,使用JSON格式输出便于解析
@@ -82,6 +102,8 @@ func (c *checker) buildHostHCAs() map[string][]string {
 */
 func (c *checker) buildHostCommands(hostHCAs map[string][]string) map[string]string {
 	hostCommands := make(map[string]string)
+	// 检查测试使用的 HCA 端口
+	ibPort := c.cfg.HCAPort()
 	for hostname, hcas := range hostHCAs {
 		// 构建JSON输出的shell脚本
 		var jsonBuilder strings.Builder
@@ -98,11 +120,14 @@ func (c *checker) buildHostCommands(hostHCAs map[string][]string) map[string]str
 				jsonBuilder.WriteString(`,`)
 			}
 			jsonBuilder.WriteString(fmt.Sprintf(`{\"name\":\"%s\",`, hca))
-			jsonBuilder.WriteString(fmt.Sprintf(`\"phys_state\":\"$(cat /sys/class/infiniband/%s/ports/1/phys_state 2>/dev/null || echo ERROR)\",`, hca))
-			jsonBuilder.WriteString(fmt.Sprintf(`\"state\":\"$(cat /sys/class/infiniband/%s/ports/1/state 2>/dev/null || echo ERROR)\",`, hca))
-			jsonBuilder.WriteString(fmt.Sprintf(`\"speed\":\"$(cat /sys/class/infiniband/%s/ports/1/rate 2>/dev/null || echo ERROR)\",`, hca))
+			jsonBuilder.WriteString(fmt.Sprintf(`\"phys_state\":\"$(cat /sys/class/infiniband/%s/ports/%d/phys_state 2>/dev/null || echo ERROR)\",`, hca, ibPort))
+			jsonBuilder.WriteString(fmt.Sprintf(`\"state\":\"$(cat /sys/class/infiniband/%s/ports/%d/state 2>/dev/null || echo ERROR)\",`, hca, ibPort))
+			jsonBuilder.WriteString(fmt.Sprintf(`\"speed\":\"$(cat /sys/class/infiniband/%s/ports/%d/rate 2>/dev/null || echo ERROR)\",`, hca, ibPort))
 			jsonBuilder.WriteString(fmt.Sprintf(`\"fw_ver\":\"$(cat /sys/class/infiniband/%s/fw_ver 2>/dev/null || echo ERROR)\",`, hca))
-			jsonBuilder.WriteString(fmt.Sprintf(`\"board_id\":\"$(cat /sys/class/infiniband/%s/board_id 2>/dev/null || echo ERROR)\"`, hca))
+			jsonBuilder.WriteString(fmt.Sprintf(`\"board_id\":\"$(cat /sys/class/infiniband/%s/board_id 2>/dev/null || echo ERROR)\",`, hca))
+			jsonBuilder.WriteString(pcieCommand(hca))
+			jsonBuilder.WriteString(`,`)
+			jsonBuilder.WriteString(roceCommand(hca, ibPort, c.cfg.GidIndex))
 			jsonBuilder.WriteString(`}`)
 		}
 
@@ -122,6 +147,25 @@ type HCAData struct {
 	Speed     string `json:"speed"`
 	FwVer     string `json:"fw_ver"`
 	BoardId   string `json:"board_id"`
+
+	// PCIe 属性，读取失败时为空
+	PCIeSpeed    string `json:"pcie_speed"`
+	PCIeMaxSpeed string `json:"pcie_max_speed"`
+	PCIeWidth    string `json:"pcie_width"`
+	PCIeMaxWidth string `json:"pcie_max_width"`
+	NUMANode     string `json:"numa_node"`
+	DevCtl       string `json:"devctl"`  // PCIe Device Control 寄存器（十六进制）
+	ACSCtl       string `json:"acs_ctl"` // 上游桥的 ACS Control 寄存器（十六进制）
+
+	// RoCE 属性（GID 为 config.GidIndex 指定的条目），读取失败时为空
+	LinkLayer string `json:"link_layer"`
+	GID       string `json:"gid"`
+	GIDType   string `json:"gid_type"`
+	NetDev    string `json:"netdev"`
+	MTU       string `json:"mtu"`
+	QoS       string `json:"qos"`    // base64 编码的 mlnx_qos 输出
+	ECNNP     string `json:"ecn_np"` // 各优先级 roce_np 的 ECN 开关，例如 "1 1 1 1 1 1 1 1"
+	ECNRP     string `json:"ecn_rp"` // 各优先级 roce_rp 的 ECN 开关
 }
 
 // HasError 检查 HCA 的任何字段是否包含 ERROR
@@ -235,6 +279,29 @@ func (c *checker) convertHostDataToResults(hostDataList []*HostPrecheckData) []P
 				BoardId:      hca.BoardId,
 				SerialNumber: hostData.Serial,
 				IsHealthy:    hca.IsHealthy(), // 使用 HCAData 的面向对象方法
+
+				PCIeLink:        hca.PCIeLink(),
+				PCIeMaxLink:     hca.PCIeMaxLink(),
+				NUMANode:        hca.NUMANode,
+				RelaxedOrdering: hca.RelaxedOrdering(),
+				ACS:             hca.ACS(),
+				PCIeIssues:      hca.PCIeIssues(c.cfg.Precheck.PCIe),
+
+				LinkLayer:  strings.TrimSpace(hca.LinkLayer),
+				RoCEIssues: hca.RoCEIssues(c.cfg.GidIndex, c.cfg.Precheck.RoCE),
+			}
+			if hca.IsRoCE() {
+				result.GID = hca.GIDAddress()
+				result.GIDType = strings.TrimSpace(hca.GIDType)
+				result.NetDev = strings.TrimSpace(hca.NetDev)
+				result.MTU = strings.TrimSpace(hca.MTU)
+				result.Trust = hca.Trust()
+				if pfc, ok := hca.PFCPriorities(); ok {
+					result.PFC = FormatPriorities(pfc)
+				}
+				if ecn, ok := hca.ECNPriorities(); ok {
+					result.ECN = FormatPriorities(ecn)
+				}
 			}
 
 			// 如果有错误信息，记录到 Error 字段
@@ -261,7 +328,7 @@ func (c *checker) execAndParseHostCommand(hostname, command string) *HostPrechec
 	c.logger.Debug("Executing precheck command", slog.String("ssh_command", sshWrapper.String()))
 	cmd := exec.Command("bash", "-c", sshWrapper.String())
 
-	output, err := cmd.CombinedOutput()
+	output, err := audit.CombinedOutput(c.ctx, hostname, command, cmd)
 	if err != nil {
 		c.logger.Error("Precheck command execution failed", slog.String("ssh_command", sshWrapper.String()), slog.Any("error", err))
 		result.Error = fmt.Sprintf("SSH execution failed: %v", err)
@@ -317,12 +384,22 @@ type PrecheckSummary struct {
 	AllSpeedsSame  bool             `json:"all_speeds_same"`
 	CheckPassed    bool             `json:"check_passed"`
 	Results        []PrecheckResult `json:"results"`
-	SpeedStats     map[string]int   `json:"speed_stats"`    // 速度统计
-	FwVerStats     map[string]int   `json:"fw_ver_stats"`   // 固件版本统计
-	BoardIdStats   map[string]int   `json:"board_id_stats"` // 板卡ID统计
+	SpeedStats     map[string]int   `json:"speed_stats"`      // 速度统计
+	FwVerStats     map[string]int   `json:"fw_ver_stats"`     // 固件版本统计
+	BoardIdStats   map[string]int   `json:"board_id_stats"`   // 板卡ID统计
+	PCIeIssueCount int              `json:"pcie_issue_count"` // PCIe 与期望不符的 HCA 数量
+	RoCEIssueCount int              `json:"roce_issue_count"` // RoCE 配置与期望不符的 HCA 数量
+
+	Perftest            []PerftestResult `json:"perftest,omitempty"`    // 每台主机的 perftest 工具检查结果
+	PerftestFailedHosts int              `json:"perftest_failed_hosts"` // perftest 工具缺失或版本过旧的主机数量
+
+	HostTuning           []HostTuningResult `json:"host_tuning,omitempty"`   // 开启 host_tuning 检查时每台主机的调优检查结果
+	HostTuningIssueCount int                `json:"host_tuning_issue_count"` // 调优与期望不符的主机数量
+
+	Firmware *FirmwareReport `json:"firmware,omitempty"` // 按板卡型号的固件一致性报告
 }
 
-// ExecPrecheck 执行 precheck 并返回结构化数据（用于 API）
+// ExecPrecheck 执行 precheck 并返回结构化数据（用于 API）；设置了 WithCache 时复用并更新缓存
 func (c *checker) DoCheckForAPI(cfg *config.Config) (*PrecheckSummary, error) {
 	// 复用 Precheck 函数获取结果
 	results := c.DoCheck()
@@ -330,7 +407,53 @@ func (c *checker) DoCheckForAPI(cfg *config.Config) (*PrecheckSummary, error) {
 	if len(results) == 0 {
 		return nil, fmt.Errorf("no HCAs configured in config file")
 	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	summary := Summarize(results)
+	perftest := c.CheckPerftest()
+	summary.AddPerftest(perftest)
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	hostTuning := c.CheckHostTuning()
+	summary.AddHostTuning(hostTuning)
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	c.SaveCache(results, perftest, hostTuning)
+	summary.AddFirmware(c.CheckFirmware(results))
+	return summary, nil
+}
+
+// AddPerftest 加入 perftest 工具的检查结果；有主机无法运行测试时 precheck 不通过
+func (s *PrecheckSummary) AddPerftest(results []PerftestResult) {
+	s.Perftest = results
+	s.PerftestFailedHosts = PerftestFailedHosts(results)
+	s.updateCheckPassed()
+}
 
+// AddFirmware 加入固件一致性报告；有 HCA 的固件与固定版本不同时 precheck 不通过，
+// 与多数版本不同只报告
+func (s *PrecheckSummary) AddFirmware(report *FirmwareReport) {
+	s.Firmware = report
+	s.updateCheckPassed()
+}
+
+// updateCheckPassed 汇总会使 precheck 不通过的检查
+func (s *PrecheckSummary) updateCheckPassed() {
+	s.CheckPassed = s.AllHealthy && s.AllSpeedsSame && s.PerftestFailedHosts == 0 &&
+		(s.Firmware == nil || s.Firmware.PinViolationCount == 0)
+}
+
+// AddHostTuning 加入主机调优的检查结果；调优问题只报告，不影响 precheck 是否通过
+func (s *PrecheckSummary) AddHostTuning(results []HostTuningResult) {
+	s.HostTuning = results
+	s.HostTuningIssueCount = lo.CountBy(results, func(r HostTuningResult) bool { return len(r.Issues) > 0 })
+}
+
+// Summarize 统计 precheck 结果（CLI 推送指标时也使用）
+func Summarize(results []PrecheckResult) *PrecheckSummary {
 	// 统计信息
 	summary := &PrecheckSummary{
 		TotalHCAs:    len(results),
@@ -364,6 +487,16 @@ func (c *checker) DoCheckForAPI(cfg *config.Config) (*PrecheckSummary, error) {
 		if result.BoardId != "" {
 			summary.BoardIdStats[result.BoardId]++
 		}
+
+		// 统计 PCIe 问题
+		if len(result.PCIeIssues) > 0 {
+			summary.PCIeIssueCount++
+		}
+
+		// 统计 RoCE 问题
+		if len(result.RoCEIssues) > 0 {
+			summary.RoCEIssueCount++
+		}
 	}
 
 	// 检查是否所有HCA都健康
@@ -373,9 +506,9 @@ func (c *checker) DoCheckForAPI(cfg *config.Config) (*PrecheckSummary, error) {
 	summary.AllSpeedsSame = (len(summary.SpeedStats) <= 1)
 
 	// 总体检查结果
-	summary.CheckPassed = summary.AllHealthy && summary.AllSpeedsSame
+	summary.updateCheckPassed()
 
-	return summary, nil
+	return summary
 }
 
 // DisplayPrecheckResultsV2 使用新的展示层DTO（清晰分离着色和展示逻辑）
@@ -401,6 +534,10 @@ func (c *checker) Display(results []PrecheckResult) {
 		"Speed",
 		"FW Version",
 		"Board ID",
+		"PCIe Link",
+		"NUMA",
+		"RoCE",
+		"Switch Port",
 		"Status",
 	})
 
@@ -425,10 +562,14 @@ func (c *checker) Display(results []PrecheckResult) {
 			item.HCA,
 			item.PhysState,
 			item.State,
-			item.Speed.ApplyColor(),   // 应用颜色
-			item.FwVer.ApplyColor(),   // 应用颜色
-			item.BoardId.ApplyColor(), // 应用颜色
-			item.Status.ApplyColor(),  // 应用颜色
+			item.Speed.ApplyColor(),    // 应用颜色
+			item.FwVer.ApplyColor(),    // 应用颜色
+			item.BoardId.ApplyColor(),  // 应用颜色
+			item.PCIeLink.ApplyColor(), // 应用颜色
+			item.NUMANode,
+			item.RoCE.ApplyColor(), // 应用颜色
+			item.SwitchPort,
+			item.Status.ApplyColor(), // 应用颜色
 		})
 	}
 
@@ -441,4 +582,27 @@ func (c *checker) Display(results []PrecheckResult) {
 		ColorRed, displayData.UnhealthyCount, ColorReset,
 		ColorYellow, displayData.ErrorCount, ColorReset,
 		displayData.TotalCount)
+
+	if hosts := cachedHosts(results); len(hosts) > 0 {
+		sort.Strings(hosts)
+		fmt.Printf("♻️  Reused cached results of %d HCAs on %d hosts: %s\n",
+			lo.CountBy(results, func(r PrecheckResult) bool { return r.Cached }), len(hosts), strings.Join(hosts, ", "))
+	}
+
+	// 7. 显示与期望不符的详情
+	displayIssues("PCIe", displayData.PCIeIssueCount, displayData.Items, func(item PrecheckDisplayItem) []string { return item.PCIeIssues })
+	displayIssues("RoCE", displayData.RoCEIssueCount, displayData.Items, func(item PrecheckDisplayItem) []string { return item.RoCEIssues })
+}
+
+// displayIssues 按主机和 HCA 列出某一类检查与期望不符的项
+func displayIssues(kind string, count int, items []PrecheckDisplayItem, issuesOf func(PrecheckDisplayItem) []string) {
+	if count == 0 {
+		return
+	}
+	fmt.Printf("\n%s%s issues on %d HCAs:%s\n", ColorRed, kind, count, ColorReset)
+	for _, item := range items {
+		for _, issue := range issuesOf(item) {
+			fmt.Printf("  %s %s: %s\n", item.Hostname, item.HCA, issue)
+		}
+	}
 }
@@ -0,0 +1,164 @@
+package precheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"xnetperf/config"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/samber/lo"
+)
+
+// ExcludedFileName 是被排除的 HCA 在报告目录中的文件名
+const ExcludedFileName = "excluded_hcas.json"
+
+// ExcludedHCA 是 precheck 发现不健康、按 precheck.unhealthy_hca: exclude 从测试计划中排除的 HCA
+type ExcludedHCA struct {
+	Hostname string `json:"hostname"`
+	HCA      string `json:"hca"`
+	Reason   string `json:"reason"` // 检查错误，或不健康时的物理/逻辑状态
+}
+
+// UnhealthyHCAs 返回检查结果中不健康的 HCA；主机级别的错误（例如 SSH 失败）
+// 展开为该主机配置的所有 HCA
+func (c *checker) UnhealthyHCAs(results []PrecheckResult) []ExcludedHCA {
+	hostHCAs := c.cfg.HostHCAs()
+	var unhealthy []ExcludedHCA
+	for _, r := range results {
+		if r.Error == "" && r.IsHealthy {
+			continue
+		}
+		reason := r.Error
+		if reason == "" {
+			reason = fmt.Sprintf("%s / %s", r.PhysState, r.State)
+		}
+		if r.HCA != "" {
+			unhealthy = append(unhealthy, ExcludedHCA{Hostname: r.Hostname, HCA: r.HCA, Reason: reason})
+			continue
+		}
+		for _, hca := range hostHCAs[r.Hostname] {
+			unhealthy = append(unhealthy, ExcludedHCA{Hostname: r.Hostname, HCA: hca, Reason: reason})
+		}
+	}
+
+	unhealthy = lo.UniqBy(unhealthy, func(e ExcludedHCA) string { return e.Hostname + "/" + e.HCA })
+	sort.Slice(unhealthy, func(i, j int) bool {
+		if unhealthy[i].Hostname != unhealthy[j].Hostname {
+			return unhealthy[i].Hostname < unhealthy[j].Hostname
+		}
+		return unhealthy[i].HCA < unhealthy[j].HCA
+	})
+	return unhealthy
+}
+
+// UnhealthyHCADecision 是按 precheck.unhealthy_hca 处理不健康 HCA 的结果
+type UnhealthyHCADecision struct {
+	Policy    string        `json:"policy"`              // 生效的处理方式：abort、warn 或 exclude
+	Unhealthy []ExcludedHCA `json:"unhealthy,omitempty"` // 所有不健康的 HCA
+	Excluded  []ExcludedHCA `json:"excluded,omitempty"`  // 从测试计划中排除的 HCA，只有 exclude 时非空
+}
+
+// Message 描述测试按什么计划继续，没有不健康的 HCA 时为空
+func (d *UnhealthyHCADecision) Message() string {
+	switch {
+	case d == nil || len(d.Unhealthy) == 0:
+		return ""
+	case len(d.Excluded) > 0:
+		return fmt.Sprintf("Excluding %d unhealthy HCAs from the test plan", len(d.Excluded))
+	default:
+		return fmt.Sprintf("Precheck found %d unhealthy HCAs, running the full test plan", len(d.Unhealthy))
+	}
+}
+
+// ApplyUnhealthyHCAPolicy 按 precheck.unhealthy_hca（未配置时为 def）处理不健康的 HCA：
+// abort 返回错误；warn 继续完整的测试计划；exclude 在结果中返回需要从测试计划中排除的 HCA，
+// 排除后服务端或客户端没有剩余健康的 HCA 时返回错误。结果由调用方展示或记录
+func (c *checker) ApplyUnhealthyHCAPolicy(results []PrecheckResult, def string) (*UnhealthyHCADecision, error) {
+	decision := &UnhealthyHCADecision{Policy: c.cfg.Precheck.UnhealthyHCAPolicy(def), Unhealthy: c.UnhealthyHCAs(results)}
+	unhealthy := decision.Unhealthy
+	if len(unhealthy) == 0 {
+		return decision, nil
+	}
+
+	switch decision.Policy {
+	case config.UnhealthyHCAAbort:
+		return decision, fmt.Errorf("precheck found %d unhealthy HCAs", len(unhealthy))
+	case config.UnhealthyHCAExclude:
+		excluded := ExcludedHostHCAs(unhealthy)
+		isHealthy := func(hosts, hcas []string) bool {
+			return lo.SomeBy(hosts, func(host string) bool {
+				return lo.SomeBy(hcas, func(hca string) bool { return !lo.Contains(excluded[host], hca) })
+			})
+		}
+		if !isHealthy(c.cfg.Server.Hostname, c.cfg.Server.Hca) || !isHealthy(c.cfg.Client.Hostname, c.cfg.Client.Hca) {
+			return decision, fmt.Errorf("precheck found %d unhealthy HCAs and no healthy server or client HCA is left", len(unhealthy))
+		}
+		decision.Excluded = unhealthy
+	}
+	return decision, nil
+}
+
+// DisplayUnhealthyHCADecision 打印不健康 HCA 的处理结果和被排除的 HCA
+func DisplayUnhealthyHCADecision(decision *UnhealthyHCADecision) {
+	if message := decision.Message(); message != "" {
+		fmt.Printf("%s⚠️  %s%s\n", ColorYellow, message, ColorReset)
+	}
+	if decision != nil {
+		DisplayExcluded(decision.Excluded)
+	}
+}
+
+// ExcludedHostHCAs 把被排除的 HCA 转换为 主机 -> HCA 列表
+func ExcludedHostHCAs(excluded []ExcludedHCA) map[string][]string {
+	hostHCAs := make(map[string][]string)
+	for _, e := range excluded {
+		hostHCAs[e.Hostname] = append(hostHCAs[e.Hostname], e.HCA)
+	}
+	return hostHCAs
+}
+
+// SaveExcluded 把被排除的 HCA 写入报告目录
+func SaveExcluded(reportsDir string, excluded []ExcludedHCA) error {
+	data, err := json.MarshalIndent(excluded, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(reportsDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(reportsDir, ExcludedFileName), data, 0644)
+}
+
+// LoadExcluded 读取报告目录中被排除的 HCA；没有排除 HCA 的运行返回满足 os.IsNotExist 的错误
+func LoadExcluded(reportsDir string) ([]ExcludedHCA, error) {
+	data, err := os.ReadFile(filepath.Join(reportsDir, ExcludedFileName))
+	if err != nil {
+		return nil, err
+	}
+	var excluded []ExcludedHCA
+	if err := json.Unmarshal(data, &excluded); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ExcludedFileName, err)
+	}
+	return excluded, nil
+}
+
+// DisplayExcluded 列出从测试计划中排除的 HCA 及原因
+func DisplayExcluded(excluded []ExcludedHCA) {
+	if len(excluded) == 0 {
+		return
+	}
+
+	fmt.Println("\n=== Excluded HCAs ===")
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleRounded)
+	t.AppendHeader(table.Row{"Hostname", "HCA", "Status", "Reason"})
+	for _, e := range excluded {
+		t.AppendRow(table.Row{e.Hostname, e.HCA, ColorYellow + "EXCLUDED" + ColorReset, e.Reason})
+	}
+	t.Render()
+}
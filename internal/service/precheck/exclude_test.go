@@ -0,0 +1,105 @@
+package precheck_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"xnetperf/config"
+	"xnetperf/internal/service/precheck"
+)
+
+func excludeConfig(policy string) *config.Config {
+	return &config.Config{
+		Server:   config.ServerConfig{Hostname: []string{"node-01"}, Hca: []string{"mlx5_0", "mlx5_1"}},
+		Client:   config.ClientConfig{Hostname: []string{"node-02", "node-03"}, Hca: []string{"mlx5_0", "mlx5_1"}},
+		Precheck: config.Precheck{UnhealthyHCA: policy},
+	}
+}
+
+func excludeResults() []precheck.PrecheckResult {
+	return []precheck.PrecheckResult{
+		{Hostname: "node-01", HCA: "mlx5_0", PhysState: "LinkUp", State: "ACTIVE", IsHealthy: true},
+		{Hostname: "node-01", HCA: "mlx5_1", PhysState: "Disabled", State: "DOWN"},
+		{Hostname: "node-02", HCA: "mlx5_0", PhysState: "LinkUp", State: "ACTIVE", IsHealthy: true},
+		{Hostname: "node-02", HCA: "mlx5_1", PhysState: "LinkUp", State: "ACTIVE", IsHealthy: true},
+		{Hostname: "node-03", Error: "SSH execution failed: exit status 255"},
+	}
+}
+
+func TestUnhealthyHCAs(t *testing.T) {
+	want := []precheck.ExcludedHCA{
+		{Hostname: "node-01", HCA: "mlx5_1", Reason: "Disabled / DOWN"},
+		{Hostname: "node-03", HCA: "mlx5_0", Reason: "SSH execution failed: exit status 255"},
+		{Hostname: "node-03", HCA: "mlx5_1", Reason: "SSH execution failed: exit status 255"},
+	}
+	got := precheck.New(excludeConfig("")).UnhealthyHCAs(excludeResults())
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnhealthyHCAs() = %+v, want %+v", got, want)
+	}
+
+	hostHCAs := precheck.ExcludedHostHCAs(got)
+	if !reflect.DeepEqual(hostHCAs, map[string][]string{"node-01": {"mlx5_1"}, "node-03": {"mlx5_0", "mlx5_1"}}) {
+		t.Errorf("ExcludedHostHCAs() = %v", hostHCAs)
+	}
+}
+
+func TestApplyUnhealthyHCAPolicy(t *testing.T) {
+	allServersDown := excludeResults()
+	allServersDown[0].IsHealthy = false
+
+	tests := []struct {
+		name         string
+		policy       string
+		def          string
+		results      []precheck.PrecheckResult
+		wantPolicy   string
+		wantExcluded int
+		wantMessage  bool
+		wantErr      bool
+	}{
+		{name: "default abort", def: config.UnhealthyHCAAbort, results: excludeResults(), wantPolicy: config.UnhealthyHCAAbort, wantMessage: true, wantErr: true},
+		{name: "default warn", def: config.UnhealthyHCAWarn, results: excludeResults(), wantPolicy: config.UnhealthyHCAWarn, wantMessage: true},
+		{name: "abort overrides default", policy: config.UnhealthyHCAAbort, def: config.UnhealthyHCAWarn, results: excludeResults(), wantPolicy: config.UnhealthyHCAAbort, wantMessage: true, wantErr: true},
+		{name: "exclude", policy: config.UnhealthyHCAExclude, def: config.UnhealthyHCAAbort, results: excludeResults(), wantPolicy: config.UnhealthyHCAExclude, wantExcluded: 3, wantMessage: true},
+		{name: "no healthy server left", policy: config.UnhealthyHCAExclude, def: config.UnhealthyHCAAbort, results: allServersDown, wantPolicy: config.UnhealthyHCAExclude, wantMessage: true, wantErr: true},
+		{name: "all healthy", policy: config.UnhealthyHCAAbort, def: config.UnhealthyHCAAbort, results: excludeResults()[2:4], wantPolicy: config.UnhealthyHCAAbort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := precheck.New(excludeConfig(tt.policy)).ApplyUnhealthyHCAPolicy(tt.results, tt.def)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyUnhealthyHCAPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if decision.Policy != tt.wantPolicy {
+				t.Errorf("ApplyUnhealthyHCAPolicy() policy = %q, want %q", decision.Policy, tt.wantPolicy)
+			}
+			if len(decision.Excluded) != tt.wantExcluded {
+				t.Errorf("ApplyUnhealthyHCAPolicy() excluded %d HCAs, want %d", len(decision.Excluded), tt.wantExcluded)
+			}
+			if (decision.Message() != "") != tt.wantMessage {
+				t.Errorf("ApplyUnhealthyHCAPolicy() message = %q, want message %v", decision.Message(), tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestSaveLoadExcluded(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := precheck.LoadExcluded(dir); !os.IsNotExist(err) {
+		t.Errorf("LoadExcluded() without file error = %v, want not exist", err)
+	}
+
+	excluded := []precheck.ExcludedHCA{{Hostname: "node-01", HCA: "mlx5_1", Reason: "Disabled / DOWN"}}
+	if err := precheck.SaveExcluded(dir, excluded); err != nil {
+		t.Fatalf("SaveExcluded() error = %v", err)
+	}
+	loaded, err := precheck.LoadExcluded(dir)
+	if err != nil {
+		t.Fatalf("LoadExcluded() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, excluded) {
+		t.Errorf("LoadExcluded() = %+v, want %+v", loaded, excluded)
+	}
+}
@@ -0,0 +1,239 @@
+package precheck
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/samber/lo"
+)
+
+// HCA 固件与期望版本的比较结果
+const (
+	FirmwareOK           = "ok"
+	FirmwareOutlier      = "outlier"       // 与同型号多数 HCA 的版本不同
+	FirmwarePinViolation = "pin_violation" // 与 precheck.firmware.pinned 固定的版本不同
+)
+
+// FirmwareReport 是按板卡型号（board_id）分组的固件一致性报告
+type FirmwareReport struct {
+	Boards            []BoardFirmware `json:"boards"`
+	HCAs              []FirmwareHCA   `json:"hcas"`
+	OutlierCount      int             `json:"outlier_count"`       // 与多数版本不同的 HCA 数量
+	PinViolationCount int             `json:"pin_violation_count"` // 与固定版本不同的 HCA 数量
+}
+
+// BoardFirmware 是一种板卡型号的固件版本分布
+type BoardFirmware struct {
+	BoardID  string         `json:"board_id"`
+	HCAs     int            `json:"hcas"`
+	Expected string         `json:"expected"` // 固定的版本，未固定时为多数 HCA 的版本
+	Pinned   bool           `json:"pinned"`
+	Versions map[string]int `json:"versions"` // 固件版本 -> HCA 数量
+}
+
+// FirmwareHCA 是一个 HCA 的固件与期望版本的比较
+type FirmwareHCA struct {
+	Hostname     string `json:"hostname"`
+	HCA          string `json:"hca"`
+	SerialNumber string `json:"serial_number"`
+	BoardID      string `json:"board_id"`
+	FwVer        string `json:"fw_ver"`
+	Expected     string `json:"expected"`
+	Status       string `json:"status"` // FirmwareOK、FirmwareOutlier 或 FirmwarePinViolation
+}
+
+// FirmwareConsistency 按 board_id 分组 HCA，找出每种型号的期望版本（pinned 中固定的版本，
+// 否则为多数 HCA 的版本）并标记版本不同的 HCA。读不到 board_id 或 fw_ver 的 HCA 不参与统计。
+func FirmwareConsistency(results []PrecheckResult, pinned map[string]string) *FirmwareReport {
+	valid := lo.Filter(results, func(r PrecheckResult, _ int) bool {
+		return r.HCA != "" && firmwareValue(r.BoardId) != "" && firmwareValue(r.FwVer) != ""
+	})
+	byBoard := lo.GroupBy(valid, func(r PrecheckResult) string { return firmwareValue(r.BoardId) })
+
+	report := &FirmwareReport{}
+	for _, boardID := range lo.Keys(byBoard) {
+		board := BoardFirmware{BoardID: boardID, HCAs: len(byBoard[boardID]), Versions: make(map[string]int)}
+		for _, r := range byBoard[boardID] {
+			board.Versions[firmwareValue(r.FwVer)]++
+		}
+		board.Expected, board.Pinned = pinned[boardID], pinned[boardID] != ""
+		if !board.Pinned {
+			board.Expected = majorityVersion(board.Versions)
+		}
+		report.Boards = append(report.Boards, board)
+
+		for _, r := range byBoard[boardID] {
+			hca := FirmwareHCA{
+				Hostname:     r.Hostname,
+				HCA:          r.HCA,
+				SerialNumber: r.SerialNumber,
+				BoardID:      boardID,
+				FwVer:        firmwareValue(r.FwVer),
+				Expected:     board.Expected,
+				Status:       FirmwareOK,
+			}
+			switch {
+			case hca.FwVer == hca.Expected:
+			case board.Pinned:
+				hca.Status = FirmwarePinViolation
+				report.PinViolationCount++
+			default:
+				hca.Status = FirmwareOutlier
+				report.OutlierCount++
+			}
+			report.HCAs = append(report.HCAs, hca)
+		}
+	}
+
+	sort.Slice(report.Boards, func(i, j int) bool { return report.Boards[i].BoardID < report.Boards[j].BoardID })
+	sort.Slice(report.HCAs, func(i, j int) bool {
+		a, b := report.HCAs[i], report.HCAs[j]
+		if a.BoardID != b.BoardID {
+			return a.BoardID < b.BoardID
+		}
+		if a.Hostname != b.Hostname {
+			return a.Hostname < b.Hostname
+		}
+		return a.HCA < b.HCA
+	})
+	return report
+}
+
+// CheckFirmware 按 precheck.firmware.pinned 生成检查结果的固件一致性报告
+func (c *checker) CheckFirmware(results []PrecheckResult) *FirmwareReport {
+	return FirmwareConsistency(results, c.cfg.Precheck.Firmware.Pinned)
+}
+
+// firmwareValue 去掉 sysfs 值的空白，读取失败的 ERROR 视为空
+func firmwareValue(value string) string {
+	value = strings.TrimSpace(value)
+	return lo.Ternary(value == "ERROR", "", value)
+}
+
+// majorityVersion 返回 HCA 数量最多的版本，数量相同时取较新的版本
+func majorityVersion(versions map[string]int) string {
+	majority := ""
+	for version, count := range versions {
+		if majority == "" || count > versions[majority] ||
+			(count == versions[majority] && compareVersions(version, majority) > 0) {
+			majority = version
+		}
+	}
+	return majority
+}
+
+// compareVersions 按数字逐段比较 "28.39.1002" 格式的版本号
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// Deviations 返回版本与期望不同的 HCA
+func (r *FirmwareReport) Deviations() []FirmwareHCA {
+	return lo.Filter(r.HCAs, func(h FirmwareHCA, _ int) bool { return h.Status != FirmwareOK })
+}
+
+// DisplayFirmware 展示每种板卡型号的固件版本分布及版本不同的 HCA
+func DisplayFirmware(report *FirmwareReport) {
+	if report == nil || len(report.Boards) == 0 {
+		return
+	}
+
+	fmt.Println("\n=== Firmware Consistency ===")
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleRounded)
+	t.AppendHeader(table.Row{"Board ID", "HCAs", "Expected FW", "Source", "Versions", "Deviations"})
+	for _, board := range report.Boards {
+		deviations := board.HCAs - board.Versions[board.Expected]
+		t.AppendRow(table.Row{
+			board.BoardID,
+			board.HCAs,
+			board.Expected,
+			lo.Ternary(board.Pinned, "pinned", "majority"),
+			formatVersions(board.Versions),
+			lo.Ternary(deviations == 0, ColorGreen+"0"+ColorReset, fmt.Sprintf("%s%d%s", ColorRed, deviations, ColorReset)),
+		})
+	}
+	t.Render()
+
+	deviations := report.Deviations()
+	if len(deviations) == 0 {
+		return
+	}
+	fmt.Printf("\n%sFirmware deviations on %d HCAs (%d pinned, %d outliers):%s\n",
+		ColorRed, len(deviations), report.PinViolationCount, report.OutlierCount, ColorReset)
+	for _, h := range deviations {
+		source := lo.Ternary(h.Status == FirmwarePinViolation, "pinned", "majority")
+		fmt.Printf("  %s %s (SN %s): %s runs %s, expected %s (%s)\n", h.Hostname, h.HCA, h.SerialNumber, h.BoardID, h.FwVer, h.Expected, source)
+	}
+}
+
+// formatVersions 按 HCA 数量从多到少格式化版本分布，例如 "28.39.1002 x14, 28.36.1010 x2"
+func formatVersions(versions map[string]int) string {
+	names := lo.Keys(versions)
+	sort.Slice(names, func(i, j int) bool {
+		if versions[names[i]] != versions[names[j]] {
+			return versions[names[i]] > versions[names[j]]
+		}
+		return compareVersions(names[i], names[j]) > 0
+	})
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s x%d", name, versions[name])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ExportFirmware 把固件一致性报告写入 path，.json 写入整个报告，.csv 每个 HCA 一行
+func ExportFirmware(report *FirmwareReport, path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, data, 0644)
+	case ".csv":
+		return exportFirmwareCSV(report, path)
+	}
+	return fmt.Errorf("unsupported firmware export file '%s', must end in .json or .csv", path)
+}
+
+func exportFirmwareCSV(report *FirmwareReport, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file '%s': %w", path, err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"board_id", "hostname", "hca", "serial_number", "fw_ver", "expected", "status"})
+	for _, h := range report.HCAs {
+		w.Write([]string{h.BoardID, h.Hostname, h.HCA, h.SerialNumber, h.FwVer, h.Expected, h.Status})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,156 @@
+package precheck_test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"xnetperf/internal/service/precheck"
+)
+
+func firmwareResults() []precheck.PrecheckResult {
+	return []precheck.PrecheckResult{
+		{Hostname: "node-01", HCA: "mlx5_0", SerialNumber: "SN01", BoardId: "MT_0000000838", FwVer: "28.39.1002"},
+		{Hostname: "node-01", HCA: "mlx5_1", SerialNumber: "SN01", BoardId: "MT_0000000838", FwVer: "28.39.1002"},
+		{Hostname: "node-02", HCA: "mlx5_0", SerialNumber: "SN02", BoardId: "MT_0000000838", FwVer: "28.36.1010"},
+		{Hostname: "node-02", HCA: "mlx5_1", SerialNumber: "SN02", BoardId: "MT_0000000970", FwVer: "22.39.1002"},
+		{Hostname: "node-03", HCA: "mlx5_0", SerialNumber: "SN03", BoardId: "MT_0000000970", FwVer: "22.41.1000"},
+		{Hostname: "node-03", HCA: "mlx5_1", BoardId: "ERROR", FwVer: "ERROR"},
+		{Hostname: "node-04", Error: "SSH execution failed: exit status 255"},
+	}
+}
+
+func TestFirmwareConsistency(t *testing.T) {
+	tests := []struct {
+		name          string
+		pinned        map[string]string
+		wantExpected  map[string]string // board -> expected
+		wantStatus    map[string]string // host/hca -> status
+		wantOutliers  int
+		wantViolators int
+	}{
+		{
+			name: "majority",
+			// a tie between two versions picks the newer one
+			wantExpected: map[string]string{"MT_0000000838": "28.39.1002", "MT_0000000970": "22.41.1000"},
+			wantStatus: map[string]string{
+				"node-01/mlx5_0": precheck.FirmwareOK,
+				"node-01/mlx5_1": precheck.FirmwareOK,
+				"node-02/mlx5_0": precheck.FirmwareOutlier,
+				"node-02/mlx5_1": precheck.FirmwareOutlier,
+				"node-03/mlx5_0": precheck.FirmwareOK,
+			},
+			wantOutliers: 2,
+		},
+		{
+			name:         "pinned",
+			pinned:       map[string]string{"MT_0000000838": "28.36.1010"},
+			wantExpected: map[string]string{"MT_0000000838": "28.36.1010", "MT_0000000970": "22.41.1000"},
+			wantStatus: map[string]string{
+				"node-01/mlx5_0": precheck.FirmwarePinViolation,
+				"node-01/mlx5_1": precheck.FirmwarePinViolation,
+				"node-02/mlx5_0": precheck.FirmwareOK,
+				"node-02/mlx5_1": precheck.FirmwareOutlier,
+				"node-03/mlx5_0": precheck.FirmwareOK,
+			},
+			wantOutliers:  1,
+			wantViolators: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := precheck.FirmwareConsistency(firmwareResults(), tt.pinned)
+
+			expected := make(map[string]string)
+			for _, board := range report.Boards {
+				expected[board.BoardID] = board.Expected
+				if board.Pinned != (tt.pinned[board.BoardID] != "") {
+					t.Errorf("board %s pinned = %v", board.BoardID, board.Pinned)
+				}
+			}
+			if !reflect.DeepEqual(expected, tt.wantExpected) {
+				t.Errorf("expected versions = %v, want %v", expected, tt.wantExpected)
+			}
+
+			status := make(map[string]string)
+			for _, hca := range report.HCAs {
+				status[hca.Hostname+"/"+hca.HCA] = hca.Status
+			}
+			if !reflect.DeepEqual(status, tt.wantStatus) {
+				t.Errorf("statuses = %v, want %v", status, tt.wantStatus)
+			}
+			if report.OutlierCount != tt.wantOutliers || report.PinViolationCount != tt.wantViolators {
+				t.Errorf("outliers/violations = %d/%d, want %d/%d", report.OutlierCount, report.PinViolationCount, tt.wantOutliers, tt.wantViolators)
+			}
+			if got := len(report.Deviations()); got != tt.wantOutliers+tt.wantViolators {
+				t.Errorf("Deviations() = %d HCAs, want %d", got, tt.wantOutliers+tt.wantViolators)
+			}
+		})
+	}
+}
+
+func TestFirmwarePinFailsPrecheck(t *testing.T) {
+	summary := &precheck.PrecheckSummary{AllHealthy: true, AllSpeedsSame: true, CheckPassed: true}
+	summary.AddFirmware(precheck.FirmwareConsistency(firmwareResults(), nil))
+	if !summary.CheckPassed {
+		t.Error("majority outliers should not fail the precheck")
+	}
+	summary.AddFirmware(precheck.FirmwareConsistency(firmwareResults(), map[string]string{"MT_0000000970": "22.41.1000"}))
+	if summary.CheckPassed {
+		t.Error("pinned firmware violations should fail the precheck")
+	}
+}
+
+func TestExportFirmware(t *testing.T) {
+	report := precheck.FirmwareConsistency(firmwareResults(), nil)
+	dir := t.TempDir()
+
+	jsonPath := filepath.Join(dir, "firmware.json")
+	if err := precheck.ExportFirmware(report, jsonPath); err != nil {
+		t.Fatalf("ExportFirmware(json) error = %v", err)
+	}
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded precheck.FirmwareReport
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("invalid JSON export: %v", err)
+	}
+	if !reflect.DeepEqual(&loaded, report) {
+		t.Errorf("JSON export = %+v, want %+v", loaded, report)
+	}
+
+	csvPath := filepath.Join(dir, "firmware.csv")
+	if err := precheck.ExportFirmware(report, csvPath); err != nil {
+		t.Fatalf("ExportFirmware(csv) error = %v", err)
+	}
+	file, err := os.Open(csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV export: %v", err)
+	}
+	want := [][]string{
+		{"board_id", "hostname", "hca", "serial_number", "fw_ver", "expected", "status"},
+		{"MT_0000000838", "node-01", "mlx5_0", "SN01", "28.39.1002", "28.39.1002", "ok"},
+		{"MT_0000000838", "node-01", "mlx5_1", "SN01", "28.39.1002", "28.39.1002", "ok"},
+		{"MT_0000000838", "node-02", "mlx5_0", "SN02", "28.36.1010", "28.39.1002", "outlier"},
+		{"MT_0000000970", "node-02", "mlx5_1", "SN02", "22.39.1002", "22.41.1000", "outlier"},
+		{"MT_0000000970", "node-03", "mlx5_0", "SN03", "22.41.1000", "22.41.1000", "ok"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("CSV export = %v, want %v", records, want)
+	}
+
+	if err := precheck.ExportFirmware(report, filepath.Join(dir, "firmware.txt")); err == nil {
+		t.Error("ExportFirmware(txt) should fail")
+	}
+}
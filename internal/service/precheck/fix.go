@@ -0,0 +1,274 @@
+package precheck
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"xnetperf/config"
+	"xnetperf/internal/audit"
+	"xnetperf/pkg/tools"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/samber/lo"
+)
+
+// memlockLimitsFile 是 memlock 修复写入的 pam_limits 配置，对之后的 SSH 会话生效，
+// 重启后仍然保留，所以 memlock 修复只有在 precheck.fix.actions 中列出时才执行
+const memlockLimitsFile = "/etc/security/limits.d/99-xnetperf-memlock.conf"
+
+// netDevName 匹配可以直接写入 ip link 命令的网络接口名（Linux 接口名最长 15 个字符）；
+// 其他名称不生成命令，port_up 改为在主机上查找接口
+var netDevName = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,15}$`)
+
+// fixOrder 是同一台主机上修复的执行顺序：先加载模块，再启用端口，最后设置 MTU 和 memlock
+var fixOrder = []string{config.FixModule, config.FixPortUp, config.FixMTU, config.FixMemlock}
+
+// FixAction 是 precheck --fix 对一个失败项的修复
+type FixAction struct {
+	Hostname    string `json:"hostname"`
+	HCA         string `json:"hca,omitempty"`    // 主机级别的修复为空
+	Action      string `json:"action"`           // config.FixPortUp 等
+	Description string `json:"description"`      // 要做的修改，例如 "ip link set dev eth2 mtu 9000"
+	Command     string `json:"command"`          // 在主机上执行的命令
+	Applied     bool   `json:"applied"`          // 命令执行成功
+	Output      string `json:"output,omitempty"` // 命令输出，例如启用的网络接口或加载的模块
+	Error       string `json:"error,omitempty"`
+	Resolved    bool   `json:"resolved"` // 重新检查后失败项已消失
+}
+
+// PlanFixes 根据检查结果列出 precheck.fix 开启的修复，按主机和执行顺序排序
+func (c *checker) PlanFixes(results []PrecheckResult, hostTuning []HostTuningResult) []FixAction {
+	fix := c.cfg.Precheck.Fix
+	var actions []FixAction
+
+	if fix.Enabled(config.FixModule) {
+		modules := lo.Ternary(len(fix.Modules) > 0, fix.Modules, config.DefaultFixModules)
+		// 配置加载时已校验，这里再过滤一次，保证不合法的名称不会进入远程命令
+		modules = lo.Filter(modules, func(m string, _ int) bool { return config.ValidFixModule(m) })
+		hosts := lo.Uniq(lo.FilterMap(results, func(r PrecheckResult, _ int) (string, bool) {
+			return r.Hostname, hcaMissing(r)
+		}))
+		for _, host := range lo.Ternary(len(modules) > 0, hosts, nil) {
+			actions = append(actions, FixAction{
+				Hostname:    host,
+				Action:      config.FixModule,
+				Description: "modprobe the unloaded modules of " + strings.Join(modules, " "),
+				Command:     moduleFixCommand(modules),
+			})
+		}
+	}
+
+	for _, r := range results {
+		if fix.Enabled(config.FixPortUp) && portDown(r) {
+			actions = append(actions, portUpFix(r))
+		}
+		if expected := c.cfg.Precheck.RoCE.MTU; expected > 0 && fix.Enabled(config.FixMTU) && netDevName.MatchString(r.NetDev) && r.MTU != "" && r.MTU != fmt.Sprint(expected) {
+			actions = append(actions, FixAction{
+				Hostname:    r.Hostname,
+				HCA:         r.HCA,
+				Action:      config.FixMTU,
+				Description: fmt.Sprintf("ip link set dev %s mtu %d (was %s)", r.NetDev, expected, r.MTU),
+				Command:     fmt.Sprintf("ip link set dev %s mtu %d", r.NetDev, expected),
+			})
+		}
+	}
+
+	if expected := c.cfg.Precheck.HostTuning.Memlock; fix.Enabled(config.FixMemlock) && expected != "" {
+		for _, r := range hostTuning {
+			if r.Memlock == "" || memlockSatisfies(r.Memlock, expected) {
+				continue
+			}
+			actions = append(actions, FixAction{
+				Hostname:    r.Hostname,
+				Action:      config.FixMemlock,
+				Description: fmt.Sprintf("persistently set memlock to %s in %s (was %s)", expected, memlockLimitsFile, r.Memlock),
+				Command:     fmt.Sprintf(`printf '* soft memlock %[1]s\n* hard memlock %[1]s\n' > %[2]s`, expected, memlockLimitsFile),
+			})
+		}
+	}
+
+	sort.SliceStable(actions, func(i, j int) bool {
+		if actions[i].Hostname != actions[j].Hostname {
+			return actions[i].Hostname < actions[j].Hostname
+		}
+		return lo.IndexOf(fixOrder, actions[i].Action) < lo.IndexOf(fixOrder, actions[j].Action)
+	})
+	return actions
+}
+
+// hcaMissing 判断 HCA 是否不在 /sys/class/infiniband 中，通常是驱动模块没有加载
+func hcaMissing(r PrecheckResult) bool {
+	return r.HCA != "" && r.PhysState == "ERROR"
+}
+
+// portDown 判断 HCA 端口是否物理上没有 LinkUp；LinkUp 但不是 ACTIVE 通常是子网管理器的问题，无法在主机上修复
+func portDown(r PrecheckResult) bool {
+	return r.HCA != "" && !r.IsHealthy && r.PhysState != "" && r.PhysState != "ERROR" && r.PhysState != "LinkUp"
+}
+
+// portUpFix 启用 HCA 对应的网络接口；InfiniBand HCA 没有记录网络接口，在主机上查找
+func portUpFix(r PrecheckResult) FixAction {
+	action := FixAction{Hostname: r.Hostname, HCA: r.HCA, Action: config.FixPortUp}
+	if netDevName.MatchString(r.NetDev) {
+		action.Description = fmt.Sprintf("ip link set dev %s up", r.NetDev)
+		action.Command = action.Description + " && echo " + r.NetDev
+		return action
+	}
+	action.Description = fmt.Sprintf("ip link set dev <netdev of %s> up", r.HCA)
+	action.Command = fmt.Sprintf(`n=$(ls /sys/class/infiniband/%s/device/net 2>/dev/null | head -1); `+
+		`[ -n "$n" ] || { echo "no netdev found for %s"; exit 1; }; ip link set dev "$n" up && echo "$n"`, r.HCA, r.HCA)
+	return action
+}
+
+// moduleFixCommand 加载没有加载的模块并输出加载的模块名
+func moduleFixCommand(modules []string) string {
+	return fmt.Sprintf(`rc=0; for m in %s; do [ -d "/sys/module/$m" ] || { modprobe "$m" && echo "$m" || rc=1; }; done; exit $rc`,
+		strings.Join(modules, " "))
+}
+
+// ApplyFixes 执行修复：不同主机并发执行，同一台主机按顺序执行
+func (c *checker) ApplyFixes(actions []FixAction) []FixAction {
+	byHost := make(map[string][]int)
+	for i, action := range actions {
+		byHost[action.Hostname] = append(byHost[action.Hostname], i)
+	}
+
+	var wg sync.WaitGroup
+	for host, indexes := range byHost {
+		wg.Add(1)
+		go func(host string, indexes []int) {
+			defer wg.Done()
+			for _, i := range indexes {
+				c.applyFix(&actions[i])
+			}
+		}(host, indexes)
+	}
+	wg.Wait()
+	return actions
+}
+
+func (c *checker) applyFix(action *FixAction) {
+	c.logger.Info("Applying precheck fix", slog.String("host", action.Hostname), slog.String("action", action.Action), slog.String("command", action.Command))
+	cmd := tools.BuildSSHCommand(action.Hostname, action.Command, c.cfg.SSH.PrivateKey, c.cfg.SSH.User)
+	output, err := audit.CombinedOutput(c.ctx, action.Hostname, action.Command, cmd)
+	action.Output = strings.Join(strings.Fields(string(output)), " ")
+	if err != nil {
+		c.logger.Error("Precheck fix failed", slog.String("host", action.Hostname), slog.String("action", action.Action), slog.Any("error", err))
+		action.Error = err.Error()
+		return
+	}
+	action.Applied = true
+}
+
+// Recheck 只重新检查修复成功的主机和 HCA 并展示这些结果：module 修复重新检查主机上所有的 HCA，
+// port_up 和 mtu 修复重新检查对应的 HCA，memlock 修复重新检查对应主机的调优设置。
+// 返回合并后的检查结果并标记已解决的修复，其他结果沿用原来的
+func (c *checker) Recheck(actions []FixAction, results []PrecheckResult, hostTuning []HostTuningResult) ([]PrecheckResult, []HostTuningResult) {
+	applied := lo.Filter(actions, func(a FixAction, _ int) bool { return a.Applied })
+	configured := c.cfg.HostHCAs()
+
+	hostHCAs := make(map[string][]string)
+	for _, a := range applied {
+		switch a.Action {
+		case config.FixMemlock:
+		case config.FixModule:
+			hostHCAs[a.Hostname] = lo.Union(hostHCAs[a.Hostname], configured[a.Hostname])
+		default:
+			hostHCAs[a.Hostname] = lo.Union(hostHCAs[a.Hostname], []string{a.HCA})
+		}
+	}
+	if len(hostHCAs) > 0 {
+		rechecked := c.checkHCAs(hostHCAs)
+		c.Display(rechecked)
+		results = append(lo.Reject(results, func(r PrecheckResult, _ int) bool {
+			hcas, ok := hostHCAs[r.Hostname]
+			return ok && (r.HCA == "" || lo.Contains(hcas, r.HCA))
+		}), rechecked...)
+	}
+
+	memlockHosts := lo.Uniq(lo.FilterMap(applied, func(a FixAction, _ int) (string, bool) {
+		return a.Hostname, a.Action == config.FixMemlock
+	}))
+	if len(memlockHosts) > 0 {
+		rechecked := c.checkHostTuningHosts(memlockHosts, configured)
+		hostTuning = lo.Map(hostTuning, func(r HostTuningResult, _ int) HostTuningResult {
+			if updated, ok := rechecked[r.Hostname]; ok {
+				return updated
+			}
+			return r
+		})
+		c.DisplayHostTuning(lo.Map(memlockHosts, func(host string, _ int) HostTuningResult { return rechecked[host] }))
+	}
+
+	for i := range actions {
+		if actions[i].Applied {
+			actions[i].Resolved = c.fixResolved(actions[i], results, hostTuning)
+		}
+	}
+	return results, hostTuning
+}
+
+// fixResolved 判断修复的失败项在重新检查的结果中是否已消失
+func (c *checker) fixResolved(action FixAction, results []PrecheckResult, hostTuning []HostTuningResult) bool {
+	if action.Action == config.FixMemlock {
+		r, ok := lo.Find(hostTuning, func(r HostTuningResult) bool { return r.Hostname == action.Hostname })
+		return ok && r.Memlock != "" && memlockSatisfies(r.Memlock, c.cfg.Precheck.HostTuning.Memlock)
+	}
+	if action.Action == config.FixModule {
+		hcas := lo.Filter(results, func(r PrecheckResult, _ int) bool { return r.Hostname == action.Hostname && r.HCA != "" })
+		return len(hcas) > 0 && !lo.SomeBy(hcas, hcaMissing)
+	}
+
+	r, ok := lo.Find(results, func(r PrecheckResult) bool { return r.Hostname == action.Hostname && r.HCA == action.HCA })
+	if !ok {
+		return false
+	}
+	if action.Action == config.FixPortUp {
+		return r.IsHealthy
+	}
+	return r.MTU == fmt.Sprint(c.cfg.Precheck.RoCE.MTU)
+}
+
+// DisplayFixes 展示修复；dryRun 时只列出将要执行的修改
+func (c *checker) DisplayFixes(actions []FixAction, dryRun bool) {
+	if len(actions) == 0 {
+		fmt.Println("\n✅ No failures that precheck --fix can remediate")
+		return
+	}
+
+	fmt.Println(lo.Ternary(dryRun, "\n=== Precheck Fixes (dry run) ===", "\n=== Precheck Fixes ==="))
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleRounded)
+	t.AppendHeader(table.Row{"Hostname", "HCA", "Action", "Change", "Output", "Status"})
+	for _, action := range actions {
+		t.AppendRow(table.Row{action.Hostname, lo.Ternary(action.HCA == "", "-", action.HCA), action.Action,
+			action.Description, lo.Ternary(action.Output == "", "-", action.Output), fixStatus(action, dryRun)})
+	}
+	t.Render()
+
+	if dryRun {
+		fmt.Printf("%d changes would be made; run precheck --fix without --dry-run to apply them\n", len(actions))
+		return
+	}
+	resolved := lo.CountBy(actions, func(a FixAction) bool { return a.Resolved })
+	failed := lo.CountBy(actions, func(a FixAction) bool { return !a.Applied })
+	fmt.Printf("Applied %d of %d fixes, %d resolved their failure\n", len(actions)-failed, len(actions), resolved)
+}
+
+func fixStatus(action FixAction, dryRun bool) string {
+	switch {
+	case dryRun:
+		return ColorYellow + "[~] PLANNED" + ColorReset
+	case !action.Applied:
+		return ColorRed + "[!] FAILED" + ColorReset
+	case !action.Resolved:
+		return ColorYellow + "[-] STILL FAILING" + ColorReset
+	}
+	return ColorGreen + "[+] FIXED" + ColorReset
+}
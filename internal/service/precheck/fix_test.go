@@ -0,0 +1,114 @@
+package precheck_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"xnetperf/config"
+	"xnetperf/internal/service/precheck"
+)
+
+func TestPlanFixes(t *testing.T) {
+	results := []precheck.PrecheckResult{
+		{Hostname: "node-02", HCA: "mlx5_0", PhysState: "LinkUp", State: "ACTIVE", IsHealthy: true, NetDev: "eth2", MTU: "1500"},
+		{Hostname: "node-02", HCA: "mlx5_1", PhysState: "Disabled", State: "DOWN", NetDev: "eth3", MTU: "9000"},
+		{Hostname: "node-01", HCA: "mlx5_0", PhysState: "Polling", State: "DOWN"},
+		{Hostname: "node-01", HCA: "mlx5_1", PhysState: "LinkUp", State: "INIT"},
+		{Hostname: "node-01", HCA: "mlx5_2", PhysState: "ERROR", State: "ERROR", Error: "Failed to read some HCA attributes for mlx5_2"},
+		{Hostname: "node-03", Error: "SSH execution failed: exit status 255"},
+	}
+	hostTuning := []precheck.HostTuningResult{
+		{Hostname: "node-01", Memlock: "unlimited"},
+		{Hostname: "node-02", Memlock: "64"},
+		{Hostname: "node-03", Error: "SSH execution failed: exit status 255"},
+	}
+
+	tests := []struct {
+		name string
+		fix  config.PrecheckFix
+		want []string // hostname/hca/action
+	}{
+		{
+			name: "all actions",
+			want: []string{
+				"node-01//module",
+				"node-01/mlx5_0/port_up",
+				"node-02/mlx5_1/port_up",
+				"node-02/mlx5_0/mtu",
+			},
+		},
+		{
+			name: "selected actions",
+			fix:  config.PrecheckFix{Actions: []string{config.FixMTU, config.FixMemlock}},
+			want: []string{"node-02/mlx5_0/mtu", "node-02//memlock"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Precheck.RoCE.MTU = 9000
+			cfg.Precheck.HostTuning.Memlock = config.MemlockUnlimited
+			cfg.Precheck.Fix = tt.fix
+
+			var got []string
+			for _, action := range precheck.New(cfg).PlanFixes(results, hostTuning) {
+				got = append(got, action.Hostname+"/"+action.HCA+"/"+action.Action)
+				if action.Command == "" || action.Description == "" {
+					t.Errorf("action %+v has no command or description", action)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PlanFixes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanFixesCommands(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Precheck.RoCE.MTU = 9000
+	cfg.Precheck.HostTuning.Memlock = "131072"
+	cfg.Precheck.Fix.Actions = config.FixActions
+	cfg.Precheck.Fix.Modules = []string{"mlx5_ib"}
+
+	results := []precheck.PrecheckResult{
+		{Hostname: "node-01", HCA: "mlx5_0", PhysState: "Disabled", NetDev: "eth2", MTU: "1500"},
+		{Hostname: "node-01", HCA: "mlx5_1", PhysState: "ERROR"},
+	}
+	hostTuning := []precheck.HostTuningResult{{Hostname: "node-01", Memlock: "64"}}
+
+	want := []string{
+		`rc=0; for m in mlx5_ib; do [ -d "/sys/module/$m" ] || { modprobe "$m" && echo "$m" || rc=1; }; done; exit $rc`,
+		"ip link set dev eth2 up && echo eth2",
+		"ip link set dev eth2 mtu 9000",
+		`printf '* soft memlock 131072\n* hard memlock 131072\n' > /etc/security/limits.d/99-xnetperf-memlock.conf`,
+	}
+	var got []string
+	for _, action := range precheck.New(cfg).PlanFixes(results, hostTuning) {
+		got = append(got, action.Command)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PlanFixes() commands = %q, want %q", got, want)
+	}
+}
+
+func TestPlanFixesRejectsUnsafeNames(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Precheck.RoCE.MTU = 9000
+	cfg.Precheck.Fix.Modules = []string{"mlx5_ib; reboot", "$(reboot)"}
+
+	results := []precheck.PrecheckResult{
+		{Hostname: "node-01", HCA: "mlx5_0", PhysState: "Disabled", NetDev: "eth2;reboot", MTU: "1500"},
+		{Hostname: "node-01", HCA: "mlx5_1", PhysState: "ERROR"},
+	}
+
+	actions := precheck.New(cfg).PlanFixes(results, nil)
+	if len(actions) != 1 || actions[0].Action != config.FixPortUp {
+		t.Fatalf("PlanFixes() = %+v, want only a port_up fix", actions)
+	}
+	if strings.Contains(actions[0].Command, "reboot") {
+		t.Errorf("port_up command uses the unsafe netdev: %q", actions[0].Command)
+	}
+}
@@ -0,0 +1,349 @@
+package precheck
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"xnetperf/config"
+	"xnetperf/internal/audit"
+	"xnetperf/pkg/tools"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/samber/lo"
+)
+
+var cStateRe = regexp.MustCompile(`^C(\d+)`)
+
+// HostTuningResult 是一台主机的调优检查结果，读取失败的项为空且不参与检查
+type HostTuningResult struct {
+	Hostname       string           `json:"hostname"`
+	CPUGovernor    string           `json:"cpu_governor,omitempty"` // 各 CPU 的 cpufreq governor，不一致时用逗号分隔
+	CStates        []string         `json:"cstates,omitempty"`      // cpu0 上启用的 idle 状态，例如 ["POLL", "C1", "C1E"]
+	IOMMU          string           `json:"iommu,omitempty"`        // passthrough、translated 或 off
+	HugePagesTotal int              `json:"hugepages_total"`        // 大页总数
+	HugePagesFree  int              `json:"hugepages_free"`         // 空闲大页数
+	HugePageSizeKB int              `json:"hugepage_size_kb"`       // 大页大小
+	Memlock        string           `json:"memlock,omitempty"`      // ulimit -l：unlimited 或 KB
+	IRQs           []HCAIRQAffinity `json:"irqs,omitempty"`         // 每个 HCA 的中断亲和性
+	Issues         []string         `json:"issues,omitempty"`       // 与期望不符的项，见 config.HostTuningCheck
+	Error          string           `json:"error,omitempty"`
+}
+
+// HCAIRQAffinity 是一个 HCA 的中断亲和性
+type HCAIRQAffinity struct {
+	HCA       string `json:"hca"`
+	LocalCPUs string `json:"local_cpus"` // HCA 所在 NUMA 节点的 CPU，例如 "0-31,64-95"
+	IRQs      int    `json:"irqs"`       // HCA 的 MSI 中断数量
+	OffNode   int    `json:"off_node"`   // 亲和性不在本地 NUMA 节点 CPU 内的中断数量
+}
+
+// DeepestCState 返回启用的最深 C-state，例如 C6 返回 6；未知时 ok 为 false
+func (r *HostTuningResult) DeepestCState() (int, bool) {
+	deepest, ok := 0, false
+	for _, name := range r.CStates {
+		if name == "POLL" {
+			ok = true
+			continue
+		}
+		if m := cStateRe.FindStringSubmatch(name); m != nil {
+			depth, _ := strconv.Atoi(m[1])
+			deepest, ok = max(deepest, depth), true
+		}
+	}
+	return deepest, ok
+}
+
+// CheckIssues 比较主机调优与期望值，结果写入 Issues
+func (r *HostTuningResult) CheckIssues(expected config.HostTuningCheck) {
+	r.Issues = nil
+	if expected.CPUGovernor != "" && r.CPUGovernor != "" && r.CPUGovernor != expected.CPUGovernor {
+		r.Issues = append(r.Issues, fmt.Sprintf("CPU governor %s, expected %s", r.CPUGovernor, expected.CPUGovernor))
+	}
+	if deepest, ok := r.DeepestCState(); ok && expected.MaxCState > 0 && deepest > expected.MaxCState {
+		r.Issues = append(r.Issues, fmt.Sprintf("C-state C%d enabled, expected at most C%d", deepest, expected.MaxCState))
+	}
+	if expected.IOMMU != "" && r.IOMMU != "" && r.IOMMU != expected.IOMMU {
+		r.Issues = append(r.Issues, fmt.Sprintf("IOMMU %s, expected %s", r.IOMMU, expected.IOMMU))
+	}
+	if expected.MinHugePages > 0 && r.HugePagesFree < expected.MinHugePages {
+		r.Issues = append(r.Issues, fmt.Sprintf("%d free hugepages, expected at least %d", r.HugePagesFree, expected.MinHugePages))
+	}
+	if expected.Memlock != "" && r.Memlock != "" && !memlockSatisfies(r.Memlock, expected.Memlock) {
+		r.Issues = append(r.Issues, fmt.Sprintf("memlock limit %s, expected %s", r.Memlock, expected.Memlock))
+	}
+	if expected.IRQAffinity {
+		for _, irq := range r.IRQs {
+			if irq.OffNode > 0 {
+				r.Issues = append(r.Issues, fmt.Sprintf("%d of %d IRQs of %s not pinned to its local CPUs %s", irq.OffNode, irq.IRQs, irq.HCA, irq.LocalCPUs))
+			}
+		}
+	}
+}
+
+// memlockSatisfies 判断 ulimit -l 的值是否满足期望：unlimited 满足任何期望
+func memlockSatisfies(actual, expected string) bool {
+	if actual == config.MemlockUnlimited {
+		return true
+	}
+	if expected == config.MemlockUnlimited {
+		return false
+	}
+	actualKB, err1 := strconv.Atoi(actual)
+	expectedKB, err2 := strconv.Atoi(expected)
+	return err1 != nil || err2 != nil || actualKB >= expectedKB
+}
+
+// CheckHostTuning 在开启 precheck.host_tuning 时读取每台主机的调优设置并与期望比较
+func (c *checker) CheckHostTuning() []HostTuningResult {
+	if !c.cfg.Precheck.HostTuning.Enabled {
+		return nil
+	}
+	hostHCAs := c.cfg.HostHCAs()
+	hosts := lo.Keys(hostHCAs)
+	sort.Strings(hosts)
+
+	cached := func(host string) *HostTuningResult {
+		if reused := c.reused[host]; reused != nil {
+			return reused.HostTuning
+		}
+		return nil
+	}
+	checked := c.checkHostTuningHosts(lo.Reject(hosts, func(host string, _ int) bool { return cached(host) != nil }), hostHCAs)
+
+	results := make([]HostTuningResult, len(hosts))
+	for i, host := range hosts {
+		if r := cached(host); r != nil {
+			results[i] = *r
+			continue
+		}
+		results[i] = checked[host]
+	}
+	return results
+}
+
+// checkHostTuningHosts 并发检查给定主机的调优设置
+func (c *checker) checkHostTuningHosts(hosts []string, hostHCAs map[string][]string) map[string]HostTuningResult {
+	results := make(map[string]HostTuningResult, len(hosts))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, host := range hosts {
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+			result := c.checkHostTuning(host, hostHCAs[host])
+			mu.Lock()
+			results[host] = result
+			mu.Unlock()
+		}(host)
+	}
+	wg.Wait()
+	return results
+}
+
+func (c *checker) checkHostTuning(host string, hcas []string) HostTuningResult {
+	command := hostTuningCommand(hcas)
+	cmd := tools.BuildSSHCommand(host, command, c.cfg.SSH.PrivateKey, c.cfg.SSH.User)
+	output, err := audit.Output(c.ctx, host, command, cmd)
+	if err != nil {
+		c.logger.Error("Host tuning check failed", slog.String("host", host), slog.Any("error", err))
+		return HostTuningResult{Hostname: host, Error: fmt.Sprintf("SSH execution failed: %v", err)}
+	}
+
+	result := ParseHostTuning(host, string(output))
+	result.CheckIssues(c.cfg.Precheck.HostTuning)
+	return result
+}
+
+// hostTuningCommand 每项输出一行 "<key> <values...>"，每个 HCA 输出一行
+// "irq <hca> <local_cpulist> <affinity of each IRQ...>"，读不到 local_cpulist 时为 "-"
+func hostTuningCommand(hcas []string) string {
+	lines := []string{
+		`echo "governor $(cat /sys/devices/system/cpu/cpu*/cpufreq/scaling_governor 2>/dev/null | sort -u | xargs)"`,
+		`echo "cstates $(for s in /sys/devices/system/cpu/cpu0/cpuidle/state*; do [ "$(cat $s/disable 2>/dev/null)" = 0 ] && cat $s/name; done 2>/dev/null | xargs)"`,
+		`echo "iommu_groups $(ls /sys/kernel/iommu_groups 2>/dev/null | wc -l)"`,
+		`echo "iommu_types $(cat /sys/kernel/iommu_groups/*/type 2>/dev/null | sort -u | xargs)"`,
+		`echo "cmdline $(cat /proc/cmdline)"`,
+		`echo "hugepages $(awk '/^HugePages_Total:/{t=$2} /^HugePages_Free:/{f=$2} /^Hugepagesize:/{s=$2} END{print t, f, s}' /proc/meminfo)"`,
+		`echo "memlock $(ulimit -l)"`,
+	}
+	for _, hca := range hcas {
+		device := fmt.Sprintf("/sys/class/infiniband/%s/device", hca)
+		lines = append(lines, fmt.Sprintf(`echo "irq %s $(cat %s/local_cpulist 2>/dev/null || echo -) $(for i in $(ls %s/msi_irqs 2>/dev/null); do cat /proc/irq/$i/smp_affinity_list 2>/dev/null; done | xargs)"`,
+			hca, device, device))
+	}
+	return strings.Join(lines, "; ")
+}
+
+// ParseHostTuning 解析 hostTuningCommand 的输出
+func ParseHostTuning(hostname, output string) HostTuningResult {
+	result := HostTuningResult{Hostname: hostname}
+	var iommuGroups int
+	var iommuTypes, cmdline []string
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		values := fields[1:]
+		switch fields[0] {
+		case "governor":
+			result.CPUGovernor = strings.Join(values, ",")
+		case "cstates":
+			if len(values) > 0 {
+				result.CStates = values
+			}
+		case "iommu_groups":
+			if len(values) == 1 {
+				iommuGroups, _ = strconv.Atoi(values[0])
+			}
+		case "iommu_types":
+			iommuTypes = values
+		case "cmdline":
+			cmdline = values
+		case "hugepages":
+			if len(values) == 3 {
+				result.HugePagesTotal, _ = strconv.Atoi(values[0])
+				result.HugePagesFree, _ = strconv.Atoi(values[1])
+				result.HugePageSizeKB, _ = strconv.Atoi(values[2])
+			}
+		case "memlock":
+			if len(values) == 1 {
+				result.Memlock = values[0]
+			}
+		case "irq":
+			if len(values) >= 2 {
+				result.IRQs = append(result.IRQs, parseIRQAffinity(values[0], values[1], values[2:]))
+			}
+		}
+	}
+	result.IOMMU = iommuMode(iommuGroups, iommuTypes, cmdline)
+	return result
+}
+
+// iommuMode 根据 IOMMU 组的默认域类型判断 IOMMU 模式；旧内核没有 type 文件时看内核参数
+func iommuMode(groups int, types, cmdline []string) string {
+	if groups == 0 {
+		return config.IOMMUOff
+	}
+	if len(types) > 0 {
+		if len(types) == 1 && types[0] == "identity" {
+			return config.IOMMUPassthrough
+		}
+		return config.IOMMUTranslated
+	}
+	if lo.Contains(cmdline, "iommu=pt") {
+		return config.IOMMUPassthrough
+	}
+	return config.IOMMUTranslated
+}
+
+func parseIRQAffinity(hca, localCPUs string, affinities []string) HCAIRQAffinity {
+	irq := HCAIRQAffinity{HCA: hca, LocalCPUs: localCPUs, IRQs: len(affinities)}
+	local, ok := parseCPUList(localCPUs)
+	if !ok {
+		// 没有 local_cpulist 时无法判断中断是否在本地 NUMA 节点
+		irq.LocalCPUs = ""
+		return irq
+	}
+	for _, affinity := range affinities {
+		cpus, ok := parseCPUList(affinity)
+		if !ok {
+			continue
+		}
+		for cpu := range cpus {
+			if !local[cpu] {
+				irq.OffNode++
+				break
+			}
+		}
+	}
+	return irq
+}
+
+// parseCPUList 解析 "0-3,8,10-11" 格式的 CPU 列表
+func parseCPUList(list string) (map[int]bool, bool) {
+	cpus := make(map[int]bool)
+	for _, part := range strings.Split(list, ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, false
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(last); err != nil || end < start {
+				return nil, false
+			}
+		}
+		for cpu := start; cpu <= end; cpu++ {
+			cpus[cpu] = true
+		}
+	}
+	return cpus, true
+}
+
+// DisplayHostTuning 展示每台主机的调优检查结果及与期望不符的项
+func (c *checker) DisplayHostTuning(results []HostTuningResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	fmt.Println("\n=== Host Tuning ===")
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleRounded)
+	t.AppendHeader(table.Row{"Hostname", "Governor", "C-States", "IOMMU", "Hugepages (free/total)", "Memlock", "IRQ Affinity", "Status"})
+
+	withIssues := 0
+	for _, r := range results {
+		if r.Error != "" {
+			t.AppendRow(table.Row{r.Hostname, r.Error, "", "", "", "", "", ColorRed + "[!] ERROR" + ColorReset})
+			continue
+		}
+		status := ColorGreen + "[+] OK" + ColorReset
+		if len(r.Issues) > 0 {
+			withIssues++
+			status = fmt.Sprintf("%s[-] %d ISSUES%s", ColorRed, len(r.Issues), ColorReset)
+		}
+		t.AppendRow(table.Row{
+			r.Hostname,
+			lo.Ternary(r.CPUGovernor == "", "N/A", r.CPUGovernor),
+			lo.Ternary(len(r.CStates) == 0, "N/A", strings.Join(r.CStates, " ")),
+			lo.Ternary(r.IOMMU == "", "N/A", r.IOMMU),
+			fmt.Sprintf("%d/%d", r.HugePagesFree, r.HugePagesTotal),
+			lo.Ternary(r.Memlock == "", "N/A", r.Memlock),
+			irqAffinitySummary(r.IRQs),
+			status,
+		})
+	}
+	t.Render()
+
+	if withIssues == 0 {
+		return
+	}
+	fmt.Printf("\n%sHost tuning issues on %d hosts:%s\n", ColorRed, withIssues, ColorReset)
+	for _, r := range results {
+		for _, issue := range r.Issues {
+			fmt.Printf("  %s: %s\n", r.Hostname, issue)
+		}
+	}
+}
+
+// irqAffinitySummary 概括主机上 HCA 的中断亲和性，例如 "local" 或 "1/2 HCAs off-node"
+func irqAffinitySummary(irqs []HCAIRQAffinity) string {
+	if len(irqs) == 0 {
+		return "N/A"
+	}
+	offNode := lo.CountBy(irqs, func(irq HCAIRQAffinity) bool { return irq.OffNode > 0 })
+	if offNode > 0 {
+		return fmt.Sprintf("%d/%d HCAs off-node", offNode, len(irqs))
+	}
+	return "local"
+}
@@ -0,0 +1,178 @@
+package precheck_test
+
+import (
+	"reflect"
+	"testing"
+
+	"xnetperf/config"
+	"xnetperf/internal/service/precheck"
+)
+
+func TestParseHostTuning(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   precheck.HostTuningResult
+	}{
+		{
+			name: "tuned host",
+			output: "governor performance\n" +
+				"cstates POLL C1\n" +
+				"iommu_groups 120\n" +
+				"iommu_types identity\n" +
+				"cmdline BOOT_IMAGE=/vmlinuz ro intel_iommu=on iommu=pt\n" +
+				"hugepages 1024 1000 2048\n" +
+				"memlock unlimited\n" +
+				"irq mlx5_0 0-31,64-95 1 2 3\n" +
+				"irq mlx5_1 32-63,96-127 40 33-34\n",
+			want: precheck.HostTuningResult{
+				Hostname:       "node-01",
+				CPUGovernor:    "performance",
+				CStates:        []string{"POLL", "C1"},
+				IOMMU:          config.IOMMUPassthrough,
+				HugePagesTotal: 1024,
+				HugePagesFree:  1000,
+				HugePageSizeKB: 2048,
+				Memlock:        "unlimited",
+				IRQs: []precheck.HCAIRQAffinity{
+					{HCA: "mlx5_0", LocalCPUs: "0-31,64-95", IRQs: 3},
+					{HCA: "mlx5_1", LocalCPUs: "32-63,96-127", IRQs: 2},
+				},
+			},
+		},
+		{
+			name: "untuned host",
+			output: "governor powersave schedutil\n" +
+				"cstates POLL C1 C1E C6\n" +
+				"iommu_groups 120\n" +
+				"iommu_types DMA identity\n" +
+				"cmdline BOOT_IMAGE=/vmlinuz ro\n" +
+				"hugepages 0 0 2048\n" +
+				"memlock 64\n" +
+				"irq mlx5_0 0-31 0-127 5\n" +
+				"irq mlx5_1 - 1\n",
+			want: precheck.HostTuningResult{
+				Hostname:       "node-01",
+				CPUGovernor:    "powersave,schedutil",
+				CStates:        []string{"POLL", "C1", "C1E", "C6"},
+				IOMMU:          config.IOMMUTranslated,
+				HugePageSizeKB: 2048,
+				Memlock:        "64",
+				IRQs: []precheck.HCAIRQAffinity{
+					{HCA: "mlx5_0", LocalCPUs: "0-31", IRQs: 2, OffNode: 1},
+					{HCA: "mlx5_1", IRQs: 1},
+				},
+			},
+		},
+		{
+			name:   "IOMMU off and nothing readable",
+			output: "governor \ncstates \niommu_groups 0\niommu_types \ncmdline ro\nhugepages  \nmemlock \n",
+			want:   precheck.HostTuningResult{Hostname: "node-01", IOMMU: config.IOMMUOff},
+		},
+		{
+			name:   "passthrough from cmdline on kernels without group types",
+			output: "iommu_groups 12\niommu_types \ncmdline ro iommu=pt\n",
+			want:   precheck.HostTuningResult{Hostname: "node-01", IOMMU: config.IOMMUPassthrough},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := precheck.ParseHostTuning("node-01", tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseHostTuning() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHostTuningIssues(t *testing.T) {
+	expected := config.HostTuningCheck{
+		Enabled:      true,
+		CPUGovernor:  "performance",
+		MaxCState:    1,
+		IOMMU:        config.IOMMUPassthrough,
+		MinHugePages: 512,
+		Memlock:      "unlimited",
+		IRQAffinity:  true,
+	}
+
+	tests := []struct {
+		name     string
+		result   precheck.HostTuningResult
+		expected config.HostTuningCheck
+		want     []string
+	}{
+		{
+			name: "matches profile",
+			result: precheck.HostTuningResult{
+				CPUGovernor: "performance", CStates: []string{"POLL", "C1", "C1E"}, IOMMU: config.IOMMUPassthrough,
+				HugePagesFree: 512, Memlock: "unlimited",
+				IRQs: []precheck.HCAIRQAffinity{{HCA: "mlx5_0", LocalCPUs: "0-31", IRQs: 8}},
+			},
+			expected: expected,
+		},
+		{
+			name: "every item differs",
+			result: precheck.HostTuningResult{
+				CPUGovernor: "powersave", CStates: []string{"POLL", "C1", "C6"}, IOMMU: config.IOMMUTranslated,
+				HugePagesFree: 10, Memlock: "64",
+				IRQs: []precheck.HCAIRQAffinity{{HCA: "mlx5_0", LocalCPUs: "0-31", IRQs: 8, OffNode: 3}},
+			},
+			expected: expected,
+			want: []string{
+				"CPU governor powersave, expected performance",
+				"C-state C6 enabled, expected at most C1",
+				"IOMMU translated, expected passthrough",
+				"10 free hugepages, expected at least 512",
+				"memlock limit 64, expected unlimited",
+				"3 of 8 IRQs of mlx5_0 not pinned to its local CPUs 0-31",
+			},
+		},
+		{
+			name:     "memlock in KB",
+			result:   precheck.HostTuningResult{Memlock: "65536"},
+			expected: config.HostTuningCheck{Memlock: "131072"},
+			want:     []string{"memlock limit 65536, expected 131072"},
+		},
+		{
+			name:     "unlimited memlock satisfies a size",
+			result:   precheck.HostTuningResult{Memlock: "unlimited"},
+			expected: config.HostTuningCheck{Memlock: "131072"},
+		},
+		{
+			name:     "unreadable values are not checked",
+			result:   precheck.HostTuningResult{},
+			expected: config.HostTuningCheck{CPUGovernor: "performance", MaxCState: 1, IOMMU: config.IOMMUOff, Memlock: "unlimited"},
+		},
+		{
+			name: "empty profile checks nothing",
+			result: precheck.HostTuningResult{
+				CPUGovernor: "powersave", CStates: []string{"C6"}, Memlock: "64",
+				IRQs: []precheck.HCAIRQAffinity{{HCA: "mlx5_0", IRQs: 8, OffNode: 8}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.result.CheckIssues(tt.expected)
+			if !reflect.DeepEqual(tt.result.Issues, tt.want) {
+				t.Errorf("CheckIssues() = %q, want %q", tt.result.Issues, tt.want)
+			}
+		})
+	}
+}
+
+func TestHostTuningIssueCount(t *testing.T) {
+	summary := &precheck.PrecheckSummary{AllHealthy: true, AllSpeedsSame: true, CheckPassed: true}
+	summary.AddHostTuning([]precheck.HostTuningResult{
+		{Hostname: "node-01"},
+		{Hostname: "node-02", Issues: []string{"CPU governor powersave, expected performance"}},
+	})
+	if summary.HostTuningIssueCount != 1 {
+		t.Errorf("HostTuningIssueCount = %d, want 1", summary.HostTuningIssueCount)
+	}
+	if !summary.CheckPassed {
+		t.Error("host tuning issues should not fail the precheck")
+	}
+}
@@ -0,0 +1,141 @@
+package precheck
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"xnetperf/config"
+)
+
+// devCtlRelaxedOrdering is the "Enable Relaxed Ordering" bit of the PCIe Device Control register
+const devCtlRelaxedOrdering = 0x10
+
+// pcieCommand returns the JSON fields of the PCIe attributes of an HCA. The
+// values are empty when they cannot be read, e.g. for virtual devices or when
+// setpci is missing, and are then not checked.
+func pcieCommand(hca string) string {
+	device := fmt.Sprintf("/sys/class/infiniband/%s/device", hca)
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf(`\"pcie_speed\":\"$(cat %s/current_link_speed 2>/dev/null)\",`, device))
+	b.WriteString(fmt.Sprintf(`\"pcie_max_speed\":\"$(cat %s/max_link_speed 2>/dev/null)\",`, device))
+	b.WriteString(fmt.Sprintf(`\"pcie_width\":\"$(cat %s/current_link_width 2>/dev/null)\",`, device))
+	b.WriteString(fmt.Sprintf(`\"pcie_max_width\":\"$(cat %s/max_link_width 2>/dev/null)\",`, device))
+	b.WriteString(fmt.Sprintf(`\"numa_node\":\"$(cat %s/numa_node 2>/dev/null)\",`, device))
+	// Device Control register of the HCA and ACS Control register of its
+	// upstream bridge; the group keeps errors of missing devices off the output
+	b.WriteString(fmt.Sprintf(`\"devctl\":\"$({ setpci -s $(basename $(readlink -f %s)) CAP_EXP+8.w; } 2>/dev/null)\",`, device))
+	b.WriteString(fmt.Sprintf(`\"acs_ctl\":\"$({ setpci -s $(basename $(dirname $(readlink -f %s))) ECAP_ACS+6.w; } 2>/dev/null)\"`, device))
+	return b.String()
+}
+
+// PCIeLink returns the current PCIe link, e.g. "16.0 GT/s x16"
+func (h *HCAData) PCIeLink() string {
+	return formatLink(h.PCIeSpeed, h.PCIeWidth)
+}
+
+// PCIeMaxLink returns the maximum PCIe link of the device
+func (h *HCAData) PCIeMaxLink() string {
+	return formatLink(h.PCIeMaxSpeed, h.PCIeMaxWidth)
+}
+
+func formatLink(speed, width string) string {
+	speed, width = cleanLinkSpeed(speed), strings.TrimSpace(width)
+	switch {
+	case speed == "" && width == "":
+		return ""
+	case width == "":
+		return speed
+	case speed == "":
+		return "x" + width
+	}
+	return speed + " x" + width
+}
+
+// RelaxedOrdering returns whether relaxed ordering is enabled on the HCA:
+// enabled, disabled or empty when unknown
+func (h *HCAData) RelaxedOrdering() string {
+	value, err := strconv.ParseUint(strings.TrimSpace(h.DevCtl), 16, 16)
+	if err != nil {
+		return ""
+	}
+	if value&devCtlRelaxedOrdering != 0 {
+		return config.PCIeEnabled
+	}
+	return config.PCIeDisabled
+}
+
+// ACS returns whether access control services are enabled on the upstream
+// bridge of the HCA: enabled, disabled or empty when unknown
+func (h *HCAData) ACS() string {
+	value, err := strconv.ParseUint(strings.TrimSpace(h.ACSCtl), 16, 16)
+	if err != nil {
+		return ""
+	}
+	if value != 0 {
+		return config.PCIeEnabled
+	}
+	return config.PCIeDisabled
+}
+
+// PCIeIssues compares the PCIe attributes of the HCA with the expected values.
+// Without an expected speed or width the link must run at the maximum of the
+// device. Attributes that could not be read are not checked.
+func (h *HCAData) PCIeIssues(expected config.PCIeCheck) []string {
+	var issues []string
+
+	speed, speedOK := parseLinkSpeed(h.PCIeSpeed)
+	wantSpeed, wantSpeedOK := expected.Speed, expected.Speed > 0
+	if !wantSpeedOK {
+		wantSpeed, wantSpeedOK = parseLinkSpeed(h.PCIeMaxSpeed)
+	}
+	if speedOK && wantSpeedOK && speed < wantSpeed {
+		issues = append(issues, fmt.Sprintf("PCIe link speed %s, expected %s", cleanLinkSpeed(h.PCIeSpeed), formatLinkSpeed(wantSpeed)))
+	}
+
+	width, widthErr := strconv.Atoi(strings.TrimSpace(h.PCIeWidth))
+	wantWidth := expected.Width
+	if wantWidth == 0 {
+		wantWidth, _ = strconv.Atoi(strings.TrimSpace(h.PCIeMaxWidth))
+	}
+	if widthErr == nil && wantWidth > 0 && width < wantWidth {
+		issues = append(issues, fmt.Sprintf("PCIe link width x%d, expected x%d", width, wantWidth))
+	}
+
+	if want, ok := expected.NUMANodes[h.Name]; ok {
+		if node := strings.TrimSpace(h.NUMANode); node != "" && node != strconv.Itoa(want) {
+			issues = append(issues, fmt.Sprintf("NUMA node %s, expected %d", node, want))
+		}
+	}
+
+	if actual := h.RelaxedOrdering(); expected.RelaxedOrdering != "" && actual != "" && actual != expected.RelaxedOrdering {
+		issues = append(issues, fmt.Sprintf("relaxed ordering %s, expected %s", actual, expected.RelaxedOrdering))
+	}
+	if actual := h.ACS(); expected.ACS != "" && actual != "" && actual != expected.ACS {
+		issues = append(issues, fmt.Sprintf("ACS %s on upstream bridge, expected %s", actual, expected.ACS))
+	}
+	return issues
+}
+
+// cleanLinkSpeed removes the " PCIe" suffix newer kernels append, e.g.
+// "16.0 GT/s PCIe" -> "16.0 GT/s"
+func cleanLinkSpeed(speed string) string {
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(speed), "PCIe"))
+}
+
+// parseLinkSpeed parses a link speed such as "16.0 GT/s PCIe" into GT/s
+func parseLinkSpeed(speed string) (float64, bool) {
+	fields := strings.Fields(speed)
+	if len(fields) == 0 {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+func formatLinkSpeed(speed float64) string {
+	return strconv.FormatFloat(speed, 'f', 1, 64) + " GT/s"
+}
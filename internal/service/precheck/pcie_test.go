@@ -0,0 +1,103 @@
+package precheck_test
+
+import (
+	"reflect"
+	"testing"
+
+	"xnetperf/config"
+	"xnetperf/internal/service/precheck"
+)
+
+func TestPCIeIssues(t *testing.T) {
+	healthy := precheck.HCAData{
+		Name:         "mlx5_0",
+		PCIeSpeed:    "32.0 GT/s PCIe",
+		PCIeMaxSpeed: "32.0 GT/s PCIe",
+		PCIeWidth:    "16",
+		PCIeMaxWidth: "16",
+		NUMANode:     "0",
+		DevCtl:       "5937",
+		ACSCtl:       "0000",
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(h *precheck.HCAData)
+		expected config.PCIeCheck
+		want     []string
+	}{
+		{name: "healthy", modify: func(h *precheck.HCAData) {}},
+		{
+			name:   "trained below max",
+			modify: func(h *precheck.HCAData) { h.PCIeSpeed, h.PCIeWidth = "8.0 GT/s PCIe", "8" },
+			want:   []string{"PCIe link speed 8.0 GT/s, expected 32.0 GT/s", "PCIe link width x8, expected x16"},
+		},
+		{
+			name:     "expected values override max",
+			modify:   func(h *precheck.HCAData) { h.PCIeMaxSpeed, h.PCIeMaxWidth = "64.0 GT/s PCIe", "32" },
+			expected: config.PCIeCheck{Speed: 32, Width: 16},
+		},
+		{
+			name:     "settings differ",
+			modify:   func(h *precheck.HCAData) { h.NUMANode, h.DevCtl, h.ACSCtl = "1", "5927", "005d" },
+			expected: config.PCIeCheck{NUMANodes: map[string]int{"mlx5_0": 0}, RelaxedOrdering: config.PCIeEnabled, ACS: config.PCIeDisabled},
+			want:     []string{"NUMA node 1, expected 0", "relaxed ordering disabled, expected enabled", "ACS enabled on upstream bridge, expected disabled"},
+		},
+		{
+			name:     "unreadable attributes are not checked",
+			modify:   func(h *precheck.HCAData) { *h = precheck.HCAData{Name: "mlx5_0"} },
+			expected: config.PCIeCheck{Speed: 32, Width: 16, NUMANodes: map[string]int{"mlx5_0": 0}, RelaxedOrdering: config.PCIeEnabled, ACS: config.PCIeDisabled},
+		},
+		{
+			name:     "numa node of other hca",
+			expected: config.PCIeCheck{NUMANodes: map[string]int{"mlx5_1": 1}},
+			modify:   func(h *precheck.HCAData) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hca := healthy
+			tt.modify(&hca)
+			if got := hca.PCIeIssues(tt.expected); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PCIeIssues() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPCIeAttributes(t *testing.T) {
+	hca := precheck.HCAData{
+		PCIeSpeed:    "16.0 GT/s PCIe",
+		PCIeMaxSpeed: "32.0 GT/s",
+		PCIeWidth:    "8",
+		PCIeMaxWidth: "16",
+		DevCtl:       "5937",
+		ACSCtl:       "005d",
+	}
+	if got := hca.PCIeLink(); got != "16.0 GT/s x8" {
+		t.Errorf("PCIeLink() = %q", got)
+	}
+	if got := hca.PCIeMaxLink(); got != "32.0 GT/s x16" {
+		t.Errorf("PCIeMaxLink() = %q", got)
+	}
+	if got := hca.RelaxedOrdering(); got != config.PCIeEnabled {
+		t.Errorf("RelaxedOrdering() = %q", got)
+	}
+	if got := hca.ACS(); got != config.PCIeEnabled {
+		t.Errorf("ACS() = %q", got)
+	}
+
+	var empty precheck.HCAData
+	if empty.PCIeLink() != "" || empty.RelaxedOrdering() != "" || empty.ACS() != "" {
+		t.Errorf("Unreadable attributes should be empty")
+	}
+
+	summary := precheck.Summarize([]precheck.PrecheckResult{
+		{HCA: "mlx5_0", IsHealthy: true, PCIeIssues: []string{"PCIe link width x8, expected x16"}},
+		{HCA: "mlx5_1", IsHealthy: true},
+	})
+	if summary.PCIeIssueCount != 1 {
+		t.Errorf("PCIeIssueCount = %d, want 1", summary.PCIeIssueCount)
+	}
+}
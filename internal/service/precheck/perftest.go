@@ -0,0 +1,282 @@
+package precheck
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"xnetperf/config"
+	"xnetperf/internal/audit"
+	"xnetperf/internal/script"
+	"xnetperf/pkg/tools"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/samber/lo"
+)
+
+// perftestFlags are the long options of the perftest binaries xnetperf may use
+var perftestFlags = []string{"--out_json", "--out_json_file", "--report_gbits", "--run_infinitely"}
+
+var perftestVersionRe = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// PerftestResult 是一台主机上 perftest 工具的检查结果
+type PerftestResult struct {
+	Hostname string           `json:"hostname"`
+	Binaries []PerftestBinary `json:"binaries,omitempty"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// PerftestBinary 是一个 perftest 工具的检查结果
+type PerftestBinary struct {
+	Name         string   `json:"name"`                    // 例如 ib_write_bw
+	Path         string   `json:"path,omitempty"`          // 为空表示主机上没有该工具
+	Version      string   `json:"version,omitempty"`       // --version 输出的版本号，例如 "6.10"
+	Flags        []string `json:"flags,omitempty"`         // --help 中出现的 perftestFlags
+	MissingFlags []string `json:"missing_flags,omitempty"` // 测试需要但 --help 中没有的参数
+	Installed    bool     `json:"installed,omitempty"`     // 本次 precheck 从 bundle_dir 推送安装
+	InstallError string   `json:"install_error,omitempty"` // 推送安装失败的原因
+}
+
+// OK 判断该工具是否存在且支持测试需要的所有参数
+func (b PerftestBinary) OK() bool {
+	return b.Path != "" && len(b.MissingFlags) == 0
+}
+
+// OK 判断主机上测试需要的所有 perftest 工具是否可用
+func (r PerftestResult) OK() bool {
+	if r.Error != "" {
+		return false
+	}
+	for _, binary := range r.Binaries {
+		if !binary.OK() {
+			return false
+		}
+	}
+	return true
+}
+
+// PerftestFailedHosts 返回 perftest 工具缺失或不支持所需参数的主机数量
+func PerftestFailedHosts(results []PerftestResult) int {
+	return lo.CountBy(results, func(r PerftestResult) bool { return !r.OK() })
+}
+
+// WithTestType 只检查该测试类型运行的 perftest 工具；未设置时检查所有工具
+func (c *checker) WithTestType(testType script.TestType) *checker {
+	c.testType = testType
+	return c
+}
+
+// perftestBinaries 返回需要检查的 perftest 工具
+func (c *checker) perftestBinaries() []string {
+	switch c.testType {
+	case script.TestTypeBandwidth:
+		return []string{script.TestTypeBandwidth.Command()}
+	case script.TestTypeLatency, script.TestTypeConnectivity:
+		// 连通性测试使用 ib_write_lat
+		return []string{script.TestTypeLatency.Command()}
+	}
+	return []string{script.TestTypeBandwidth.Command(), script.TestTypeLatency.Command()}
+}
+
+// requiredPerftestFlags 返回按当前配置运行测试时工具必须支持的参数
+func requiredPerftestFlags(cfg *config.Config, binary string) []string {
+	var flags []string
+	if cfg.Report.Enable {
+		flags = append(flags, "--out_json", "--out_json_file")
+		if binary == script.TestTypeBandwidth.Command() {
+			flags = append(flags, "--report_gbits")
+		}
+	}
+	if cfg.Run.Infinitely {
+		flags = append(flags, "--run_infinitely")
+	}
+	return flags
+}
+
+// CheckPerftest 检查每台主机上的 perftest 工具是否存在、记录版本并检查测试需要的参数。
+// 配置了 precheck.perftest.bundle_dir 时，把缺少的工具推送到主机后重新检查。
+func (c *checker) CheckPerftest() []PerftestResult {
+	hosts := lo.Keys(c.cfg.HostHCAs())
+	sort.Strings(hosts)
+	binaries := c.perftestBinaries()
+
+	results := make([]PerftestResult, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		if cached := c.reused[host]; cached != nil && cached.Perftest != nil && perftestCovers(*cached.Perftest, binaries) {
+			results[i] = *cached.Perftest
+			continue
+		}
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+			results[i] = c.checkPerftestHost(host, binaries)
+		}(i, host)
+	}
+	wg.Wait()
+	return results
+}
+
+// perftestCovers 判断检查结果是否包含所有需要的 perftest 工具
+func perftestCovers(result PerftestResult, binaries []string) bool {
+	return lo.Every(lo.Map(result.Binaries, func(b PerftestBinary, _ int) string { return b.Name }), binaries)
+}
+
+func (c *checker) checkPerftestHost(host string, binaries []string) PerftestResult {
+	result := c.readPerftestHost(host, binaries)
+	if result.Error != "" || c.cfg.Precheck.Perftest.BundleDir == "" {
+		return result
+	}
+
+	installed := make(map[string]string) // 工具 -> 安装错误
+	for _, binary := range result.Binaries {
+		if binary.Path == "" {
+			if err := c.installPerftest(host, binary.Name); err != nil {
+				installed[binary.Name] = err.Error()
+			} else {
+				installed[binary.Name] = ""
+			}
+		}
+	}
+	if len(installed) == 0 {
+		return result
+	}
+
+	// 重新检查，确认推送的工具可以运行
+	result = c.readPerftestHost(host, binaries)
+	for i, binary := range result.Binaries {
+		if errText, ok := installed[binary.Name]; ok {
+			result.Binaries[i].Installed = errText == ""
+			result.Binaries[i].InstallError = errText
+		}
+	}
+	return result
+}
+
+// readPerftestHost 通过 SSH 读取主机上 perftest 工具的路径、版本和支持的参数
+func (c *checker) readPerftestHost(host string, binaries []string) PerftestResult {
+	result := PerftestResult{Hostname: host}
+	command := perftestCommand(binaries)
+	cmd := tools.BuildSSHCommand(host, command, c.cfg.SSH.PrivateKey, c.cfg.SSH.User)
+	output, err := audit.Output(c.ctx, host, command, cmd)
+	if err != nil {
+		c.logger.Error("Perftest check failed", slog.String("host", host), slog.Any("error", err))
+		result.Error = fmt.Sprintf("SSH execution failed: %v", err)
+		return result
+	}
+
+	found := ParsePerftestOutput(string(output))
+	for _, name := range binaries {
+		binary, ok := found[name]
+		if !ok {
+			binary = PerftestBinary{Name: name}
+		}
+		if binary.Path != "" {
+			binary.MissingFlags = lo.Without(requiredPerftestFlags(c.cfg, name), binary.Flags...)
+		}
+		result.Binaries = append(result.Binaries, binary)
+	}
+	return result
+}
+
+// perftestCommand 对每个工具输出一行 "<name>|<path>|<version>|<flags>"，
+// flags 是 --help 中出现的 perftestFlags
+func perftestCommand(binaries []string) string {
+	grep := "grep -o"
+	for _, flag := range perftestFlags {
+		grep += " -e " + flag
+	}
+	return fmt.Sprintf(`for b in %s; do p=$(command -v $b); v=; f=; `+
+		`if [ -n "$p" ]; then v=$($b --version 2>&1 | head -1); f=$($b --help 2>&1 | %s | sort -u | xargs); fi; `+
+		`echo "$b|$p|$v|$f"; done`, strings.Join(binaries, " "), grep)
+}
+
+// ParsePerftestOutput 解析 perftestCommand 的输出，返回工具名到检查结果的映射
+func ParsePerftestOutput(output string) map[string]PerftestBinary {
+	binaries := make(map[string]PerftestBinary)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), "|", 4)
+		if len(fields) != 4 || fields[0] == "" {
+			continue
+		}
+		binary := PerftestBinary{
+			Name: fields[0],
+			Path: strings.TrimSpace(fields[1]),
+		}
+		if strings.Contains(strings.ToLower(fields[2]), "version") {
+			binary.Version = perftestVersionRe.FindString(fields[2])
+		}
+		binary.Flags = strings.Fields(fields[3])
+		binaries[binary.Name] = binary
+	}
+	return binaries
+}
+
+// installPerftest 把 bundle_dir 中的工具推送到主机的 install_dir
+func (c *checker) installPerftest(host, binary string) error {
+	local := filepath.Join(c.cfg.Precheck.Perftest.BundleDir, binary)
+	if _, err := os.Stat(local); err != nil {
+		return fmt.Errorf("no bundled %s: %v", binary, err)
+	}
+	installDir := c.cfg.Precheck.Perftest.InstallDir
+	if installDir == "" {
+		installDir = config.DefaultPerftestInstallDir
+	}
+	remote := path.Join(installDir, binary)
+
+	c.logger.Info("Installing bundled perftest binary", slog.String("host", host), slog.String("binary", local), slog.String("target", remote))
+	cmd := tools.BuildSCPCommand(host, local, remote, c.cfg.SSH.PrivateKey, c.cfg.SSH.User)
+	if output, err := audit.CombinedOutput(c.ctx, host, fmt.Sprintf("scp %s %s", local, remote), cmd); err != nil {
+		return fmt.Errorf("scp to %s failed: %v %s", remote, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// DisplayPerftest 展示每台主机上 perftest 工具的检查结果
+func (c *checker) DisplayPerftest(results []PerftestResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	fmt.Println("\n=== Perftest Binaries ===")
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleRounded)
+	t.AppendHeader(table.Row{"Hostname", "Binary", "Path", "Version", "Status"})
+
+	for _, result := range results {
+		if result.Error != "" {
+			t.AppendRow(table.Row{result.Hostname, "-", result.Error, "-", ColorRed + "[!] ERROR" + ColorReset})
+			continue
+		}
+		for _, binary := range result.Binaries {
+			t.AppendRow(table.Row{result.Hostname, binary.Name, lo.Ternary(binary.Path == "", "-", binary.Path),
+				lo.Ternary(binary.Version == "", "unknown", binary.Version), perftestStatus(binary)})
+		}
+	}
+	t.Render()
+
+	if failed := PerftestFailedHosts(results); failed > 0 {
+		fmt.Printf("%sPerftest check failed on %d of %d hosts; tests cannot run there%s\n", ColorRed, failed, len(results), ColorReset)
+	}
+}
+
+func perftestStatus(binary PerftestBinary) string {
+	switch {
+	case binary.Path == "" && binary.InstallError != "":
+		return ColorRed + "[-] MISSING (install failed: " + binary.InstallError + ")" + ColorReset
+	case binary.Path == "":
+		return ColorRed + "[-] MISSING" + ColorReset
+	case len(binary.MissingFlags) > 0:
+		return ColorRed + "[-] NO " + strings.Join(binary.MissingFlags, " ") + ColorReset
+	case binary.Installed:
+		return ColorGreen + "[+] INSTALLED" + ColorReset
+	}
+	return ColorGreen + "[+] OK" + ColorReset
+}
@@ -0,0 +1,54 @@
+package precheck_test
+
+import (
+	"reflect"
+	"testing"
+
+	"xnetperf/internal/service/precheck"
+)
+
+func TestParsePerftestOutput(t *testing.T) {
+	output := "ib_write_bw|/usr/bin/ib_write_bw|Version: 6.10|--out_json --out_json_file --report_gbits --run_infinitely\n" +
+		"ib_write_lat|/usr/bin/ib_write_lat|ib_write_lat: unrecognized option '--version'|--run_infinitely\n" +
+		"ib_send_bw|||\n" +
+		"bash: warning: setlocale: LC_ALL: cannot change locale\n"
+
+	want := map[string]precheck.PerftestBinary{
+		"ib_write_bw": {
+			Name:    "ib_write_bw",
+			Path:    "/usr/bin/ib_write_bw",
+			Version: "6.10",
+			Flags:   []string{"--out_json", "--out_json_file", "--report_gbits", "--run_infinitely"},
+		},
+		"ib_write_lat": {Name: "ib_write_lat", Path: "/usr/bin/ib_write_lat", Flags: []string{"--run_infinitely"}},
+		"ib_send_bw":   {Name: "ib_send_bw", Flags: []string{}},
+	}
+	if got := precheck.ParsePerftestOutput(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParsePerftestOutput() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPerftestSummary(t *testing.T) {
+	results := []precheck.PerftestResult{
+		{Hostname: "node-01", Binaries: []precheck.PerftestBinary{{Name: "ib_write_bw", Path: "/usr/bin/ib_write_bw"}}},
+		{Hostname: "node-02", Binaries: []precheck.PerftestBinary{{Name: "ib_write_bw"}}},
+		{Hostname: "node-03", Binaries: []precheck.PerftestBinary{{Name: "ib_write_bw", Path: "/usr/bin/ib_write_bw", MissingFlags: []string{"--out_json"}}}},
+		{Hostname: "node-04", Error: "SSH execution failed: exit status 255"},
+	}
+	if got := precheck.PerftestFailedHosts(results); got != 3 {
+		t.Errorf("PerftestFailedHosts() = %d, want 3", got)
+	}
+
+	summary := precheck.Summarize([]precheck.PrecheckResult{{Hostname: "node-01", HCA: "mlx5_0", IsHealthy: true, Speed: "400 Gb/sec"}})
+	if !summary.CheckPassed {
+		t.Fatalf("CheckPassed = false before adding perftest results")
+	}
+	summary.AddPerftest(results[:1])
+	if !summary.CheckPassed || summary.PerftestFailedHosts != 0 {
+		t.Errorf("CheckPassed = %v, PerftestFailedHosts = %d with usable perftest", summary.CheckPassed, summary.PerftestFailedHosts)
+	}
+	summary.AddPerftest(results)
+	if summary.CheckPassed || summary.PerftestFailedHosts != 3 {
+		t.Errorf("CheckPassed = %v, PerftestFailedHosts = %d with missing perftest", summary.CheckPassed, summary.PerftestFailedHosts)
+	}
+}
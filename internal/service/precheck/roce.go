@@ -0,0 +1,177 @@
+package precheck
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"xnetperf/config"
+)
+
+// Values of the link_layer attribute of an HCA port
+const (
+	LinkLayerEthernet   = "Ethernet"
+	LinkLayerInfiniBand = "InfiniBand"
+)
+
+// gidTypeRoCEv2 is the gid_attrs type of RoCE v2 GIDs
+const gidTypeRoCEv2 = "RoCE v2"
+
+// roceCommand returns the JSON fields of the RoCE attributes of an HCA port: the
+// GID at gidIndex, its type and netdev, and the MTU, QoS (mlnx_qos output,
+// base64 encoded to keep it on one JSON line) and ECN settings of that
+// netdev. Values that cannot be read are empty and are then not checked.
+func roceCommand(hca string, ibPort, gidIndex int) string {
+	port := fmt.Sprintf("/sys/class/infiniband/%s/ports/%d", hca, ibPort)
+	ndev := fmt.Sprintf("$(cat %s/gid_attrs/ndevs/%d 2>/dev/null)", port, gidIndex)
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf(`\"link_layer\":\"$(cat %s/link_layer 2>/dev/null)\",`, port))
+	b.WriteString(fmt.Sprintf(`\"gid\":\"$(cat %s/gids/%d 2>/dev/null)\",`, port, gidIndex))
+	b.WriteString(fmt.Sprintf(`\"gid_type\":\"$(cat %s/gid_attrs/types/%d 2>/dev/null)\",`, port, gidIndex))
+	b.WriteString(fmt.Sprintf(`\"netdev\":\"%s\",`, ndev))
+	b.WriteString(fmt.Sprintf(`\"mtu\":\"$(cat /sys/class/net/%s/mtu 2>/dev/null)\",`, ndev))
+	b.WriteString(fmt.Sprintf(`\"qos\":\"$({ mlnx_qos -i %s | base64 -w0; } 2>/dev/null)\",`, ndev))
+	b.WriteString(fmt.Sprintf(`\"ecn_np\":\"$(cat /sys/class/net/%s/ecn/roce_np/enable/[0-7] 2>/dev/null | xargs)\",`, ndev))
+	b.WriteString(fmt.Sprintf(`\"ecn_rp\":\"$(cat /sys/class/net/%s/ecn/roce_rp/enable/[0-7] 2>/dev/null | xargs)\"`, ndev))
+	return b.String()
+}
+
+// IsRoCE returns true if the HCA port runs over Ethernet
+func (h *HCAData) IsRoCE() bool {
+	return strings.TrimSpace(h.LinkLayer) == LinkLayerEthernet
+}
+
+// GIDAddress returns the IPv4 address of an IPv4-mapped GID, or empty if the
+// GID is empty or not IPv4-mapped
+func (h *HCAData) GIDAddress() string {
+	ip := net.ParseIP(strings.TrimSpace(h.GID))
+	if ip == nil || ip.To4() == nil || ip.To4().IsUnspecified() {
+		return ""
+	}
+	return ip.To4().String()
+}
+
+// Trust returns the trust mode of the netdev (pcp or dscp) from the mlnx_qos output
+func (h *HCAData) Trust() string {
+	for _, line := range h.qosLines() {
+		if _, value, ok := strings.Cut(line, "trust state:"); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// PFCPriorities returns the priorities PFC is enabled on from the mlnx_qos
+// output; ok is false if the output has no PFC configuration
+func (h *HCAData) PFCPriorities() (priorities []int, ok bool) {
+	inPFC := false
+	for _, line := range h.qosLines() {
+		fields := strings.Fields(line)
+		switch {
+		case strings.HasPrefix(line, "PFC configuration"):
+			inPFC = true
+		case inPFC && len(fields) == 9 && fields[0] == "enabled":
+			for priority, value := range fields[1:] {
+				if value == "1" {
+					priorities = append(priorities, priority)
+				}
+			}
+			return priorities, true
+		}
+	}
+	return nil, false
+}
+
+// ECNPriorities returns the priorities ECN is enabled on for both the
+// notification point and the reaction point; ok is false if the ECN
+// settings could not be read
+func (h *HCAData) ECNPriorities() (priorities []int, ok bool) {
+	np, rp := strings.Fields(h.ECNNP), strings.Fields(h.ECNRP)
+	if len(np) != 8 && len(rp) != 8 {
+		return nil, false
+	}
+	for priority := 0; priority < 8; priority++ {
+		npEnabled := len(np) != 8 || np[priority] == "1"
+		rpEnabled := len(rp) != 8 || rp[priority] == "1"
+		if npEnabled && rpEnabled {
+			priorities = append(priorities, priority)
+		}
+	}
+	return priorities, true
+}
+
+func (h *HCAData) qosLines() []string {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(h.QoS))
+	if err != nil {
+		return nil
+	}
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimSpace(scanner.Text()))
+	}
+	return lines
+}
+
+// RoCEIssues checks the GID at gidIndex and the settings of its netdev
+// against the expected values. HCAs running InfiniBand are not checked.
+func (h *HCAData) RoCEIssues(gidIndex int, expected config.RoCECheck) []string {
+	if !h.IsRoCE() {
+		return nil
+	}
+
+	var issues []string
+	switch gidType := strings.TrimSpace(h.GIDType); {
+	case strings.TrimSpace(h.GID) == "":
+		issues = append(issues, fmt.Sprintf("GID index %d not found", gidIndex))
+	case h.GIDAddress() == "":
+		issues = append(issues, fmt.Sprintf("GID index %d is %s, expected an IPv4-mapped address", gidIndex, strings.TrimSpace(h.GID)))
+	case gidType != "" && gidType != gidTypeRoCEv2:
+		issues = append(issues, fmt.Sprintf("GID index %d is %s, expected %s", gidIndex, gidType, gidTypeRoCEv2))
+	}
+
+	if mtu, err := strconv.Atoi(strings.TrimSpace(h.MTU)); err == nil && expected.MTU > 0 && mtu != expected.MTU {
+		issues = append(issues, fmt.Sprintf("MTU of %s is %d, expected %d", h.NetDev, mtu, expected.MTU))
+	}
+	if trust := h.Trust(); trust != "" && expected.Trust != "" && trust != expected.Trust {
+		issues = append(issues, fmt.Sprintf("trust mode of %s is %s, expected %s", h.NetDev, trust, expected.Trust))
+	}
+	if pfc, ok := h.PFCPriorities(); ok && expected.PFCPriorities != nil && !samePriorities(pfc, expected.PFCPriorities) {
+		issues = append(issues, fmt.Sprintf("PFC of %s enabled on priorities %s, expected %s", h.NetDev, FormatPriorities(pfc), FormatPriorities(expected.PFCPriorities)))
+	}
+	if ecn, ok := h.ECNPriorities(); ok {
+		var missing []int
+		for _, priority := range expected.ECNPriorities {
+			if !slices.Contains(ecn, priority) {
+				missing = append(missing, priority)
+			}
+		}
+		if len(missing) > 0 {
+			issues = append(issues, fmt.Sprintf("ECN of %s disabled on priorities %s", h.NetDev, FormatPriorities(missing)))
+		}
+	}
+	return issues
+}
+
+// FormatPriorities formats priorities as "3,4", or "none"
+func FormatPriorities(priorities []int) string {
+	if len(priorities) == 0 {
+		return "none"
+	}
+	values := make([]string, len(priorities))
+	for i, priority := range priorities {
+		values[i] = strconv.Itoa(priority)
+	}
+	return strings.Join(values, ",")
+}
+
+func samePriorities(a, b []int) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
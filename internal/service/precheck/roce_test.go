@@ -0,0 +1,131 @@
+package precheck_test
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	"xnetperf/config"
+	"xnetperf/internal/service/precheck"
+)
+
+// mlnxQoS is the relevant part of the output of mlnx_qos -i eth2
+const mlnxQoS = `DCBX mode: OS controlled
+Priority trust state: dscp
+Cable len: 7
+PFC configuration:
+	priority    0   1   2   3   4   5   6   7
+	enabled     0   0   0   1   0   0   0   0
+	buffer      0   0   0   1   0   0   0   0
+tc: 0 ratelimit: unlimited, tsa: vendor
+	 priority:  1
+`
+
+func TestRoCEIssues(t *testing.T) {
+	healthy := precheck.HCAData{
+		Name:      "mlx5_0",
+		LinkLayer: "Ethernet",
+		GID:       "0000:0000:0000:0000:0000:ffff:0a00:0001",
+		GIDType:   "RoCE v2",
+		NetDev:    "eth2",
+		MTU:       "4200",
+		QoS:       base64.StdEncoding.EncodeToString([]byte(mlnxQoS)),
+		ECNNP:     "0 0 0 1 0 0 0 0",
+		ECNRP:     "0 0 0 1 0 0 0 0",
+	}
+	expected := config.RoCECheck{MTU: 4200, Trust: config.TrustDSCP, PFCPriorities: []int{3}, ECNPriorities: []int{3}}
+
+	tests := []struct {
+		name     string
+		modify   func(h *precheck.HCAData)
+		expected config.RoCECheck
+		want     []string
+	}{
+		{name: "healthy", modify: func(h *precheck.HCAData) {}, expected: expected},
+		{name: "gid only", modify: func(h *precheck.HCAData) {}},
+		{
+			name:   "infiniband is not checked",
+			modify: func(h *precheck.HCAData) { *h = precheck.HCAData{Name: "mlx5_0", LinkLayer: "InfiniBand"} },
+		},
+		{
+			name:   "gid missing",
+			modify: func(h *precheck.HCAData) { h.GID, h.GIDType = "", "" },
+			want:   []string{"GID index 3 not found"},
+		},
+		{
+			name:   "gid not ipv4-mapped",
+			modify: func(h *precheck.HCAData) { h.GID = "fe80:0000:0000:0000:0a42:a1ff:fe4b:1c3e" },
+			want:   []string{"GID index 3 is fe80:0000:0000:0000:0a42:a1ff:fe4b:1c3e, expected an IPv4-mapped address"},
+		},
+		{
+			name:   "roce v1 gid",
+			modify: func(h *precheck.HCAData) { h.GIDType = "IB/RoCE v1" },
+			want:   []string{"GID index 3 is IB/RoCE v1, expected RoCE v2"},
+		},
+		{
+			name:     "settings differ",
+			modify:   func(h *precheck.HCAData) { h.MTU, h.ECNRP = "1500", "0 0 0 0 0 0 0 0" },
+			expected: config.RoCECheck{MTU: 4200, Trust: config.TrustPCP, PFCPriorities: []int{3, 4}, ECNPriorities: []int{3}},
+			want: []string{
+				"MTU of eth2 is 1500, expected 4200",
+				"trust mode of eth2 is dscp, expected pcp",
+				"PFC of eth2 enabled on priorities 3, expected 3,4",
+				"ECN of eth2 disabled on priorities 3",
+			},
+		},
+		{
+			name:     "pfc expected off",
+			modify:   func(h *precheck.HCAData) {},
+			expected: config.RoCECheck{PFCPriorities: []int{}},
+			want:     []string{"PFC of eth2 enabled on priorities 3, expected none"},
+		},
+		{
+			name:     "unreadable settings are not checked",
+			modify:   func(h *precheck.HCAData) { h.MTU, h.QoS, h.ECNNP, h.ECNRP = "", "", "", "" },
+			expected: expected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hca := healthy
+			tt.modify(&hca)
+			if got := hca.RoCEIssues(3, tt.expected); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RoCEIssues() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoCEAttributes(t *testing.T) {
+	hca := precheck.HCAData{
+		LinkLayer: "Ethernet\n",
+		GID:       "0000:0000:0000:0000:0000:ffff:0a00:0001",
+		QoS:       base64.StdEncoding.EncodeToString([]byte(mlnxQoS)),
+		ECNNP:     "1 1 1 1 1 1 1 1",
+		ECNRP:     "0 0 0 1 1 0 0 0",
+	}
+	if !hca.IsRoCE() {
+		t.Errorf("IsRoCE() = false")
+	}
+	if got := hca.GIDAddress(); got != "10.0.0.1" {
+		t.Errorf("GIDAddress() = %q", got)
+	}
+	if got := hca.Trust(); got != config.TrustDSCP {
+		t.Errorf("Trust() = %q", got)
+	}
+	if got, ok := hca.PFCPriorities(); !ok || !reflect.DeepEqual(got, []int{3}) {
+		t.Errorf("PFCPriorities() = %v, %v", got, ok)
+	}
+	if got, ok := hca.ECNPriorities(); !ok || !reflect.DeepEqual(got, []int{3, 4}) {
+		t.Errorf("ECNPriorities() = %v, %v", got, ok)
+	}
+
+	summary := precheck.Summarize([]precheck.PrecheckResult{
+		{HCA: "mlx5_0", IsHealthy: true, RoCEIssues: []string{"MTU of eth2 is 1500, expected 4200"}},
+		{HCA: "mlx5_1", IsHealthy: true},
+	})
+	if summary.RoCEIssueCount != 1 {
+		t.Errorf("RoCEIssueCount = %d, want 1", summary.RoCEIssueCount)
+	}
+}
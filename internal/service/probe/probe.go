@@ -1,12 +1,15 @@
 package probe
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"strings"
 	"sync"
 	"time"
 	"xnetperf/config"
+	"xnetperf/internal/audit"
+	"xnetperf/internal/events"
 	"xnetperf/pkg/tools"
 )
 
@@ -22,12 +25,51 @@ type ProbeResult struct {
 type Prober struct {
 	cfg    *config.Config
 	logger *slog.Logger
+	events events.Publisher
+	ctx    context.Context
+
+	mu         sync.Mutex
+	lastCounts map[string]int // process count per host at the previous probe
 }
 
 func New(cfg *config.Config) *Prober {
 	return &Prober{
-		cfg:    cfg,
-		logger: slog.Default().With("module", "PROBE"),
+		cfg:        cfg,
+		logger:     slog.Default().With("module", "PROBE"),
+		events:     events.Discard,
+		ctx:        context.Background(),
+		lastCounts: make(map[string]int),
+	}
+}
+
+// WithEvents sets the publisher a process_count event is sent to whenever the
+// number of ib_write_bw processes on a host changes between probes
+func (p *Prober) WithEvents(publisher events.Publisher) *Prober {
+	p.events = publisher
+	return p
+}
+
+// WithContext sets the context that cancels the probes; a cancelled probe
+// returns the context error instead of reporting the hosts as finished
+func (p *Prober) WithContext(ctx context.Context) *Prober {
+	p.ctx = ctx
+	return p
+}
+
+func (p *Prober) publishChanges(results []ProbeResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, result := range results {
+		if last, seen := p.lastCounts[result.Hostname]; seen && last == result.ProcessCount {
+			continue
+		}
+		p.lastCounts[result.Hostname] = result.ProcessCount
+		p.events.Publish(events.Event{
+			Type:    events.TypeProcessCount,
+			Host:    result.Hostname,
+			Message: result.Status,
+			Data:    map[string]any{"process": "ib_write_bw", "count": result.ProcessCount},
+		})
 	}
 }
 
@@ -81,6 +123,10 @@ func (p *Prober) DoProbe() ([]ProbeResult, error) {
 	}
 
 	ret := p.probeAllHosts(allHosts, p.cfg.SSH.PrivateKey, p.cfg.SSH.User)
+	if err := p.ctx.Err(); err != nil {
+		return nil, err
+	}
+	p.publishChanges(ret)
 
 	p.logger.Info("Probe operation completed successfully")
 	return ret, nil
@@ -113,8 +159,9 @@ func (p *Prober) probeHost(hostname, sshKeyPath, user string) ProbeResult {
 	}
 
 	// 使用SSH执行ps命令查找ib_write_bw进程
-	cmd := tools.BuildSSHCommand(hostname, "ps aux | grep ib_write_bw | grep -v grep", sshKeyPath, user)
-	output, err := cmd.CombinedOutput()
+	command := "ps aux | grep ib_write_bw | grep -v grep"
+	cmd := tools.BuildSSHCommand(hostname, command, sshKeyPath, user)
+	output, err := audit.CombinedOutput(p.ctx, hostname, command, cmd)
 
 	if err != nil {
 		// 如果没有找到进程或SSH连接失败
@@ -1,27 +1,61 @@
 package runner
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"sync"
 	"xnetperf/config"
+	"xnetperf/internal/audit"
+	"xnetperf/internal/events"
 	"xnetperf/internal/script"
 	"xnetperf/pkg/tools"
 	"xnetperf/pkg/tools/logger"
 )
 
 type runner struct {
-	cfg    *config.Config
-	logger *slog.Logger
+	cfg        *config.Config
+	logger     *slog.Logger
+	scriptsDir string
+	events     events.Publisher
+	excluded   map[string][]string
+	ctx        context.Context
 }
 
 func New(cfg *config.Config) *runner {
 	return &runner{
 		cfg:    cfg,
 		logger: logger.GetLogger().With("module", "RUN"),
+		events: events.Discard,
+		ctx:    context.Background(),
 	}
 }
 
+// WithScriptsDir sets the directory generated scripts are saved to
+func (r *runner) WithScriptsDir(dir string) *runner {
+	r.scriptsDir = dir
+	return r
+}
+
+// WithEvents sets the publisher the executor reports server start progress to
+func (r *runner) WithEvents(publisher events.Publisher) *runner {
+	r.events = publisher
+	return r
+}
+
+// WithContext sets the context that cancels the run; scripts not started yet
+// are skipped and the SSH commands in flight are killed
+func (r *runner) WithContext(ctx context.Context) *runner {
+	r.ctx = ctx
+	return r
+}
+
+// WithExcludedHCAs drops every flow touching the given host -> HCAs from the test plan
+func (r *runner) WithExcludedHCAs(hostHCAs map[string][]string) *runner {
+	r.excluded = hostHCAs
+	return r
+}
+
 func (r *runner) Run(testType script.TestType) error {
 	r.logger.Info("Starting network test run")
 
@@ -32,10 +66,13 @@ func (r *runner) Run(testType script.TestType) error {
 	}
 
 	if r.cfg.Report.Enable {
-		cleanupRemoteReportFiles(r.cfg)
+		cleanupRemoteReportFiles(r.ctx, r.cfg)
+	}
+	if err := r.ctx.Err(); err != nil {
+		return err
 	}
 
-	err := executor.Execute()
+	err := executor.WithScriptsDir(r.scriptsDir).WithEvents(r.events).WithContext(r.ctx).WithExcludedHCAs(r.excluded).Execute()
 	if err != nil {
 		r.logger.Error("Run step failed: %v. Aborting workflow.", slog.Any("error", err))
 		return fmt.Errorf("Run step failed: %v. Aborting workflow.", err)
@@ -50,6 +87,7 @@ type RunResult struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
 	Error   string `json:"error,omitempty"`
+	RunID   string `json:"run_id,omitempty"` // 运行记录 ID，由调用方填写
 }
 
 func (r *runner) RunAndGetResult(testType script.TestType) (RunResult, error) {
@@ -68,7 +106,7 @@ func (r *runner) RunAndGetResult(testType script.TestType) (RunResult, error) {
 	}, nil
 }
 
-func cleanupRemoteReportFiles(cfg *config.Config) {
+func cleanupRemoteReportFiles(ctx context.Context, cfg *config.Config) {
 	fmt.Println("Cleaning up old report files on remote hosts before starting tests...")
 
 	// 获取所有主机列表
@@ -91,7 +129,7 @@ func cleanupRemoteReportFiles(cfg *config.Config) {
 			rmCmd := fmt.Sprintf("rm -f %s/*%s*.json", cfg.Report.Dir, host)
 			cmd := tools.BuildSSHCommand(host, rmCmd, cfg.SSH.PrivateKey, cfg.SSH.User)
 
-			output, err := cmd.CombinedOutput()
+			output, err := audit.CombinedOutput(ctx, host, rmCmd, cmd)
 			if err != nil {
 				fmt.Printf("   [WARNING] ⚠️  %s: Failed to cleanup old reports: %v\n", host, err)
 				if len(output) > 0 {
@@ -0,0 +1,263 @@
+package sampler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"xnetperf/config"
+	"xnetperf/internal/audit"
+	"xnetperf/pkg/tools"
+	"xnetperf/pkg/tools/logger"
+
+	"github.com/samber/lo"
+)
+
+const (
+	// DefaultInterval is the time between two counter readings
+	DefaultInterval = 5 * time.Second
+	// DefaultHistorySize is the number of samples kept in memory for the HTTP API
+	DefaultHistorySize = 10000
+)
+
+// port_xmit_data/port_rcv_data count 32-bit words, not bytes
+const counterWordBytes = 4
+
+// Sample is the bandwidth of one HCA port over one sampling interval
+type Sample struct {
+	Time     time.Time `json:"time"`
+	Hostname string    `json:"hostname"`
+	HCA      string    `json:"hca"`
+	TxGbps   float64   `json:"tx_gbps"`
+	RxGbps   float64   `json:"rx_gbps"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Reading is a raw counter snapshot of one HCA port
+type Reading struct {
+	Time time.Time
+	Tx   uint64 // port_xmit_data, in 4-byte words
+	Rx   uint64 // port_rcv_data, in 4-byte words
+	Err  string
+}
+
+// Sampler periodically reads per-port traffic counters on every host over SSH
+// and turns consecutive readings into per-HCA Gbps
+type Sampler struct {
+	cfg         *config.Config
+	logger      *slog.Logger
+	hostHCAs    map[string][]string
+	historySize int
+
+	mu      sync.Mutex
+	last    map[string]Reading // host|hca -> previous Reading
+	history []Sample
+}
+
+// New creates a sampler for all hosts and HCAs in the config
+func New(cfg *config.Config) *Sampler {
+	return &Sampler{
+		cfg:         cfg,
+		logger:      logger.GetLogger().With("module", "SAMPLER"),
+		hostHCAs:    cfg.HostHCAs(),
+		historySize: DefaultHistorySize,
+		last:        make(map[string]Reading),
+	}
+}
+
+// Sample reads the counters of all hosts once and returns the rates since the
+// previous call. The first call for an HCA only primes its counters.
+// Cancelling ctx kills the counter reads in flight.
+func (s *Sampler) Sample(ctx context.Context) []Sample {
+	readings := s.readAllHosts(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var samples []Sample
+	for host, hcas := range readings {
+		for hca, cur := range hcas {
+			key := host + "|" + hca
+			if cur.Err != "" {
+				samples = append(samples, Sample{Time: cur.Time, Hostname: host, HCA: hca, Error: cur.Err})
+				delete(s.last, key)
+				continue
+			}
+			prev, ok := s.last[key]
+			s.last[key] = cur
+			if !ok {
+				continue
+			}
+			tx, rx, ok := Rate(prev, cur)
+			if !ok {
+				continue
+			}
+			samples = append(samples, Sample{Time: cur.Time, Hostname: host, HCA: hca, TxGbps: tx, RxGbps: rx})
+		}
+	}
+
+	sort.Slice(samples, func(i, j int) bool {
+		if samples[i].Hostname != samples[j].Hostname {
+			return samples[i].Hostname < samples[j].Hostname
+		}
+		return samples[i].HCA < samples[j].HCA
+	})
+
+	s.history = append(s.history, samples...)
+	if over := len(s.history) - s.historySize; over > 0 {
+		s.history = s.history[over:]
+	}
+	return samples
+}
+
+// Run samples every interval until ctx is cancelled, passing each batch of samples to the sinks
+func (s *Sampler) Run(ctx context.Context, interval time.Duration, sinks ...func([]Sample)) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	s.logger.Info("Starting bandwidth sampling", "hosts", len(s.hostHCAs), "interval", interval)
+
+	// Prime the counters so the first tick already yields rates
+	s.Sample(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Bandwidth sampling stopped")
+			return
+		case <-ticker.C:
+			samples := s.Sample(ctx)
+			for _, sink := range sinks {
+				sink(samples)
+			}
+		}
+	}
+}
+
+// History returns the samples kept in memory that were taken after since.
+// Sample times come from the clock of each host, so the history is not
+// ordered by time and is filtered as a whole.
+func (s *Sampler) History(since time.Time) []Sample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return lo.Filter(s.history, func(sample Sample, _ int) bool {
+		return sample.Time.After(since)
+	})
+}
+
+func (s *Sampler) readAllHosts(ctx context.Context) map[string]map[string]Reading {
+	results := make(map[string]map[string]Reading)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for host, hcas := range s.hostHCAs {
+		wg.Add(1)
+		go func(host string, hcas []string) {
+			defer wg.Done()
+			readings := s.readHost(ctx, host, hcas)
+			mu.Lock()
+			results[host] = readings
+			mu.Unlock()
+		}(host, hcas)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// readHost reads the counters of the HCAs of one host; every HCA gets a
+// reading, with Err set when its counters could not be read
+func (s *Sampler) readHost(ctx context.Context, host string, hcas []string) map[string]Reading {
+	hostError := func(message string) map[string]Reading {
+		readings := make(map[string]Reading)
+		for _, hca := range hcas {
+			readings[hca] = Reading{Time: time.Now(), Err: message}
+		}
+		return readings
+	}
+
+	command := buildCounterCommand(hcas, s.cfg.HCAPort())
+	cmd := tools.BuildSSHCommand(host, command, s.cfg.SSH.PrivateKey, s.cfg.SSH.User)
+	output, err := audit.Output(ctx, host, command, cmd)
+	if err != nil {
+		s.logger.Warn("Failed to read counters", "host", host, "error", err)
+		return hostError(fmt.Sprintf("SSH error: %v", err))
+	}
+
+	readings, err := ParseCounters(string(output))
+	if err != nil {
+		s.logger.Warn("Failed to parse counters", "host", host, "error", err)
+		return hostError(fmt.Sprintf("invalid counter output: %v", err))
+	}
+	for _, hca := range hcas {
+		if _, ok := readings[hca]; !ok {
+			readings[hca] = Reading{Time: time.Now(), Err: "no counters in output"}
+		}
+	}
+	return readings
+}
+
+// buildCounterCommand prints the host time followed by one "<hca> <xmit> <rcv>" line per HCA
+func buildCounterCommand(hcas []string, port int) string {
+	var b strings.Builder
+	b.WriteString("date +%s.%N")
+	for _, hca := range hcas {
+		counters := fmt.Sprintf("/sys/class/infiniband/%s/ports/%d/counters", hca, port)
+		b.WriteString(fmt.Sprintf(`; echo "%s $(cat %s/port_xmit_data 2>/dev/null || echo ERROR) $(cat %s/port_rcv_data 2>/dev/null || echo ERROR)"`,
+			hca, counters, counters))
+	}
+	return b.String()
+}
+
+// ParseCounters parses the output of the remote counter command: the host
+// time followed by one "<hca> <xmit> <rcv>" line per HCA
+func ParseCounters(output string) (map[string]Reading, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) == 0 || lines[0] == "" {
+		return nil, fmt.Errorf("empty counter output")
+	}
+
+	epoch, err := strconv.ParseFloat(strings.TrimSpace(lines[0]), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp %q: %w", lines[0], err)
+	}
+	sec := int64(epoch)
+	ts := time.Unix(sec, int64((epoch-float64(sec))*1e9))
+
+	readings := make(map[string]Reading)
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		r := Reading{Time: ts}
+		tx, txErr := strconv.ParseUint(fields[1], 10, 64)
+		rx, rxErr := strconv.ParseUint(fields[2], 10, 64)
+		if txErr != nil || rxErr != nil {
+			r.Err = "failed to read port counters"
+		}
+		r.Tx, r.Rx = tx, rx
+		readings[fields[0]] = r
+	}
+	return readings, nil
+}
+
+// Rate converts two readings into Gbps; counter resets or wraps yield ok=false
+func Rate(prev, cur Reading) (txGbps, rxGbps float64, ok bool) {
+	seconds := cur.Time.Sub(prev.Time).Seconds()
+	if seconds <= 0 || cur.Tx < prev.Tx || cur.Rx < prev.Rx {
+		return 0, 0, false
+	}
+	toGbps := func(delta uint64) float64 {
+		return float64(delta) * counterWordBytes * 8 / seconds / 1e9
+	}
+	return toGbps(cur.Tx - prev.Tx), toGbps(cur.Rx - prev.Rx), true
+}
@@ -0,0 +1,92 @@
+package sampler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// Display prints one batch of samples as a table with per-host totals
+func Display(samples []Sample) {
+	if len(samples) == 0 {
+		return
+	}
+
+	fmt.Printf("=== Bandwidth Samples (%s) ===\n", samples[0].Time.Format("15:04:05"))
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleRounded)
+	t.AppendHeader(table.Row{"Hostname", "HCA", "TX (Gbps)", "RX (Gbps)"})
+
+	var totalTx, totalRx float64
+	for _, s := range samples {
+		if s.Error != "" {
+			t.AppendRow(table.Row{s.Hostname, s.HCA, s.Error, ""})
+			continue
+		}
+		totalTx += s.TxGbps
+		totalRx += s.RxGbps
+		t.AppendRow(table.Row{s.Hostname, s.HCA, fmt.Sprintf("%.2f", s.TxGbps), fmt.Sprintf("%.2f", s.RxGbps)})
+	}
+	t.AppendFooter(table.Row{"Total", "", fmt.Sprintf("%.2f", totalTx), fmt.Sprintf("%.2f", totalRx)})
+	t.Render()
+	fmt.Println()
+}
+
+// CSVWriter appends samples to a CSV file
+type CSVWriter struct {
+	file   *os.File
+	writer *csv.Writer
+}
+
+// NewCSVWriter opens path for appending, creating it with the CSV header if
+// it is new or empty, so a restarted sampler keeps the earlier samples
+func NewCSVWriter(path string) (*CSVWriter, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CSV file '%s': %w", path, err)
+	}
+	w := &CSVWriter{file: file, writer: csv.NewWriter(file)}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.Size() > 0 {
+		return w, nil
+	}
+	if err := w.writer.Write([]string{"time", "hostname", "hca", "tx_gbps", "rx_gbps", "error"}); err != nil {
+		file.Close()
+		return nil, err
+	}
+	w.writer.Flush()
+	return w, w.writer.Error()
+}
+
+// Write appends a batch of samples and flushes it to disk
+func (w *CSVWriter) Write(samples []Sample) error {
+	for _, s := range samples {
+		record := []string{
+			s.Time.Format(time.RFC3339Nano),
+			s.Hostname,
+			s.HCA,
+			fmt.Sprintf("%.3f", s.TxGbps),
+			fmt.Sprintf("%.3f", s.RxGbps),
+			s.Error,
+		}
+		if err := w.writer.Write(record); err != nil {
+			return err
+		}
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// Close flushes and closes the file
+func (w *CSVWriter) Close() error {
+	w.writer.Flush()
+	return w.file.Close()
+}
@@ -0,0 +1,116 @@
+package sampler_test
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"xnetperf/internal/service/sampler"
+)
+
+func TestParseCounters(t *testing.T) {
+	output := "1700000000.500000000\n" +
+		"mlx5_0 1000 2000\n" +
+		"mlx5_1 ERROR ERROR\n"
+
+	readings, err := sampler.ParseCounters(output)
+	if err != nil {
+		t.Fatalf("ParseCounters failed: %v", err)
+	}
+	if len(readings) != 2 {
+		t.Fatalf("Expected 2 readings, got %d", len(readings))
+	}
+
+	r := readings["mlx5_0"]
+	if r.Tx != 1000 || r.Rx != 2000 || r.Err != "" {
+		t.Errorf("Unexpected reading for mlx5_0: %+v", r)
+	}
+	if want := time.Unix(1700000000, 500000000); !r.Time.Equal(want) {
+		t.Errorf("Expected time %v, got %v", want, r.Time)
+	}
+	if readings["mlx5_1"].Err == "" {
+		t.Error("Expected an error for mlx5_1")
+	}
+
+	if _, err := sampler.ParseCounters(""); err == nil {
+		t.Error("Expected an error for empty output")
+	}
+}
+
+func TestRate(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	tests := []struct {
+		name   string
+		prev   sampler.Reading
+		cur    sampler.Reading
+		wantTx float64
+		wantRx float64
+		wantOK bool
+	}{
+		{
+			// 1.25e9 words * 4 bytes * 8 bits over 1s = 40 Gbps
+			name:   "steady traffic",
+			prev:   sampler.Reading{Time: start, Tx: 0, Rx: 0},
+			cur:    sampler.Reading{Time: start.Add(time.Second), Tx: 1_250_000_000, Rx: 625_000_000},
+			wantTx: 40,
+			wantRx: 20,
+			wantOK: true,
+		},
+		{
+			name:   "counter reset",
+			prev:   sampler.Reading{Time: start, Tx: 1000, Rx: 1000},
+			cur:    sampler.Reading{Time: start.Add(time.Second), Tx: 10, Rx: 2000},
+			wantOK: false,
+		},
+		{
+			name:   "no elapsed time",
+			prev:   sampler.Reading{Time: start, Tx: 0, Rx: 0},
+			cur:    sampler.Reading{Time: start, Tx: 1000, Rx: 1000},
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, rx, ok := sampler.Rate(tt.prev, tt.cur)
+			if ok != tt.wantOK {
+				t.Fatalf("Expected ok=%v, got %v", tt.wantOK, ok)
+			}
+			if math.Abs(tx-tt.wantTx) > 1e-9 || math.Abs(rx-tt.wantRx) > 1e-9 {
+				t.Errorf("Expected %.2f/%.2f Gbps, got %.2f/%.2f", tt.wantTx, tt.wantRx, tx, rx)
+			}
+		})
+	}
+}
+
+func TestCSVWriterAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples.csv")
+	write := func(hca string) {
+		t.Helper()
+		w, err := sampler.NewCSVWriter(path)
+		if err != nil {
+			t.Fatalf("NewCSVWriter failed: %v", err)
+		}
+		if err := w.Write([]sampler.Sample{{Time: time.Unix(1700000000, 0), Hostname: "node1", HCA: hca, TxGbps: 1}}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+	}
+	write("mlx5_0")
+	write("mlx5_1")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "time,") ||
+		!strings.Contains(lines[1], "mlx5_0") || !strings.Contains(lines[2], "mlx5_1") {
+		t.Errorf("Expected one header and both samples, got:\n%s", data)
+	}
+}
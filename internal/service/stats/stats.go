@@ -0,0 +1,166 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"xnetperf/internal/service/analyze"
+	"xnetperf/internal/service/lat"
+)
+
+// DefaultCVThresholdPercent is the coefficient of variation above which a link is flagged unstable
+const DefaultCVThresholdPercent = 5.0
+
+// Series kinds
+const (
+	KindBandwidth = "bandwidth"
+	KindLatency   = "latency"
+)
+
+// Summary holds the descriptive statistics of a series of samples
+type Summary struct {
+	Samples   int     `json:"samples"`
+	Mean      float64 `json:"mean"`
+	StdDev    float64 `json:"stddev"`
+	Min       float64 `json:"min"`
+	Max       float64 `json:"max"`
+	CVPercent float64 `json:"cv_percent"` // coefficient of variation: stddev / mean * 100
+}
+
+// Summarize computes mean, sample standard deviation, min, max and CV of values
+func Summarize(values []float64) Summary {
+	s := Summary{Samples: len(values)}
+	if len(values) == 0 {
+		return s
+	}
+
+	s.Min, s.Max = values[0], values[0]
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+		s.Min = math.Min(s.Min, v)
+		s.Max = math.Max(s.Max, v)
+	}
+	s.Mean = sum / float64(len(values))
+
+	if len(values) > 1 {
+		sq := 0.0
+		for _, v := range values {
+			sq += (v - s.Mean) * (v - s.Mean)
+		}
+		s.StdDev = math.Sqrt(sq / float64(len(values)-1))
+	}
+	if s.Mean != 0 {
+		s.CVPercent = s.StdDev / s.Mean * 100
+	}
+	return s
+}
+
+// Row is the statistics of one device (bandwidth) or HCA pair (latency) across iterations
+type Row struct {
+	Kind     string    `json:"kind"`
+	Role     string    `json:"role,omitempty"` // tx/rx/p2p for bandwidth
+	Hostname string    `json:"hostname"`
+	Device   string    `json:"device"`
+	Target   string    `json:"target,omitempty"` // host:hca for latency
+	Values   []float64 `json:"values"`
+	Summary
+	Unstable bool `json:"unstable"`
+}
+
+// Result is the statistical analysis of repeated runs
+type Result struct {
+	Iterations         int     `json:"iterations"`
+	CVThresholdPercent float64 `json:"cv_threshold_percent"`
+	Rows               []Row   `json:"rows"`
+	UnstableCount      int     `json:"unstable_count"`
+}
+
+// HasUnstable reports whether any link exceeded the variance threshold or missed samples
+func (r *Result) HasUnstable() bool {
+	return r.UnstableCount > 0
+}
+
+// Accumulator collects per-iteration results of repeated runs
+type Accumulator struct {
+	cvThreshold float64
+	iterations  int
+	rows        map[string]*Row
+}
+
+// New creates an accumulator flagging links whose CV exceeds cvThresholdPercent
+func New(cvThresholdPercent float64) *Accumulator {
+	return &Accumulator{
+		cvThreshold: cvThresholdPercent,
+		rows:        make(map[string]*Row),
+	}
+}
+
+// AddBandwidth adds the per-device bandwidth of one iteration
+func (a *Accumulator) AddBandwidth(devices []analyze.DeviceBandwidth) {
+	a.iterations++
+	for _, d := range devices {
+		a.add(Row{Kind: KindBandwidth, Role: d.Role, Hostname: d.Hostname, Device: d.Device}, d.BandwidthGbps)
+	}
+}
+
+// AddLatency adds the per-pair average latency of one iteration
+func (a *Accumulator) AddLatency(pairs []lat.LatencyData) {
+	a.iterations++
+	for _, p := range pairs {
+		a.add(Row{
+			Kind:     KindLatency,
+			Hostname: p.SourceHost,
+			Device:   p.SourceHCA,
+			Target:   fmt.Sprintf("%s:%s", p.TargetHost, p.TargetHCA),
+		}, p.AvgLatencyUs)
+	}
+}
+
+func (a *Accumulator) add(row Row, value float64) {
+	key := row.Kind + "|" + row.Role + "|" + row.Hostname + "|" + row.Device + "|" + row.Target
+	existing, ok := a.rows[key]
+	if !ok {
+		existing = &row
+		a.rows[key] = existing
+	}
+	existing.Values = append(existing.Values, value)
+}
+
+// Result computes the statistics of everything added so far. Links missing from
+// some iterations are flagged unstable as well.
+func (a *Accumulator) Result() *Result {
+	result := &Result{
+		Iterations:         a.iterations,
+		CVThresholdPercent: a.cvThreshold,
+	}
+
+	for _, row := range a.rows {
+		r := *row
+		r.Summary = Summarize(r.Values)
+		r.Unstable = r.CVPercent > a.cvThreshold || r.Samples < a.iterations
+		if r.Unstable {
+			result.UnstableCount++
+		}
+		result.Rows = append(result.Rows, r)
+	}
+
+	sort.Slice(result.Rows, func(i, j int) bool {
+		ri, rj := result.Rows[i], result.Rows[j]
+		if ri.Kind != rj.Kind {
+			return ri.Kind < rj.Kind
+		}
+		if ri.Role != rj.Role {
+			return ri.Role > rj.Role // tx before rx
+		}
+		if ri.Hostname != rj.Hostname {
+			return ri.Hostname < rj.Hostname
+		}
+		if ri.Device != rj.Device {
+			return ri.Device < rj.Device
+		}
+		return ri.Target < rj.Target
+	})
+	return result
+}
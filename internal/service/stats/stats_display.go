@@ -0,0 +1,85 @@
+package stats
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// ANSI color codes
+const (
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorReset = "\033[0m"
+)
+
+// Display prints per-device statistics of repeated runs, highlighting unstable links in red
+func Display(result *Result) {
+	fmt.Printf("=== Statistics over %d iterations (unstable when CV > %.1f%%) ===\n\n",
+		result.Iterations, result.CVThresholdPercent)
+
+	var bandwidth, latency []Row
+	for _, row := range result.Rows {
+		if row.Kind == KindBandwidth {
+			bandwidth = append(bandwidth, row)
+		} else {
+			latency = append(latency, row)
+		}
+	}
+
+	if len(bandwidth) > 0 {
+		fmt.Println("BANDWIDTH (Gbps)")
+		t := newTable()
+		t.AppendHeader(table.Row{"Role", "Hostname", "Device", "Samples", "Mean", "StdDev", "Min", "Max", "CV", "Status"})
+		for _, row := range bandwidth {
+			t.AppendRow(append(table.Row{row.Role, row.Hostname, row.Device}, statsCells(row, result.Iterations)...))
+		}
+		t.Render()
+		fmt.Println()
+	}
+
+	if len(latency) > 0 {
+		fmt.Println("LATENCY (μs)")
+		t := newTable()
+		t.AppendHeader(table.Row{"Source Host", "Source HCA", "Target", "Samples", "Mean", "StdDev", "Min", "Max", "CV", "Status"})
+		for _, row := range latency {
+			t.AppendRow(append(table.Row{row.Hostname, row.Device, row.Target}, statsCells(row, result.Iterations)...))
+		}
+		t.Render()
+		fmt.Println()
+	}
+
+	if result.HasUnstable() {
+		fmt.Printf("%s⚠️  %d unstable link(s) out of %d%s\n", colorRed, result.UnstableCount, len(result.Rows), colorReset)
+	} else {
+		fmt.Printf("%s✅ All %d link(s) stable%s\n", colorGreen, len(result.Rows), colorReset)
+	}
+}
+
+func statsCells(row Row, iterations int) table.Row {
+	status := colorGreen + "STABLE" + colorReset
+	if row.Unstable {
+		status = colorRed + "UNSTABLE" + colorReset
+	}
+	cv := fmt.Sprintf("%.2f%%", row.CVPercent)
+	if row.Unstable {
+		cv = colorRed + cv + colorReset
+	}
+	return table.Row{
+		fmt.Sprintf("%d/%d", row.Samples, iterations),
+		fmt.Sprintf("%.2f", row.Mean),
+		fmt.Sprintf("%.2f", row.StdDev),
+		fmt.Sprintf("%.2f", row.Min),
+		fmt.Sprintf("%.2f", row.Max),
+		cv,
+		status,
+	}
+}
+
+func newTable() table.Writer {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleRounded)
+	return t
+}
@@ -0,0 +1,100 @@
+package stats_test
+
+import (
+	"math"
+	"testing"
+
+	"xnetperf/internal/service/analyze"
+	"xnetperf/internal/service/lat"
+	"xnetperf/internal/service/stats"
+)
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   stats.Summary
+	}{
+		{"empty", nil, stats.Summary{}},
+		{"single", []float64{400}, stats.Summary{Samples: 1, Mean: 400, Min: 400, Max: 400}},
+		{"spread", []float64{2, 4, 4, 4, 5, 5, 7, 9}, stats.Summary{Samples: 8, Mean: 5, StdDev: 2.138, Min: 2, Max: 9, CVPercent: 42.76}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := stats.Summarize(tt.values)
+			if got.Samples != tt.want.Samples || got.Min != tt.want.Min || got.Max != tt.want.Max {
+				t.Errorf("Summarize(%v) = %+v, want %+v", tt.values, got, tt.want)
+			}
+			for _, f := range []struct {
+				name      string
+				got, want float64
+			}{
+				{"mean", got.Mean, tt.want.Mean},
+				{"stddev", got.StdDev, tt.want.StdDev},
+				{"cv", got.CVPercent, tt.want.CVPercent},
+			} {
+				if math.Abs(f.got-f.want) > 0.01 {
+					t.Errorf("%s = %.3f, want %.3f", f.name, f.got, f.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAccumulatorFlagsUnstableLinks(t *testing.T) {
+	acc := stats.New(5)
+	iterations := [][]float64{
+		{400, 400, 390},
+		{398, 300, 392},
+		{402, 380},
+	}
+	for _, bw := range iterations {
+		var devices []analyze.DeviceBandwidth
+		for i, v := range bw {
+			devices = append(devices, analyze.DeviceBandwidth{
+				Hostname: "host1", Device: []string{"mlx5_0", "mlx5_1", "mlx5_2"}[i], Role: analyze.RoleTX, BandwidthGbps: v,
+			})
+		}
+		acc.AddBandwidth(devices)
+	}
+
+	result := acc.Result()
+	if result.Iterations != 3 {
+		t.Fatalf("Expected 3 iterations, got %d", result.Iterations)
+	}
+
+	want := map[string]bool{
+		"mlx5_0": false, // stable
+		"mlx5_1": true,  // 400/300/380: CV well above 5%
+		"mlx5_2": true,  // missing from the last iteration
+	}
+	for _, row := range result.Rows {
+		if row.Unstable != want[row.Device] {
+			t.Errorf("%s: expected unstable=%v, got %v (cv %.2f%%, samples %d)", row.Device, want[row.Device], row.Unstable, row.CVPercent, row.Samples)
+		}
+	}
+	if result.UnstableCount != 2 {
+		t.Errorf("Expected 2 unstable links, got %d", result.UnstableCount)
+	}
+}
+
+func TestAccumulatorLatency(t *testing.T) {
+	acc := stats.New(10)
+	for _, v := range []float64{2.0, 2.1, 1.9} {
+		acc.AddLatency([]lat.LatencyData{
+			{SourceHost: "host1", SourceHCA: "mlx5_0", TargetHost: "host2", TargetHCA: "mlx5_0", AvgLatencyUs: v},
+		})
+	}
+
+	result := acc.Result()
+	if len(result.Rows) != 1 {
+		t.Fatalf("Expected 1 row, got %d", len(result.Rows))
+	}
+	row := result.Rows[0]
+	if row.Kind != stats.KindLatency || row.Target != "host2:mlx5_0" {
+		t.Errorf("Unexpected row %+v", row)
+	}
+	if math.Abs(row.Mean-2.0) > 1e-9 || row.Unstable {
+		t.Errorf("Expected stable mean 2.0, got mean %.3f unstable=%v", row.Mean, row.Unstable)
+	}
+}
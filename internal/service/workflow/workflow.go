@@ -0,0 +1,501 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"xnetperf/config"
+	"xnetperf/internal/events"
+	"xnetperf/internal/script"
+	"xnetperf/internal/service/analyze"
+	"xnetperf/internal/service/collect"
+	"xnetperf/internal/service/connectivity"
+	"xnetperf/internal/service/counters"
+	"xnetperf/internal/service/lat"
+	"xnetperf/internal/service/precheck"
+	"xnetperf/internal/service/probe"
+	runnerservice "xnetperf/internal/service/runner"
+	"xnetperf/internal/store"
+	"xnetperf/pkg/tools/logger"
+
+	"github.com/samber/lo"
+)
+
+// DefaultProbeInterval is the time between two probes while waiting for the tests to finish
+const DefaultProbeInterval = 5 * time.Second
+
+// Step names
+const (
+	StepPrecheck     = "precheck"
+	StepRun          = "run"
+	StepProbe        = "probe"
+	StepCollect      = "collect"
+	StepAnalyze      = "analyze"
+	StepConnectivity = "connectivity"
+)
+
+// Step statuses
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusSkipped   = "skipped"
+)
+
+// Step is the state of one workflow step
+type Step struct {
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	Message    string     `json:"message,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Result is the outcome of a workflow: the status of every step, the precheck
+// summary and the handling of unhealthy HCAs when precheck ran, and the final
+// report of the test type (*analyze.ReportData, *lat.LatencySummary or
+// *connectivity.ConnectivitySummary)
+type Result struct {
+	TestType     string                         `json:"test_type"`
+	RunID        string                         `json:"run_id,omitempty"` // 运行记录 ID，由调用方填写
+	Steps        []*Step                        `json:"steps"`
+	Precheck     *precheck.PrecheckSummary      `json:"precheck,omitempty"`
+	UnhealthyHCA *precheck.UnhealthyHCADecision `json:"unhealthy_hca,omitempty"` // 只在 precheck 发现不健康的 HCA 且按策略继续时设置
+	Report       any                            `json:"report,omitempty"`
+}
+
+// Workflow runs the complete run -> probe -> collect -> analyze workflow of a
+// test type, the server-side counterpart of the execute, lat and check-conn commands
+type Workflow struct {
+	cfg           *config.Config
+	testType      script.TestType
+	logger        *slog.Logger
+	events        events.Publisher
+	progress      func(percent int, step string)
+	runDir        string
+	precheck      bool
+	precheckCache *precheck.Cache // cached precheck results; nil checks every host
+	probeInterval time.Duration
+
+	counterBefore *counters.Snapshot     // port counters read before the tests started
+	counterDeltas []counters.Delta       // port counter increases during the tests
+	excluded      []precheck.ExcludedHCA // unhealthy HCAs excluded from the test plan
+
+	mu     sync.Mutex
+	result *Result
+}
+
+// New creates a workflow for the test type
+func New(cfg *config.Config, testType script.TestType) *Workflow {
+	return &Workflow{
+		cfg:           cfg,
+		testType:      testType,
+		logger:        logger.GetLogger().With("module", "WORKFLOW"),
+		events:        events.Discard,
+		progress:      func(int, string) {},
+		probeInterval: DefaultProbeInterval,
+	}
+}
+
+// WithRunDir sets the per-run directory scripts and reports are written to
+func (w *Workflow) WithRunDir(dir string) *Workflow {
+	w.runDir = dir
+	return w
+}
+
+// WithEvents sets the publisher the workflow and its services report progress to
+func (w *Workflow) WithEvents(publisher events.Publisher) *Workflow {
+	w.events = publisher
+	return w
+}
+
+// WithProgress sets a callback that receives the overall progress (0-100) and current step
+func (w *Workflow) WithProgress(fn func(percent int, step string)) *Workflow {
+	w.progress = fn
+	return w
+}
+
+// WithPrecheck enables precheck gating: the tests only start when all HCAs are
+// healthy and run at the same speed, unless precheck.unhealthy_hca allows
+// unhealthy HCAs to be reported or excluded
+func (w *Workflow) WithPrecheck(enabled bool) *Workflow {
+	w.precheck = enabled
+	return w
+}
+
+// WithPrecheckCache reuses the cached precheck results of recently checked
+// hosts in the precheck step and records the hosts it checks
+func (w *Workflow) WithPrecheckCache(cache *precheck.Cache) *Workflow {
+	w.precheckCache = cache
+	return w
+}
+
+// WithProbeInterval sets the time between two probes while waiting for the tests
+func (w *Workflow) WithProbeInterval(interval time.Duration) *Workflow {
+	w.probeInterval = interval
+	return w
+}
+
+// Validate reports configs the workflow cannot run to completion
+func Validate(cfg *config.Config, testType script.TestType) error {
+	if cfg.Run.Infinitely && testType != script.TestTypeConnectivity {
+		return fmt.Errorf("infinite runs (run.infinitely: true) never finish; use the run endpoint instead")
+	}
+	if testType == script.TestTypeLatency && cfg.StreamType == config.P2P {
+		return fmt.Errorf("latency tests do not support the p2p stream type")
+	}
+	if testType == script.TestTypeConnectivity && !cfg.Report.Enable {
+		return fmt.Errorf("report generation must be enabled for connectivity testing")
+	}
+	return nil
+}
+
+// Result returns a copy of the current workflow state; safe to call while Run is in progress
+func (w *Workflow) Result() *Result {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.result == nil {
+		return nil
+	}
+	result := *w.result
+	result.Steps = make([]*Step, len(w.result.Steps))
+	for i, step := range w.result.Steps {
+		copied := *step
+		result.Steps[i] = &copied
+	}
+	return &result
+}
+
+// Run executes the workflow. Cancelling ctx stops it between steps and kills
+// the remote commands in flight; test processes already started on the hosts
+// keep running.
+// The returned result is never nil and tells which step failed.
+func (w *Workflow) Run(ctx context.Context) (*Result, error) {
+	if err := Validate(w.cfg, w.testType); err != nil {
+		return &Result{TestType: w.testType.String()}, err
+	}
+
+	names := w.stepNames()
+	w.mu.Lock()
+	w.result = &Result{TestType: w.testType.String()}
+	for _, name := range names {
+		w.result.Steps = append(w.result.Steps, &Step{Name: name, Status: StatusPending})
+	}
+	w.mu.Unlock()
+
+	w.logger.Info("Starting workflow", "test_type", w.testType, "steps", names)
+	for i, name := range names {
+		if err := ctx.Err(); err != nil {
+			w.skipRemaining(i, "workflow cancelled")
+			return w.Result(), err
+		}
+
+		w.progress(i*100/len(names), fmt.Sprintf("Step %d/%d: %s", i+1, len(names), name))
+		w.startStep(name)
+		message, err := w.runStep(ctx, name)
+		if errors.Is(err, errSkipRemaining) {
+			w.finishStep(name, message, nil)
+			w.skipRemaining(i+1, message)
+			break
+		}
+		w.finishStep(name, message, err)
+		if err != nil {
+			w.skipRemaining(i+1, fmt.Sprintf("%s step failed", name))
+			return w.Result(), fmt.Errorf("%s step failed: %w", name, err)
+		}
+	}
+
+	w.progress(100, "Workflow completed")
+	w.logger.Info("Workflow completed", "test_type", w.testType)
+	return w.Result(), nil
+}
+
+// errSkipRemaining ends the workflow successfully after the current step
+var errSkipRemaining = errors.New("skip remaining steps")
+
+func (w *Workflow) stepNames() []string {
+	var names []string
+	if w.precheck {
+		names = append(names, StepPrecheck)
+	}
+	if w.testType == script.TestTypeConnectivity {
+		return append(names, StepConnectivity)
+	}
+	return append(names, StepRun, StepProbe, StepCollect, StepAnalyze)
+}
+
+func (w *Workflow) runStep(ctx context.Context, name string) (string, error) {
+	switch name {
+	case StepPrecheck:
+		return w.runPrecheck(ctx)
+	case StepRun:
+		return w.runTests(ctx)
+	case StepProbe:
+		return w.waitForTests(ctx)
+	case StepCollect:
+		return w.collectReports(ctx)
+	case StepAnalyze:
+		return w.analyzeReports(ctx)
+	case StepConnectivity:
+		return w.checkConnectivity(ctx)
+	}
+	return "", fmt.Errorf("unknown step %q", name)
+}
+
+func (w *Workflow) runPrecheck(ctx context.Context) (string, error) {
+	checker := precheck.New(w.cfg).WithTestType(w.testType).WithContext(ctx).WithCache(w.precheckCache, false)
+	summary, err := checker.DoCheckForAPI(w.cfg)
+	if err != nil {
+		return "", err
+	}
+	w.mu.Lock()
+	w.result.Precheck = summary
+	w.mu.Unlock()
+
+	pinViolations := 0
+	if summary.Firmware != nil {
+		pinViolations = summary.Firmware.PinViolationCount
+	}
+	if !summary.CheckPassed {
+		// Unhealthy HCAs only block the tests under the abort policy; the
+		// speeds must still match among the healthy ones
+		healthy := lo.Filter(summary.Results, func(r precheck.PrecheckResult, _ int) bool { return r.Error == "" && r.IsHealthy })
+		if summary.PerftestFailedHosts == 0 && pinViolations == 0 && precheck.Summarize(healthy).AllSpeedsSame &&
+			w.cfg.Precheck.UnhealthyHCAPolicy(config.UnhealthyHCAAbort) != config.UnhealthyHCAAbort {
+			decision, err := checker.ApplyUnhealthyHCAPolicy(summary.Results, config.UnhealthyHCAAbort)
+			if err != nil {
+				return "", err
+			}
+			w.logger.Warn(decision.Message(), "policy", decision.Policy, "unhealthy", len(decision.Unhealthy), "excluded", len(decision.Excluded))
+			w.excluded = decision.Excluded
+			w.mu.Lock()
+			w.result.UnhealthyHCA = decision
+			w.mu.Unlock()
+			if len(decision.Excluded) > 0 {
+				return fmt.Sprintf("%d HCAs healthy, %d unhealthy HCAs excluded", summary.HealthyCount, len(decision.Excluded)), nil
+			}
+			return fmt.Sprintf("%d HCAs healthy, %d unhealthy HCAs reported", summary.HealthyCount, summary.UnhealthyCount+summary.ErrorCount), nil
+		}
+		return "", fmt.Errorf("precheck failed: %d healthy, %d unhealthy, %d errors, all speeds same: %v, hosts without usable perftest: %d, HCAs off pinned firmware: %d",
+			summary.HealthyCount, summary.UnhealthyCount, summary.ErrorCount, summary.AllSpeedsSame, summary.PerftestFailedHosts, pinViolations)
+	}
+	return fmt.Sprintf("All %d HCAs healthy", summary.HealthyCount), nil
+}
+
+func (w *Workflow) runTests(ctx context.Context) (string, error) {
+	w.counterBefore = w.counterReader(ctx).Snapshot()
+
+	runner := runnerservice.New(w.cfg).
+		WithScriptsDir(store.ScriptsPath(w.runDir)).
+		WithEvents(w.events).
+		WithContext(ctx).
+		WithExcludedHCAs(precheck.ExcludedHostHCAs(w.excluded))
+	if err := runner.Run(w.testType); err != nil {
+		return "", err
+	}
+
+	// Same as the execute command: p2p tests are short-lived and produce no report
+	if w.testType == script.TestTypeBandwidth && w.cfg.StreamType == config.P2P {
+		return "P2P tests started; probe, collect and analyze are skipped", errSkipRemaining
+	}
+	return "Test processes started on all hosts", nil
+}
+
+// waitForTests probes the hosts until no test process is left
+func (w *Workflow) waitForTests(ctx context.Context) (string, error) {
+	lastCounts := make(map[string]int)
+	startTime := time.Now()
+
+	for {
+		counts, err := w.probeProcessCounts(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		running := 0
+		for host, count := range counts {
+			running += count
+			if last, seen := lastCounts[host]; seen && last == count {
+				continue
+			}
+			lastCounts[host] = count
+			w.events.Publish(events.Event{
+				Type: events.TypeProcessCount,
+				Host: host,
+				Data: map[string]any{"process": w.testType.Command(), "count": count},
+			})
+		}
+		if running == 0 {
+			if w.counterBefore != nil {
+				w.counterDeltas = counters.Diff(w.counterBefore, w.counterReader(ctx).Snapshot())
+			}
+			return fmt.Sprintf("All test processes completed after %v", time.Since(startTime).Round(time.Second)), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(w.probeInterval):
+		}
+	}
+}
+
+// probeProcessCounts returns the number of running test processes per host;
+// hosts that cannot be probed count as finished, like the probe command does
+func (w *Workflow) probeProcessCounts(ctx context.Context) (map[string]int, error) {
+	counts := make(map[string]int)
+	if w.testType == script.TestTypeLatency {
+		results, err := lat.New(w.cfg).WithContext(ctx).DoLatencyProbe()
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range results {
+			counts[r.Hostname] = r.ProcessCount
+		}
+		return counts, nil
+	}
+
+	results, err := probe.New(w.cfg).WithContext(ctx).DoProbe()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range results {
+		counts[r.Hostname] = r.ProcessCount
+	}
+	return counts, nil
+}
+
+func (w *Workflow) collectReports(ctx context.Context) (string, error) {
+	if !w.cfg.Report.Enable {
+		return "Report generation is disabled; collect and analyze are skipped", errSkipRemaining
+	}
+
+	result, err := collect.New(w.cfg).
+		WithEvents(w.events).
+		WithContext(ctx).
+		CollectAndGetResult(w.cfg, store.ReportsPath(w.runDir))
+	if err != nil {
+		return "", err
+	}
+	// Saved next to the reports so the analyze step adds them to the report
+	if w.counterDeltas != nil {
+		if err := counters.Save(store.ReportsPath(w.runDir), w.counterDeltas); err != nil {
+			w.logger.Warn("Failed to save counter deltas", "error", err)
+		}
+	}
+	if len(w.excluded) > 0 {
+		if err := precheck.SaveExcluded(store.ReportsPath(w.runDir), w.excluded); err != nil {
+			w.logger.Warn("Failed to save excluded HCAs", "error", err)
+		}
+	}
+	files := 0
+	for _, count := range result.CollectedFiles {
+		files += count
+	}
+	return fmt.Sprintf("Collected %d report files from %d hosts", files, len(result.CollectedFiles)), nil
+}
+
+// counterReader reads the port counters, cancelled with ctx
+func (w *Workflow) counterReader(ctx context.Context) *counters.Reader {
+	return counters.New(w.cfg).WithContext(ctx)
+}
+
+func (w *Workflow) analyzeReports(ctx context.Context) (string, error) {
+	reportsDir := store.ReportsPath(w.runDir)
+
+	var report any
+	var err error
+	if w.testType == script.TestTypeLatency {
+		report, err = lat.New(w.cfg).WithEvents(w.events).WithContext(ctx).GenerateLatencyReport(reportsDir)
+	} else {
+		report, err = analyze.New(w.cfg).WithEvents(w.events).WithContext(ctx).GenerateReport(reportsDir)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	w.mu.Lock()
+	w.result.Report = report
+	w.mu.Unlock()
+	return "Report generated", nil
+}
+
+func (w *Workflow) checkConnectivity(ctx context.Context) (string, error) {
+	summary, err := connectivity.New(w.cfg).
+		WithRunDir(w.runDir).
+		WithEvents(w.events).
+		WithContext(ctx).
+		CheckConnectivity()
+	if err != nil {
+		return "", err
+	}
+
+	w.mu.Lock()
+	w.result.Report = summary
+	w.mu.Unlock()
+	return fmt.Sprintf("%d/%d pairs connected", summary.ConnectedPairs, summary.TotalPairs), nil
+}
+
+func (w *Workflow) startStep(name string) {
+	now := time.Now()
+	w.mu.Lock()
+	step := w.step(name)
+	step.Status = StatusRunning
+	step.StartedAt = &now
+	w.mu.Unlock()
+	w.publishStep(name, StatusRunning, "", "")
+}
+
+func (w *Workflow) finishStep(name, message string, err error) {
+	now := time.Now()
+	w.mu.Lock()
+	step := w.step(name)
+	step.FinishedAt = &now
+	step.Message = message
+	step.Status = StatusSucceeded
+	if err != nil {
+		step.Status = StatusFailed
+		step.Error = err.Error()
+	}
+	status, errText := step.Status, step.Error
+	w.mu.Unlock()
+	w.publishStep(name, status, message, errText)
+}
+
+func (w *Workflow) skipRemaining(from int, reason string) {
+	w.mu.Lock()
+	var skipped []string
+	for _, step := range w.result.Steps[from:] {
+		step.Status = StatusSkipped
+		step.Message = reason
+		skipped = append(skipped, step.Name)
+	}
+	w.mu.Unlock()
+	for _, name := range skipped {
+		w.publishStep(name, StatusSkipped, reason, "")
+	}
+}
+
+// step returns the named step; callers hold w.mu
+func (w *Workflow) step(name string) *Step {
+	for _, step := range w.result.Steps {
+		if step.Name == name {
+			return step
+		}
+	}
+	return nil
+}
+
+func (w *Workflow) publishStep(name, status, message, errText string) {
+	data := map[string]any{"step": name, "status": status}
+	if errText != "" {
+		data["error"] = errText
+	}
+	w.events.Publish(events.Event{Type: events.TypeStep, Message: message, Data: data})
+}
@@ -0,0 +1,81 @@
+package workflow_test
+
+import (
+	"context"
+	"testing"
+
+	"xnetperf/config"
+	"xnetperf/internal/script"
+	"xnetperf/internal/service/workflow"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.Config
+		testType script.TestType
+		wantErr  bool
+	}{
+		{
+			name:     "bandwidth",
+			cfg:      config.Config{StreamType: config.FullMesh, Report: config.Report{Enable: true}},
+			testType: script.TestTypeBandwidth,
+		},
+		{
+			name:     "infinite bandwidth run never finishes",
+			cfg:      config.Config{StreamType: config.FullMesh, Run: config.Run{Infinitely: true}},
+			testType: script.TestTypeBandwidth,
+			wantErr:  true,
+		},
+		{
+			name:     "p2p latency",
+			cfg:      config.Config{StreamType: config.P2P},
+			testType: script.TestTypeLatency,
+			wantErr:  true,
+		},
+		{
+			name:     "connectivity without reports",
+			cfg:      config.Config{StreamType: config.FullMesh},
+			testType: script.TestTypeConnectivity,
+			wantErr:  true,
+		},
+		{
+			name:     "connectivity ignores infinite runs",
+			cfg:      config.Config{StreamType: config.FullMesh, Run: config.Run{Infinitely: true}, Report: config.Report{Enable: true}},
+			testType: script.TestTypeConnectivity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := workflow.Validate(&tt.cfg, tt.testType)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRunCancelledSkipsAllSteps(t *testing.T) {
+	cfg := &config.Config{StreamType: config.FullMesh, Report: config.Report{Enable: true}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := workflow.New(cfg, script.TestTypeBandwidth).WithPrecheck(true).Run(ctx)
+	if err == nil {
+		t.Fatal("Expected an error for a cancelled workflow")
+	}
+
+	want := []string{workflow.StepPrecheck, workflow.StepRun, workflow.StepProbe, workflow.StepCollect, workflow.StepAnalyze}
+	if len(result.Steps) != len(want) {
+		t.Fatalf("Expected %d steps, got %d", len(want), len(result.Steps))
+	}
+	for i, step := range result.Steps {
+		if step.Name != want[i] {
+			t.Errorf("Step %d: expected %s, got %s", i, want[i], step.Name)
+		}
+		if step.Status != workflow.StatusSkipped {
+			t.Errorf("Step %s: expected skipped, got %s", step.Name, step.Status)
+		}
+	}
+}
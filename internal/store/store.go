@@ -0,0 +1,516 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"xnetperf/config"
+	"xnetperf/pkg/tools/logger"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultRoot is the directory runs are stored in when none is given
+const DefaultRoot = "runs"
+
+// LegacyReportsDir is the shared reports directory used when no run directory is given
+const LegacyReportsDir = "reports"
+
+// Run statuses
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// ErrRunning is returned by Delete for a run that has not finished
+var ErrRunning = errors.New("run is still running")
+
+// Files and directories inside a run directory
+const (
+	runFile       = "run.json"
+	configFile    = "config.yaml"
+	reportsSubdir = "reports"
+	scriptsSubdir = "scripts"
+	resultsSubdir = "results"
+)
+
+// cacheSubdir holds cached check results shared by all runs
+const cacheSubdir = "cache"
+
+// ReportsPath returns the raw reports directory inside a run directory,
+// or the legacy shared reports directory when runDir is empty
+func ReportsPath(runDir string) string {
+	if runDir == "" {
+		return LegacyReportsDir
+	}
+	return filepath.Join(runDir, reportsSubdir)
+}
+
+// ScriptsPath returns the generated scripts directory inside a run directory,
+// or "" (scripts are not saved) when runDir is empty
+func ScriptsPath(runDir string) string {
+	if runDir == "" {
+		return ""
+	}
+	return filepath.Join(runDir, scriptsSubdir)
+}
+
+// iterationPrefix names the reports and scripts subdirectories of the
+// iterations of a run repeated with --repeat, e.g. reports/iteration-2
+const iterationPrefix = "iteration-"
+
+// IterationDir returns the subdirectory of dir holding one iteration of a
+// repeated run
+func IterationDir(dir string, iteration int) string {
+	return filepath.Join(dir, fmt.Sprintf("%s%d", iterationPrefix, iteration))
+}
+
+// IsIterationDir reports whether a directory name is that of an iteration
+func IsIterationDir(name string) bool {
+	n, err := strconv.Atoi(strings.TrimPrefix(name, iterationPrefix))
+	return strings.HasPrefix(name, iterationPrefix) && err == nil && n > 0
+}
+
+// Iterations returns how many iterations a repeated run saved in reportsDir,
+// 0 for a run that was not repeated
+func Iterations(reportsDir string) int {
+	entries, err := os.ReadDir(reportsDir)
+	if err != nil {
+		return 0
+	}
+	count := 0
+	for _, e := range entries {
+		if e.IsDir() && IsIterationDir(e.Name()) {
+			count++
+		}
+	}
+	return count
+}
+
+// Run is the metadata of a single test run
+type Run struct {
+	ID          string         `json:"id"`
+	ConfigName  string         `json:"config_name"`
+	TestType    string         `json:"test_type"`
+	Status      string         `json:"status"`
+	StartedAt   time.Time      `json:"started_at"`
+	FinishedAt  *time.Time     `json:"finished_at,omitempty"`
+	Error       string         `json:"error,omitempty"`
+	Config      *config.Config `json:"config"`
+	Results     []string       `json:"results"`      // names of stored analyzed results
+	ReportFiles int            `json:"report_files"` // number of stored raw report files
+}
+
+// Duration returns how long the run took, or has been running so far
+func (r *Run) Duration() time.Duration {
+	if r.FinishedAt != nil {
+		return r.FinishedAt.Sub(r.StartedAt)
+	}
+	return time.Since(r.StartedAt)
+}
+
+// Filter narrows down the runs returned by List; empty fields match everything
+type Filter struct {
+	ConfigName string
+	TestType   string
+	Status     string
+	Limit      int
+}
+
+// Store persists runs as directories under a root directory:
+//
+//	<root>/<run-id>/run.json      metadata
+//	<root>/<run-id>/config.yaml   config snapshot
+//	<root>/<run-id>/reports/      raw report files
+//	<root>/<run-id>/scripts/      generated test scripts
+//	<root>/<run-id>/results/      analyzed results (<name>.json)
+//	<root>/cache/                 cached check results shared by all runs (<name>.json)
+type Store struct {
+	root   string
+	mu     sync.Mutex
+	logger *slog.Logger
+}
+
+// Open opens (and creates if needed) a run store rooted at root
+func Open(root string) (*Store, error) {
+	if root == "" {
+		root = DefaultRoot
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create run store directory '%s': %w", root, err)
+	}
+	return &Store{
+		root:   root,
+		logger: logger.GetLogger().With("module", "STORE"),
+	}, nil
+}
+
+// Root returns the store root directory
+func (s *Store) Root() string {
+	return s.root
+}
+
+// Dir returns the directory of a run
+func (s *Store) Dir(id string) string {
+	return filepath.Join(s.root, id)
+}
+
+// ReportsDir returns the raw reports directory of a run
+func (s *Store) ReportsDir(id string) string {
+	return ReportsPath(s.Dir(id))
+}
+
+// Create registers a new running run and snapshots its config with secrets
+// redacted; the snapshot is only readable by the owner
+func (s *Store) Create(configName, testType string, cfg *config.Config) (*Run, error) {
+	if cfg != nil {
+		cfg = cfg.Redacted()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	id, err := s.claimID(now, configName, testType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create run directory: %w", err)
+	}
+	run := &Run{
+		ID:         id,
+		ConfigName: configName,
+		TestType:   testType,
+		Status:     StatusRunning,
+		StartedAt:  now,
+		Config:     cfg,
+		Results:    []string{},
+	}
+
+	if err := os.MkdirAll(filepath.Join(s.Dir(id), resultsSubdir), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create run directory: %w", err)
+	}
+	if cfg != nil {
+		data, err := yaml.Marshal(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal config snapshot: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(s.Dir(id), configFile), data, 0600); err != nil {
+			return nil, fmt.Errorf("failed to write config snapshot: %w", err)
+		}
+	}
+	if err := s.writeRun(run); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Run created", "id", id, "config", configName, "test_type", testType)
+	return run, nil
+}
+
+// IndexReports counts the raw report files collected into the run directory
+func (s *Store) IndexReports(run *Run) error {
+	count := 0
+	err := filepath.Walk(s.ReportsDir(run.ID), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.IsDir() {
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index reports of run %q: %w", run.ID, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run.ReportFiles = count
+	return s.writeRun(run)
+}
+
+// SaveResult stores an analyzed result of the run under the given name
+func (s *Store) SaveResult(run *Run, name string, result any) error {
+	if !validName(name) {
+		return fmt.Errorf("invalid result name %q", name)
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal result %q: %w", name, err)
+	}
+	if err := writeFileAtomic(filepath.Join(s.Dir(run.ID), resultsSubdir, name+".json"), data); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range run.Results {
+		if existing == name {
+			return s.writeRun(run)
+		}
+	}
+	run.Results = append(run.Results, name)
+	sort.Strings(run.Results)
+	return s.writeRun(run)
+}
+
+// Finish marks the run as succeeded, or failed when runErr is not nil
+func (s *Store) Finish(run *Run, runErr error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	run.FinishedAt = &now
+	run.Status = StatusSucceeded
+	if runErr != nil {
+		run.Status = StatusFailed
+		run.Error = runErr.Error()
+	}
+	s.logger.Info("Run finished", "id", run.ID, "status", run.Status, "duration", run.Duration().Round(time.Second))
+	return s.writeRun(run)
+}
+
+// Get loads a run by ID
+func (s *Store) Get(id string) (*Run, error) {
+	if !validName(id) {
+		return nil, fmt.Errorf("invalid run id %q", id)
+	}
+	data, err := os.ReadFile(filepath.Join(s.Dir(id), runFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("run %q not found", id)
+		}
+		return nil, fmt.Errorf("failed to read run %q: %w", id, err)
+	}
+	var run Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to parse run %q: %w", id, err)
+	}
+	return &run, nil
+}
+
+// ReadResult returns the raw JSON of a stored result
+func (s *Store) ReadResult(id, name string) (json.RawMessage, error) {
+	if !validName(id) || !validName(name) {
+		return nil, fmt.Errorf("invalid run id or result name")
+	}
+	data, err := os.ReadFile(filepath.Join(s.Dir(id), resultsSubdir, name+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("result %q not found for run %q", name, id)
+		}
+		return nil, err
+	}
+	return json.RawMessage(data), nil
+}
+
+// SaveCache stores a value shared by all runs, e.g. the last precheck results, under the given name
+func (s *Store) SaveCache(name string, value any) error {
+	return s.UpdateCache(name, value, nil)
+}
+
+// UpdateCache loads the cached value under name into value, lets update change
+// it and stores the result. Other processes sharing the store wait on a lock
+// file until the update is written, so that concurrent updates are not lost;
+// with a nil update the loaded value is discarded and value is stored as is.
+// An unreadable cache is replaced.
+func (s *Store) UpdateCache(name string, value any, update func() error) error {
+	if !validName(name) {
+		return fmt.Errorf("invalid cache name %q", name)
+	}
+	dir := filepath.Join(s.root, cacheSubdir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory '%s': %w", dir, err)
+	}
+	unlock, err := lockFile(filepath.Join(dir, name+".lock"))
+	if err != nil {
+		return fmt.Errorf("failed to lock cache %q: %w", name, err)
+	}
+	defer unlock()
+
+	if update != nil {
+		if _, err := s.ReadCache(name, value); err != nil {
+			s.logger.Warn("Replacing unreadable cache", "name", name, "error", err)
+		}
+		if err := update(); err != nil {
+			return err
+		}
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache %q: %w", name, err)
+	}
+	return writeFileAtomic(filepath.Join(dir, name+".json"), data)
+}
+
+// ReadCache loads the value stored by SaveCache into value; found is false when nothing is cached
+func (s *Store) ReadCache(name string, value any) (found bool, err error) {
+	if !validName(name) {
+		return false, fmt.Errorf("invalid cache name %q", name)
+	}
+	data, err := os.ReadFile(filepath.Join(s.root, cacheSubdir, name+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read cache %q: %w", name, err)
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return false, fmt.Errorf("failed to parse cache %q: %w", name, err)
+	}
+	return true, nil
+}
+
+// List returns runs matching the filter, newest first
+func (s *Store) List(filter Filter) ([]*Run, error) {
+	entries, err := os.ReadDir(s.root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run store: %w", err)
+	}
+
+	var runs []*Run
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		run, err := s.Get(entry.Name())
+		if err != nil {
+			s.logger.Debug("Skipping directory without run metadata", "dir", entry.Name(), "error", err)
+			continue
+		}
+		if filter.ConfigName != "" && run.ConfigName != filter.ConfigName {
+			continue
+		}
+		if filter.TestType != "" && run.TestType != filter.TestType {
+			continue
+		}
+		if filter.Status != "" && run.Status != filter.Status {
+			continue
+		}
+		runs = append(runs, run)
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+	if filter.Limit > 0 && len(runs) > filter.Limit {
+		runs = runs[:filter.Limit]
+	}
+	return runs, nil
+}
+
+// Latest returns the newest run matching the filter
+func (s *Store) Latest(filter Filter) (*Run, error) {
+	filter.Limit = 1
+	runs, err := s.List(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("no matching run found")
+	}
+	return runs[0], nil
+}
+
+// Delete removes a run and all of its files. Runs that are still running
+// are refused with ErrRunning unless force is set, since the process writing
+// them would recreate parts of the directory; force is meant for runs left
+// running by a process that died.
+func (s *Store) Delete(id string, force bool) error {
+	run, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if run.Status == StatusRunning && !force {
+		return fmt.Errorf("%w: %s", ErrRunning, id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.RemoveAll(s.Dir(id)); err != nil {
+		return fmt.Errorf("failed to delete run %q: %w", id, err)
+	}
+	s.logger.Info("Run deleted", "id", id)
+	return nil
+}
+
+// claimID creates the directory of a new run and returns its sortable, human
+// readable ID: <timestamp>-<config>-<type>, with a -N suffix when that is
+// taken. The directory is claimed with os.Mkdir, which fails if it exists,
+// so processes sharing the store (a cron CLI run and the server) never get
+// the same ID.
+func (s *Store) claimID(t time.Time, configName, testType string) (string, error) {
+	base := fmt.Sprintf("%s-%s-%s", t.Format("20060102-150405"), sanitize(configName), sanitize(testType))
+	id := base
+	for i := 2; ; i++ {
+		err := os.Mkdir(s.Dir(id), 0755)
+		if err == nil {
+			return id, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return "", err
+		}
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+func (s *Store) writeRun(run *Run) error {
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal run %q: %w", run.ID, err)
+	}
+	return writeFileAtomic(filepath.Join(s.Dir(run.ID), runFile), data)
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// sanitize turns a config name like "configs/a.yaml" into a path-safe "a"
+func sanitize(name string) string {
+	name = strings.TrimSuffix(strings.TrimSuffix(filepath.Base(name), ".yaml"), ".yml")
+	name = unsafeChars.ReplaceAllString(name, "_")
+	if name == "" || name == "." {
+		return "unknown"
+	}
+	return name
+}
+
+func validName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it
+// over path, so readers never see a partly written file. Every writer uses its
+// own temporary file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write '%s': %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write '%s': %w", path, err)
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write '%s': %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write '%s': %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write '%s': %w", path, err)
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 type IBCommand struct {
 	commandType    CommandType
 	device         string
+	ibPort         int
 	runInfinitely  bool
 	durationSec    int
 	queuePairNum   int // Only used for bandwidth tests
@@ -52,6 +53,12 @@ func (c *IBCommand) Device(device string) *IBCommand {
 	return c
 }
 
+// IBPort sets the HCA port the test runs on (adds -i flag); 0 keeps the perftest default of port 1
+func (c *IBCommand) IBPort(port int) *IBCommand {
+	c.ibPort = port
+	return c
+}
+
 // RunInfinitely sets whether to run the test infinitely
 func (c *IBCommand) RunInfinitely(enable bool) *IBCommand {
 	c.runInfinitely = enable
@@ -149,6 +156,11 @@ func (c *IBCommand) Build() string {
 		cmd.WriteString(fmt.Sprintf(" -d %s", c.device))
 	}
 
+	// HCA port
+	if c.ibPort > 0 {
+		cmd.WriteString(fmt.Sprintf(" -i %d", c.ibPort))
+	}
+
 	// Duration mode
 	if c.runInfinitely {
 		cmd.WriteString(" --run_infinitely")
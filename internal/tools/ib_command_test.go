@@ -291,3 +291,29 @@ func TestCommandTypeHelpers(t *testing.T) {
 		}
 	})
 }
+
+func TestIBCommandIBPort(t *testing.T) {
+	tests := []struct {
+		name     string
+		port     int
+		expected string
+	}{
+		{name: "default port", port: 0, expected: "ib_write_bw -d mlx5_0 -p 18515"},
+		{name: "second port", port: 2, expected: "ib_write_bw -d mlx5_0 -i 2 -p 18515"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tools.NewIBWriteBwCommand().
+				Device("mlx5_0").
+				IBPort(tt.port).
+				Port(18515).
+				RedirectOutput("").
+				Background(false).
+				String()
+			if got != tt.expected {
+				t.Errorf("String() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
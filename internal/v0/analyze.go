@@ -1,6 +1,7 @@
 package v0
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 	"xnetperf/config"
+	"xnetperf/internal/audit"
 	"xnetperf/internal/tools"
 )
 
@@ -105,13 +107,14 @@ func runP2PAnalyze(reportsDir string, cfg *config.Config, generateMD bool) {
 func getSerialNumberForHost(hostname string, sshKeyPath string, user string) string {
 	// 尝试通过SSH获取系统序列号
 	var cmd *exec.Cmd
+	host := hostname
 	if user != "" && !strings.Contains(hostname, "@") {
 		hostname = fmt.Sprintf("%s@%s", user, hostname)
 	}
 	command := "cat /sys/class/dmi/id/product_serial"
 	sshWrapper := tools.NewSSHWrapper(hostname).Command(command).PrivateKey(sshKeyPath)
 	cmd = exec.Command("bash", "-c", sshWrapper.String())
-	output, err := cmd.CombinedOutput()
+	output, err := audit.CombinedOutput(context.Background(), host, command, cmd)
 	if err != nil {
 		return "N/A"
 	}
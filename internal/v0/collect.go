@@ -1,6 +1,7 @@
 package v0
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -8,6 +9,7 @@ import (
 	"strings"
 	"sync"
 	"xnetperf/config"
+	"xnetperf/internal/audit"
 	"xnetperf/pkg/tools"
 )
 
@@ -115,7 +117,7 @@ func collectFromHost(hostname, remoteDir, localBaseDir, sshKeyPath, user string,
 		cmd = exec.Command("scp", "-i", sshKeyPath, fmt.Sprintf("%s:%s", tmpHost, scpCmd), hostDir+"/")
 	}
 
-	output, err := cmd.CombinedOutput()
+	output, err := audit.CombinedOutput(context.Background(), hostname, "scp "+scpCmd, cmd)
 	if err != nil {
 		// 检查是否是因为没有匹配的文件
 		if string(output) != "" {
@@ -152,7 +154,7 @@ func cleanupRemoteFiles(hostname, remoteDir, sshKeyPath, user string) {
 	checkCmd := fmt.Sprintf("ls %s/*%s*.json 2>/dev/null | wc -l", remoteDir, hostname)
 	checkExec := tools.BuildSSHCommand(hostname, checkCmd, sshKeyPath, user)
 
-	checkOutput, err := checkExec.CombinedOutput()
+	checkOutput, err := audit.CombinedOutput(context.Background(), hostname, checkCmd, checkExec)
 	if err != nil {
 		fmt.Printf("   [WARNING] ⚠️  %s: Failed to check remote files: %v\n", hostname, err)
 		return
@@ -168,7 +170,7 @@ func cleanupRemoteFiles(hostname, remoteDir, sshKeyPath, user string) {
 	rmCmd := fmt.Sprintf("rm -f %s/*%s*.json", remoteDir, hostname)
 	cmd := tools.BuildSSHCommand(hostname, rmCmd, sshKeyPath, user)
 
-	output, err := cmd.CombinedOutput()
+	output, err := audit.CombinedOutput(context.Background(), hostname, rmCmd, cmd)
 	if err != nil {
 		fmt.Printf("   [WARNING] ⚠️  %s: Failed to cleanup remote files: %v\n", hostname, err)
 		if len(output) > 0 {
@@ -181,7 +183,7 @@ func cleanupRemoteFiles(hostname, remoteDir, sshKeyPath, user string) {
 	verifyCmd := fmt.Sprintf("ls %s/*%s*.json 2>/dev/null | wc -l", remoteDir, hostname)
 	verifyExec := tools.BuildSSHCommand(hostname, verifyCmd, sshKeyPath, user)
 
-	verifyOutput, err := verifyExec.CombinedOutput()
+	verifyOutput, err := audit.CombinedOutput(context.Background(), hostname, verifyCmd, verifyExec)
 	if err == nil && string(verifyOutput) == "0\n" {
 		fmt.Printf("   [CLEANUP] ✅ %s: Remote files cleaned up successfully\n", hostname)
 	} else {
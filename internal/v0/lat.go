@@ -1,6 +1,7 @@
 package v0
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"xnetperf/config"
+	"xnetperf/internal/audit"
 	"xnetperf/internal/script"
 	"xnetperf/internal/service/collect"
 	"xnetperf/internal/service/precheck"
@@ -114,7 +116,7 @@ func executeLatencyGenerateStep(cfg *config.Config) bool {
 func executeLatencyRunStep(cfg *config.Config) bool {
 	fmt.Println("Executing latency tests...")
 
-	if err := stream.RunLatencyScripts(cfg); err != nil {
+	if err := stream.RunLatencyScripts(context.Background(), cfg); err != nil {
 		fmt.Printf("❌ Error running latency scripts: %v\n", err)
 		return false
 	}
@@ -143,7 +145,7 @@ func executeLatencyCollectStep(cfg *config.Config) bool {
 	fmt.Println("Collecting latency report files from remote hosts...")
 
 	collector := collect.New(cfg)
-	if err := collector.DoCollect(cleanupRemote); err != nil {
+	if err := collector.DoCollect("reports", cleanupRemote); err != nil {
 		fmt.Printf("❌ Error during report collection: %v\n", err)
 		return false
 	}
@@ -758,7 +760,7 @@ func probeLatencyHost(hostname, sshKeyPath, user string) LatencyProbeResult {
 
 	// Use SSH to execute ps command to find ib_write_lat processes
 	cmd := tools.BuildSSHCommand(hostname, "ps aux | grep ib_write_lat | grep -v grep", sshKeyPath, user)
-	output, err := cmd.CombinedOutput()
+	output, err := audit.CombinedOutput(context.Background(), hostname, "ps aux | grep ib_write_lat | grep -v grep", cmd)
 
 	if err != nil {
 		// If no processes found or SSH connection failed
@@ -1,12 +1,14 @@
 package v0
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 	"xnetperf/config"
+	"xnetperf/internal/audit"
 	"xnetperf/pkg/tools"
 )
 
@@ -138,7 +140,7 @@ func precheckHCA(hostname, hca, sshKeyPath, user string) PrecheckResult {
 	// 检查物理状态
 	physStateCmd := fmt.Sprintf("cat /sys/class/infiniband/%s/ports/1/phys_state", hca)
 	cmd := tools.BuildSSHCommand(hostname, physStateCmd, sshKeyPath, user)
-	physOutput, err := cmd.CombinedOutput()
+	physOutput, err := audit.CombinedOutput(context.Background(), hostname, physStateCmd, cmd)
 
 	if err != nil {
 		result.Error = fmt.Sprintf("Failed to check phys_state: %v", err)
@@ -148,7 +150,7 @@ func precheckHCA(hostname, hca, sshKeyPath, user string) PrecheckResult {
 	// 检查逻辑状态
 	stateCmd := fmt.Sprintf("cat /sys/class/infiniband/%s/ports/1/state", hca)
 	cmd = tools.BuildSSHCommand(hostname, stateCmd, sshKeyPath, user)
-	stateOutput, err := cmd.CombinedOutput()
+	stateOutput, err := audit.CombinedOutput(context.Background(), hostname, stateCmd, cmd)
 
 	if err != nil {
 		result.Error = fmt.Sprintf("Failed to check state: %v", err)
@@ -158,7 +160,7 @@ func precheckHCA(hostname, hca, sshKeyPath, user string) PrecheckResult {
 	// 检查网卡速度
 	speedCmd := fmt.Sprintf("cat /sys/class/infiniband/%s/ports/1/rate", hca)
 	cmd = tools.BuildSSHCommand(hostname, speedCmd, sshKeyPath, user)
-	speedOutput, err := cmd.CombinedOutput()
+	speedOutput, err := audit.CombinedOutput(context.Background(), hostname, speedCmd, cmd)
 
 	if err != nil {
 		result.Error = fmt.Sprintf("Failed to check speed: %v", err)
@@ -168,7 +170,7 @@ func precheckHCA(hostname, hca, sshKeyPath, user string) PrecheckResult {
 	// 检查固件版本
 	fwVerCmd := fmt.Sprintf("cat /sys/class/infiniband/%s/fw_ver", hca)
 	cmd = tools.BuildSSHCommand(hostname, fwVerCmd, sshKeyPath, user)
-	fwVerOutput, err := cmd.CombinedOutput()
+	fwVerOutput, err := audit.CombinedOutput(context.Background(), hostname, fwVerCmd, cmd)
 
 	if err != nil {
 		result.Error = fmt.Sprintf("Failed to check fw_ver: %v", err)
@@ -178,7 +180,7 @@ func precheckHCA(hostname, hca, sshKeyPath, user string) PrecheckResult {
 	// 检查板卡ID
 	boardIdCmd := fmt.Sprintf("cat /sys/class/infiniband/%s/board_id", hca)
 	cmd = tools.BuildSSHCommand(hostname, boardIdCmd, sshKeyPath, user)
-	boardIdOutput, err := cmd.CombinedOutput()
+	boardIdOutput, err := audit.CombinedOutput(context.Background(), hostname, boardIdCmd, cmd)
 
 	if err != nil {
 		result.Error = fmt.Sprintf("Failed to check board_id: %v", err)
@@ -188,7 +190,7 @@ func precheckHCA(hostname, hca, sshKeyPath, user string) PrecheckResult {
 	// 检查系统序列号
 	serialNumberCmd := "cat /sys/class/dmi/id/product_serial"
 	cmd = tools.BuildSSHCommand(hostname, serialNumberCmd, sshKeyPath, user)
-	serialNumberOutput, err := cmd.CombinedOutput()
+	serialNumberOutput, err := audit.CombinedOutput(context.Background(), hostname, serialNumberCmd, cmd)
 
 	if err != nil {
 		result.Error = fmt.Sprintf("Failed to check serial number: %v", err)
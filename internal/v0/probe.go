@@ -1,11 +1,13 @@
 package v0
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 	"xnetperf/config"
+	"xnetperf/internal/audit"
 	"xnetperf/pkg/tools"
 )
 
@@ -99,7 +101,7 @@ func probeHost(hostname, sshKeyPath, user string) ProbeResult {
 
 	// 使用SSH执行ps命令查找ib_write_bw进程
 	cmd := tools.BuildSSHCommand(hostname, "ps aux | grep ib_write_bw | grep -v grep", sshKeyPath, user)
-	output, err := cmd.CombinedOutput()
+	output, err := audit.CombinedOutput(context.Background(), hostname, "ps aux | grep ib_write_bw | grep -v grep", cmd)
 
 	if err != nil {
 		// 如果没有找到进程或SSH连接失败
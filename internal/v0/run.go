@@ -1,10 +1,12 @@
 package v0
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"sync"
 	"xnetperf/config"
+	"xnetperf/internal/audit"
 	"xnetperf/pkg/tools"
 	"xnetperf/stream"
 )
@@ -57,7 +59,7 @@ func cleanupRemoteReportFiles(cfg *config.Config) {
 			rmCmd := fmt.Sprintf("rm -f %s/*%s*.json", cfg.Report.Dir, host)
 			cmd := tools.BuildSSHCommand(host, rmCmd, cfg.SSH.PrivateKey, cfg.SSH.User)
 
-			output, err := cmd.CombinedOutput()
+			output, err := audit.CombinedOutput(context.Background(), host, rmCmd, cmd)
 			if err != nil {
 				fmt.Printf("   [WARNING] ⚠️  %s: Failed to cleanup old reports: %v\n", host, err)
 				if len(output) > 0 {
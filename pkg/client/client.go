@@ -0,0 +1,260 @@
+// Package client is a typed Go client of the xnetperf HTTP server API.
+//
+// The methods in zz_generated.go are generated from the OpenAPI document of
+// the server routes; regenerate them after changing routes or response types:
+//
+//	go generate ./pkg/client
+//
+// Long-running operations (precheck, run, collect, connectivity, execute)
+// return the submitted job. Wait for it with WaitJob, follow its progress
+// with StreamJobEvents and decode its result with DecodeResult:
+//
+//	c := client.New("https://xnetperf.example.com:8443").WithToken(token)
+//	job, err := c.PrecheckConfig(ctx, "config.yaml")
+//	...
+//	job, err = c.WaitJob(ctx, job.ID, 0)
+//	var summary client.PrecheckSummary
+//	err = client.DecodeResult(job, &summary)
+package client
+
+//go:generate go run ./internal/gen -o zz_generated.go
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPollInterval is how often WaitJob polls a job
+const DefaultPollInterval = 2 * time.Second
+
+// States of a Job
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// Client calls the API of one server
+type Client struct {
+	baseURL  string
+	http     *http.Client
+	token    string
+	username string
+	password string
+}
+
+// New creates a client for the server at baseURL, e.g. http://host:8080
+func New(baseURL string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{},
+	}
+}
+
+// WithToken authenticates with an API token or OIDC token
+func (c *Client) WithToken(token string) *Client {
+	c.token = token
+	return c
+}
+
+// WithBasicAuth authenticates as a user of the server's users file
+func (c *Client) WithBasicAuth(username, password string) *Client {
+	c.username = username
+	c.password = password
+	return c
+}
+
+// WithHTTPClient sets the HTTP client, e.g. one with a custom TLS config.
+// Requests are bounded by their context, so the client needs no timeout.
+func (c *Client) WithHTTPClient(client *http.Client) *Client {
+	c.http = client
+	return c
+}
+
+// APIError is a response with a non-zero code
+type APIError struct {
+	StatusCode int             // HTTP status
+	Code       int             // code of the response body
+	Message    string          // message of the response body
+	Data       json.RawMessage // data of the response body, e.g. validation errors
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("xnetperf server returned %d: %s", e.StatusCode, e.Message)
+}
+
+// envelope is the response body of all /api endpoints
+type envelope struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// do sends a request and decodes the data of the response into out
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := c.newRequest(ctx, method, path, query, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var env envelope
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		if resp.StatusCode/100 != 2 {
+			return &APIError{StatusCode: resp.StatusCode, Code: resp.StatusCode, Message: resp.Status}
+		}
+		return fmt.Errorf("decode response of %s %s: %w", method, path, err)
+	}
+	if resp.StatusCode/100 != 2 || env.Code != 0 {
+		return &APIError{StatusCode: resp.StatusCode, Code: env.Code, Message: env.Message, Data: env.Data}
+	}
+	if out == nil || len(env.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(env.Data, out); err != nil {
+		return fmt.Errorf("decode response of %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body io.Reader) (*http.Request, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c.token != "":
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	case c.username != "":
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return req, nil
+}
+
+// WaitJob polls a job every interval (DefaultPollInterval if zero) until it
+// finishes. The finished job is returned with an error unless it succeeded.
+func (c *Client) WaitJob(ctx context.Context, id string, interval time.Duration) (*Job, error) {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		job, err := c.GetJob(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if job.State != JobQueued && job.State != JobRunning {
+			if job.State != JobSucceeded {
+				return job, fmt.Errorf("job %s %s: %s", job.ID, job.State, job.Error)
+			}
+			return job, nil
+		}
+		select {
+		case <-ctx.Done():
+			return job, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// DecodeResult decodes the result of a finished job, e.g. into a
+// PrecheckSummary for precheck jobs or a WorkflowResult for execute jobs
+func DecodeResult(job *Job, v any) error {
+	if job.Result == nil {
+		return fmt.Errorf("job %s has no result", job.ID)
+	}
+	data, err := json.Marshal(job.Result)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// StreamJobEvents follows the events of a job (GET /api/jobs/{id}/events),
+// calling fn for each one after the event with sequence number after. It
+// returns nil when the job finishes and the server closes the stream, or
+// the error of fn, which stops the stream.
+func (c *Client) StreamJobEvents(ctx context.Context, id string, after int64, fn func(JobEvent) error) error {
+	var query url.Values
+	if after > 0 {
+		query = url.Values{"after": {strconv.FormatInt(after, 10)}}
+	}
+	req, err := c.newRequest(ctx, http.MethodGet, "/api/jobs/"+url.PathEscape(id)+"/events", query, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var env envelope
+		json.NewDecoder(resp.Body).Decode(&env)
+		return &APIError{StatusCode: resp.StatusCode, Code: env.Code, Message: env.Message}
+	}
+
+	// Events are "id:", "event:" and "data:" lines ended by a blank line;
+	// lines starting with ":" are keep-alive comments
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data.Len() == 0 {
+				continue
+			}
+			var e JobEvent
+			if err := json.Unmarshal([]byte(data.String()), &e); err != nil {
+				return fmt.Errorf("decode job event: %w", err)
+			}
+			data.Reset()
+			if err := fn(e); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "data:"):
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,144 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"xnetperf/config"
+	"xnetperf/internal/events"
+	"xnetperf/internal/jobs"
+	"xnetperf/pkg/client"
+	"xnetperf/server"
+)
+
+func newServer(t *testing.T) (*client.Client, *jobs.Manager, string) {
+	t.Helper()
+	jobManager := jobs.New(2, 8)
+	t.Cleanup(jobManager.Shutdown)
+	srv := httptest.NewServer(server.NewServer(config.HTTPServer{}, nil, jobManager).Handler())
+	t.Cleanup(srv.Close)
+	return client.New(srv.URL + "/"), jobManager, srv.URL
+}
+
+func TestClient(t *testing.T) {
+	c, _, _ := newServer(t)
+	ctx := context.Background()
+
+	health, err := c.GetHealth(ctx)
+	if err != nil || health.Status != "ok" {
+		t.Errorf("GetHealth() = %+v, %v", health, err)
+	}
+
+	identity, err := c.GetIdentity(ctx)
+	if err != nil || identity.AuthEnabled || identity.Role != "admin" {
+		t.Errorf("GetIdentity() = %+v, %v", identity, err)
+	}
+
+	_, err = c.GetConfig(ctx, "missing.yaml")
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Code != 404 {
+		t.Errorf("GetConfig(missing) error = %v, want a 404 APIError", err)
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	_, _, url := newServer(t)
+	resp, err := http.Get(url + "/api/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var doc struct {
+		OpenAPI string                    `json:"openapi"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != "3.0.3" {
+		t.Errorf("openapi = %q", doc.OpenAPI)
+	}
+	if _, ok := doc.Paths["/api/configs/{name}/report"]["get"]; !ok {
+		t.Errorf("Paths are missing GET /api/configs/{name}/report")
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("Authorization"))
+		w.Write([]byte(`{"code":0,"message":"success","data":{"status":"ok"}}`))
+	}))
+	defer srv.Close()
+
+	client.New(srv.URL).WithToken("secret").GetHealth(context.Background())
+	client.New(srv.URL).WithBasicAuth("alice", "pw").GetHealth(context.Background())
+	if len(got) != 2 || got[0] != "Bearer secret" || got[1] != "Basic YWxpY2U6cHc=" {
+		t.Errorf("Authorization headers = %q", got)
+	}
+}
+
+func TestJobs(t *testing.T) {
+	c, jobManager, _ := newServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	release := make(chan struct{})
+	submit := func(fail bool) *jobs.Job {
+		job, err := jobManager.Submit("precheck", "config.yaml", func(ctx context.Context, job *jobs.Job) (any, error) {
+			<-release
+			job.Publish(events.Event{Type: events.TypeLog, Host: "node1", Message: "checked"})
+			if fail {
+				return nil, errors.New("hosts unreachable")
+			}
+			return &client.PrecheckSummary{TotalHCAs: 2, AllHealthy: true}, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return job
+	}
+	succeeded := submit(false)
+	failed := submit(true)
+
+	var streamed []client.JobEvent
+	streamDone := make(chan error, 1)
+	go func() {
+		streamDone <- c.StreamJobEvents(ctx, succeeded.Info().ID, 0, func(e client.JobEvent) error {
+			streamed = append(streamed, e)
+			return nil
+		})
+	}()
+	close(release)
+
+	job, err := c.WaitJob(ctx, succeeded.Info().ID, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitJob: %v", err)
+	}
+	var summary client.PrecheckSummary
+	if err := client.DecodeResult(job, &summary); err != nil || summary.TotalHCAs != 2 || !summary.AllHealthy {
+		t.Errorf("DecodeResult() = %+v, %v", summary, err)
+	}
+
+	if err := <-streamDone; err != nil {
+		t.Fatalf("StreamJobEvents: %v", err)
+	}
+	found := false
+	for _, e := range streamed {
+		found = found || (e.Host == "node1" && e.Message == "checked")
+	}
+	if !found {
+		t.Errorf("Streamed events %+v are missing the published event", streamed)
+	}
+
+	job, err = c.WaitJob(ctx, failed.Info().ID, 10*time.Millisecond)
+	if err == nil || job == nil || job.State != client.JobFailed {
+		t.Errorf("WaitJob(failed) = %+v, %v", job, err)
+	}
+}
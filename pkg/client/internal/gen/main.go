@@ -0,0 +1,29 @@
+// Command gen writes the generated part of the API client from the OpenAPI
+// document of the server routes. Run it with go generate ./pkg/client.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"xnetperf/config"
+	"xnetperf/internal/openapi/clientgen"
+	"xnetperf/server"
+)
+
+func main() {
+	output := flag.String("o", "zz_generated.go", "output file")
+	flag.Parse()
+
+	doc := server.NewServer(config.HTTPServer{}, nil, nil).OpenAPI()
+	src, err := clientgen.Generate(doc, clientgen.ClientOptions)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*output, src, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,1172 @@
+// Code generated by xnetperf/pkg/client/internal/gen; DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Actor is audit.Actor
+type Actor struct {
+	User   string `json:"user"`
+	Auth   string `json:"auth,omitempty"`
+	Client string `json:"client,omitempty"`
+	JobID  string `json:"job_id,omitempty"`
+}
+
+// AuditEntry is audit.Entry
+type AuditEntry struct {
+	Time       time.Time `json:"time"`
+	User       string    `json:"user"`
+	Auth       string    `json:"auth,omitempty"`
+	Client     string    `json:"client,omitempty"`
+	JobID      string    `json:"job_id,omitempty"`
+	Host       string    `json:"host"`
+	Command    string    `json:"command"`
+	ExitCode   int       `json:"exit_code"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	Background bool      `json:"background,omitempty"`
+}
+
+// BandwidthSample is sampler.Sample
+type BandwidthSample struct {
+	Time     time.Time `json:"time"`
+	Hostname string    `json:"hostname"`
+	HCA      string    `json:"hca"`
+	TxGbps   float64   `json:"tx_gbps"`
+	RxGbps   float64   `json:"rx_gbps"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// BoardFirmware is precheck.BoardFirmware
+type BoardFirmware struct {
+	BoardID  string         `json:"board_id"`
+	HCAs     int            `json:"hcas"`
+	Expected string         `json:"expected"`
+	Pinned   bool           `json:"pinned"`
+	Versions map[string]int `json:"versions"`
+}
+
+// CallerIdentity is server.CallerIdentity
+type CallerIdentity struct {
+	AuthEnabled bool   `json:"auth_enabled"`
+	Subject     string `json:"subject,omitempty"`
+	Role        string `json:"role"`
+	Method      string `json:"method,omitempty"`
+}
+
+// ClientConfig is config.ClientConfig
+type ClientConfig struct {
+	Hostname []string `json:"hostname"`
+	Hca      []string `json:"hca"`
+}
+
+// ClientDeviceData is analyze.ClientDeviceData
+type ClientDeviceData struct {
+	Hostname      string  `json:"hostname"`
+	Device        string  `json:"device"`
+	SerialNumber  string  `json:"serial_number,omitempty"`
+	Switch        string  `json:"switch,omitempty"`
+	SwitchPort    string  `json:"switch_port,omitempty"`
+	ActualBW      float64 `json:"actual_bw"`
+	TheoreticalBW float64 `json:"theoretical_bw"`
+	Delta         float64 `json:"delta"`
+	DeltaPercent  float64 `json:"delta_percent"`
+	Status        string  `json:"status"`
+}
+
+// CollectResult is collect.CollectResult
+type CollectResult struct {
+	Success        bool           `json:"success"`
+	Message        string         `json:"message"`
+	CollectedFiles map[string]int `json:"collected_files"`
+	Error          string         `json:"error,omitempty"`
+	RunID          string         `json:"run_id,omitempty"`
+}
+
+// Config is config.Config
+type Config struct {
+	StartPort          int          `json:"start_port"`
+	StreamType         string       `json:"stream_type"`
+	QpNum              int          `json:"qp_num"`
+	MessageSizeBytes   int          `json:"message_size_bytes"`
+	OutputBase         string       `json:"output_base"`
+	WaitingTimeSeconds int          `json:"waiting_time_seconds"`
+	Speed              float64      `json:"speed"`
+	RdmaCm             bool         `json:"rdma_cm"`
+	GidIndex           int          `json:"gid_index"`
+	NetworkInterface   string       `json:"network_interface"`
+	IBPort             int          `json:"ib_port,omitempty"`
+	Report             Report       `json:"report"`
+	Run                ConfigRun    `json:"run"`
+	SSH                SSH          `json:"ssh"`
+	Logger             Logger       `json:"logger"`
+	Server             ServerConfig `json:"server"`
+	Client             ClientConfig `json:"client"`
+	Version            string       `json:"version"`
+	Precheck           Precheck     `json:"precheck"`
+}
+
+// ConfigFileInfo is server.ConfigFileInfo
+type ConfigFileInfo struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	IsDefault   bool   `json:"is_default"`
+	IsDeletable bool   `json:"is_deletable"`
+}
+
+// ConfigPreview is server.ConfigPreview
+type ConfigPreview struct {
+	YAML string `json:"yaml"`
+}
+
+// ConfigRun is config.Run
+type ConfigRun struct {
+	Infinitely      bool `json:"infinitely"`
+	DurationSeconds int  `json:"duration_seconds"`
+}
+
+// ConfigValidation is server.ConfigValidation
+type ConfigValidation struct {
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors,omitempty"`
+	Config *Config  `json:"config,omitempty"`
+}
+
+// ConnectivityResult is connectivity.ConnectivityResult
+type ConnectivityResult struct {
+	SourceHost   string  `json:"source_host"`
+	SourceHCA    string  `json:"source_hca"`
+	TargetHost   string  `json:"target_host"`
+	TargetHCA    string  `json:"target_hca"`
+	Connected    bool    `json:"connected"`
+	AvgLatencyUs float64 `json:"avg_latency_us"`
+	MinLatencyUs float64 `json:"min_latency_us"`
+	MaxLatencyUs float64 `json:"max_latency_us"`
+	Error        string  `json:"error,omitempty"`
+}
+
+// ConnectivitySummary is connectivity.ConnectivitySummary
+type ConnectivitySummary struct {
+	TotalPairs        int                  `json:"total_pairs"`
+	ConnectedPairs    int                  `json:"connected_pairs"`
+	DisconnectedPairs int                  `json:"disconnected_pairs"`
+	ErrorPairs        int                  `json:"error_pairs"`
+	Results           []ConnectivityResult `json:"results"`
+}
+
+// CreateConfigRequest is server.CreateConfigRequest
+type CreateConfigRequest struct {
+	Name   string `json:"name"`
+	Config Config `json:"config"`
+}
+
+// DeletedRun is server.DeletedRun
+type DeletedRun struct {
+	ID string `json:"id"`
+}
+
+// Delta is counters.Delta
+type Delta struct {
+	Hostname   string            `json:"hostname"`
+	HCA        string            `json:"hca"`
+	Counters   map[string]uint64 `json:"counters,omitempty"`
+	Errors     uint64            `json:"errors"`
+	Congestion uint64            `json:"congestion"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// ExcludedHCA is precheck.ExcludedHCA
+type ExcludedHCA struct {
+	Hostname string `json:"hostname"`
+	HCA      string `json:"hca"`
+	Reason   string `json:"reason"`
+}
+
+// ExecuteRequest is server.ExecuteRequest
+type ExecuteRequest struct {
+	TestType string `json:"test_type"`
+	Precheck bool   `json:"precheck"`
+}
+
+// FirmwareCheck is config.FirmwareCheck
+type FirmwareCheck struct {
+	Pinned map[string]string `json:"pinned,omitempty"`
+}
+
+// FirmwareHCA is precheck.FirmwareHCA
+type FirmwareHCA struct {
+	Hostname     string `json:"hostname"`
+	HCA          string `json:"hca"`
+	SerialNumber string `json:"serial_number"`
+	BoardID      string `json:"board_id"`
+	FwVer        string `json:"fw_ver"`
+	Expected     string `json:"expected"`
+	Status       string `json:"status"`
+}
+
+// FirmwareReport is precheck.FirmwareReport
+type FirmwareReport struct {
+	Boards            []BoardFirmware `json:"boards"`
+	HCAs              []FirmwareHCA   `json:"hcas"`
+	OutlierCount      int             `json:"outlier_count"`
+	PinViolationCount int             `json:"pin_violation_count"`
+}
+
+// HCAIRQAffinity is precheck.HCAIRQAffinity
+type HCAIRQAffinity struct {
+	HCA       string `json:"hca"`
+	LocalCPUs string `json:"local_cpus"`
+	IRQs      int    `json:"irqs"`
+	OffNode   int    `json:"off_node"`
+}
+
+// HCAsRequest is server.HCAsRequest
+type HCAsRequest struct {
+	HCAs []string `json:"hcas"`
+}
+
+// HealthStatus is server.HealthStatus
+type HealthStatus struct {
+	Status string `json:"status"`
+}
+
+// HostTuningCheck is config.HostTuningCheck
+type HostTuningCheck struct {
+	Enabled      bool   `json:"enabled,omitempty"`
+	CPUGovernor  string `json:"cpu_governor,omitempty"`
+	MaxCState    int    `json:"max_cstate,omitempty"`
+	IOMMU        string `json:"iommu,omitempty"`
+	MinHugePages int    `json:"min_hugepages,omitempty"`
+	Memlock      string `json:"memlock,omitempty"`
+	IRQAffinity  bool   `json:"irq_affinity,omitempty"`
+}
+
+// HostTuningResult is precheck.HostTuningResult
+type HostTuningResult struct {
+	Hostname       string           `json:"hostname"`
+	CPUGovernor    string           `json:"cpu_governor,omitempty"`
+	CStates        []string         `json:"cstates,omitempty"`
+	IOMMU          string           `json:"iommu,omitempty"`
+	HugePagesTotal int              `json:"hugepages_total"`
+	HugePagesFree  int              `json:"hugepages_free"`
+	HugePageSizeKB int              `json:"hugepage_size_kb"`
+	Memlock        string           `json:"memlock,omitempty"`
+	IRQs           []HCAIRQAffinity `json:"irqs,omitempty"`
+	Issues         []string         `json:"issues,omitempty"`
+	Error          string           `json:"error,omitempty"`
+}
+
+// HostnamesRequest is server.HostnamesRequest
+type HostnamesRequest struct {
+	Hostnames []string `json:"hostnames"`
+}
+
+// Job is jobs.Info
+type Job struct {
+	ID         string     `json:"id"`
+	Type       string     `json:"type"`
+	ConfigName string     `json:"config_name"`
+	Initiator  Actor      `json:"initiator"`
+	State      string     `json:"state"`
+	Progress   int        `json:"progress"`
+	Step       string     `json:"step,omitempty"`
+	RunID      string     `json:"run_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	Result     any        `json:"result,omitempty"`
+	Logs       []LogEntry `json:"logs,omitempty"`
+}
+
+// JobEvent is events.Event
+type JobEvent struct {
+	Seq     int64          `json:"seq"`
+	Time    time.Time      `json:"time"`
+	Type    string         `json:"type"`
+	Host    string         `json:"host,omitempty"`
+	Message string         `json:"message,omitempty"`
+	Data    map[string]any `json:"data,omitempty"`
+}
+
+// LatencyProbeResult is lat.LatencyProbeResult
+type LatencyProbeResult struct {
+	Hostname     string   `json:"hostname"`
+	ProcessCount int      `json:"process_count"`
+	Processes    []string `json:"processes,omitempty"`
+	Error        string   `json:"error,omitempty"`
+	Status       string   `json:"status"`
+}
+
+// LatencyProbeSummary is lat.LatencyProbeSummary
+type LatencyProbeSummary struct {
+	Timestamp      string               `json:"timestamp"`
+	Results        []LatencyProbeResult `json:"results"`
+	RunningHosts   int                  `json:"running_hosts"`
+	CompletedHosts int                  `json:"completed_hosts"`
+	ErrorHosts     int                  `json:"error_hosts"`
+	TotalProcesses int                  `json:"total_processes"`
+	AllCompleted   bool                 `json:"all_completed"`
+}
+
+// LatencyStatistics is lat.LatencyStatistics
+type LatencyStatistics struct {
+	MinLatency float64 `json:"min_latency"`
+	MaxLatency float64 `json:"max_latency"`
+	AvgLatency float64 `json:"avg_latency"`
+	TotalCount int     `json:"total_count"`
+}
+
+// LatencyStats is lat.LatencyStats
+type LatencyStats struct {
+	AvgLatency float64 `json:"avg_latency"`
+	Count      int     `json:"count"`
+}
+
+// LatencySummary is lat.LatencySummary
+type LatencySummary struct {
+	StreamType    string                        `json:"stream_type"`
+	Matrix        map[string]map[string]float64 `json:"matrix"`
+	Statistics    LatencyStatistics             `json:"statistics"`
+	ClientStats   map[string]LatencyStats       `json:"client_stats,omitempty"`
+	ServerStats   map[string]LatencyStats       `json:"server_stats,omitempty"`
+	CounterDeltas []Delta                       `json:"counter_deltas,omitempty"`
+	Neighbors     []Neighbor                    `json:"neighbors,omitempty"`
+	Excluded      []ExcludedHCA                 `json:"excluded,omitempty"`
+}
+
+// LogEntry is jobs.LogEntry
+type LogEntry struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+// Logger is config.Logger
+type Logger struct {
+	LogLevel  string `json:"log_level"`
+	LogFormat string `json:"log_format"`
+}
+
+// Neighbor is neighbor.Neighbor
+type Neighbor struct {
+	Hostname string `json:"hostname"`
+	HCA      string `json:"hca"`
+	Switch   string `json:"switch,omitempty"`
+	SwitchID string `json:"switch_id,omitempty"`
+	Port     string `json:"port,omitempty"`
+	Source   string `json:"source,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// P2PDeviceDataInfo is analyze.P2PDeviceDataInfo
+type P2PDeviceDataInfo struct {
+	Hostname string  `json:"hostname"`
+	Device   string  `json:"device"`
+	AvgSpeed float64 `json:"avg_speed"`
+	Count    int     `json:"count"`
+}
+
+// P2PSummary is analyze.P2PSummary
+type P2PSummary struct {
+	TotalPairs int     `json:"total_pairs"`
+	AvgSpeed   float64 `json:"avg_speed"`
+}
+
+// PCIeCheck is config.PCIeCheck
+type PCIeCheck struct {
+	Speed           float64        `json:"speed,omitempty"`
+	Width           int            `json:"width,omitempty"`
+	NUMANodes       map[string]int `json:"numa_nodes,omitempty"`
+	RelaxedOrdering string         `json:"relaxed_ordering,omitempty"`
+	ACS             string         `json:"acs,omitempty"`
+}
+
+// PerftestBinary is precheck.PerftestBinary
+type PerftestBinary struct {
+	Name         string   `json:"name"`
+	Path         string   `json:"path,omitempty"`
+	Version      string   `json:"version,omitempty"`
+	Flags        []string `json:"flags,omitempty"`
+	MissingFlags []string `json:"missing_flags,omitempty"`
+	Installed    bool     `json:"installed,omitempty"`
+	InstallError string   `json:"install_error,omitempty"`
+}
+
+// PerftestCheck is config.PerftestCheck
+type PerftestCheck struct {
+	BundleDir  string `json:"bundle_dir,omitempty"`
+	InstallDir string `json:"install_dir,omitempty"`
+}
+
+// PerftestResult is precheck.PerftestResult
+type PerftestResult struct {
+	Hostname string           `json:"hostname"`
+	Binaries []PerftestBinary `json:"binaries,omitempty"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// Precheck is config.Precheck
+type Precheck struct {
+	PCIe            PCIeCheck       `json:"pcie"`
+	RoCE            RoCECheck       `json:"roce"`
+	Perftest        PerftestCheck   `json:"perftest"`
+	HostTuning      HostTuningCheck `json:"host_tuning"`
+	Fix             PrecheckFix     `json:"fix"`
+	Firmware        FirmwareCheck   `json:"firmware"`
+	CacheTTLSeconds int             `json:"cache_ttl_seconds,omitempty"`
+	UnhealthyHCA    string          `json:"unhealthy_hca,omitempty"`
+}
+
+// PrecheckFix is config.PrecheckFix
+type PrecheckFix struct {
+	Actions []string `json:"actions,omitempty"`
+	Modules []string `json:"modules,omitempty"`
+}
+
+// PrecheckResult is precheck.PrecheckResult
+type PrecheckResult struct {
+	Hostname        string   `json:"hostname"`
+	HCA             string   `json:"hca"`
+	PhysState       string   `json:"phys_state"`
+	State           string   `json:"state"`
+	Speed           string   `json:"speed"`
+	FwVer           string   `json:"fw_ver"`
+	BoardId         string   `json:"board_id"`
+	IsHealthy       bool     `json:"is_healthy"`
+	SerialNumber    string   `json:"serial_number"`
+	Error           string   `json:"error"`
+	PCIeLink        string   `json:"pcie_link,omitempty"`
+	PCIeMaxLink     string   `json:"pcie_max_link,omitempty"`
+	NUMANode        string   `json:"numa_node,omitempty"`
+	RelaxedOrdering string   `json:"relaxed_ordering,omitempty"`
+	ACS             string   `json:"acs,omitempty"`
+	PCIeIssues      []string `json:"pcie_issues,omitempty"`
+	LinkLayer       string   `json:"link_layer,omitempty"`
+	GID             string   `json:"gid,omitempty"`
+	GIDType         string   `json:"gid_type,omitempty"`
+	NetDev          string   `json:"netdev,omitempty"`
+	MTU             string   `json:"mtu,omitempty"`
+	Trust           string   `json:"trust,omitempty"`
+	PFC             string   `json:"pfc,omitempty"`
+	ECN             string   `json:"ecn,omitempty"`
+	RoCEIssues      []string `json:"roce_issues,omitempty"`
+	Switch          string   `json:"switch,omitempty"`
+	SwitchID        string   `json:"switch_id,omitempty"`
+	SwitchPort      string   `json:"switch_port,omitempty"`
+	Cached          bool     `json:"cached,omitempty"`
+}
+
+// PrecheckSummary is precheck.PrecheckSummary
+type PrecheckSummary struct {
+	TotalHCAs            int                `json:"total_hcas"`
+	HealthyCount         int                `json:"healthy_count"`
+	UnhealthyCount       int                `json:"unhealthy_count"`
+	ErrorCount           int                `json:"error_count"`
+	AllHealthy           bool               `json:"all_healthy"`
+	AllSpeedsSame        bool               `json:"all_speeds_same"`
+	CheckPassed          bool               `json:"check_passed"`
+	Results              []PrecheckResult   `json:"results"`
+	SpeedStats           map[string]int     `json:"speed_stats"`
+	FwVerStats           map[string]int     `json:"fw_ver_stats"`
+	BoardIdStats         map[string]int     `json:"board_id_stats"`
+	PCIeIssueCount       int                `json:"pcie_issue_count"`
+	RoCEIssueCount       int                `json:"roce_issue_count"`
+	Perftest             []PerftestResult   `json:"perftest,omitempty"`
+	PerftestFailedHosts  int                `json:"perftest_failed_hosts"`
+	HostTuning           []HostTuningResult `json:"host_tuning,omitempty"`
+	HostTuningIssueCount int                `json:"host_tuning_issue_count"`
+	Firmware             *FirmwareReport    `json:"firmware,omitempty"`
+}
+
+// ProbeResult is probe.ProbeResult
+type ProbeResult struct {
+	Hostname     string   `json:"hostname"`
+	ProcessCount int      `json:"process_count"`
+	Processes    []string `json:"processes,omitempty"`
+	Error        string   `json:"error,omitempty"`
+	Status       string   `json:"status"`
+}
+
+// ProbeSummary is probe.ProbeSummary
+type ProbeSummary struct {
+	Timestamp      string        `json:"timestamp"`
+	Results        []ProbeResult `json:"results"`
+	RunningHosts   int           `json:"running_hosts"`
+	CompletedHosts int           `json:"completed_hosts"`
+	ErrorHosts     int           `json:"error_hosts"`
+	TotalProcesses int           `json:"total_processes"`
+	AllCompleted   bool          `json:"all_completed"`
+}
+
+// Report is config.Report
+type Report struct {
+	Enable bool   `json:"enable"`
+	Dir    string `json:"dir"`
+}
+
+// ReportData is analyze.ReportData
+type ReportData struct {
+	StreamType             string                                   `json:"stream_type"`
+	TheoreticalBWPerClient float64                                  `json:"theoretical_bw_per_client,omitempty"`
+	TotalServerBW          float64                                  `json:"total_server_bw,omitempty"`
+	ClientCount            int                                      `json:"client_count,omitempty"`
+	ClientData             map[string]map[string]*ClientDeviceData  `json:"client_data,omitempty"`
+	ServerData             map[string]map[string]*ServerDeviceData  `json:"server_data,omitempty"`
+	P2PData                map[string]map[string]*P2PDeviceDataInfo `json:"p2p_data,omitempty"`
+	P2PSummary             *P2PSummary                              `json:"p2p_summary,omitempty"`
+	CounterDeltas          []Delta                                  `json:"counter_deltas,omitempty"`
+	Excluded               []ExcludedHCA                            `json:"excluded,omitempty"`
+}
+
+// RoCECheck is config.RoCECheck
+type RoCECheck struct {
+	MTU           int    `json:"mtu,omitempty"`
+	Trust         string `json:"trust,omitempty"`
+	PFCPriorities []int  `json:"pfc_priorities,omitempty"`
+	ECNPriorities []int  `json:"ecn_priorities,omitempty"`
+}
+
+// Run is store.Run
+type Run struct {
+	ID          string     `json:"id"`
+	ConfigName  string     `json:"config_name"`
+	TestType    string     `json:"test_type"`
+	Status      string     `json:"status"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	Config      *Config    `json:"config"`
+	Results     []string   `json:"results"`
+	ReportFiles int        `json:"report_files"`
+}
+
+// RunRequest is server.RunRequest
+type RunRequest struct {
+	TestType string `json:"test_type"`
+}
+
+// RunResult is runner.RunResult
+type RunResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+	RunID   string `json:"run_id,omitempty"`
+}
+
+// SSH is config.SSH
+type SSH struct {
+	User       string `json:"user"`
+	PrivateKey string `json:"private_key"`
+}
+
+// SamplerStartRequest is server.SamplerStartRequest
+type SamplerStartRequest struct {
+	IntervalSeconds int    `json:"interval_seconds"`
+	RunID           string `json:"run_id"`
+}
+
+// SamplerStatus is server.SamplerStatus
+type SamplerStatus struct {
+	Running         bool      `json:"running"`
+	IntervalSeconds int       `json:"interval_seconds"`
+	StartedAt       time.Time `json:"started_at"`
+	RunID           string    `json:"run_id,omitempty"`
+	CSVPath         string    `json:"csv_path,omitempty"`
+}
+
+// SamplesResponse is server.SamplesResponse
+type SamplesResponse struct {
+	Status  SamplerStatus     `json:"status"`
+	Samples []BandwidthSample `json:"samples"`
+}
+
+// ServerConfig is config.ServerConfig
+type ServerConfig struct {
+	Hostname []string `json:"hostname"`
+	Hca      []string `json:"hca"`
+}
+
+// ServerDeviceData is analyze.ServerDeviceData
+type ServerDeviceData struct {
+	Hostname      string  `json:"hostname"`
+	Device        string  `json:"device"`
+	SerialNumber  string  `json:"serial_number,omitempty"`
+	Switch        string  `json:"switch,omitempty"`
+	SwitchPort    string  `json:"switch_port,omitempty"`
+	RxBW          float64 `json:"rx_bw"`
+	TheoreticalBW float64 `json:"theoretical_bw"`
+	Delta         float64 `json:"delta"`
+	DeltaPercent  float64 `json:"delta_percent"`
+	Status        string  `json:"status"`
+}
+
+// Step is workflow.Step
+type Step struct {
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	Message    string     `json:"message,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// UnhealthyHCADecision is precheck.UnhealthyHCADecision
+type UnhealthyHCADecision struct {
+	Policy    string        `json:"policy"`
+	Unhealthy []ExcludedHCA `json:"unhealthy,omitempty"`
+	Excluded  []ExcludedHCA `json:"excluded,omitempty"`
+}
+
+// WorkflowResult is workflow.Result
+type WorkflowResult struct {
+	TestType     string                `json:"test_type"`
+	RunID        string                `json:"run_id,omitempty"`
+	Steps        []*Step               `json:"steps"`
+	Precheck     *PrecheckSummary      `json:"precheck,omitempty"`
+	UnhealthyHCA *UnhealthyHCADecision `json:"unhealthy_hca,omitempty"`
+	Report       any                   `json:"report,omitempty"`
+}
+
+// QueryAuditParams are the optional query parameters of QueryAudit
+type QueryAuditParams struct {
+	// 按主机过滤
+	Host string
+	// 按发起者过滤
+	User string
+	// 按任务 ID 过滤
+	JobID string
+	// RFC3339 时间或 Unix 秒
+	Since string
+	// RFC3339 时间或 Unix 秒
+	Until string
+	// 最多返回的条数，默认 500，最大 10000
+	Limit int
+}
+
+func (p *QueryAuditParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Host != "" {
+		v.Set("host", p.Host)
+	}
+	if p.User != "" {
+		v.Set("user", p.User)
+	}
+	if p.JobID != "" {
+		v.Set("job_id", p.JobID)
+	}
+	if p.Since != "" {
+		v.Set("since", p.Since)
+	}
+	if p.Until != "" {
+		v.Set("until", p.Until)
+	}
+	if p.Limit != 0 {
+		v.Set("limit", strconv.FormatInt(int64(p.Limit), 10))
+	}
+	return v
+}
+
+// QueryAudit 查询审计日志（最新的在前）
+//
+// GET /api/audit (admin)
+func (c *Client) QueryAudit(ctx context.Context, params *QueryAuditParams) ([]AuditEntry, error) {
+	var out []AuditEntry
+	err := c.do(ctx, "GET", "/api/audit", params.values(), nil, &out)
+	return out, err
+}
+
+// GetIdentity 获取当前调用者的身份和角色
+//
+// GET /api/auth/me (viewer)
+func (c *Client) GetIdentity(ctx context.Context) (*CallerIdentity, error) {
+	var out CallerIdentity
+	if err := c.do(ctx, "GET", "/api/auth/me", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListConfigs 获取配置文件列表
+//
+// GET /api/configs (viewer)
+func (c *Client) ListConfigs(ctx context.Context) ([]ConfigFileInfo, error) {
+	var out []ConfigFileInfo
+	err := c.do(ctx, "GET", "/api/configs", nil, nil, &out)
+	return out, err
+}
+
+// CreateConfig 创建配置文件
+//
+// POST /api/configs (admin)
+func (c *Client) CreateConfig(ctx context.Context, body CreateConfigRequest) (*ConfigFileInfo, error) {
+	var out ConfigFileInfo
+	if err := c.do(ctx, "POST", "/api/configs", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteConfig 删除配置文件
+//
+// DELETE /api/configs/{name} (admin)
+func (c *Client) DeleteConfig(ctx context.Context, name string) error {
+	return c.do(ctx, "DELETE", "/api/configs/"+url.PathEscape(name), nil, nil, nil)
+}
+
+// GetConfig 获取指定配置文件
+//
+// GET /api/configs/{name} (viewer)
+func (c *Client) GetConfig(ctx context.Context, name string) (*Config, error) {
+	var out Config
+	if err := c.do(ctx, "GET", "/api/configs/"+url.PathEscape(name), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateConfig 更新配置文件
+//
+// PUT /api/configs/{name} (admin)
+func (c *Client) UpdateConfig(ctx context.Context, name string, body Config) error {
+	return c.do(ctx, "PUT", "/api/configs/"+url.PathEscape(name), nil, body, nil)
+}
+
+// CollectReportsParams are the optional query parameters of CollectReports
+type CollectReportsParams struct {
+	// 收集到该运行记录，默认使用运行中的记录
+	RunID string
+	// 没有运行中的记录时新建记录的测试类型，默认 bandwidth
+	TestType string
+}
+
+func (p *CollectReportsParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.RunID != "" {
+		v.Set("run_id", p.RunID)
+	}
+	if p.TestType != "" {
+		v.Set("test_type", p.TestType)
+	}
+	return v
+}
+
+// CollectReports 收集报告
+//
+// POST /api/configs/{name}/collect (operator)
+//
+// The server runs it as a job; wait for the result with WaitJob.
+func (c *Client) CollectReports(ctx context.Context, name string, params *CollectReportsParams) (*Job, error) {
+	var out Job
+	if err := c.do(ctx, "POST", "/api/configs/"+url.PathEscape(name)+"/collect", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CheckConnectivity 检查网络连通性
+//
+// POST /api/configs/{name}/connectivity (operator)
+//
+// The server runs it as a job; wait for the result with WaitJob.
+func (c *Client) CheckConnectivity(ctx context.Context, name string) (*Job, error) {
+	var out Job
+	if err := c.do(ctx, "POST", "/api/configs/"+url.PathEscape(name)+"/connectivity", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExecuteWorkflow 执行完整测试流程（precheck、run、probe、collect、analyze）
+//
+// POST /api/configs/{name}/execute (operator)
+//
+// The server runs it as a job; wait for the result with WaitJob.
+func (c *Client) ExecuteWorkflow(ctx context.Context, name string, body ExecuteRequest) (*Job, error) {
+	var out Job
+	if err := c.do(ctx, "POST", "/api/configs/"+url.PathEscape(name)+"/execute", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PrecheckConfig 执行 precheck 检查
+//
+// POST /api/configs/{name}/precheck (operator)
+//
+// The server runs it as a job; wait for the result with WaitJob.
+func (c *Client) PrecheckConfig(ctx context.Context, name string) (*Job, error) {
+	var out Job
+	if err := c.do(ctx, "POST", "/api/configs/"+url.PathEscape(name)+"/precheck", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PreviewConfig 预览配置文件（YAML 格式）
+//
+// GET /api/configs/{name}/preview (viewer)
+func (c *Client) PreviewConfig(ctx context.Context, name string) (*ConfigPreview, error) {
+	var out ConfigPreview
+	if err := c.do(ctx, "GET", "/api/configs/"+url.PathEscape(name)+"/preview", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ProbeTest 探测带宽测试进程 (ib_write_bw)
+//
+// POST /api/configs/{name}/probe (operator)
+func (c *Client) ProbeTest(ctx context.Context, name string) (*ProbeSummary, error) {
+	var out ProbeSummary
+	if err := c.do(ctx, "POST", "/api/configs/"+url.PathEscape(name)+"/probe", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ProbeLatencyTest 探测延迟测试进程 (ib_write_lat)
+//
+// POST /api/configs/{name}/probe-lat (operator)
+func (c *Client) ProbeLatencyTest(ctx context.Context, name string) (*LatencyProbeSummary, error) {
+	var out LatencyProbeSummary
+	if err := c.do(ctx, "POST", "/api/configs/"+url.PathEscape(name)+"/probe-lat", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetReportParams are the optional query parameters of GetReport
+type GetReportParams struct {
+	// 运行记录 ID，默认使用最近一次记录
+	RunID string
+}
+
+func (p *GetReportParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.RunID != "" {
+		v.Set("run_id", p.RunID)
+	}
+	return v
+}
+
+// GetReport 获取带宽测试报告
+//
+// GET /api/configs/{name}/report (viewer)
+func (c *Client) GetReport(ctx context.Context, name string, params *GetReportParams) (*ReportData, error) {
+	var out ReportData
+	if err := c.do(ctx, "GET", "/api/configs/"+url.PathEscape(name)+"/report", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetLatencyReportParams are the optional query parameters of GetLatencyReport
+type GetLatencyReportParams struct {
+	// 运行记录 ID，默认使用最近一次记录
+	RunID string
+}
+
+func (p *GetLatencyReportParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.RunID != "" {
+		v.Set("run_id", p.RunID)
+	}
+	return v
+}
+
+// GetLatencyReport 获取延迟测试报告
+//
+// GET /api/configs/{name}/report-lat (viewer)
+func (c *Client) GetLatencyReport(ctx context.Context, name string, params *GetLatencyReportParams) (*LatencySummary, error) {
+	var out LatencySummary
+	if err := c.do(ctx, "GET", "/api/configs/"+url.PathEscape(name)+"/report-lat", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RunTest 运行测试（不含 precheck）
+//
+// POST /api/configs/{name}/run (operator)
+//
+// The server runs it as a job; wait for the result with WaitJob.
+func (c *Client) RunTest(ctx context.Context, name string, body RunRequest) (*Job, error) {
+	var out Job
+	if err := c.do(ctx, "POST", "/api/configs/"+url.PathEscape(name)+"/run", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StartSampler 启动带宽时间序列采样
+//
+// POST /api/configs/{name}/sampler/start (operator)
+func (c *Client) StartSampler(ctx context.Context, name string, body SamplerStartRequest) (*SamplerStatus, error) {
+	var out SamplerStatus
+	if err := c.do(ctx, "POST", "/api/configs/"+url.PathEscape(name)+"/sampler/start", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StopSampler 停止带宽采样
+//
+// POST /api/configs/{name}/sampler/stop (operator)
+func (c *Client) StopSampler(ctx context.Context, name string) (*SamplerStatus, error) {
+	var out SamplerStatus
+	if err := c.do(ctx, "POST", "/api/configs/"+url.PathEscape(name)+"/sampler/stop", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSamplesParams are the optional query parameters of GetSamples
+type GetSamplesParams struct {
+	// RFC3339 时间或 Unix 秒
+	Since string
+}
+
+func (p *GetSamplesParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Since != "" {
+		v.Set("since", p.Since)
+	}
+	return v
+}
+
+// GetSamples 获取带宽采样数据
+//
+// GET /api/configs/{name}/samples (viewer)
+func (c *Client) GetSamples(ctx context.Context, name string, params *GetSamplesParams) (*SamplesResponse, error) {
+	var out SamplesResponse
+	if err := c.do(ctx, "GET", "/api/configs/"+url.PathEscape(name)+"/samples", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ValidateConfig 验证配置文件
+//
+// POST /api/configs/{name}/validate (viewer)
+func (c *Client) ValidateConfig(ctx context.Context, name string) (*ConfigValidation, error) {
+	var out ConfigValidation
+	if err := c.do(ctx, "POST", "/api/configs/"+url.PathEscape(name)+"/validate", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetHCAs 获取 HCA 列表
+//
+// GET /api/dictionary/hcas (viewer)
+func (c *Client) GetHCAs(ctx context.Context) ([]string, error) {
+	var out []string
+	err := c.do(ctx, "GET", "/api/dictionary/hcas", nil, nil, &out)
+	return out, err
+}
+
+// UpdateHCAs 更新 HCA 列表
+//
+// PUT /api/dictionary/hcas (admin)
+func (c *Client) UpdateHCAs(ctx context.Context, body HCAsRequest) ([]string, error) {
+	var out []string
+	err := c.do(ctx, "PUT", "/api/dictionary/hcas", nil, body, &out)
+	return out, err
+}
+
+// GetHostnames 获取主机名列表
+//
+// GET /api/dictionary/hostnames (viewer)
+func (c *Client) GetHostnames(ctx context.Context) ([]string, error) {
+	var out []string
+	err := c.do(ctx, "GET", "/api/dictionary/hostnames", nil, nil, &out)
+	return out, err
+}
+
+// UpdateHostnames 更新主机名列表
+//
+// PUT /api/dictionary/hostnames (admin)
+func (c *Client) UpdateHostnames(ctx context.Context, body HostnamesRequest) ([]string, error) {
+	var out []string
+	err := c.do(ctx, "PUT", "/api/dictionary/hostnames", nil, body, &out)
+	return out, err
+}
+
+// ListJobsParams are the optional query parameters of ListJobs
+type ListJobsParams struct {
+	// 按配置文件名过滤
+	Config string
+	// 按任务类型过滤
+	Type string
+	// 按状态过滤：queued, running, succeeded, failed, cancelled
+	State string
+}
+
+func (p *ListJobsParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Config != "" {
+		v.Set("config", p.Config)
+	}
+	if p.Type != "" {
+		v.Set("type", p.Type)
+	}
+	if p.State != "" {
+		v.Set("state", p.State)
+	}
+	return v
+}
+
+// ListJobs 获取任务列表（不含日志）
+//
+// GET /api/jobs (viewer)
+func (c *Client) ListJobs(ctx context.Context, params *ListJobsParams) ([]Job, error) {
+	var out []Job
+	err := c.do(ctx, "GET", "/api/jobs", params.values(), nil, &out)
+	return out, err
+}
+
+// GetJob 获取任务状态、进度、日志和结果
+//
+// GET /api/jobs/{id} (viewer)
+func (c *Client) GetJob(ctx context.Context, id string) (*Job, error) {
+	var out Job
+	if err := c.do(ctx, "GET", "/api/jobs/"+url.PathEscape(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CancelJob 取消任务
+//
+// POST /api/jobs/{id}/cancel (operator)
+func (c *Client) CancelJob(ctx context.Context, id string) (*Job, error) {
+	var out Job
+	if err := c.do(ctx, "POST", "/api/jobs/"+url.PathEscape(id)+"/cancel", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListRunsParams are the optional query parameters of ListRuns
+type ListRunsParams struct {
+	// 按配置文件名过滤
+	Config string
+	// 按测试类型过滤
+	Type string
+	// 按状态过滤：running, succeeded, failed
+	Status string
+	// 最多返回的条数
+	Limit int
+}
+
+func (p *ListRunsParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Config != "" {
+		v.Set("config", p.Config)
+	}
+	if p.Type != "" {
+		v.Set("type", p.Type)
+	}
+	if p.Status != "" {
+		v.Set("status", p.Status)
+	}
+	if p.Limit != 0 {
+		v.Set("limit", strconv.FormatInt(int64(p.Limit), 10))
+	}
+	return v
+}
+
+// ListRuns 获取运行记录列表
+//
+// GET /api/runs (viewer)
+func (c *Client) ListRuns(ctx context.Context, params *ListRunsParams) ([]Run, error) {
+	var out []Run
+	err := c.do(ctx, "GET", "/api/runs", params.values(), nil, &out)
+	return out, err
+}
+
+// DeleteRunParams are the optional query parameters of DeleteRun
+type DeleteRunParams struct {
+	// 为 true 时也删除运行中的记录
+	Force bool
+}
+
+func (p *DeleteRunParams) values() url.Values {
+	if p == nil {
+		return nil
+	}
+	v := url.Values{}
+	if p.Force {
+		v.Set("force", strconv.FormatBool(p.Force))
+	}
+	return v
+}
+
+// DeleteRun 删除运行记录（运行中的记录返回 409）
+//
+// DELETE /api/runs/{id} (admin)
+func (c *Client) DeleteRun(ctx context.Context, id string, params *DeleteRunParams) (*DeletedRun, error) {
+	var out DeletedRun
+	if err := c.do(ctx, "DELETE", "/api/runs/"+url.PathEscape(id), params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetRun 获取运行记录
+//
+// GET /api/runs/{id} (viewer)
+func (c *Client) GetRun(ctx context.Context, id string) (*Run, error) {
+	var out Run
+	if err := c.do(ctx, "GET", "/api/runs/"+url.PathEscape(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetRunResult 获取运行记录的分析结果（bandwidth、latency、connectivity 等）
+//
+// GET /api/runs/{id}/results/{result} (viewer)
+func (c *Client) GetRunResult(ctx context.Context, id string, result string) (json.RawMessage, error) {
+	var out json.RawMessage
+	err := c.do(ctx, "GET", "/api/runs/"+url.PathEscape(id)+"/results/"+url.PathEscape(result), nil, nil, &out)
+	return out, err
+}
+
+// GetHealth 健康检查
+//
+// GET /health
+func (c *Client) GetHealth(ctx context.Context) (*HealthStatus, error) {
+	var out HealthStatus
+	if err := c.do(ctx, "GET", "/health", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
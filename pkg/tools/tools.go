@@ -18,3 +18,18 @@ func BuildSSHCommand(hostname, remoteCmd, sshKeyPath string, user string) *exec.
 	}
 	return exec.Command("ssh", "-o", "StrictHostKeyChecking=no", "-o", "LogLevel=ERROR", host, remoteCmd)
 }
+
+// BuildSCPCommand builds an scp command copying a local file to a remote path,
+// preserving its mode, with optional user and private key
+func BuildSCPCommand(hostname, localPath, remotePath, sshKeyPath string, user string) *exec.Cmd {
+	host := hostname
+	if user != "" {
+		host = fmt.Sprintf("%s@%s", user, hostname)
+	}
+	target := fmt.Sprintf("%s:%s", host, remotePath)
+
+	if sshKeyPath != "" {
+		return exec.Command("scp", "-p", "-i", sshKeyPath, "-o", "StrictHostKeyChecking=no", "-o", "LogLevel=ERROR", localPath, target)
+	}
+	return exec.Command("scp", "-p", "-o", "StrictHostKeyChecking=no", "-o", "LogLevel=ERROR", localPath, target)
+}
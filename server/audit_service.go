@@ -0,0 +1,70 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"xnetperf/internal/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 审计日志查询默认和最多返回的条数
+const (
+	defaultAuditLimit = 500
+	maxAuditLimit     = 10000
+)
+
+// AuditService 远程命令审计日志查询服务
+type AuditService struct{}
+
+// NewAuditService 创建审计日志查询服务，查询的是 audit.Default() 指向的日志
+func NewAuditService() *AuditService {
+	return &AuditService{}
+}
+
+// QueryAudit 查询审计日志，最新的在前；支持 host/user/job_id/since/until/limit 参数，
+// since 和 until 为 RFC3339 时间或 Unix 秒
+func (s *AuditService) QueryAudit(c *gin.Context) {
+	auditLog := audit.Default()
+	if auditLog == nil {
+		c.JSON(404, Error(404, "审计日志未启用"))
+		return
+	}
+
+	filter := audit.Filter{
+		Host:  c.Query("host"),
+		User:  c.Query("user"),
+		JobID: c.Query("job_id"),
+		Limit: defaultAuditLimit,
+	}
+	for name, target := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if v := c.Query(name); v != "" {
+			t, err := parseSince(v)
+			if err != nil {
+				c.JSON(400, Error(400, fmt.Sprintf("无效的 %s 参数: %s", name, v)))
+				return
+			}
+			*target = t
+		}
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxAuditLimit {
+			c.JSON(400, Error(400, fmt.Sprintf("无效的 limit 参数: %s（取值 1-%d）", v, maxAuditLimit)))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := auditLog.Query(filter)
+	if err != nil {
+		c.JSON(500, Error(500, fmt.Sprintf("读取审计日志失败: %v", err)))
+		return
+	}
+	if entries == nil {
+		entries = []audit.Entry{}
+	}
+	c.JSON(200, Success(entries))
+}
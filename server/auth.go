@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"xnetperf/internal/audit"
+	"xnetperf/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// identityKey 上下文中保存调用者身份的键
+const identityKey = "identity"
+
+// requireRole 返回检查调用者角色的中间件；未配置认证时不做任何检查
+func (s *Server) requireRole(role auth.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.authenticator == nil {
+			c.Next()
+			return
+		}
+
+		identity, err := s.authenticator.Authenticate(c.Request)
+		if err != nil {
+			for _, challenge := range s.challenges() {
+				c.Writer.Header().Add("WWW-Authenticate", challenge)
+			}
+			message := "未提供认证信息"
+			if errors.Is(err, auth.ErrInvalidCredentials) {
+				message = "认证失败"
+			}
+			c.AbortWithStatusJSON(401, Error(401, message))
+			return
+		}
+
+		if !identity.Role.Allows(role) {
+			c.AbortWithStatusJSON(403, Error(403, "权限不足，需要 "+role.String()+" 角色"))
+			return
+		}
+
+		c.Set(identityKey, identity)
+		c.Next()
+	}
+}
+
+// challenges 返回 401 响应中声明的认证方式
+func (s *Server) challenges() []string {
+	if chain, ok := s.authenticator.(auth.Chain); ok {
+		return chain.Challenges()
+	}
+	if challenge := s.authenticator.Challenge(); challenge != "" {
+		return []string{challenge}
+	}
+	return nil
+}
+
+// CallerIdentity 当前调用者的身份和角色
+type CallerIdentity struct {
+	AuthEnabled bool   `json:"auth_enabled"`
+	Subject     string `json:"subject,omitempty"`
+	Role        string `json:"role"`             // viewer, operator 或 admin
+	Method      string `json:"method,omitempty"` // token, basic, oidc 或 cert
+}
+
+// GetIdentity 获取当前调用者的身份和角色
+func (s *Server) GetIdentity(c *gin.Context) {
+	value, ok := c.Get(identityKey)
+	if !ok {
+		// 未配置认证时所有调用者都拥有全部权限
+		c.JSON(200, Success(CallerIdentity{AuthEnabled: false, Role: auth.RoleAdmin.String()}))
+		return
+	}
+
+	identity := value.(*auth.Identity)
+	c.JSON(200, Success(CallerIdentity{
+		AuthEnabled: true,
+		Subject:     identity.Subject,
+		Role:        identity.Role.String(),
+		Method:      identity.Method,
+	}))
+}
+
+// actorContext 返回以请求调用者为审计发起者的 context。
+// 它不随请求结束而取消，可以用于在请求返回后继续运行的采样等任务
+func actorContext(c *gin.Context) context.Context {
+	return audit.WithActor(context.Background(), requestActor(c))
+}
+
+// requestActor 返回审计日志中记录的调用者；未启用认证时记录为 anonymous
+func requestActor(c *gin.Context) audit.Actor {
+	actor := audit.Actor{User: "anonymous", Client: c.ClientIP()}
+	if value, ok := c.Get(identityKey); ok {
+		identity := value.(*auth.Identity)
+		actor.User = identity.Subject
+		actor.Auth = identity.Method
+	}
+	return actor
+}
@@ -1,12 +1,16 @@
 package server
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"xnetperf/config"
+	"xnetperf/internal/events"
+	"xnetperf/internal/jobs"
 	"xnetperf/internal/script"
 	"xnetperf/internal/service/analyze"
 	"xnetperf/internal/service/collect"
@@ -15,6 +19,9 @@ import (
 	"xnetperf/internal/service/precheck"
 	"xnetperf/internal/service/probe"
 	runnerservice "xnetperf/internal/service/runner"
+	"xnetperf/internal/service/workflow"
+	"xnetperf/internal/store"
+	"xnetperf/pkg/tools/logger"
 
 	"github.com/gin-gonic/gin"
 	"gopkg.in/yaml.v3"
@@ -33,13 +40,45 @@ type ConfigFileInfo struct {
 	IsDeletable bool   `json:"is_deletable"` // 是否可删除
 }
 
+// ConfigPreview 配置文件原始内容
+type ConfigPreview struct {
+	YAML string `json:"yaml"`
+}
+
+// CreateConfigRequest 创建配置文件请求
+type CreateConfigRequest struct {
+	Name   string        `json:"name" binding:"required"` // 文件名，以 .yaml 或 .yml 结尾
+	Config config.Config `json:"config" binding:"required"`
+}
+
+// ConfigValidation 配置文件验证结果：通过时返回解析后的配置，未通过时返回错误列表
+type ConfigValidation struct {
+	Valid  bool           `json:"valid"`
+	Errors []string       `json:"errors,omitempty"`
+	Config *config.Config `json:"config,omitempty"`
+}
+
+// RunRequest 运行测试请求
+type RunRequest struct {
+	TestType string `json:"test_type" form:"test_type"` // bandwidth, latency, connectivity，默认 bandwidth
+}
+
 // ConfigService 配置文件服务
 type ConfigService struct {
+	runStore *store.Store
+	jobs     *jobs.Manager
+	metrics  *Metrics
+	logger   *slog.Logger
 }
 
 // NewConfigService 创建配置文件服务
-func NewConfigService() *ConfigService {
-	return &ConfigService{}
+func NewConfigService(runStore *store.Store, jobManager *jobs.Manager, metrics *Metrics) *ConfigService {
+	return &ConfigService{
+		runStore: runStore,
+		jobs:     jobManager,
+		metrics:  metrics,
+		logger:   logger.GetLogger().With("module", "CONFIG"),
+	}
 }
 
 // ListConfigs 获取配置文件列表
@@ -121,22 +160,24 @@ func (s *ConfigService) PreviewConfig(c *gin.Context) {
 		filePath = filepath.Join(ConfigsDir, name)
 	}
 
-	// 读取配置文件原始内容
-	data, err := os.ReadFile(filePath)
+	// 不返回原始文件内容：metrics_push 的请求头和 URL 中可能有凭据，查看者角色不应看到
+	cfg, err := config.LoadConfig(filePath)
 	if err != nil {
 		c.JSON(404, Error(404, fmt.Sprintf("配置文件不存在或读取失败: %v", err)))
 		return
 	}
+	data, err := yaml.Marshal(cfg.Redacted())
+	if err != nil {
+		c.JSON(500, Error(500, fmt.Sprintf("配置序列化失败: %v", err)))
+		return
+	}
 
-	c.JSON(200, Success(gin.H{"yaml": string(data)}))
+	c.JSON(200, Success(ConfigPreview{YAML: string(data)}))
 }
 
 // CreateConfig 创建新配置文件
 func (s *ConfigService) CreateConfig(c *gin.Context) {
-	var req struct {
-		Name   string        `json:"name" binding:"required"`
-		Config config.Config `json:"config" binding:"required"`
-	}
+	var req CreateConfigRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(400, Error(400, fmt.Sprintf("请求参数错误: %v", err)))
@@ -157,6 +198,10 @@ func (s *ConfigService) CreateConfig(c *gin.Context) {
 
 	// 应用默认值到未指定的字段
 	req.Config.ApplyDefaults()
+	if err := req.Config.Precheck.Validate(); err != nil {
+		c.JSON(400, Error(400, fmt.Sprintf("配置校验失败: %v", err)))
+		return
+	}
 
 	// 确保 configs 目录存在
 	if err := os.MkdirAll(ConfigsDir, 0755); err != nil {
@@ -209,6 +254,10 @@ func (s *ConfigService) UpdateConfig(c *gin.Context) {
 
 	// 应用默认值到未指定的字段
 	cfg.ApplyDefaults()
+	if err := cfg.Precheck.Validate(); err != nil {
+		c.JSON(400, Error(400, fmt.Sprintf("配置校验失败: %v", err)))
+		return
+	}
 
 	// 构建文件路径
 	var filePath string
@@ -224,6 +273,16 @@ func (s *ConfigService) UpdateConfig(c *gin.Context) {
 		return
 	}
 
+	// http_server 和 metrics_push 配置不通过 API 暴露，更新时保留文件中原有的设置；
+	// 原文件不经过校验读取，这样修正无效的 precheck 设置时也不会丢失它们
+	if data, err := os.ReadFile(filePath); err == nil {
+		var existing config.Config
+		if err := yaml.Unmarshal(data, &existing); err == nil {
+			cfg.HTTPServer = existing.HTTPServer
+			cfg.MetricsPush = existing.MetricsPush
+		}
+	}
+
 	// 将配置写入文件
 	data, err := yaml.Marshal(&cfg)
 	if err != nil {
@@ -334,6 +393,11 @@ func (s *ConfigService) ValidateConfig(c *gin.Context) {
 		validationErrors = append(validationErrors, fmt.Sprintf("start_port 必须在 1-65535 之间，当前值: %d", cfg.StartPort))
 	}
 
+	// 检查 HCA 端口
+	if cfg.IBPort < 0 {
+		validationErrors = append(validationErrors, fmt.Sprintf("ib_port 不能为负数，当前值: %d", cfg.IBPort))
+	}
+
 	// 检查队列对数量
 	if cfg.QpNum <= 0 {
 		validationErrors = append(validationErrors, fmt.Sprintf("qp_num 必须大于 0，当前值: %d", cfg.QpNum))
@@ -361,29 +425,15 @@ func (s *ConfigService) ValidateConfig(c *gin.Context) {
 
 	// 如果有验证错误，返回错误信息
 	if len(validationErrors) > 0 {
-		c.JSON(400, gin.H{
-			"code":    400,
-			"message": "配置文件验证失败",
-			"data": gin.H{
-				"valid":  false,
-				"errors": validationErrors,
-			},
-		})
+		c.JSON(400, ErrorWithData(400, "配置文件验证失败", ConfigValidation{Valid: false, Errors: validationErrors}))
 		return
 	}
 
 	// 验证成功，返回配置信息
-	c.JSON(200, gin.H{
-		"code":    0,
-		"message": "配置文件验证成功",
-		"data": gin.H{
-			"valid":  true,
-			"config": cfg,
-		},
-	})
+	c.JSON(200, SuccessWithMessage("配置文件验证成功", ConfigValidation{Valid: true, Config: cfg}))
 }
 
-// PrecheckConfig 执行配置文件的 precheck 检查
+// PrecheckConfig 执行配置文件的 precheck 检查，以异步任务方式执行
 func (s *ConfigService) PrecheckConfig(c *gin.Context) {
 	name := c.Param("name")
 	if name == "" {
@@ -412,19 +462,20 @@ func (s *ConfigService) PrecheckConfig(c *gin.Context) {
 		return
 	}
 
-	// 执行 precheck
-	checker := precheck.New(cfg)
-	summary, err := checker.DoCheckForAPI(cfg)
-	if err != nil {
-		c.JSON(500, Error(500, fmt.Sprintf("Precheck 执行失败: %v", err)))
-		return
-	}
-
-	// 返回检查结果
-	c.JSON(200, Success(summary))
+	// 异步执行 precheck
+	s.submitJob(c, JobTypePrecheck, name, func(ctx context.Context, job *jobs.Job) (any, error) {
+		job.SetProgress(10, "Checking HCAs on all hosts")
+		checker := precheck.New(cfg).WithContext(ctx).WithCache(precheck.LoadCache(s.runStore), false)
+		summary, err := checker.DoCheckForAPI(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("Precheck 执行失败: %w", err)
+		}
+		s.metrics.ObserveResult(name, summary)
+		return summary, nil
+	})
 }
 
-// RunTest 运行测试（不包含 precheck）
+// RunTest 运行测试（不包含 precheck），以异步任务方式执行
 func (s *ConfigService) RunTest(c *gin.Context) {
 	name := c.Param("name")
 	if name == "" {
@@ -433,9 +484,7 @@ func (s *ConfigService) RunTest(c *gin.Context) {
 	}
 
 	// 获取请求参数
-	var req struct {
-		TestType string `json:"test_type" form:"test_type"` // bandwidth, latency, connectivity
-	}
+	var req RunRequest
 
 	// 尝试绑定 JSON 或 query 参数
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -483,16 +532,20 @@ func (s *ConfigService) RunTest(c *gin.Context) {
 		return
 	}
 
-	// 执行测试
-	runner := runnerservice.New(cfg)
-	result, err := runner.RunAndGetResult(testType)
-	if err != nil {
-		c.JSON(500, Error(500, fmt.Sprintf("测试运行失败: %v", err)))
-		return
-	}
-
-	// 返回结果
-	c.JSON(200, Success(result))
+	// 异步执行测试，脚本保存到本次运行的目录
+	s.submitJob(c, JobTypeRun, name, func(ctx context.Context, job *jobs.Job) (any, error) {
+		run := s.startRun(name, testType.String(), cfg)
+		job.SetRunID(runID(run))
+		job.SetProgress(10, fmt.Sprintf("Starting %s test", testType))
+		runner := runnerservice.New(cfg).WithScriptsDir(store.ScriptsPath(s.runDir(run))).WithEvents(job).WithContext(ctx)
+		result, err := runner.RunAndGetResult(testType)
+		if err != nil {
+			s.failRun(run, err)
+			return nil, fmt.Errorf("测试运行失败: %w", err)
+		}
+		result.RunID = runID(run)
+		return result, nil
+	})
 }
 
 // ProbeTest 探测测试状态
@@ -525,7 +578,7 @@ func (s *ConfigService) ProbeTest(c *gin.Context) {
 	}
 
 	// 执行探测
-	prober := probe.New(cfg)
+	prober := probe.New(cfg).WithContext(actorContext(c))
 	summary, err := prober.DoProbeAndGetSummary()
 	if err != nil {
 		c.JSON(500, Error(500, fmt.Sprintf("探测执行失败: %v", err)))
@@ -536,7 +589,7 @@ func (s *ConfigService) ProbeTest(c *gin.Context) {
 	c.JSON(200, Success(summary))
 }
 
-// CollectReports 收集测试报告
+// CollectReports 收集测试报告，以异步任务方式执行
 func (s *ConfigService) CollectReports(c *gin.Context) {
 	name := c.Param("name")
 	if name == "" {
@@ -565,16 +618,28 @@ func (s *ConfigService) CollectReports(c *gin.Context) {
 		return
 	}
 
-	// 执行收集
-	collector := collect.New(cfg)
-	result, err := collector.CollectAndGetResult(cfg)
-	if err != nil {
-		c.JSON(500, Error(500, fmt.Sprintf("报告收集失败: %v", err)))
+	// 收集到运行中的记录的目录，没有运行中的记录时新建一条
+	run, ok := s.findRun(c, name, "", store.StatusRunning)
+	if !ok {
 		return
 	}
+	if run == nil {
+		run = s.startRun(name, c.DefaultQuery("test_type", script.TestTypeBandwidth.String()), cfg)
+	}
 
-	// 返回结果
-	c.JSON(200, Success(result))
+	// 异步执行收集
+	s.submitJob(c, JobTypeCollect, name, func(ctx context.Context, job *jobs.Job) (any, error) {
+		job.SetRunID(runID(run))
+		job.SetProgress(10, "Collecting report files from all hosts")
+		collector := collect.New(cfg).WithEvents(job).WithContext(ctx)
+		result, err := collector.CollectAndGetResult(cfg, store.ReportsPath(s.runDir(run)))
+		if err != nil {
+			return nil, fmt.Errorf("报告收集失败: %w", err)
+		}
+		s.indexReports(run)
+		result.RunID = runID(run)
+		return result, nil
+	})
 }
 
 // GetReport 获取性能报告
@@ -607,12 +672,18 @@ func (s *ConfigService) GetReport(c *gin.Context) {
 	}
 
 	// 生成报告
-	analyzeer := analyze.New(cfg)
-	report, err := analyzeer.GenerateReport()
+	run, ok := s.findRun(c, name, script.TestTypeBandwidth.String(), "")
+	if !ok {
+		return
+	}
+	analyzeer := analyze.New(cfg).WithContext(actorContext(c))
+	report, err := analyzeer.GenerateReport(store.ReportsPath(s.runDir(run)))
 	if err != nil {
 		c.JSON(500, Error(500, fmt.Sprintf("报告生成失败: %v", err)))
 		return
 	}
+	s.finishRun(run, script.TestTypeBandwidth.String(), report)
+	s.metrics.ObserveResult(name, report)
 
 	// 返回结果
 	c.JSON(200, Success(report))
@@ -648,7 +719,7 @@ func (s *ConfigService) ProbeLatencyTest(c *gin.Context) {
 	}
 
 	// 执行延迟探测
-	latRunner := lat.New(cfg)
+	latRunner := lat.New(cfg).WithContext(actorContext(c))
 	summary, err := latRunner.DoLatencyProbeAndGetSummary()
 	if err != nil {
 		c.JSON(500, Error(500, fmt.Sprintf("延迟探测执行失败: %v", err)))
@@ -689,18 +760,24 @@ func (s *ConfigService) GetLatencyReport(c *gin.Context) {
 	}
 
 	// 生成延迟报告
-	latRunner := lat.New(cfg)
-	report, err := latRunner.GenerateLatencyReport()
+	run, ok := s.findRun(c, name, script.TestTypeLatency.String(), "")
+	if !ok {
+		return
+	}
+	latRunner := lat.New(cfg).WithContext(actorContext(c))
+	report, err := latRunner.GenerateLatencyReport(store.ReportsPath(s.runDir(run)))
 	if err != nil {
 		c.JSON(500, Error(500, fmt.Sprintf("延迟报告生成失败: %v", err)))
 		return
 	}
+	s.finishRun(run, script.TestTypeLatency.String(), report)
+	s.metrics.ObserveResult(name, report)
 
 	// 返回结果
 	c.JSON(200, Success(report))
 }
 
-// CheckConnectivity 检查网络连通性
+// CheckConnectivity 检查网络连通性，以异步任务方式执行
 func (s *ConfigService) CheckConnectivity(c *gin.Context) {
 	name := c.Param("name")
 	if name == "" {
@@ -729,14 +806,126 @@ func (s *ConfigService) CheckConnectivity(c *gin.Context) {
 		return
 	}
 
-	// 执行连通性检查
-	checker := connectivity.New(cfg)
-	summary, err := checker.CheckConnectivity()
+	// 异步执行连通性检查
+	s.submitJob(c, JobTypeConnectivity, name, func(ctx context.Context, job *jobs.Job) (any, error) {
+		run := s.startRun(name, script.TestTypeConnectivity.String(), cfg)
+		job.SetRunID(runID(run))
+		job.SetProgress(10, "Checking connectivity in both directions")
+		checker := connectivity.New(cfg).WithRunDir(s.runDir(run)).WithEvents(job).WithContext(ctx)
+		summary, err := checker.CheckConnectivity()
+		if err != nil {
+			s.failRun(run, err)
+			return nil, fmt.Errorf("连通性检查失败: %w", err)
+		}
+		s.indexReports(run)
+		s.finishRun(run, script.TestTypeConnectivity.String(), summary)
+		return summary, nil
+	})
+}
+
+// ExecuteRequest 完整测试流程请求
+type ExecuteRequest struct {
+	TestType string `json:"test_type"` // bandwidth, latency, connectivity，默认 bandwidth
+	Precheck bool   `json:"precheck"`  // 是否先执行 precheck，未通过时不启动测试
+}
+
+// ExecuteWorkflow 在服务端执行完整测试流程（precheck -> run -> probe -> collect -> analyze），以异步任务方式执行
+func (s *ConfigService) ExecuteWorkflow(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(400, Error(400, "配置文件名不能为空"))
+		return
+	}
+
+	var req ExecuteRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, Error(400, fmt.Sprintf("请求参数错误: %v", err)))
+			return
+		}
+	}
+	if req.TestType == "" {
+		req.TestType = c.DefaultQuery("test_type", "bandwidth")
+	}
+
+	// 验证 test_type 参数
+	var testType script.TestType
+	switch req.TestType {
+	case "bandwidth":
+		testType = script.TestTypeBandwidth
+	case "latency":
+		testType = script.TestTypeLatency
+	case "connectivity":
+		testType = script.TestTypeConnectivity
+	default:
+		c.JSON(400, Error(400, fmt.Sprintf("无效的 test_type 参数: %s，可选值: bandwidth, latency, connectivity", req.TestType)))
+		return
+	}
+
+	// 构建文件路径
+	var filePath string
+	if name == DefaultConfigFile {
+		filePath = DefaultConfigFile
+	} else {
+		filePath = filepath.Join(ConfigsDir, name)
+	}
+
+	// 检查文件是否存在
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		c.JSON(404, Error(404, "配置文件不存在"))
+		return
+	}
+
+	// 加载配置文件
+	cfg, err := config.LoadConfig(filePath)
 	if err != nil {
-		c.JSON(500, Error(500, fmt.Sprintf("连通性检查失败: %v", err)))
+		c.JSON(400, Error(400, fmt.Sprintf("配置文件解析失败: %v", err)))
+		return
+	}
+	if err := workflow.Validate(cfg, testType); err != nil {
+		c.JSON(400, Error(400, fmt.Sprintf("该配置无法执行完整测试流程: %v", err)))
 		return
 	}
 
-	// 返回结果
-	c.JSON(200, Success(summary))
+	// 异步执行完整流程，脚本和报告写入本次运行的目录
+	s.submitJob(c, JobTypeExecute, name, func(ctx context.Context, job *jobs.Job) (any, error) {
+		run := s.startRun(name, testType.String(), cfg)
+		job.SetRunID(runID(run))
+
+		wf := workflow.New(cfg, testType).
+			WithRunDir(s.runDir(run)).
+			WithPrecheck(req.Precheck).
+			WithPrecheckCache(precheck.LoadCache(s.runStore)).
+			WithProgress(job.SetProgress)
+		wf.WithEvents(&workflowPublisher{job: job, workflow: wf})
+
+		result, err := wf.Run(ctx)
+		result.RunID = runID(run)
+		s.indexReports(run)
+		s.metrics.ObserveResult(name, result)
+		if err != nil {
+			s.failRun(run, err)
+			return result, fmt.Errorf("测试流程执行失败: %w", err)
+		}
+		if result.Report != nil {
+			s.finishRun(run, testType.String(), result.Report)
+		} else {
+			s.finishRun(run, "workflow", result)
+		}
+		return result, nil
+	})
+}
+
+// workflowPublisher 把流程事件转发到任务，并在步骤状态变化时更新任务的中间结果，
+// 这样 GET /api/jobs/:id 在流程执行期间也能看到每个步骤的状态
+type workflowPublisher struct {
+	job      *jobs.Job
+	workflow *workflow.Workflow
+}
+
+func (p *workflowPublisher) Publish(e events.Event) {
+	if e.Type == events.TypeStep && e.Data["step"] != nil {
+		p.job.SetResult(p.workflow.Result())
+	}
+	p.job.Publish(e)
 }
@@ -16,6 +16,16 @@ const (
 	HCAFile       = "hcas.txt"
 )
 
+// HostnamesRequest 更新主机名列表请求
+type HostnamesRequest struct {
+	Hostnames []string `json:"hostnames" binding:"required"`
+}
+
+// HCAsRequest 更新 HCA 列表请求
+type HCAsRequest struct {
+	HCAs []string `json:"hcas" binding:"required"`
+}
+
 // DictionaryService 字典服务
 type DictionaryService struct{}
 
@@ -95,9 +105,7 @@ func (s *DictionaryService) GetHostnames(c *gin.Context) {
 
 // UpdateHostnames 更新主机名列表
 func (s *DictionaryService) UpdateHostnames(c *gin.Context) {
-	var req struct {
-		Hostnames []string `json:"hostnames" binding:"required"`
-	}
+	var req HostnamesRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(400, Error(400, fmt.Sprintf("请求参数错误: %v", err)))
@@ -136,9 +144,7 @@ func (s *DictionaryService) GetHCAs(c *gin.Context) {
 
 // UpdateHCAs 更新 HCA 列表
 func (s *DictionaryService) UpdateHCAs(c *gin.Context) {
-	var req struct {
-		HCAs []string `json:"hcas" binding:"required"`
-	}
+	var req HCAsRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(400, Error(400, fmt.Sprintf("请求参数错误: %v", err)))
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"xnetperf/internal/events"
+	"xnetperf/internal/jobs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 异步任务类型
+const (
+	JobTypePrecheck     = "precheck"
+	JobTypeRun          = "run"
+	JobTypeCollect      = "collect"
+	JobTypeConnectivity = "connectivity"
+	JobTypeExecute      = "execute"
+)
+
+// JobService 异步任务查询服务
+type JobService struct {
+	jobs *jobs.Manager
+}
+
+// NewJobService 创建异步任务查询服务
+func NewJobService(jobManager *jobs.Manager) *JobService {
+	return &JobService{jobs: jobManager}
+}
+
+// ListJobs 获取任务列表（不含日志），支持 config/type/state 查询参数
+func (s *JobService) ListJobs(c *gin.Context) {
+	infos := s.jobs.List(jobs.Filter{
+		ConfigName: c.Query("config"),
+		Type:       c.Query("type"),
+		State:      jobs.State(c.Query("state")),
+	})
+	if infos == nil {
+		infos = []jobs.Info{}
+	}
+
+	c.JSON(200, Success(infos))
+}
+
+// GetJob 获取任务状态、进度、日志和结果
+func (s *JobService) GetJob(c *gin.Context) {
+	job, err := s.jobs.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(404, Error(404, fmt.Sprintf("任务不存在: %s", c.Param("id"))))
+		return
+	}
+
+	c.JSON(200, Success(job.Info()))
+}
+
+// sseKeepAlive 事件流空闲时发送注释行的间隔，避免代理断开连接
+const sseKeepAlive = 15 * time.Second
+
+// StreamJobEvents 以 Server-Sent Events 推送任务事件：先回放已有事件，再推送新事件，任务结束后关闭。
+// 断线重连时通过 Last-Event-ID 请求头（或 after 查询参数）只获取之后的事件。
+func (s *JobService) StreamJobEvents(c *gin.Context) {
+	job, err := s.jobs.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(404, Error(404, fmt.Sprintf("任务不存在: %s", c.Param("id"))))
+		return
+	}
+
+	after := c.GetHeader("Last-Event-ID")
+	if after == "" {
+		after = c.Query("after")
+	}
+	var afterSeq int64
+	if after != "" {
+		afterSeq, err = strconv.ParseInt(after, 10, 64)
+		if err != nil {
+			c.JSON(400, Error(400, fmt.Sprintf("无效的事件 ID: %s", after)))
+			return
+		}
+	}
+
+	replay, ch, cancel := job.Events().Subscribe(afterSeq)
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(200)
+
+	for _, e := range replay {
+		writeSSE(c, e)
+	}
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(sseKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			writeSSE(c, e)
+			c.Writer.Flush()
+		case <-ticker.C:
+			fmt.Fprint(c.Writer, ": keep-alive\n\n")
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// writeSSE 写出一条事件，事件名为事件类型，数据为 JSON
+func writeSSE(c *gin.Context, e events.Event) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", e.Seq, e.Type, data)
+}
+
+// CancelJob 取消排队中或运行中的任务
+func (s *JobService) CancelJob(c *gin.Context) {
+	id := c.Param("id")
+	err := s.jobs.Cancel(id)
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		c.JSON(404, Error(404, fmt.Sprintf("任务不存在: %s", id)))
+		return
+	case errors.Is(err, jobs.ErrFinished):
+		c.JSON(409, Error(409, "任务已结束，无法取消"))
+		return
+	case err != nil:
+		c.JSON(500, Error(500, fmt.Sprintf("取消任务失败: %v", err)))
+		return
+	}
+
+	job, _ := s.jobs.Get(id)
+	c.JSON(200, SuccessWithMessage("任务取消请求已提交", job.Info()))
+}
+
+// submitJob 提交异步任务并返回 202 和任务信息。
+// 请求带 wait=true 参数时等待任务结束，直接返回任务结果（兼容原来的同步调用方式）。
+func (s *ConfigService) submitJob(c *gin.Context, jobType, configName string, fn jobs.Func) {
+	job, err := s.jobs.SubmitAs(requestActor(c), jobType, configName, fn)
+	if err != nil {
+		if errors.Is(err, jobs.ErrQueueFull) {
+			c.JSON(503, Error(503, "任务队列已满，请稍后重试"))
+			return
+		}
+		c.JSON(500, Error(500, fmt.Sprintf("提交任务失败: %v", err)))
+		return
+	}
+
+	if c.Query("wait") != "true" {
+		c.JSON(202, SuccessWithMessage("任务已提交", job.Info()))
+		return
+	}
+
+	select {
+	case <-job.Done():
+	case <-c.Request.Context().Done():
+		// 客户端断开，任务继续在后台执行
+		return
+	}
+
+	info := job.Info()
+	if info.State != jobs.StateSucceeded {
+		c.JSON(500, ErrorWithData(500, info.Error, gin.H{"job_id": info.ID, "state": info.State}))
+		return
+	}
+	c.JSON(200, Success(info.Result))
+}
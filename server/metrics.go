@@ -0,0 +1,140 @@
+package server
+
+import (
+	"net/http"
+
+	"xnetperf/internal/audit"
+	"xnetperf/internal/exporter"
+	"xnetperf/internal/jobs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// durationBuckets 是远程命令和任务耗时的直方图桶（秒）
+var durationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300, 900}
+
+// Metrics 服务器的 Prometheus 指标：进程、异步任务、远程命令，以及每个配置文件最近一次的
+// 带宽、延迟和 precheck 结果
+type Metrics struct {
+	handler http.Handler
+
+	jobsFinished *prometheus.CounterVec
+	jobDuration  *prometheus.HistogramVec
+	sshDuration  *prometheus.HistogramVec
+	sshFailures  *prometheus.CounterVec
+
+	results *exporter.Results
+}
+
+// NewMetrics 创建指标并注册到任务管理器和远程命令审计
+func NewMetrics(jobManager *jobs.Manager) *Metrics {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewGoCollector(),
+		&activeJobsCollector{jobManager: jobManager},
+	)
+	factory := promauto.With(registry)
+
+	m := &Metrics{
+		handler: promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+
+		jobsFinished: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "xnetperf_jobs_finished_total",
+			Help: "Number of finished server jobs by type and final state.",
+		}, []string{"type", "state"}),
+		jobDuration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "xnetperf_job_duration_seconds",
+			Help:    "Run time of server jobs from start to finish.",
+			Buckets: durationBuckets,
+		}, []string{"type", "state"}),
+		sshDuration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "xnetperf_ssh_command_duration_seconds",
+			Help:    "Duration of remote commands run over SSH or scp, per host.",
+			Buckets: durationBuckets,
+		}, []string{"host"}),
+		sshFailures: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "xnetperf_ssh_command_failures_total",
+			Help: "Number of remote commands that failed or exited non-zero, per host.",
+		}, []string{"host"}),
+
+		results: exporter.NewResults(registry),
+	}
+
+	if jobManager != nil {
+		jobManager.OnFinish(m.observeJob)
+	}
+	audit.AddObserver(m.observeCommand)
+	return m
+}
+
+// ServeMetrics 以 Prometheus 文本格式输出所有指标
+func (m *Metrics) ServeMetrics(c *gin.Context) {
+	m.handler.ServeHTTP(c.Writer, c.Request)
+}
+
+// activeJobsCollector 在抓取时统计排队和运行中的任务
+type activeJobsCollector struct {
+	jobManager *jobs.Manager
+}
+
+var activeJobsDesc = prometheus.NewDesc("xnetperf_jobs",
+	"Number of queued and running server jobs by type.", []string{"type", "state"}, nil)
+
+// Describe 实现 prometheus.Collector
+func (c *activeJobsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeJobsDesc
+}
+
+// Collect 实现 prometheus.Collector
+func (c *activeJobsCollector) Collect(ch chan<- prometheus.Metric) {
+	if c.jobManager == nil {
+		return
+	}
+
+	counts := make(map[[2]string]int)
+	for _, state := range []jobs.State{jobs.StateQueued, jobs.StateRunning} {
+		for _, jobType := range []string{JobTypePrecheck, JobTypeRun, JobTypeCollect, JobTypeConnectivity, JobTypeExecute} {
+			counts[[2]string{jobType, string(state)}] = 0
+		}
+	}
+	for _, info := range c.jobManager.List(jobs.Filter{}) {
+		if !info.State.Finished() {
+			counts[[2]string{info.Type, string(info.State)}]++
+		}
+	}
+	for key, count := range counts {
+		ch <- prometheus.MustNewConstMetric(activeJobsDesc, prometheus.GaugeValue, float64(count), key[0], key[1])
+	}
+}
+
+func (m *Metrics) observeJob(info jobs.Info) {
+	m.jobsFinished.WithLabelValues(info.Type, string(info.State)).Inc()
+	// 排队时被取消的任务没有运行时间
+	if info.StartedAt != nil && info.FinishedAt != nil {
+		m.jobDuration.WithLabelValues(info.Type, string(info.State)).Observe(info.FinishedAt.Sub(*info.StartedAt).Seconds())
+	}
+}
+
+func (m *Metrics) observeCommand(e audit.Entry) {
+	if e.ExitCode != 0 {
+		m.sshFailures.WithLabelValues(e.Host).Inc()
+	}
+	// 后台命令只记录了启动耗时
+	if !e.Background {
+		m.sshDuration.WithLabelValues(e.Host).Observe(float64(e.DurationMs) / 1000)
+	}
+}
+
+// ObserveResult 用测试结果更新结果指标，同一配置文件上一次的同类结果被替换；
+// 支持 precheck 汇总、带宽报告、延迟报告和完整流程结果
+func (m *Metrics) ObserveResult(configName string, result any) {
+	if m == nil {
+		return
+	}
+	m.results.Observe(configName, result)
+}
@@ -0,0 +1,338 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"mime"
+	"path"
+	"sort"
+	"strings"
+
+	"xnetperf/config"
+	"xnetperf/internal/audit"
+	"xnetperf/internal/auth"
+	"xnetperf/internal/events"
+	"xnetperf/internal/jobs"
+	"xnetperf/internal/openapi"
+	"xnetperf/internal/service/analyze"
+	"xnetperf/internal/service/collect"
+	"xnetperf/internal/service/connectivity"
+	"xnetperf/internal/service/lat"
+	"xnetperf/internal/service/precheck"
+	"xnetperf/internal/service/probe"
+	runnerservice "xnetperf/internal/service/runner"
+	"xnetperf/internal/service/sampler"
+	"xnetperf/internal/service/workflow"
+	"xnetperf/internal/store"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// apiParam 查询参数
+type apiParam struct {
+	name        string
+	typ         string // string, integer 或 boolean
+	description string
+}
+
+// apiOperation 路由的 OpenAPI 描述：请求体和响应 data 字段的 Go 类型用于生成 schema
+type apiOperation struct {
+	id      string
+	summary string
+	tag     string
+	role    auth.Role // auth.RoleNone 表示无需认证
+	query   []apiParam
+	body    any // 请求体，nil 表示没有
+	data    any // 成功响应中 data 字段的类型，nil 表示没有数据
+	async   bool
+	raw     string // 不使用统一响应结构时的响应类型，如 text/event-stream
+}
+
+// 查询参数
+var (
+	waitParam   = apiParam{"wait", "boolean", "为 true 时等待任务结束，直接返回任务结果"}
+	configParam = apiParam{"config", "string", "按配置文件名过滤"}
+	sinceParam  = apiParam{"since", "string", "RFC3339 时间或 Unix 秒"}
+)
+
+// apiOperations 所有路由的描述，键为 "方法 路径"。新增路由时需要在这里添加描述，
+// 否则 internal/openapi/clientgen 的 TestOpenAPICoversRoutes 失败
+var apiOperations = map[string]apiOperation{
+	"GET /api/auth/me": {id: "getIdentity", summary: "获取当前调用者的身份和角色", tag: "auth", role: auth.RoleViewer,
+		data: CallerIdentity{}},
+
+	"GET /api/configs": {id: "listConfigs", summary: "获取配置文件列表", tag: "configs", role: auth.RoleViewer,
+		data: []ConfigFileInfo{}},
+	"GET /api/configs/:name": {id: "getConfig", summary: "获取指定配置文件", tag: "configs", role: auth.RoleViewer,
+		data: config.Config{}},
+	"GET /api/configs/:name/preview": {id: "previewConfig", summary: "预览配置文件（YAML 格式）", tag: "configs", role: auth.RoleViewer,
+		data: ConfigPreview{}},
+	"POST /api/configs": {id: "createConfig", summary: "创建配置文件", tag: "configs", role: auth.RoleAdmin,
+		body: CreateConfigRequest{}, data: ConfigFileInfo{}},
+	"PUT /api/configs/:name": {id: "updateConfig", summary: "更新配置文件", tag: "configs", role: auth.RoleAdmin,
+		body: config.Config{}},
+	"DELETE /api/configs/:name": {id: "deleteConfig", summary: "删除配置文件", tag: "configs", role: auth.RoleAdmin},
+	"POST /api/configs/:name/validate": {id: "validateConfig", summary: "验证配置文件", tag: "configs", role: auth.RoleViewer,
+		data: ConfigValidation{}},
+
+	"POST /api/configs/:name/precheck": {id: "precheckConfig", summary: "执行 precheck 检查", tag: "tests", role: auth.RoleOperator,
+		data: precheck.PrecheckSummary{}, async: true},
+	"POST /api/configs/:name/run": {id: "runTest", summary: "运行测试（不含 precheck）", tag: "tests", role: auth.RoleOperator,
+		body: RunRequest{}, data: runnerservice.RunResult{}, async: true},
+	"POST /api/configs/:name/probe": {id: "probeTest", summary: "探测带宽测试进程 (ib_write_bw)", tag: "tests", role: auth.RoleOperator,
+		data: probe.ProbeSummary{}},
+	"POST /api/configs/:name/probe-lat": {id: "probeLatencyTest", summary: "探测延迟测试进程 (ib_write_lat)", tag: "tests", role: auth.RoleOperator,
+		data: lat.LatencyProbeSummary{}},
+	"POST /api/configs/:name/collect": {id: "collectReports", summary: "收集报告", tag: "tests", role: auth.RoleOperator,
+		query: []apiParam{{"run_id", "string", "收集到该运行记录，默认使用运行中的记录"}, {"test_type", "string", "没有运行中的记录时新建记录的测试类型，默认 bandwidth"}},
+		data:  collect.CollectResult{}, async: true},
+	"GET /api/configs/:name/report": {id: "getReport", summary: "获取带宽测试报告", tag: "tests", role: auth.RoleViewer,
+		query: []apiParam{{"run_id", "string", "运行记录 ID，默认使用最近一次记录"}},
+		data:  analyze.ReportData{}},
+	"GET /api/configs/:name/report-lat": {id: "getLatencyReport", summary: "获取延迟测试报告", tag: "tests", role: auth.RoleViewer,
+		query: []apiParam{{"run_id", "string", "运行记录 ID，默认使用最近一次记录"}},
+		data:  lat.LatencySummary{}},
+	"POST /api/configs/:name/connectivity": {id: "checkConnectivity", summary: "检查网络连通性", tag: "tests", role: auth.RoleOperator,
+		data: connectivity.ConnectivitySummary{}, async: true},
+	"POST /api/configs/:name/execute": {id: "executeWorkflow", summary: "执行完整测试流程（precheck、run、probe、collect、analyze）", tag: "tests", role: auth.RoleOperator,
+		body: ExecuteRequest{}, data: workflow.Result{}, async: true},
+
+	"POST /api/configs/:name/sampler/start": {id: "startSampler", summary: "启动带宽时间序列采样", tag: "sampler", role: auth.RoleOperator,
+		body: SamplerStartRequest{}, data: SamplerStatus{}},
+	"POST /api/configs/:name/sampler/stop": {id: "stopSampler", summary: "停止带宽采样", tag: "sampler", role: auth.RoleOperator,
+		data: SamplerStatus{}},
+	"GET /api/configs/:name/samples": {id: "getSamples", summary: "获取带宽采样数据", tag: "sampler", role: auth.RoleViewer,
+		query: []apiParam{sinceParam}, data: SamplesResponse{}},
+
+	"GET /api/jobs": {id: "listJobs", summary: "获取任务列表（不含日志）", tag: "jobs", role: auth.RoleViewer,
+		query: []apiParam{configParam, {"type", "string", "按任务类型过滤"}, {"state", "string", "按状态过滤：queued, running, succeeded, failed, cancelled"}},
+		data:  []jobs.Info{}},
+	"GET /api/jobs/:id": {id: "getJob", summary: "获取任务状态、进度、日志和结果", tag: "jobs", role: auth.RoleViewer,
+		data: jobs.Info{}},
+	"GET /api/jobs/:id/events": {id: "streamJobEvents", summary: "任务事件流 (Server-Sent Events)", tag: "jobs", role: auth.RoleViewer,
+		query: []apiParam{{"after", "integer", "只推送序号大于该值的事件，也可以通过 Last-Event-ID 请求头指定"}},
+		data:  events.Event{}, raw: "text/event-stream"},
+	"POST /api/jobs/:id/cancel": {id: "cancelJob", summary: "取消任务", tag: "jobs", role: auth.RoleOperator,
+		data: jobs.Info{}},
+
+	"GET /api/runs": {id: "listRuns", summary: "获取运行记录列表", tag: "runs", role: auth.RoleViewer,
+		query: []apiParam{configParam, {"type", "string", "按测试类型过滤"}, {"status", "string", "按状态过滤：running, succeeded, failed"}, {"limit", "integer", "最多返回的条数"}},
+		data:  []store.Run{}},
+	"GET /api/runs/:id": {id: "getRun", summary: "获取运行记录", tag: "runs", role: auth.RoleViewer,
+		data: store.Run{}},
+	"GET /api/runs/:id/results/:result": {id: "getRunResult", summary: "获取运行记录的分析结果（bandwidth、latency、connectivity 等）", tag: "runs", role: auth.RoleViewer,
+		data: json.RawMessage{}},
+	"DELETE /api/runs/:id": {id: "deleteRun", summary: "删除运行记录（运行中的记录返回 409）", tag: "runs", role: auth.RoleAdmin,
+		query: []apiParam{{"force", "boolean", "为 true 时也删除运行中的记录"}},
+		data:  DeletedRun{}},
+
+	"GET /api/audit": {id: "queryAudit", summary: "查询审计日志（最新的在前）", tag: "audit", role: auth.RoleAdmin,
+		query: []apiParam{{"host", "string", "按主机过滤"}, {"user", "string", "按发起者过滤"}, {"job_id", "string", "按任务 ID 过滤"},
+			sinceParam, {"until", "string", "RFC3339 时间或 Unix 秒"}, {"limit", "integer", "最多返回的条数，默认 500，最大 10000"}},
+		data: []audit.Entry{}},
+
+	"GET /api/dictionary/hostnames": {id: "getHostnames", summary: "获取主机名列表", tag: "dictionary", role: auth.RoleViewer,
+		data: []string{}},
+	"PUT /api/dictionary/hostnames": {id: "updateHostnames", summary: "更新主机名列表", tag: "dictionary", role: auth.RoleAdmin,
+		body: HostnamesRequest{}, data: []string{}},
+	"GET /api/dictionary/hcas": {id: "getHCAs", summary: "获取 HCA 列表", tag: "dictionary", role: auth.RoleViewer,
+		data: []string{}},
+	"PUT /api/dictionary/hcas": {id: "updateHCAs", summary: "更新 HCA 列表", tag: "dictionary", role: auth.RoleAdmin,
+		body: HCAsRequest{}, data: []string{}},
+
+	"GET /api/openapi.json": {id: "getOpenAPI", summary: "OpenAPI 文档", tag: "system", raw: "application/json"},
+	"GET /api/docs":         {id: "getAPIDocs", summary: "Swagger UI", tag: "system", raw: "text/html"},
+	"GET /api/docs/*file":   {id: "getAPIDocsAsset", summary: "Swagger UI 静态文件", tag: "system", raw: "application/octet-stream"},
+	"GET /metrics":          {id: "getMetrics", summary: "Prometheus 指标", tag: "system", role: auth.RoleViewer, raw: "text/plain"},
+	"GET /health":           {id: "getHealth", summary: "健康检查", tag: "system", data: HealthStatus{}},
+}
+
+// apiTags 接口分组
+var apiTags = []openapi.Tag{
+	{Name: "configs", Description: "配置文件管理"},
+	{Name: "tests", Description: "测试执行与报告；precheck、run、collect、connectivity 和 execute 以异步任务执行"},
+	{Name: "sampler", Description: "带宽时间序列采样"},
+	{Name: "jobs", Description: "异步任务"},
+	{Name: "runs", Description: "测试运行记录"},
+	{Name: "audit", Description: "远程命令审计日志"},
+	{Name: "dictionary", Description: "主机名和 HCA 字典"},
+	{Name: "auth", Description: "认证"},
+	{Name: "system", Description: "文档、指标和健康检查"},
+}
+
+// pathParamDescriptions 路径参数说明
+var pathParamDescriptions = map[string]string{
+	"name":   "配置文件名，如 config.yaml",
+	"id":     "任务或运行记录 ID",
+	"result": "分析结果名称",
+}
+
+// BuildOpenAPI 根据已注册的 gin 路由生成 OpenAPI 文档；没有描述的路由只包含路径和方法
+func BuildOpenAPI(routes gin.RoutesInfo) *openapi.Document {
+	doc := openapi.NewDocument(openapi.Info{
+		Title:       "xnetperf API",
+		Description: "xnetperf HTTP Server API。所有 /api 接口返回统一的响应结构 {code, message, data}，code 为 0 表示成功。",
+		Version:     "v1",
+	})
+	doc.Tags = apiTags
+	doc.Components.SecuritySchemes["bearerAuth"] = &openapi.SecurityScheme{
+		Type: "http", Scheme: "bearer", Description: "API token 或 OIDC JWT",
+	}
+	doc.Components.SecuritySchemes["basicAuth"] = &openapi.SecurityScheme{
+		Type: "http", Scheme: "basic", Description: "htpasswd 用户",
+	}
+	// 未启用认证时不需要凭据
+	doc.Security = []map[string][]string{{"bearerAuth": {}}, {"basicAuth": {}}, {}}
+
+	// 名称在包外含义不明确的类型
+	doc.SetName(jobs.Info{}, "Job")
+	doc.SetName(store.Run{}, "Run")
+	doc.SetName(audit.Entry{}, "AuditEntry")
+	doc.SetName(events.Event{}, "JobEvent")
+	doc.SetName(sampler.Sample{}, "BandwidthSample")
+	doc.SetName(runnerservice.RunResult{}, "RunResult")
+	doc.SetName(workflow.Result{}, "WorkflowResult")
+	doc.Schema(Response{})
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	for _, route := range routes {
+		api, ok := apiOperations[route.Method+" "+route.Path]
+		if !ok {
+			api = apiOperation{id: strings.ToLower(route.Method) + strings.NewReplacer("/", "_", ":", "").Replace(route.Path)}
+		}
+		doc.AddOperation(route.Method, route.Path, buildOperation(doc, route, api))
+	}
+	return doc
+}
+
+func buildOperation(doc *openapi.Document, route gin.RouteInfo, api apiOperation) *openapi.Operation {
+	op := &openapi.Operation{
+		OperationID: api.id,
+		Summary:     api.summary,
+		Responses:   make(map[string]*openapi.Response),
+	}
+	if api.tag != "" {
+		op.Tags = []string{api.tag}
+	}
+	if api.role != auth.RoleNone {
+		op.Role = api.role.String()
+		op.Description = fmt.Sprintf("启用认证时需要 %s 角色。", api.role)
+	} else {
+		// 公开接口
+		op.Security = []map[string][]string{{}}
+	}
+
+	for _, name := range openapi.PathParams(route.Path) {
+		op.Parameters = append(op.Parameters, &openapi.Parameter{
+			Name: name, In: "path", Required: true,
+			Description: pathParamDescriptions[name],
+			Schema:      &openapi.Schema{Type: "string"},
+		})
+	}
+	query := api.query
+	if api.async {
+		query = append(query, waitParam)
+	}
+	for _, p := range query {
+		op.Parameters = append(op.Parameters, &openapi.Parameter{
+			Name: p.name, In: "query", Description: p.description,
+			Schema: &openapi.Schema{Type: p.typ},
+		})
+	}
+
+	if api.body != nil {
+		op.RequestBody = &openapi.RequestBody{
+			Required: true,
+			Content:  map[string]*openapi.MediaType{"application/json": {Schema: doc.Schema(api.body)}},
+		}
+	}
+
+	switch {
+	case api.raw != "":
+		response := &openapi.Response{Description: "成功", Content: map[string]*openapi.MediaType{api.raw: {}}}
+		if api.data != nil {
+			response.Description = "每个事件的 data 为 JSON"
+			response.Content[api.raw].Schema = doc.Schema(api.data)
+		}
+		op.Responses["200"] = response
+	case api.async:
+		op.Description += "提交异步任务并返回 202 和任务信息，通过 GET /api/jobs/{id} 查询结果；wait=true 时等待任务结束，直接返回任务结果。"
+		op.Responses["202"] = envelope(doc, "任务已提交", jobs.Info{})
+		op.Responses["200"] = envelope(doc, "wait=true 时的任务结果", api.data)
+	default:
+		op.Responses["200"] = envelope(doc, "成功", api.data)
+	}
+	if api.raw == "" || api.role != auth.RoleNone {
+		op.Responses["default"] = envelope(doc, "失败，code 为 HTTP 状态码", nil)
+	}
+	return op
+}
+
+// swaggerUIPage 加载同目录下 openapi.json 的 Swagger UI 页面；swagger-ui-dist 的文件由
+// github.com/swaggo/files/v2 嵌入二进制，通过 /api/docs/ 提供，不需要访问外网
+var swaggerUIPage = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+  <meta charset="utf-8">
+  <title>xnetperf API</title>
+  <link rel="stylesheet" href="docs/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="docs/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "openapi.json", dom_id: "#swagger-ui", persistAuthorization: true });
+  </script>
+</body>
+</html>
+`
+
+// ServeOpenAPI 返回 OpenAPI 文档
+func (s *Server) ServeOpenAPI(c *gin.Context) {
+	c.Data(200, "application/json; charset=utf-8", s.openapiJSON)
+}
+
+// ServeAPIDocs 返回 Swagger UI 页面
+func (s *Server) ServeAPIDocs(c *gin.Context) {
+	c.Data(200, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
+
+// ServeAPIDocsAsset 返回嵌入的 swagger-ui-dist 文件
+func (s *Server) ServeAPIDocsAsset(c *gin.Context) {
+	name := strings.TrimPrefix(c.Param("file"), "/")
+	data, err := fs.ReadFile(swaggerFiles.FS, name)
+	if err != nil {
+		c.JSON(404, Error(404, fmt.Sprintf("文件不存在: %s", name)))
+		return
+	}
+	// 文件随二进制版本变化，浏览器可以缓存一天
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Data(200, mime.TypeByExtension(path.Ext(name)), data)
+}
+
+// OpenAPI 返回根据当前路由生成的 OpenAPI 文档
+func (s *Server) OpenAPI() *openapi.Document {
+	return BuildOpenAPI(s.engine.Routes())
+}
+
+// envelope 统一响应结构，data 为指定类型
+func envelope(doc *openapi.Document, description string, data any) *openapi.Response {
+	schema := openapi.Ref("Response")
+	if data != nil {
+		schema = &openapi.Schema{AllOf: []*openapi.Schema{
+			openapi.Ref("Response"),
+			{Type: "object", Properties: map[string]*openapi.Schema{"data": doc.Schema(data)}},
+		}}
+	}
+	return &openapi.Response{
+		Description: description,
+		Content:     map[string]*openapi.MediaType{"application/json": {Schema: schema}},
+	}
+}
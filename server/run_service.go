@@ -0,0 +1,193 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"xnetperf/config"
+	"xnetperf/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeletedRun 已删除的运行记录
+type DeletedRun struct {
+	ID string `json:"id"`
+}
+
+// RunService 测试运行记录服务
+type RunService struct {
+	runStore *store.Store
+}
+
+// NewRunService 创建测试运行记录服务
+func NewRunService(runStore *store.Store) *RunService {
+	return &RunService{runStore: runStore}
+}
+
+// ListRuns 获取运行记录列表，支持 config/type/status/limit 查询参数
+func (s *RunService) ListRuns(c *gin.Context) {
+	limit := 0
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			c.JSON(400, Error(400, fmt.Sprintf("无效的 limit 参数: %s", v)))
+			return
+		}
+		limit = n
+	}
+
+	runs, err := s.runStore.List(store.Filter{
+		ConfigName: c.Query("config"),
+		TestType:   c.Query("type"),
+		Status:     c.Query("status"),
+		Limit:      limit,
+	})
+	if err != nil {
+		c.JSON(500, Error(500, fmt.Sprintf("读取运行记录失败: %v", err)))
+		return
+	}
+	if runs == nil {
+		runs = []*store.Run{}
+	}
+
+	c.JSON(200, Success(runs))
+}
+
+// GetRun 获取指定运行记录
+func (s *RunService) GetRun(c *gin.Context) {
+	run, err := s.runStore.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(404, Error(404, fmt.Sprintf("运行记录不存在: %v", err)))
+		return
+	}
+
+	c.JSON(200, Success(run))
+}
+
+// GetRunResult 获取运行记录中的分析结果
+func (s *RunService) GetRunResult(c *gin.Context) {
+	data, err := s.runStore.ReadResult(c.Param("id"), c.Param("result"))
+	if err != nil {
+		c.JSON(404, Error(404, fmt.Sprintf("分析结果不存在: %v", err)))
+		return
+	}
+
+	c.JSON(200, Success(data))
+}
+
+// DeleteRun 删除运行记录。运行中的记录返回 409，除非指定 force=true（用于进程异常退出后遗留的记录）
+func (s *RunService) DeleteRun(c *gin.Context) {
+	id := c.Param("id")
+	err := s.runStore.Delete(id, c.Query("force") == "true")
+	switch {
+	case errors.Is(err, store.ErrRunning):
+		c.JSON(409, Error(409, "运行记录仍在运行中，如确认已异常结束请使用 force=true 删除"))
+		return
+	case err != nil:
+		c.JSON(404, Error(404, fmt.Sprintf("删除运行记录失败: %v", err)))
+		return
+	}
+
+	c.JSON(200, SuccessWithMessage("运行记录删除成功", DeletedRun{ID: id}))
+}
+
+// 以下方法把 ConfigService 各步骤关联到运行记录。
+// run 接口创建运行记录，脚本和原始报告都写入该运行的独立目录；collect 接口收集报告到运行目录；
+// report 接口从运行目录分析报告，保存分析结果并结束运行。
+// 请求可以通过 run_id 参数指定运行记录，否则使用该配置文件最近的运行记录。
+// 指定的运行记录不存在时返回 404；没有指定时记录失败只写日志，此时退回到共享的 reports 目录。
+
+// startRun 为配置文件创建一条新的运行记录
+func (s *ConfigService) startRun(configName, testType string, cfg *config.Config) *store.Run {
+	if s.runStore == nil {
+		return nil
+	}
+	run, err := s.runStore.Create(configName, testType, cfg)
+	if err != nil {
+		s.logger.Warn("Failed to record run", "config", configName, "test_type", testType, "error", err)
+		return nil
+	}
+	return run
+}
+
+// findRun 返回请求指定的运行记录，或该配置文件最近一条符合条件的运行记录（可能为 nil）。
+// 请求通过 run_id 指定的运行记录不存在、不属于该配置文件或测试类型不符时返回 404，
+// 此时第二个返回值为 false
+func (s *ConfigService) findRun(c *gin.Context, configName, testType, status string) (*store.Run, bool) {
+	if id := c.Query("run_id"); id != "" {
+		var run *store.Run
+		var err error
+		if s.runStore != nil {
+			run, err = s.runStore.Get(id)
+		}
+		if s.runStore == nil || err != nil || run.ConfigName != configName || (testType != "" && run.TestType != testType) {
+			s.logger.Warn("Requested run not found for config", "run", id, "config", configName, "test_type", testType)
+			c.JSON(404, Error(404, fmt.Sprintf("运行记录不存在: %s", id)))
+			return nil, false
+		}
+		return run, true
+	}
+	if s.runStore == nil {
+		return nil, true
+	}
+	run, err := s.runStore.Latest(store.Filter{ConfigName: configName, TestType: testType, Status: status})
+	if err != nil {
+		s.logger.Debug("No matching run", "config", configName, "test_type", testType, "status", status)
+		return nil, true
+	}
+	return run, true
+}
+
+// runDir 返回运行目录，没有运行记录时返回空字符串（使用共享的 reports 目录）
+func (s *ConfigService) runDir(run *store.Run) string {
+	if run == nil {
+		return ""
+	}
+	return s.runStore.Dir(run.ID)
+}
+
+// runID 返回运行记录 ID，没有运行记录时返回空字符串
+func runID(run *store.Run) string {
+	if run == nil {
+		return ""
+	}
+	return run.ID
+}
+
+// indexReports 更新运行记录中的原始报告数量
+func (s *ConfigService) indexReports(run *store.Run) {
+	if run == nil {
+		return
+	}
+	if err := s.runStore.IndexReports(run); err != nil {
+		s.logger.Warn("Failed to index reports", "run", run.ID, "error", err)
+	}
+}
+
+// finishRun 保存分析结果，运行中的记录同时标记为成功
+func (s *ConfigService) finishRun(run *store.Run, name string, result any) {
+	if run == nil {
+		return
+	}
+	if err := s.runStore.SaveResult(run, name, result); err != nil {
+		s.logger.Warn("Failed to record result", "run", run.ID, "error", err)
+	}
+	if run.Status != store.StatusRunning {
+		return
+	}
+	if err := s.runStore.Finish(run, nil); err != nil {
+		s.logger.Warn("Failed to finish run", "run", run.ID, "error", err)
+	}
+}
+
+// failRun 把运行记录标记为失败
+func (s *ConfigService) failRun(run *store.Run, runErr error) {
+	if run == nil {
+		return
+	}
+	if err := s.runStore.Finish(run, runErr); err != nil {
+		s.logger.Warn("Failed to finish run", "run", run.ID, "error", err)
+	}
+}
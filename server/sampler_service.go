@@ -0,0 +1,272 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"xnetperf/config"
+	"xnetperf/internal/service/sampler"
+	"xnetperf/internal/store"
+	"xnetperf/pkg/tools/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// samplesFile 运行目录中保存带宽采样的 CSV 文件
+const samplesFile = "samples.csv"
+
+// SamplerService 带宽时间序列采样服务，每个配置文件最多一个采样任务
+type SamplerService struct {
+	runStore *store.Store
+	logger   *slog.Logger
+
+	mu       sync.Mutex
+	sessions map[string]*samplerSession
+}
+
+// samplerSession 一个正在进行（或已停止）的采样任务
+type samplerSession struct {
+	sampler   *sampler.Sampler
+	cancel    context.CancelFunc
+	done      chan struct{}
+	interval  time.Duration
+	startedAt time.Time
+	runID     string
+	csvPath   string
+}
+
+// SamplerStartRequest 启动采样请求
+type SamplerStartRequest struct {
+	IntervalSeconds int    `json:"interval_seconds"` // 采样间隔（秒），默认 5
+	RunID           string `json:"run_id"`           // 可选，把采样写入该运行记录目录
+}
+
+// SamplerStatus 采样任务状态
+type SamplerStatus struct {
+	Running         bool      `json:"running"`
+	IntervalSeconds int       `json:"interval_seconds"`
+	StartedAt       time.Time `json:"started_at"`
+	RunID           string    `json:"run_id,omitempty"`
+	CSVPath         string    `json:"csv_path,omitempty"`
+}
+
+// SamplesResponse 采样状态和采样数据
+type SamplesResponse struct {
+	Status  SamplerStatus    `json:"status"`
+	Samples []sampler.Sample `json:"samples"`
+}
+
+// NewSamplerService 创建带宽采样服务
+func NewSamplerService(runStore *store.Store) *SamplerService {
+	return &SamplerService{
+		runStore: runStore,
+		logger:   logger.GetLogger().With("module", "SAMPLER_API"),
+		sessions: make(map[string]*samplerSession),
+	}
+}
+
+// StartSampler 启动配置文件对应主机的带宽采样
+func (s *SamplerService) StartSampler(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(400, Error(400, "配置文件名不能为空"))
+		return
+	}
+
+	var req SamplerStartRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, Error(400, fmt.Sprintf("请求参数错误: %v", err)))
+			return
+		}
+	}
+	interval := sampler.DefaultInterval
+	if req.IntervalSeconds < 0 {
+		c.JSON(400, Error(400, "采样间隔必须为正数"))
+		return
+	} else if req.IntervalSeconds > 0 {
+		interval = time.Duration(req.IntervalSeconds) * time.Second
+	}
+
+	// 构建文件路径
+	var filePath string
+	if name == DefaultConfigFile {
+		filePath = DefaultConfigFile
+	} else {
+		filePath = filepath.Join(ConfigsDir, name)
+	}
+
+	// 检查文件是否存在
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		c.JSON(404, Error(404, "配置文件不存在"))
+		return
+	}
+
+	// 加载配置文件
+	cfg, err := config.LoadConfig(filePath)
+	if err != nil {
+		c.JSON(400, Error(400, fmt.Sprintf("配置文件解析失败: %v", err)))
+		return
+	}
+
+	// 关联运行记录：指定 run_id，否则使用该配置文件正在运行的记录
+	session := &samplerSession{
+		sampler:   sampler.New(cfg),
+		done:      make(chan struct{}),
+		interval:  interval,
+		startedAt: time.Now(),
+	}
+	if run := s.findRun(name, req.RunID); run != nil {
+		session.runID = run.ID
+		session.csvPath = filepath.Join(s.runStore.Dir(run.ID), samplesFile)
+	} else if req.RunID != "" {
+		c.JSON(404, Error(404, fmt.Sprintf("运行记录不存在: %s", req.RunID)))
+		return
+	}
+
+	// 先检查冲突再打开采样文件，避免第二次启动影响正在写入的文件
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.sessions[name]; ok && existing.running() {
+		c.JSON(409, Error(409, "该配置文件的采样任务已在运行"))
+		return
+	}
+
+	var sinks []func([]sampler.Sample)
+	var csvWriter *sampler.CSVWriter
+	if session.csvPath != "" {
+		csvWriter, err = sampler.NewCSVWriter(session.csvPath)
+		if err != nil {
+			c.JSON(500, Error(500, fmt.Sprintf("创建采样文件失败: %v", err)))
+			return
+		}
+		sinks = append(sinks, func(samples []sampler.Sample) {
+			if err := csvWriter.Write(samples); err != nil {
+				s.logger.Warn("Failed to write samples", "config", name, "error", err)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(actorContext(c))
+	session.cancel = cancel
+	s.sessions[name] = session
+
+	go func() {
+		defer close(session.done)
+		if csvWriter != nil {
+			defer csvWriter.Close()
+		}
+		session.sampler.Run(ctx, interval, sinks...)
+	}()
+
+	s.logger.Info("Sampler started", "config", name, "interval", interval, "run", session.runID)
+	c.JSON(200, SuccessWithMessage("带宽采样已启动", session.status()))
+}
+
+// StopSampler 停止配置文件的带宽采样，已采集的数据仍可查询
+func (s *SamplerService) StopSampler(c *gin.Context) {
+	name := c.Param("name")
+
+	s.mu.Lock()
+	session, ok := s.sessions[name]
+	s.mu.Unlock()
+	if !ok || !session.running() {
+		c.JSON(404, Error(404, "该配置文件没有正在运行的采样任务"))
+		return
+	}
+
+	session.cancel()
+	<-session.done
+
+	s.logger.Info("Sampler stopped", "config", name)
+	c.JSON(200, SuccessWithMessage("带宽采样已停止", session.status()))
+}
+
+// GetSamples 获取采样数据，since 参数（RFC3339 或 Unix 秒）只返回之后的采样
+func (s *SamplerService) GetSamples(c *gin.Context) {
+	name := c.Param("name")
+
+	var since time.Time
+	if v := c.Query("since"); v != "" {
+		t, err := parseSince(v)
+		if err != nil {
+			c.JSON(400, Error(400, fmt.Sprintf("无效的 since 参数: %s", v)))
+			return
+		}
+		since = t
+	}
+
+	s.mu.Lock()
+	session, ok := s.sessions[name]
+	s.mu.Unlock()
+	if !ok {
+		c.JSON(404, Error(404, "该配置文件没有采样任务"))
+		return
+	}
+
+	samples := session.sampler.History(since)
+	if samples == nil {
+		samples = []sampler.Sample{}
+	}
+	c.JSON(200, Success(SamplesResponse{
+		Status:  session.status(),
+		Samples: samples,
+	}))
+}
+
+// findRun 返回指定的运行记录，或该配置文件正在运行的最近一条记录
+func (s *SamplerService) findRun(configName, id string) *store.Run {
+	if s.runStore == nil {
+		return nil
+	}
+	if id != "" {
+		run, err := s.runStore.Get(id)
+		if err != nil {
+			return nil
+		}
+		return run
+	}
+	run, err := s.runStore.Latest(store.Filter{ConfigName: configName, Status: store.StatusRunning})
+	if err != nil {
+		return nil
+	}
+	return run
+}
+
+func (ss *samplerSession) running() bool {
+	select {
+	case <-ss.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (ss *samplerSession) status() SamplerStatus {
+	return SamplerStatus{
+		Running:         ss.running(),
+		IntervalSeconds: int(ss.interval / time.Second),
+		StartedAt:       ss.startedAt,
+		RunID:           ss.runID,
+		CSVPath:         ss.csvPath,
+	}
+}
+
+// parseSince 解析 RFC3339 时间或 Unix 秒
+func parseSince(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+		return t, nil
+	}
+	sec, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	whole := int64(sec)
+	return time.Unix(whole, int64((sec-float64(whole))*1e9)), nil
+}
@@ -1,91 +1,183 @@
 package server
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io/fs"
+	"net"
+	"net/http"
+	"strconv"
 	"time"
 
+	"xnetperf/config"
+	"xnetperf/internal/auth"
+	"xnetperf/internal/jobs"
+	"xnetperf/internal/store"
 	"xnetperf/web"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
 )
 
+// DefaultPort HTTP服务器默认端口
+const DefaultPort = 8080
+
+// HealthStatus 健康检查结果
+type HealthStatus struct {
+	Status string `json:"status"`
+}
+
 // Server HTTP服务器
 type Server struct {
 	engine            *gin.Engine
 	configService     *ConfigService
 	dictionaryService *DictionaryService
-	port              int
+	runService        *RunService
+	samplerService    *SamplerService
+	jobService        *JobService
+	auditService      *AuditService
+	metrics           *Metrics
+	authenticator     auth.Authenticator
+	httpConfig        config.HTTPServer
+	openapiJSON       []byte
 }
 
-// NewServer 创建HTTP服务器
-func NewServer(port int) *Server {
+// NewServer 创建HTTP服务器，cfg 为配置文件中的 http_server 部分
+func NewServer(cfg config.HTTPServer, runStore *store.Store, jobManager *jobs.Manager) *Server {
 	gin.SetMode(gin.ReleaseMode)
 
 	engine := gin.Default()
 
-	// 配置 CORS 中间件
-	engine.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"}, // 允许所有源，生产环境建议配置具体的域名
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	}))
+	// 配置 CORS 中间件：Web UI 与 API 同源，只有列出的其他站点可以跨域调用
+	if len(cfg.CORSOrigins) > 0 {
+		engine.Use(cors.New(cors.Config{
+			AllowOrigins:  cfg.CORSOrigins,
+			AllowMethods:  []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			AllowHeaders:  []string{"Origin", "Content-Type", "Accept", "Authorization", "Last-Event-ID"},
+			ExposeHeaders: []string{"Content-Length"},
+			// 允许所有源时不能携带凭据，否则任意网站都能以用户身份调用 API
+			AllowCredentials: !lo.Contains(cfg.CORSOrigins, "*"),
+			MaxAge:           12 * time.Hour,
+		}))
+	}
 
+	metrics := NewMetrics(jobManager)
 	server := &Server{
 		engine:            engine,
-		configService:     NewConfigService(),
+		configService:     NewConfigService(runStore, jobManager, metrics),
 		dictionaryService: NewDictionaryService(),
-		port:              port,
+		runService:        NewRunService(runStore),
+		samplerService:    NewSamplerService(runStore),
+		jobService:        NewJobService(jobManager),
+		auditService:      NewAuditService(),
+		metrics:           metrics,
+		httpConfig:        cfg,
 	}
 
 	server.setupRoutes()
 	return server
 }
 
+// WithAuth 启用认证，未启用时所有 API 对任何调用者开放
+func (s *Server) WithAuth(authenticator auth.Authenticator) *Server {
+	s.authenticator = authenticator
+	return s
+}
+
+// Handler 返回处理所有路由的 http.Handler，用于测试或嵌入其他 HTTP 服务
+func (s *Server) Handler() http.Handler {
+	return s.engine
+}
+
 // setupRoutes 设置路由
 func (s *Server) setupRoutes() {
+	// 角色权限：viewer 只读，operator 可运行测试，admin 可修改配置和字典
+	viewer := s.requireRole(auth.RoleViewer)
+	operator := s.requireRole(auth.RoleOperator)
+	admin := s.requireRole(auth.RoleAdmin)
+
 	// API 分组
 	api := s.engine.Group("/api")
 	{
+		api.GET("/auth/me", viewer, s.GetIdentity) // 获取当前调用者的身份和角色
+
 		// 配置文件管理API
 		configs := api.Group("/configs")
 		{
-			configs.GET("", s.configService.ListConfigs)                           // 获取配置文件列表
-			configs.GET("/:name", s.configService.GetConfig)                       // 获取指定配置文件
-			configs.GET("/:name/preview", s.configService.PreviewConfig)           // 预览配置文件（YAML格式）
-			configs.POST("", s.configService.CreateConfig)                         // 创建配置文件
-			configs.PUT("/:name", s.configService.UpdateConfig)                    // 更新配置文件
-			configs.DELETE("/:name", s.configService.DeleteConfig)                 // 删除配置文件
-			configs.POST("/:name/validate", s.configService.ValidateConfig)        // 验证配置文件
-			configs.POST("/:name/precheck", s.configService.PrecheckConfig)        // 执行 precheck 检查
-			configs.POST("/:name/run", s.configService.RunTest)                    // 运行测试 (支持 test_type 参数: bandwidth/latency/connectivity)
-			configs.POST("/:name/probe", s.configService.ProbeTest)                // 探测测试状态 (ib_write_bw)
-			configs.POST("/:name/probe-lat", s.configService.ProbeLatencyTest)     // 探测延迟测试状态 (ib_write_lat)
-			configs.POST("/:name/collect", s.configService.CollectReports)         // 收集报告
-			configs.GET("/:name/report", s.configService.GetReport)                // 获取性能报告 (带宽测试)
-			configs.GET("/:name/report-lat", s.configService.GetLatencyReport)     // 获取延迟测试报告
-			configs.POST("/:name/connectivity", s.configService.CheckConnectivity) // 检查网络连通性
+			configs.GET("", viewer, s.configService.ListConfigs)                             // 获取配置文件列表
+			configs.GET("/:name", viewer, s.configService.GetConfig)                         // 获取指定配置文件
+			configs.GET("/:name/preview", viewer, s.configService.PreviewConfig)             // 预览配置文件（YAML格式）
+			configs.POST("", admin, s.configService.CreateConfig)                            // 创建配置文件
+			configs.PUT("/:name", admin, s.configService.UpdateConfig)                       // 更新配置文件
+			configs.DELETE("/:name", admin, s.configService.DeleteConfig)                    // 删除配置文件
+			configs.POST("/:name/validate", viewer, s.configService.ValidateConfig)          // 验证配置文件
+			configs.POST("/:name/precheck", operator, s.configService.PrecheckConfig)        // 执行 precheck 检查
+			configs.POST("/:name/run", operator, s.configService.RunTest)                    // 运行测试 (支持 test_type 参数: bandwidth/latency/connectivity)
+			configs.POST("/:name/probe", operator, s.configService.ProbeTest)                // 探测测试状态 (ib_write_bw)
+			configs.POST("/:name/probe-lat", operator, s.configService.ProbeLatencyTest)     // 探测延迟测试状态 (ib_write_lat)
+			configs.POST("/:name/collect", operator, s.configService.CollectReports)         // 收集报告
+			configs.GET("/:name/report", viewer, s.configService.GetReport)                  // 获取性能报告 (带宽测试)
+			configs.GET("/:name/report-lat", viewer, s.configService.GetLatencyReport)       // 获取延迟测试报告
+			configs.POST("/:name/connectivity", operator, s.configService.CheckConnectivity) // 检查网络连通性
+			configs.POST("/:name/execute", operator, s.configService.ExecuteWorkflow)        // 执行完整测试流程 (支持 test_type 和 precheck 参数)
+			configs.POST("/:name/sampler/start", operator, s.samplerService.StartSampler)    // 启动带宽时间序列采样
+			configs.POST("/:name/sampler/stop", operator, s.samplerService.StopSampler)      // 停止带宽采样
+			configs.GET("/:name/samples", viewer, s.samplerService.GetSamples)               // 获取带宽采样数据 (支持 since 参数)
+		}
+
+		// 异步任务API（precheck/run/collect/connectivity 接口返回的任务）
+		jobsGroup := api.Group("/jobs")
+		{
+			jobsGroup.GET("", viewer, s.jobService.ListJobs)                   // 获取任务列表 (支持 config/type/state 参数)
+			jobsGroup.GET("/:id", viewer, s.jobService.GetJob)                 // 获取任务状态、进度、日志和结果
+			jobsGroup.GET("/:id/events", viewer, s.jobService.StreamJobEvents) // 任务事件流 (Server-Sent Events)
+			jobsGroup.POST("/:id/cancel", operator, s.jobService.CancelJob)    // 取消任务
 		}
 
+		// 测试运行记录API
+		runs := api.Group("/runs")
+		{
+			runs.GET("", viewer, s.runService.ListRuns)                         // 获取运行记录列表 (支持 config/type/status/limit 参数)
+			runs.GET("/:id", viewer, s.runService.GetRun)                       // 获取指定运行记录
+			runs.GET("/:id/results/:result", viewer, s.runService.GetRunResult) // 获取运行记录的分析结果
+			runs.DELETE("/:id", admin, s.runService.DeleteRun)                  // 删除运行记录
+		}
+
+		// 审计日志API：服务器和 CLI 在集群上执行的远程命令
+		api.GET("/audit", admin, s.auditService.QueryAudit) // 查询审计日志 (支持 host/user/job_id/since/until/limit 参数)
+
 		// 字典管理API
 		dictionary := api.Group("/dictionary")
 		{
-			dictionary.GET("/hostnames", s.dictionaryService.GetHostnames)    // 获取主机名列表
-			dictionary.PUT("/hostnames", s.dictionaryService.UpdateHostnames) // 更新主机名列表
-			dictionary.GET("/hcas", s.dictionaryService.GetHCAs)              // 获取 HCA 列表
-			dictionary.PUT("/hcas", s.dictionaryService.UpdateHCAs)           // 更新 HCA 列表
+			dictionary.GET("/hostnames", viewer, s.dictionaryService.GetHostnames)   // 获取主机名列表
+			dictionary.PUT("/hostnames", admin, s.dictionaryService.UpdateHostnames) // 更新主机名列表
+			dictionary.GET("/hcas", viewer, s.dictionaryService.GetHCAs)             // 获取 HCA 列表
+			dictionary.PUT("/hcas", admin, s.dictionaryService.UpdateHCAs)           // 更新 HCA 列表
 		}
 	}
 
+	// API 文档：OpenAPI 文档和 Swagger UI 不需要认证
+	s.engine.GET("/api/openapi.json", s.ServeOpenAPI)
+	s.engine.GET("/api/docs", s.ServeAPIDocs)
+	s.engine.GET("/api/docs/*file", s.ServeAPIDocsAsset)
+
+	// Prometheus 指标
+	s.engine.GET("/metrics", viewer, s.metrics.ServeMetrics)
+
 	// 健康检查
 	s.engine.GET("/health", func(c *gin.Context) {
-		c.JSON(200, Success(gin.H{"status": "ok"}))
+		c.JSON(200, Success(HealthStatus{Status: "ok"}))
 	})
 
+	// 路由注册完成后生成 OpenAPI 文档
+	doc, err := json.Marshal(s.OpenAPI())
+	if err != nil {
+		panic(err)
+	}
+	s.openapiJSON = doc
+
 	// 静态文件服务（Web UI）- 放在最后，作为 fallback
 	staticFS, err := fs.Sub(web.Static, "static")
 	if err != nil {
@@ -128,11 +220,42 @@ func (s *Server) setupRoutes() {
 	})
 }
 
-// Start 启动服务器
+// Start 启动服务器，配置了证书时使用 HTTPS
 func (s *Server) Start() error {
-	addr := fmt.Sprintf(":%d", s.port)
-	fmt.Printf("HTTP Server starting on http://localhost%s\n", addr)
+	port := s.httpConfig.Port
+	if port == 0 {
+		port = DefaultPort
+	}
+	httpServer := &http.Server{
+		Addr:              net.JoinHostPort(s.httpConfig.Address, strconv.Itoa(port)),
+		Handler:           s.engine,
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+
+	scheme := "http"
+	if s.httpConfig.TLS.Enabled() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		tlsConfig, err := buildTLSConfig(ctx, s.httpConfig.TLS)
+		if err != nil {
+			return err
+		}
+		httpServer.TLSConfig = tlsConfig
+		scheme = "https"
+	}
+
+	host := s.httpConfig.Address
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	url := fmt.Sprintf("%s://%s/", scheme, net.JoinHostPort(host, strconv.Itoa(port)))
+	fmt.Printf("HTTP Server starting on %s (listening on %s)\n", url, httpServer.Addr)
 	fmt.Println("\n🌐 Web UI:")
-	fmt.Printf("  http://localhost%s/\n", addr)
-	return s.engine.Run(addr)
+	fmt.Printf("  %s\n", url)
+
+	if httpServer.TLSConfig != nil {
+		// 证书由 TLSConfig.GetCertificate 提供，这里不需要文件路径
+		return httpServer.ListenAndServeTLS("", "")
+	}
+	return httpServer.ListenAndServe()
 }
@@ -1,6 +1,7 @@
 package stream
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"sync"
 	"time"
 	"xnetperf/config"
+	"xnetperf/internal/audit"
 	"xnetperf/pkg/tools"
 )
 
@@ -135,7 +137,7 @@ func ClearPreviewScript(hosts []string, sshKeyPath, user string) {
 			cmd := tools.BuildSSHCommand(hostname, "killall ib_write_bw", sshKeyPath, user)
 
 			// Run the command and capture the combined standard output and standard error.
-			output, err := cmd.CombinedOutput()
+			output, err := audit.CombinedOutput(context.Background(), hostname, "killall ib_write_bw", cmd)
 
 			// --- Analyze the result ---
 			if err != nil {
@@ -294,7 +296,7 @@ func executeRemoteScript(hostname string, scriptContent []byte) error {
 	cmd := exec.Command("bash", "-c", string(scriptContent))
 
 	// Run the command and wait for it to finish.
-	err := cmd.Run()
+	err := audit.Run(context.Background(), hostname, string(scriptContent), cmd)
 	if err != nil {
 		return fmt.Errorf("failed to execute script on %s: %w", hostname, err)
 	}
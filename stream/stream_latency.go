@@ -1,12 +1,14 @@
 package stream
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"strings"
 	"time"
 	"xnetperf/config"
+	"xnetperf/internal/audit"
 )
 
 // getLatencyOutputDir returns the output directory for latency tests
@@ -252,21 +254,22 @@ func generateLatencyScriptForHCA(
 // RunLatencyScripts executes the latency test scripts with two-phase startup
 // Phase 1: Start all servers with a longer sleep for initialization
 // Phase 2: Start all clients after servers are ready
-func RunLatencyScripts(cfg *config.Config) error {
+// The scripts are attributed to the audit actor of ctx.
+func RunLatencyScripts(ctx context.Context, cfg *config.Config) error {
 	outputDir := getLatencyOutputDir(cfg)
 	fmt.Println("🚀 Starting latency test execution...")
 
 	// Dispatch based on stream type
 	if cfg.StreamType == config.InCast {
-		return runLatencyScriptsIncast(cfg, outputDir)
+		return runLatencyScriptsIncast(ctx, cfg, outputDir)
 	}
 
 	// Default to fullmesh mode
-	return runLatencyScriptsFullmesh(cfg, outputDir)
+	return runLatencyScriptsFullmesh(ctx, cfg, outputDir)
 }
 
 // runLatencyScriptsFullmesh executes fullmesh latency scripts
-func runLatencyScriptsFullmesh(cfg *config.Config, outputDir string) error {
+func runLatencyScriptsFullmesh(ctx context.Context, cfg *config.Config, outputDir string) error {
 	fmt.Println("Phase 1: Starting all server processes (FULLMESH mode)...")
 
 	// Phase 1: Start all servers
@@ -277,7 +280,7 @@ func runLatencyScriptsFullmesh(cfg *config.Config, outputDir string) error {
 				outputDir, host, hca)
 
 			fmt.Printf("  Executing: bash %s\n", serverScript)
-			if err := executeScript(serverScript); err != nil {
+			if err := executeScript(ctx, host, serverScript); err != nil {
 				return fmt.Errorf("failed to execute server script %s: %v", serverScript, err)
 			}
 		}
@@ -296,7 +299,7 @@ func runLatencyScriptsFullmesh(cfg *config.Config, outputDir string) error {
 				outputDir, host, hca)
 
 			fmt.Printf("  Executing: bash %s\n", clientScript)
-			if err := executeScript(clientScript); err != nil {
+			if err := executeScript(ctx, host, clientScript); err != nil {
 				return fmt.Errorf("failed to execute client script %s: %v", clientScript, err)
 			}
 		}
@@ -308,7 +311,7 @@ func runLatencyScriptsFullmesh(cfg *config.Config, outputDir string) error {
 
 // runLatencyScriptsIncast executes incast latency scripts
 // Only client hosts have script files in incast mode
-func runLatencyScriptsIncast(cfg *config.Config, outputDir string) error {
+func runLatencyScriptsIncast(ctx context.Context, cfg *config.Config, outputDir string) error {
 	fmt.Println("Phase 1: Starting all server processes (INCAST mode)...")
 
 	// Phase 1: Start all servers
@@ -319,7 +322,7 @@ func runLatencyScriptsIncast(cfg *config.Config, outputDir string) error {
 				outputDir, clientHost, clientHCA)
 
 			fmt.Printf("  Executing: bash %s\n", serverScript)
-			if err := executeScript(serverScript); err != nil {
+			if err := executeScript(ctx, clientHost, serverScript); err != nil {
 				return fmt.Errorf("failed to execute server script %s: %v", serverScript, err)
 			}
 			time.Sleep(time.Millisecond * 100)
@@ -340,7 +343,7 @@ func runLatencyScriptsIncast(cfg *config.Config, outputDir string) error {
 
 			fmt.Printf("  Executing: bash %s\n", clientScript)
 			time.Sleep(time.Millisecond * 100)
-			if err := executeScript(clientScript); err != nil {
+			if err := executeScript(ctx, clientHost, clientScript); err != nil {
 				return fmt.Errorf("failed to execute client script %s: %v", clientScript, err)
 			}
 			time.Sleep(time.Millisecond * 100)
@@ -351,8 +354,8 @@ func runLatencyScriptsIncast(cfg *config.Config, outputDir string) error {
 	return nil
 }
 
-// executeScript runs a shell script using bash
-func executeScript(scriptPath string) error {
+// executeScript starts a shell script that runs commands on host using bash
+func executeScript(ctx context.Context, host, scriptPath string) error {
 	// Check if script exists
 	if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
 		return fmt.Errorf("script does not exist: %s", scriptPath)
@@ -369,7 +372,7 @@ func executeScript(scriptPath string) error {
 
 	// Execute via bash
 	cmd := exec.Command("bash", "-c", string(content))
-	if err := cmd.Start(); err != nil {
+	if err := audit.Start(ctx, host, strings.TrimSpace(string(content)), cmd); err != nil {
 		return fmt.Errorf("failed to start script %s: %v", scriptPath, err)
 	}
 
@@ -1,10 +1,12 @@
 package stream
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
 	"xnetperf/config"
+	"xnetperf/internal/audit"
 	"xnetperf/pkg/tools"
 )
 
@@ -162,7 +164,7 @@ func getHostIP(hostname string, sshKeyPath, user string, networkInterface string
 	command := fmt.Sprintf("ip addr show %s | grep 'inet ' | awk '{print $2}' | cut -d'/' -f1", networkInterface)
 	cmd := tools.BuildSSHCommand(hostname, command, sshKeyPath, user)
 
-	output, err := cmd.CombinedOutput()
+	output, err := audit.CombinedOutput(context.Background(), hostname, command, cmd)
 	if err != nil {
 		return "127.0.0.1", fmt.Errorf("SSH command failed on %s: %v, output: %s", hostname, err, string(output))
 	}
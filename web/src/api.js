@@ -123,16 +123,16 @@ export async function updateHCAs(hcas) {
   })
 }
 
-// 执行 Precheck 检查
+// 执行 Precheck 检查（wait=true：等待异步任务结束后返回结果）
 export async function runPrecheck(name) {
-  return await fetchJSON(`${API_BASE}/configs/${name}/precheck`, {
+  return await fetchJSON(`${API_BASE}/configs/${name}/precheck?wait=true`, {
     method: 'POST',
   })
 }
 
-// 运行测试
+// 运行测试（wait=true：等待异步任务结束后返回结果）
 export async function runTest(name) {
-  return await fetchJSON(`${API_BASE}/configs/${name}/run`, {
+  return await fetchJSON(`${API_BASE}/configs/${name}/run?wait=true`, {
     method: 'POST',
   })
 }
@@ -144,9 +144,9 @@ export async function probeTest(name) {
   })
 }
 
-// 收集报告
+// 收集报告（wait=true：等待异步任务结束后返回结果）
 export async function collectReports(name) {
-  return await fetchJSON(`${API_BASE}/configs/${name}/collect`, {
+  return await fetchJSON(`${API_BASE}/configs/${name}/collect?wait=true`, {
     method: 'POST',
   })
 }
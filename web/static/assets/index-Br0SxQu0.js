@@ -385,7 +385,7 @@ Error generating stack: `+i.message+`
 `)},Cb=function(){var e=parseInt(document.body.getAttribute(vs)||"0",10);return isFinite(e)?e:0},v8=function(){g.useEffect(function(){return document.body.setAttribute(vs,(Cb()+1).toString()),function(){var e=Cb()-1;e<=0?document.body.removeAttribute(vs):document.body.setAttribute(vs,e.toString())}},[])},x8=function(e){var t=e.noRelative,n=e.noImportant,r=e.gapMode,o=r===void 0?"margin":r;v8();var i=g.useMemo(function(){return m8(o)},[o]);return g.createElement(g8,{styles:y8(i,!t,o,n?"":"!important")})},bm=!1;if(typeof window<"u")try{var Vc=Object.defineProperty({},"passive",{get:function(){return bm=!0,!0}});window.addEventListener("test",Vc,Vc),window.removeEventListener("test",Vc,Vc)}catch{bm=!1}var Ai=bm?{passive:!1}:!1,b8=function(e){return e.tagName==="TEXTAREA"},N2=function(e,t){if(!(e instanceof Element))return!1;var n=window.getComputedStyle(e);return n[t]!=="hidden"&&!(n.overflowY===n.overflowX&&!b8(e)&&n[t]==="visible")},S8=function(e){return N2(e,"overflowY")},w8=function(e){return N2(e,"overflowX")},Pb=function(e,t){var n=t.ownerDocument,r=t;do{typeof ShadowRoot<"u"&&r instanceof ShadowRoot&&(r=r.host);var o=D2(e,r);if(o){var i=F2(e,r),s=i[1],a=i[2];if(s>a)return!0}r=r.parentNode}while(r&&r!==n.body);return!1},k8=function(e){var t=e.scrollTop,n=e.scrollHeight,r=e.clientHeight;return[t,n,r]},C8=function(e){var t=e.scrollLeft,n=e.scrollWidth,r=e.clientWidth;return[t,n,r]},D2=function(e,t){return e==="v"?S8(t):w8(t)},F2=function(e,t){return e==="v"?k8(t):C8(t)},P8=function(e,t){return e==="h"&&t==="rtl"?-1:1},T8=function(e,t,n,r,o){var i=P8(e,window.getComputedStyle(t).direction),s=i*r,a=n.target,l=t.contains(a),c=!1,d=s>0,f=0,h=0;do{if(!a)break;var p=F2(e,a),y=p[0],v=p[1],S=p[2],x=v-S-i*y;(y||x)&&D2(e,a)&&(f+=x,h+=y);var m=a.parentNode;a=m&&m.nodeType===Node.DOCUMENT_FRAGMENT_NODE?m.host:m}while(!l&&a!==document.body||l&&(t.contains(a)||t===a));return(d&&Math.abs(f)<1||!d&&Math.abs(h)<1)&&(c=!0),c},Wc=function(e){return"changedTouches"in e?[e.changedTouches[0].clientX,e.changedTouches[0].clientY]:[0,0]},Tb=function(e){return[e.deltaX,e.deltaY]},_b=function(e){return e&&"current"in e?e.current:e},_8=function(e,t){return e[0]===t[0]&&e[1]===t[1]},E8=function(e){return`
   .block-interactivity-`.concat(e,` {pointer-events: none;}
   .allow-interactivity-`).concat(e,` {pointer-events: all;}
-`)},j8=0,$i=[];function R8(e){var t=g.useRef([]),n=g.useRef([0,0]),r=g.useRef(),o=g.useState(j8++)[0],i=g.useState(z2)[0],s=g.useRef(e);g.useEffect(function(){s.current=e},[e]),g.useEffect(function(){if(e.inert){document.body.classList.add("block-interactivity-".concat(o));var v=WL([e.lockRef.current],(e.shards||[]).map(_b),!0).filter(Boolean);return v.forEach(function(S){return S.classList.add("allow-interactivity-".concat(o))}),function(){document.body.classList.remove("block-interactivity-".concat(o)),v.forEach(function(S){return S.classList.remove("allow-interactivity-".concat(o))})}}},[e.inert,e.lockRef.current,e.shards]);var a=g.useCallback(function(v,S){if("touches"in v&&v.touches.length===2||v.type==="wheel"&&v.ctrlKey)return!s.current.allowPinchZoom;var x=Wc(v),m=n.current,b="deltaX"in v?v.deltaX:m[0]-x[0],w="deltaY"in v?v.deltaY:m[1]-x[1],P,T=v.target,C=Math.abs(b)>Math.abs(w)?"h":"v";if("touches"in v&&C==="h"&&T.type==="range")return!1;var R=Pb(C,T);if(!R)return!0;if(R?P=C:(P=C==="v"?"h":"v",R=Pb(C,T)),!R)return!1;if(!r.current&&"changedTouches"in v&&(b||w)&&(r.current=P),!P)return!0;var $=r.current||P;return T8($,S,v,$==="h"?b:w)},[]),l=g.useCallback(function(v){var S=v;if(!(!$i.length||$i[$i.length-1]!==i)){var x="deltaY"in S?Tb(S):Wc(S),m=t.current.filter(function(P){return P.name===S.type&&(P.target===S.target||S.target===P.shadowParent)&&_8(P.delta,x)})[0];if(m&&m.should){S.cancelable&&S.preventDefault();return}if(!m){var b=(s.current.shards||[]).map(_b).filter(Boolean).filter(function(P){return P.contains(S.target)}),w=b.length>0?a(S,b[0]):!s.current.noIsolation;w&&S.cancelable&&S.preventDefault()}}},[]),c=g.useCallback(function(v,S,x,m){var b={name:v,delta:S,target:x,should:m,shadowParent:A8(x)};t.current.push(b),setTimeout(function(){t.current=t.current.filter(function(w){return w!==b})},1)},[]),d=g.useCallback(function(v){n.current=Wc(v),r.current=void 0},[]),f=g.useCallback(function(v){c(v.type,Tb(v),v.target,a(v,e.lockRef.current))},[]),h=g.useCallback(function(v){c(v.type,Wc(v),v.target,a(v,e.lockRef.current))},[]);g.useEffect(function(){return $i.push(i),e.setCallbacks({onScrollCapture:f,onWheelCapture:f,onTouchMoveCapture:h}),document.addEventListener("wheel",l,Ai),document.addEventListener("touchmove",l,Ai),document.addEventListener("touchstart",d,Ai),function(){$i=$i.filter(function(v){return v!==i}),document.removeEventListener("wheel",l,Ai),document.removeEventListener("touchmove",l,Ai),document.removeEventListener("touchstart",d,Ai)}},[]);var p=e.removeScrollBar,y=e.inert;return g.createElement(g.Fragment,null,y?g.createElement(i,{styles:E8(o)}):null,p?g.createElement(x8,{noRelative:e.noRelative,gapMode:e.gapMode}):null)}function A8(e){for(var t=null;e!==null;)e instanceof ShadowRoot&&(t=e.host,e=e.host),e=e.parentNode;return t}const $8=HL(O2,R8);var L2=g.forwardRef(function(e,t){return g.createElement(ef,dr({},e,{ref:t,sideCar:$8}))});L2.classNames=ef.classNames;function M8(e){const{autoFocus:t,trapFocus:n,dialogRef:r,initialFocusRef:o,blockScrollOnMount:i,allowPinchZoom:s,finalFocusRef:a,returnFocusOnClose:l,preserveScrollBarGap:c,lockFocusAcrossFrames:d,isOpen:f}=xi(),[h,p]=pC();g.useEffect(()=>{!h&&p&&setTimeout(p)},[h,p]);const y=I2(r,f);return u.jsx(y2,{autoFocus:t,isDisabled:!n,initialFocusRef:o,finalFocusRef:a,restoreFocus:l,contentRef:r,lockFocusAcrossFrames:d,children:u.jsx(L2,{removeScrollBar:!c,allowPinchZoom:s,enabled:y===1&&i,forwardProps:!0,children:e.children})})}const I8={initial:({offsetX:e,offsetY:t,transition:n,transitionEnd:r,delay:o})=>({opacity:0,x:e,y:t,transition:(n==null?void 0:n.exit)??ii.exit(oi.exit,o),transitionEnd:r==null?void 0:r.exit}),enter:({transition:e,transitionEnd:t,delay:n})=>({opacity:1,x:0,y:0,transition:(e==null?void 0:e.enter)??ii.enter(oi.enter,n),transitionEnd:t==null?void 0:t.enter}),exit:({offsetY:e,offsetX:t,transition:n,transitionEnd:r,reverse:o,delay:i})=>{const s={x:t,y:e};return{opacity:0,transition:(n==null?void 0:n.exit)??ii.exit(oi.exit,i),...o?{...s,transitionEnd:r==null?void 0:r.exit}:{transitionEnd:{...s,...r==null?void 0:r.exit}}}}},Ta={initial:"initial",animate:"enter",exit:"exit",variants:I8},O8=g.forwardRef(function(t,n){const{unmountOnExit:r,in:o,reverse:i=!0,className:s,offsetX:a=0,offsetY:l=8,transition:c,transitionEnd:d,delay:f,animatePresenceProps:h,...p}=t,y=r?o&&r:!0,v=o||r?"enter":"exit",S={offsetX:a,offsetY:l,reverse:i,transition:c,transitionEnd:d,delay:f};return u.jsx(Kl,{...h,custom:S,children:y&&u.jsx(Ci.div,{ref:n,className:ne("chakra-offset-slide",s),custom:S,...Ta,animate:v,...p})})});O8.displayName="SlideFade";const z8={exit:({reverse:e,initialScale:t,transition:n,transitionEnd:r,delay:o})=>({opacity:0,...e?{scale:t,transitionEnd:r==null?void 0:r.exit}:{transitionEnd:{scale:t,...r==null?void 0:r.exit}},transition:(n==null?void 0:n.exit)??ii.exit(oi.exit,o)}),enter:({transitionEnd:e,transition:t,delay:n})=>({opacity:1,scale:1,transition:(t==null?void 0:t.enter)??ii.enter(oi.enter,n),transitionEnd:e==null?void 0:e.enter})},B2={initial:"exit",animate:"enter",exit:"exit",variants:z8},N8=g.forwardRef(function(t,n){const{unmountOnExit:r,in:o,reverse:i=!0,initialScale:s=.95,className:a,transition:l,transitionEnd:c,delay:d,animatePresenceProps:f,...h}=t,p=r?o&&r:!0,y=o||r?"enter":"exit",v={initialScale:s,reverse:i,transition:l,transitionEnd:c,delay:d};return u.jsx(Kl,{...f,custom:v,children:p&&u.jsx(Ci.div,{ref:n,className:ne("chakra-offset-slide",a),...B2,animate:y,custom:v,...h})})});N8.displayName="ScaleFade";const D8={slideInBottom:{...Ta,custom:{offsetY:16,reverse:!0}},slideInRight:{...Ta,custom:{offsetX:16,reverse:!0}},slideInTop:{...Ta,custom:{offsetY:-16,reverse:!0}},slideInLeft:{...Ta,custom:{offsetX:-16,reverse:!0}},scale:{...B2,custom:{initialScale:.95,reverse:!0}},none:{}},F8=V(Ci.section),L8=e=>D8[e||"none"],V2=g.forwardRef((e,t)=>{const{preset:n,motionProps:r=L8(n),...o}=e;return u.jsx(F8,{ref:t,...r,...o})});V2.displayName="ModalTransition";const tf=H((e,t)=>{const{className:n,children:r,containerProps:o,motionProps:i,...s}=e,{getDialogProps:a,getDialogContainerProps:l}=xi(),c=a(s,t),d=l(o),f=ne("chakra-modal__content",n),h=Hs(),p={display:"flex",flexDirection:"column",position:"relative",width:"100%",outline:0,...h.dialog},y={display:"flex",width:"100vw",height:"$100vh",position:"fixed",left:0,top:0,...h.dialogContainer},{motionPreset:v}=xi();return u.jsx(M8,{children:u.jsx(V.div,{...d,className:"chakra-modal__content-container",tabIndex:-1,__css:y,children:u.jsx(V2,{preset:v,motionProps:i,className:f,...c,__css:p,children:r})})})});tf.displayName="ModalContent";const nf=H((e,t)=>{const{className:n,...r}=e,{bodyId:o,setBodyMounted:i}=xi();g.useEffect(()=>(i(!0),()=>i(!1)),[i]);const s=ne("chakra-modal__body",n),a=Hs();return u.jsx(V.div,{ref:t,className:s,id:o,...r,__css:a.body})});nf.displayName="ModalBody";const rf=H((e,t)=>{const{onClick:n,className:r,...o}=e,{onClose:i}=xi(),s=ne("chakra-modal__close-btn",r),a=Hs();return u.jsx(hy,{ref:t,__css:a.closeButton,className:s,onClick:ye(n,l=>{l.stopPropagation(),i()}),...o})});rf.displayName="ModalCloseButton";const Dy=H((e,t)=>{const{className:n,...r}=e,o=ne("chakra-modal__footer",n),i=Hs(),s={display:"flex",alignItems:"center",justifyContent:"flex-end",...i.footer};return u.jsx(V.footer,{ref:t,...r,__css:s,className:o})});Dy.displayName="ModalFooter";const of=H((e,t)=>{const{className:n,...r}=e,{headerId:o,setHeaderMounted:i}=xi();g.useEffect(()=>(i(!0),()=>i(!1)),[i]);const s=ne("chakra-modal__header",n),a=Hs(),l={flex:0,...a.header};return u.jsx(V.header,{ref:t,className:s,id:o,...r,__css:l})});of.displayName="ModalHeader";const B8={enter:({transition:e,transitionEnd:t,delay:n}={})=>({opacity:1,transition:(e==null?void 0:e.enter)??ii.enter(oi.enter,n),transitionEnd:t==null?void 0:t.enter}),exit:({transition:e,transitionEnd:t,delay:n}={})=>({opacity:0,transition:(e==null?void 0:e.exit)??ii.exit(oi.exit,n),transitionEnd:t==null?void 0:t.exit})},W2={initial:"exit",animate:"enter",exit:"exit",variants:B8},V8=g.forwardRef(function(t,n){const{unmountOnExit:r,in:o,className:i,transition:s,transitionEnd:a,delay:l,animatePresenceProps:c,...d}=t,f=o||r?"enter":"exit",h=r?o&&r:!0,p={transition:s,transitionEnd:a,delay:l};return u.jsx(Kl,{...c,custom:p,children:h&&u.jsx(Ci.div,{ref:n,className:ne("chakra-fade",i),custom:p,...W2,animate:f,...d})})});V8.displayName="Fade";const W8=V(Ci.div),sf=H((e,t)=>{const{className:n,transition:r,motionProps:o,...i}=e,s=ne("chakra-modal__overlay",n),l={pos:"fixed",left:"0",top:"0",w:"100vw",h:"100vh",...Hs().overlay},{motionPreset:c}=xi(),f=o||(c==="none"?{}:W2);return u.jsx(W8,{...f,__css:l,ref:t,className:s,...i})});sf.displayName="ModalOverlay";const H8=e=>u.jsx(Jn,{viewBox:"0 0 24 24",...e,children:u.jsx("path",{fill:"currentColor",d:"M21,5H3C2.621,5,2.275,5.214,2.105,5.553C1.937,5.892,1.973,6.297,2.2,6.6l9,12 c0.188,0.252,0.485,0.4,0.8,0.4s0.611-0.148,0.8-0.4l9-12c0.228-0.303,0.264-0.708,0.095-1.047C21.725,5.214,21.379,5,21,5z"})}),U8=e=>u.jsx(Jn,{viewBox:"0 0 24 24",...e,children:u.jsx("path",{fill:"currentColor",d:"M12.8,5.4c-0.377-0.504-1.223-0.504-1.6,0l-9,12c-0.228,0.303-0.264,0.708-0.095,1.047 C2.275,18.786,2.621,19,3,19h18c0.379,0,0.725-0.214,0.895-0.553c0.169-0.339,0.133-0.744-0.095-1.047L12.8,5.4z"})});function Eb(e,t,n,r){g.useEffect(()=>{if(!e.current||!r)return;const o=e.current.ownerDocument.defaultView??window,i=Array.isArray(t)?t:[t],s=new o.MutationObserver(a=>{for(const l of a)l.type==="attributes"&&l.attributeName&&i.includes(l.attributeName)&&n(l)});return s.observe(e.current,{attributes:!0,attributeFilter:i}),()=>s.disconnect()})}const G8=50,jb=300;function K8(e,t){const[n,r]=g.useState(!1),[o,i]=g.useState(null),[s,a]=g.useState(!0),l=g.useRef(null),c=()=>clearTimeout(l.current);vj(()=>{o==="increment"&&e(),o==="decrement"&&t()},n?G8:null);const d=g.useCallback(()=>{s&&e(),l.current=setTimeout(()=>{a(!1),r(!0),i("increment")},jb)},[e,s]),f=g.useCallback(()=>{s&&t(),l.current=setTimeout(()=>{a(!1),r(!0),i("decrement")},jb)},[t,s]),h=g.useCallback(()=>{a(!0),r(!1),c()},[]);return g.useEffect(()=>()=>c(),[]),{up:d,down:f,stop:h,isSpinning:n}}const Y8=/^[Ee0-9+\-.]$/;function q8(e){return Y8.test(e)}function X8(e,t){if(e.key==null)return!0;const n=e.ctrlKey||e.altKey||e.metaKey;return!(e.key.length===1)||n?!0:t(e.key)}function Q8(e={}){const{focusInputOnChange:t=!0,clampValueOnBlur:n=!0,keepWithinRange:r=!0,min:o=Number.MIN_SAFE_INTEGER,max:i=Number.MAX_SAFE_INTEGER,step:s=1,isReadOnly:a,isDisabled:l,isRequired:c,isInvalid:d,pattern:f="[0-9]*(.[0-9]+)?",inputMode:h="decimal",allowMouseWheel:p,id:y,onChange:v,precision:S,name:x,"aria-describedby":m,"aria-label":b,"aria-labelledby":w,onFocus:P,onBlur:T,onInvalid:C,getAriaValueText:R,isValidCharacter:$,format:j,parse:N,...te}=e,X=kt(P),Y=kt(T),ce=kt(C),J=kt($??q8),z=kt(R),M=hj(e),{update:W,increment:q,decrement:U}=M,[se,F]=g.useState(!1),ie=!(a||l),I=g.useRef(null),O=g.useRef(null),ue=g.useRef(null),fe=g.useRef(null),Xe=g.useCallback(B=>B.split("").filter(J).join(""),[J]),He=g.useCallback(B=>(N==null?void 0:N(B))??B,[N]),$e=g.useCallback(B=>((j==null?void 0:j(B))??B).toString(),[j]);hi(()=>{(M.valueAsNumber>i||M.valueAsNumber<o)&&(ce==null||ce("rangeOverflow",$e(M.value),M.valueAsNumber))},[M.valueAsNumber,M.value,$e,ce]),Jo(()=>{if(!I.current)return;if(I.current.value!=M.value){const je=He(I.current.value);M.setValue(Xe(je))}},[He,Xe]);const St=g.useCallback((B=s)=>{ie&&q(B)},[q,ie,s]),Bt=g.useCallback((B=s)=>{ie&&U(B)},[U,ie,s]),Q=K8(St,Bt);Eb(ue,"disabled",Q.stop,Q.isSpinning),Eb(fe,"disabled",Q.stop,Q.isSpinning);const Ue=g.useCallback(B=>{if(B.nativeEvent.isComposing)return;const mt=He(B.currentTarget.value);W(Xe(mt)),O.current={start:B.currentTarget.selectionStart,end:B.currentTarget.selectionEnd}},[W,Xe,He]),be=g.useCallback(B=>{var je;X==null||X(B),O.current&&(B.currentTarget.selectionStart=O.current.start??((je=B.currentTarget.value)==null?void 0:je.length),B.currentTarget.selectionEnd=O.current.end??B.currentTarget.selectionStart)},[X]),nt=g.useCallback(B=>{if(B.nativeEvent.isComposing)return;X8(B,J)||B.preventDefault();const je=Mt(B)*s,mt=B.key,L={ArrowUp:()=>St(je),ArrowDown:()=>Bt(je),Home:()=>W(o),End:()=>W(i)}[mt];L&&(B.preventDefault(),L(B))},[J,s,St,Bt,W,o,i]),Mt=B=>{let je=1;return(B.metaKey||B.ctrlKey)&&(je=.1),B.shiftKey&&(je=10),je},_t=g.useMemo(()=>{const B=z==null?void 0:z(M.value);if(B!=null)return B;const je=M.value.toString();return je||void 0},[M.value,z]),lt=g.useCallback(()=>{let B=M.value;if(M.value==="")return;/^[eE]/.test(M.value.toString())?M.setValue(""):(M.valueAsNumber<o&&(B=o),M.valueAsNumber>i&&(B=i),M.cast(B))},[M,i,o]),Zt=g.useCallback(()=>{F(!1),n&&lt()},[n,F,lt]),Vt=g.useCallback(()=>{t&&requestAnimationFrame(()=>{var B;(B=I.current)==null||B.focus()})},[t]),Et=g.useCallback(B=>{B.preventDefault(),Q.up(),Vt()},[Vt,Q]),_n=g.useCallback(B=>{B.preventDefault(),Q.down(),Vt()},[Vt,Q]);mw(()=>I.current,"wheel",B=>{var ve;const mt=(((ve=I.current)==null?void 0:ve.ownerDocument)??document).activeElement===I.current;if(!p||!mt)return;B.preventDefault();const Wt=Mt(B)*s,L=Math.sign(B.deltaY);L===-1?St(Wt):L===1&&Bt(Wt)},{passive:!1});const er=g.useCallback((B={},je=null)=>{const mt=l||r&&M.isAtMax;return{...B,ref:Pt(je,ue),role:"button",tabIndex:-1,onPointerDown:ye(B.onPointerDown,Wt=>{Wt.button!==0||mt||Et(Wt)}),onPointerLeave:ye(B.onPointerLeave,Q.stop),onPointerUp:ye(B.onPointerUp,Q.stop),disabled:mt,"aria-disabled":cs(mt)}},[M.isAtMax,r,Et,Q.stop,l]),Pr=g.useCallback((B={},je=null)=>{const mt=l||r&&M.isAtMin;return{...B,ref:Pt(je,fe),role:"button",tabIndex:-1,onPointerDown:ye(B.onPointerDown,Wt=>{Wt.button!==0||mt||_n(Wt)}),onPointerLeave:ye(B.onPointerLeave,Q.stop),onPointerUp:ye(B.onPointerUp,Q.stop),disabled:mt,"aria-disabled":cs(mt)}},[M.isAtMin,r,_n,Q.stop,l]),Tr=g.useCallback((B={},je=null)=>({name:x,inputMode:h,type:"text",pattern:f,"aria-labelledby":w,"aria-label":b,"aria-describedby":m,id:y,disabled:l,role:"spinbutton",...B,readOnly:B.readOnly??a,"aria-readonly":B.readOnly??a,"aria-required":B.required??c,required:B.required??c,ref:Pt(I,je),value:$e(M.value),"aria-valuemin":o,"aria-valuemax":i,"aria-valuenow":Number.isNaN(M.valueAsNumber)?void 0:M.valueAsNumber,"aria-invalid":cs(d??M.isOutOfRange),"aria-valuetext":_t,autoComplete:"off",autoCorrect:"off",onChange:ye(B.onChange,Ue),onKeyDown:ye(B.onKeyDown,nt),onFocus:ye(B.onFocus,be,()=>F(!0)),onBlur:ye(B.onBlur,Y,Zt)}),[x,h,f,w,b,$e,m,y,l,c,a,d,M.value,M.valueAsNumber,M.isOutOfRange,o,i,_t,Ue,nt,be,Y,Zt]);return{value:$e(M.value),valueAsNumber:M.valueAsNumber,isFocused:se,isDisabled:l,isReadOnly:a,getIncrementButtonProps:er,getDecrementButtonProps:Pr,getInputProps:Tr,htmlProps:te}}const[Z8,af]=qe({name:"NumberInputStylesContext",errorMessage:`useNumberInputStyles returned is 'undefined'. Seems you forgot to wrap the components in "<NumberInput />" `}),[J8,Fy]=qe({name:"NumberInputContext",errorMessage:"useNumberInputContext: `context` is undefined. Seems you forgot to wrap number-input's components within <NumberInput />"}),Fo=H(function(t,n){const r=dn("NumberInput",t),o=tt(t),i=yy(o),{htmlProps:s,...a}=Q8(i),l=g.useMemo(()=>a,[a]);return u.jsx(J8,{value:l,children:u.jsx(Z8,{value:r,children:u.jsx(V.div,{...s,ref:n,className:ne("chakra-numberinput",t.className),__css:{position:"relative",zIndex:0,...r.root}})})})});Fo.displayName="NumberInput";const e9=H(function(t,n){const r=af();return u.jsx(V.div,{"aria-hidden":!0,ref:n,...t,__css:{display:"flex",flexDirection:"column",position:"absolute",top:"0",insetEnd:"0px",margin:"1px",height:"calc(100% - 2px)",zIndex:1,...r.stepperGroup}})});e9.displayName="NumberInputStepper";const Lo=H(function(t,n){const{getInputProps:r}=Fy(),o=r(t,n),i=af();return u.jsx(V.input,{...o,className:ne("chakra-numberinput__field",t.className),__css:{width:"100%",...i.field}})});Lo.displayName="NumberInputField";const H2=V("div",{baseStyle:{display:"flex",justifyContent:"center",alignItems:"center",flex:1,transitionProperty:"common",transitionDuration:"normal",userSelect:"none",cursor:"pointer",lineHeight:"normal"}}),t9=H(function(t,n){const r=af(),{getDecrementButtonProps:o}=Fy(),i=o(t,n);return u.jsx(H2,{...i,__css:r.stepper,children:t.children??u.jsx(H8,{})})});t9.displayName="NumberDecrementStepper";const n9=H(function(t,n){const{getIncrementButtonProps:r}=Fy(),o=r(t,n),i=af();return u.jsx(H2,{...o,__css:i.stepper,children:t.children??u.jsx(U8,{})})});n9.displayName="NumberIncrementStepper";function r9(e,t,n){return(e-t)*100/(n-t)}Bl({"0%":{strokeDasharray:"1, 400",strokeDashoffset:"0"},"50%":{strokeDasharray:"400, 400",strokeDashoffset:"-100"},"100%":{strokeDasharray:"400, 400",strokeDashoffset:"-260"}});Bl({"0%":{transform:"rotate(0deg)"},"100%":{transform:"rotate(360deg)"}});const o9=Bl({"0%":{left:"-40%"},"100%":{left:"100%"}}),i9=Bl({from:{backgroundPosition:"1rem 0"},to:{backgroundPosition:"0 0"}});function s9(e){const{value:t=0,min:n,max:r,valueText:o,getValueText:i,isIndeterminate:s,role:a="progressbar"}=e,l=r9(t,n,r);return{bind:{"data-indeterminate":s?"":void 0,"aria-valuemax":r,"aria-valuemin":n,"aria-valuenow":s?void 0:t,"aria-valuetext":(()=>{if(t!=null)return typeof i=="function"?i(t,l):o})(),role:a},percent:l,value:t}}const[a9,l9]=qe({name:"ProgressStylesContext",errorMessage:`useProgressStyles returned is 'undefined'. Seems you forgot to wrap the components in "<Progress />" `}),c9=H((e,t)=>{const{min:n,max:r,value:o,isIndeterminate:i,role:s,...a}=e,l=s9({value:o,min:n,max:r,isIndeterminate:i,role:s}),d={height:"100%",...l9().filledTrack};return u.jsx(V.div,{ref:t,style:{width:`${l.percent}%`,...a.style},...l.bind,...a,__css:d})}),U2=H((e,t)=>{var C;const{value:n,min:r=0,max:o=100,hasStripe:i,isAnimated:s,children:a,borderRadius:l,isIndeterminate:c,"aria-label":d,"aria-labelledby":f,"aria-valuetext":h,title:p,role:y,...v}=tt(e),S=dn("Progress",e),x=l??((C=S.track)==null?void 0:C.borderRadius),m={animation:`${i9} 1s linear infinite`},P={...!c&&i&&s&&m,...c&&{position:"absolute",willChange:"left",minWidth:"50%",animation:`${o9} 1s ease infinite normal none running`}},T={overflow:"hidden",position:"relative",...S.track};return u.jsx(V.div,{ref:t,borderRadius:x,__css:T,...v,children:u.jsxs(a9,{value:S,children:[u.jsx(c9,{"aria-label":d,"aria-labelledby":f,"aria-valuetext":h,min:r,max:o,value:n,isIndeterminate:c,css:P,borderRadius:x,title:p,role:y}),a]})})});U2.displayName="Progress";const G2=H(function(t,n){const{children:r,placeholder:o,className:i,...s}=t;return u.jsxs(V.select,{...s,ref:n,className:ne("chakra-select",i),children:[o&&u.jsx("option",{value:"",children:o}),r]})});G2.displayName="SelectField";const Ja=H((e,t)=>{var b;const n=dn("Select",e),{rootProps:r,placeholder:o,icon:i,color:s,height:a,h:l,minH:c,minHeight:d,iconColor:f,iconSize:h,...p}=tt(e),[y,v]=lj(p,n5),S=gy(v),x={width:"100%",height:"fit-content",position:"relative",color:s},m={paddingEnd:"2rem",...n.field,_focus:{zIndex:"unset",...(b=n.field)==null?void 0:b._focus}};return u.jsxs(V.div,{className:"chakra-select__wrapper",__css:x,...y,...r,children:[u.jsx(G2,{ref:t,height:l??a,minH:c??d,placeholder:o,...S,__css:m,children:e.children}),u.jsx(K2,{"data-disabled":he(S.disabled),...(f||s)&&{color:f||s},__css:n.icon,...h&&{fontSize:h},children:i})]})});Ja.displayName="Select";const u9=e=>u.jsx("svg",{viewBox:"0 0 24 24",...e,children:u.jsx("path",{fill:"currentColor",d:"M16.59 8.59L12 13.17 7.41 8.59 6 10l6 6 6-6z"})}),d9=V("div",{baseStyle:{position:"absolute",display:"inline-flex",alignItems:"center",justifyContent:"center",pointerEvents:"none",top:"50%",transform:"translateY(-50%)"}}),K2=e=>{const{children:t=u.jsx(u9,{}),...n}=e,r=g.cloneElement(t,{role:"presentation",className:"chakra-select__icon",focusable:!1,"aria-hidden":!0,style:{width:"1em",height:"1em",color:"currentColor"}});return u.jsx(d9,{...n,className:"chakra-select__icon-wrapper",children:g.isValidElement(t)?r:null})};K2.displayName="SelectIcon";const Y2=e=>u.jsx(V.div,{className:"chakra-stack__item",...e,__css:{display:"inline-block",flex:"0 0 auto",minWidth:0,...e.__css}});Y2.displayName="StackItem";function f9(e){const{spacing:t,direction:n}=e,r={column:{my:t,mx:0,borderLeftWidth:0,borderBottomWidth:"1px"},"column-reverse":{my:t,mx:0,borderLeftWidth:0,borderBottomWidth:"1px"},row:{mx:t,my:0,borderLeftWidth:"1px",borderBottomWidth:0},"row-reverse":{mx:t,my:0,borderLeftWidth:"1px",borderBottomWidth:0}};return{"&":Sg(n,o=>r[o])}}const Ly=H((e,t)=>{const{isInline:n,direction:r,align:o,justify:i,spacing:s="0.5rem",wrap:a,children:l,divider:c,className:d,shouldWrapChildren:f,...h}=e,p=n?"row":r??"column",y=g.useMemo(()=>f9({spacing:s,direction:p}),[s,p]),v=!!c,S=!f&&!v,x=g.useMemo(()=>{const b=lw(l);return S?b:b.map((w,P)=>{const T=typeof w.key<"u"?w.key:P,C=P+1===b.length,$=f?u.jsx(Y2,{children:w},T):w;if(!v)return $;const j=g.cloneElement(c,{__css:y}),N=C?null:j;return u.jsxs(g.Fragment,{children:[$,N]},T)})},[c,y,v,S,f,l]),m=ne("chakra-stack",d);return u.jsx(V.div,{ref:t,display:"flex",alignItems:o,justifyContent:i,flexDirection:p,flexWrap:a,gap:v?void 0:s,className:m,...h,children:x})});Ly.displayName="Stack";const Me=H((e,t)=>u.jsx(Ly,{align:"center",...e,direction:"row",ref:t}));Me.displayName="HStack";const Ie=H((e,t)=>u.jsx(Ly,{align:"center",...e,direction:"column",ref:t}));Ie.displayName="VStack";const[h9,By]=qe({name:"StatStylesContext",errorMessage:`useStatStyles returned is 'undefined'. Seems you forgot to wrap the components in "<Stat />" `}),hr=H(function(t,n){const r=dn("Stat",t),o={position:"relative",flex:"1 1 0%",...r.container},{className:i,children:s,...a}=tt(t);return u.jsx(h9,{value:r,children:u.jsx(V.div,{ref:n,...a,className:ne("chakra-stat",i),__css:o,children:u.jsx("dl",{children:s})})})});hr.displayName="Stat";const lf=H(function(t,n){return u.jsx(V.div,{...t,ref:n,role:"group",className:ne("chakra-stat__group",t.className),__css:{display:"flex",flexWrap:"wrap",justifyContent:"space-around",alignItems:"flex-start"}})});lf.displayName="StatGroup";const q2=H(function(t,n){const r=By();return u.jsx(V.dd,{ref:n,...t,className:ne("chakra-stat__help-text",t.className),__css:r.helpText})});q2.displayName="StatHelpText";const pr=H(function(t,n){const r=By();return u.jsx(V.dt,{ref:n,...t,className:ne("chakra-stat__label",t.className),__css:r.label})});pr.displayName="StatLabel";const mr=H(function(t,n){const r=By();return u.jsx(V.dd,{ref:n,...t,className:ne("chakra-stat__number",t.className),__css:{...r.number,fontFeatureSettings:"pnum",fontVariantNumeric:"proportional-nums"}})});mr.displayName="StatNumber";const bu=H(function(t,n){const r=dn("Switch",t),{spacing:o="0.5rem",children:i,...s}=tt(t),{getIndicatorProps:a,getInputProps:l,getCheckboxProps:c,getRootProps:d,getLabelProps:f}=OL(s),h=g.useMemo(()=>({display:"inline-block",position:"relative",verticalAlign:"middle",lineHeight:0,...r.container}),[r.container]),p=g.useMemo(()=>({display:"inline-flex",flexShrink:0,justifyContent:"flex-start",boxSizing:"content-box",cursor:"pointer",...r.track}),[r.track]),y=g.useMemo(()=>({userSelect:"none",marginStart:o,...r.label}),[o,r.label]);return u.jsxs(V.label,{...d(),className:ne("chakra-switch",t.className),__css:h,children:[u.jsx("input",{className:"chakra-switch__input",...l({},n)}),u.jsx(V.span,{...c(),className:"chakra-switch__track",__css:p,children:u.jsx(V.span,{__css:r.thumb,className:"chakra-switch__thumb",...a()})}),i&&u.jsx(V.span,{className:"chakra-switch__label",...f(),__css:y,children:i})]})});bu.displayName="Switch";const[p9,ec]=qe({name:"TableStylesContext",errorMessage:`useTableStyles returned is 'undefined'. Seems you forgot to wrap the components in "<Table />" `}),Ns=H((e,t)=>{const n=dn("Table",e),{className:r,layout:o,...i}=tt(e);return u.jsx(p9,{value:n,children:u.jsx(V.table,{ref:t,__css:{tableLayout:o,...n.table},className:ne("chakra-table",r),...i})})});Ns.displayName="Table";const $l=H((e,t)=>{const n=ec();return u.jsx(V.tbody,{...e,ref:t,__css:n.tbody})}),_e=H(({isNumeric:e,...t},n)=>{const r=ec();return u.jsx(V.td,{...t,ref:n,__css:r.td,"data-is-numeric":e})}),Ee=H(({isNumeric:e,...t},n)=>{const r=ec();return u.jsx(V.th,{...t,ref:n,__css:r.th,"data-is-numeric":e})}),Ml=H((e,t)=>{const n=ec();return u.jsx(V.thead,{...e,ref:t,__css:n.thead})}),gr=H((e,t)=>{const n=ec();return u.jsx(V.tr,{...e,ref:t,__css:n.tr})}),[m9,g9,y9,v9]=OC();function x9(e){const{defaultIndex:t,onChange:n,index:r,isManual:o,isLazy:i,lazyBehavior:s="unmount",orientation:a="horizontal",direction:l="ltr",...c}=e,[d,f]=g.useState(t??0),[h,p]=fj({defaultValue:t??0,value:r,onChange:n});g.useEffect(()=>{r!=null&&f(r)},[r]);const y=y9(),v=g.useId();return{id:`tabs-${e.id??v}`,selectedIndex:h,focusedIndex:d,setSelectedIndex:p,setFocusedIndex:f,isManual:o,isLazy:i,lazyBehavior:s,orientation:a,descendants:y,direction:l,htmlProps:c}}const[b9,cf]=qe({name:"TabsContext",errorMessage:"useTabsContext: `context` is undefined. Seems you forgot to wrap all tabs components within <Tabs />"});function S9(e){const{focusedIndex:t,orientation:n,direction:r}=cf(),o=g9(),i=g.useCallback(s=>{const a=()=>{var b;const m=o.nextEnabled(t);m&&((b=m.node)==null||b.focus())},l=()=>{var b;const m=o.prevEnabled(t);m&&((b=m.node)==null||b.focus())},c=()=>{var b;const m=o.firstEnabled();m&&((b=m.node)==null||b.focus())},d=()=>{var b;const m=o.lastEnabled();m&&((b=m.node)==null||b.focus())},f=n==="horizontal",h=n==="vertical",p=s.key,y=r==="ltr"?"ArrowLeft":"ArrowRight",v=r==="ltr"?"ArrowRight":"ArrowLeft",x={[y]:()=>f&&l(),[v]:()=>f&&a(),ArrowDown:()=>h&&a(),ArrowUp:()=>h&&l(),Home:c,End:d}[p];x&&(s.preventDefault(),x(s))},[o,t,n,r]);return{...e,role:"tablist","aria-orientation":n,onKeyDown:ye(e.onKeyDown,i)}}function w9(e){const{isDisabled:t=!1,isFocusable:n=!1,...r}=e,{setSelectedIndex:o,isManual:i,id:s,setFocusedIndex:a,selectedIndex:l}=cf(),{index:c,register:d}=v9({disabled:t&&!n}),f=c===l,h=()=>{o(c)},p=()=>{a(c),!i&&!(t&&n)&&o(c)};return{...j2({...r,ref:Pt(d,e.ref),isDisabled:t,isFocusable:n,onClick:ye(e.onClick,h)}),id:X2(s,c),role:"tab",tabIndex:f?0:-1,type:"button","aria-selected":f,"aria-controls":Q2(s,c),onFocus:t?void 0:ye(e.onFocus,p)}}const[k9,C9]=qe({});function P9(e){const t=cf(),{id:n,selectedIndex:r}=t,i=lw(e.children).map((s,a)=>g.createElement(k9,{key:s.key??a,value:{isSelected:a===r,id:Q2(n,a),tabId:X2(n,a),selectedIndex:r}},s));return{...e,children:i}}function T9(e){const{children:t,...n}=e,{isLazy:r,lazyBehavior:o}=cf(),{isSelected:i,id:s,tabId:a}=C9(),l=g.useRef(!1);i&&(l.current=!0);const c=hw({wasSelected:l.current,isSelected:i,enabled:r,mode:o});return{tabIndex:0,...n,children:c?t:null,role:"tabpanel","aria-labelledby":a,hidden:!i,id:s}}function X2(e,t){return`${e}--tab-${t}`}function Q2(e,t){return`${e}--tabpanel-${t}`}const[_9,uf]=qe({name:"TabsStylesContext",errorMessage:`useTabsStyles returned is 'undefined'. Seems you forgot to wrap the components in "<Tabs />" `}),Z2=H(function(t,n){const r=dn("Tabs",t),{children:o,className:i,...s}=tt(t),{htmlProps:a,descendants:l,...c}=x9(s),d=g.useMemo(()=>c,[c]),{isFitted:f,...h}=a,p={position:"relative",...r.root};return u.jsx(m9,{value:l,children:u.jsx(b9,{value:d,children:u.jsx(_9,{value:r,children:u.jsx(V.div,{className:ne("chakra-tabs",i),ref:n,...h,__css:p,children:o})})})})});Z2.displayName="Tabs";const Su=H(function(t,n){const r=uf(),o=w9({...t,ref:n}),i={outline:"0",display:"flex",alignItems:"center",justifyContent:"center",...r.tab};return u.jsx(V.button,{...o,className:ne("chakra-tabs__tab",t.className),__css:i})});Su.displayName="Tab";const J2=H(function(t,n){const r=S9({...t,ref:n}),o=uf(),i={display:"flex",...o.tablist};return u.jsx(V.div,{...r,className:ne("chakra-tabs__tablist",t.className),__css:i})});J2.displayName="TabList";const wu=H(function(t,n){const r=T9({...t,ref:n}),o=uf();return u.jsx(V.div,{outline:"0",...r,className:ne("chakra-tabs__tab-panel",t.className),__css:o.tabpanel})});wu.displayName="TabPanel";const eP=H(function(t,n){const r=P9(t),o=uf();return u.jsx(V.div,{...r,width:"100%",ref:n,className:ne("chakra-tabs__tab-panels",t.className),__css:o.tabpanels})});eP.displayName="TabPanels";const[E9,tP]=qe({name:"TagStylesContext",errorMessage:`useTagStyles returned is 'undefined'. Seems you forgot to wrap the components in "<Tag />" `}),_a=H((e,t)=>{const n=dn("Tag",e),r=tt(e),o={display:"inline-flex",verticalAlign:"top",alignItems:"center",maxWidth:"100%",...n.container};return u.jsx(E9,{value:n,children:u.jsx(V.span,{ref:t,...r,__css:o})})});_a.displayName="Tag";const Ea=H((e,t)=>{const n=tP();return u.jsx(V.span,{ref:t,noOfLines:1,...e,__css:n.label})});Ea.displayName="TagLabel";const j9=H((e,t)=>u.jsx(Jn,{ref:t,verticalAlign:"top",marginEnd:"0.5rem",...e}));j9.displayName="TagLeftIcon";const R9=H((e,t)=>u.jsx(Jn,{ref:t,verticalAlign:"top",marginStart:"0.5rem",...e}));R9.displayName="TagRightIcon";const nP=e=>u.jsx(Jn,{verticalAlign:"inherit",viewBox:"0 0 512 512",...e,children:u.jsx("path",{fill:"currentColor",d:"M289.94 256l95-95A24 24 0 00351 127l-95 95-95-95a24 24 0 00-34 34l95 95-95 95a24 24 0 1034 34l95-95 95 95a24 24 0 0034-34z"})});nP.displayName="TagCloseIcon";const ja=H((e,t)=>{const{isDisabled:n,children:r,...o}=e,s={display:"flex",alignItems:"center",justifyContent:"center",outline:"0",...tP().closeButton};return u.jsx(V.button,{ref:t,"aria-label":"close",...o,type:"button",disabled:n,__css:s,children:r||u.jsx(nP,{})})});ja.displayName="TagCloseButton";const A9=["h","minH","height","minHeight"],Sm=H((e,t)=>{const n=Zn("Textarea",e),{className:r,rows:o,...i}=tt(e),s=gy(i),a=o?bg(n,A9):n;return u.jsx(V.textarea,{ref:t,rows:o,...s,className:ne("chakra-textarea",r),__css:a})});Sm.displayName="Textarea";function $9(e,t){const n=e??"bottom",o={"top-start":{ltr:"top-left",rtl:"top-right"},"top-end":{ltr:"top-right",rtl:"top-left"},"bottom-start":{ltr:"bottom-left",rtl:"bottom-right"},"bottom-end":{ltr:"bottom-right",rtl:"bottom-left"}}[n];return(o==null?void 0:o[t])??n}function M9(e,t){const n=o=>({...t,...o,position:$9((o==null?void 0:o.position)??(t==null?void 0:t.position),e)}),r=o=>{const i=n(o),s=MC(i);return ur.notify(s,i)};return r.update=(o,i)=>{ur.update(o,n(i))},r.promise=(o,i)=>{const s=r({...i.loading,status:"loading",duration:null});o.then(a=>r.update(s,{status:"success",duration:5e3,...yn(i.success,a)})).catch(a=>r.update(s,{status:"error",duration:5e3,...yn(i.error,a)}))},r.closeAll=ur.closeAll,r.close=ur.close,r.isActive=ur.isActive,r}function tc(e){const{theme:t}=RC(),n=lL();return g.useMemo(()=>M9(t.direction,{...n,...e}),[e,t.direction,n])}const at=H(function(t,n){const r=Zn("Heading",t),{className:o,...i}=tt(t);return u.jsx(V.h2,{ref:n,className:ne("chakra-heading",t.className),...i,__css:r})});at.displayName="Heading";const ee=H(function(t,n){const r=Zn("Text",t),{className:o,align:i,decoration:s,casing:a,...l}=tt(t),c=cw({textAlign:t.align,textDecoration:t.decoration,textTransform:t.casing});return u.jsx(V.p,{ref:n,className:ne("chakra-text",t.className),...c,...l,__css:r})});ee.displayName="Text";const Ra=H(function(t,n){const{spacing:r="0.5rem",spacingX:o,spacingY:i,children:s,justify:a,direction:l,align:c,className:d,shouldWrapChildren:f,...h}=t,p=g.useMemo(()=>f?g.Children.map(s,(y,v)=>u.jsx(ns,{children:y},v)):s,[s,f]);return u.jsx(V.div,{ref:n,className:ne("chakra-wrap",d),...h,children:u.jsx(V.ul,{className:"chakra-wrap__list",__css:{display:"flex",flexWrap:"wrap",justifyContent:a,alignItems:c,flexDirection:l,listStyleType:"none",gap:r,columnGap:o,rowGap:i,padding:"0"},children:p})})});Ra.displayName="Wrap";const ns=H(function(t,n){const{className:r,...o}=t;return u.jsx(V.li,{ref:n,__css:{display:"flex",alignItems:"flex-start"},className:ne("chakra-wrap__listitem",r),...o})});ns.displayName="WrapItem";const Aa=Vs({d:"M0,12a1.5,1.5,0,0,0,1.5,1.5h8.75a.25.25,0,0,1,.25.25V22.5a1.5,1.5,0,0,0,3,0V13.75a.25.25,0,0,1,.25-.25H22.5a1.5,1.5,0,0,0,0-3H13.75a.25.25,0,0,1-.25-.25V1.5a1.5,1.5,0,0,0-3,0v8.75a.25.25,0,0,1-.25.25H1.5A1.5,1.5,0,0,0,0,12Z",displayName:"AddIcon"}),rP=Vs({displayName:"CheckCircleIcon",d:"M12,0A12,12,0,1,0,24,12,12.014,12.014,0,0,0,12,0Zm6.927,8.2-6.845,9.289a1.011,1.011,0,0,1-1.43.188L5.764,13.769a1,1,0,1,1,1.25-1.562l4.076,3.261,6.227-8.451A1,1,0,1,1,18.927,8.2Z"}),I9=Vs({displayName:"DeleteIcon",path:u.jsx("g",{fill:"currentColor",children:u.jsx("path",{d:"M19.452 7.5H4.547a.5.5 0 00-.5.545l1.287 14.136A2 2 0 007.326 24h9.347a2 2 0 001.992-1.819L19.95 8.045a.5.5 0 00-.129-.382.5.5 0 00-.369-.163zm-9.2 13a.75.75 0 01-1.5 0v-9a.75.75 0 011.5 0zm5 0a.75.75 0 01-1.5 0v-9a.75.75 0 011.5 0zM22 4h-4.75a.25.25 0 01-.25-.25V2.5A2.5 2.5 0 0014.5 0h-5A2.5 2.5 0 007 2.5v1.25a.25.25 0 01-.25.25H2a1 1 0 000 2h20a1 1 0 000-2zM9 3.75V2.5a.5.5 0 01.5-.5h5a.5.5 0 01.5.5v1.25a.25.25 0 01-.25.25h-5.5A.25.25 0 019 3.75z"})})}),O9=Vs({displayName:"RepeatIcon",path:u.jsxs("g",{fill:"currentColor",children:[u.jsx("path",{d:"M10.319,4.936a7.239,7.239,0,0,1,7.1,2.252,1.25,1.25,0,1,0,1.872-1.657A9.737,9.737,0,0,0,9.743,2.5,10.269,10.269,0,0,0,2.378,9.61a.249.249,0,0,1-.271.178l-1.033-.13A.491.491,0,0,0,.6,9.877a.5.5,0,0,0-.019.526l2.476,4.342a.5.5,0,0,0,.373.248.43.43,0,0,0,.062,0,.5.5,0,0,0,.359-.152l3.477-3.593a.5.5,0,0,0-.3-.844L5.15,10.172a.25.25,0,0,1-.2-.333A7.7,7.7,0,0,1,10.319,4.936Z"}),u.jsx("path",{d:"M23.406,14.1a.5.5,0,0,0,.015-.526l-2.5-4.329A.5.5,0,0,0,20.546,9a.489.489,0,0,0-.421.151l-3.456,3.614a.5.5,0,0,0,.3.842l1.848.221a.249.249,0,0,1,.183.117.253.253,0,0,1,.023.216,7.688,7.688,0,0,1-5.369,4.9,7.243,7.243,0,0,1-7.1-2.253,1.25,1.25,0,1,0-1.872,1.656,9.74,9.74,0,0,0,9.549,3.03,10.261,10.261,0,0,0,7.369-7.12.251.251,0,0,1,.27-.179l1.058.127a.422.422,0,0,0,.06,0A.5.5,0,0,0,23.406,14.1Z"})]})}),oP=Vs({displayName:"TimeIcon",path:u.jsxs("g",{fill:"currentColor",children:[u.jsx("path",{d:"M12,0A12,12,0,1,0,24,12,12.014,12.014,0,0,0,12,0Zm0,22A10,10,0,1,1,22,12,10.011,10.011,0,0,1,12,22Z"}),u.jsx("path",{d:"M17.134,15.81,12.5,11.561V6.5a1,1,0,0,0-2,0V12a1,1,0,0,0,.324.738l4.959,4.545a1.01,1.01,0,0,0,1.413-.061A1,1,0,0,0,17.134,15.81Z"})]})}),iP=Vs({d:"M11.983,0a12.206,12.206,0,0,0-8.51,3.653A11.8,11.8,0,0,0,0,12.207,11.779,11.779,0,0,0,11.8,24h.214A12.111,12.111,0,0,0,24,11.791h0A11.766,11.766,0,0,0,11.983,0ZM10.5,16.542a1.476,1.476,0,0,1,1.449-1.53h.027a1.527,1.527,0,0,1,1.523,1.47,1.475,1.475,0,0,1-1.449,1.53h-.027A1.529,1.529,0,0,1,10.5,16.542ZM11,12.5v-6a1,1,0,0,1,2,0v6a1,1,0,1,1-2,0Z",displayName:"WarningIcon"}),Lt="/api";async function Qt(e,t={}){const n=await fetch(e,t);if(!n.ok)throw new Error(`HTTP error! status: ${n.status}`);const r=await n.text();if(!r||r.trim()==="")throw new Error("Empty response from server");const o=JSON.parse(r);if(o.code!==0)throw new Error(o.message||"Unknown error");return o.data}async function z9(){return await Qt(`${Lt}/configs`)}async function sP(e){return await Qt(`${Lt}/configs/${e}`)}async function N9(e,t){return await Qt(`${Lt}/configs`,{method:"POST",headers:{"Content-Type":"application/json"},body:JSON.stringify({name:e,config:t})})}async function D9(e,t){return await Qt(`${Lt}/configs/${e}`,{method:"PUT",headers:{"Content-Type":"application/json"},body:JSON.stringify(t)})}async function F9(e){return await Qt(`${Lt}/configs/${e}`,{method:"DELETE"})}async function L9(e){const t=await fetch(`${Lt}/configs/${e}/validate`,{method:"POST"});if(!t.ok)throw new Error(`HTTP error! status: ${t.status}`);const n=await t.text();if(!n||n.trim()==="")throw new Error("Empty response from server");const r=JSON.parse(n);if(r.code!==0&&r.data&&r.data.errors){const o=new Error(r.message||"Validation failed");throw o.errors=r.data.errors,o}if(r.code!==0)throw new Error(r.message||"Unknown error");return r.data}async function B9(e){return await Qt(`${Lt}/configs/${e}/preview`)}async function aP(){return await Qt(`${Lt}/dictionary/hostnames`)}async function V9(e){return await Qt(`${Lt}/dictionary/hostnames`,{method:"PUT",headers:{"Content-Type":"application/json"},body:JSON.stringify({hostnames:e})})}async function lP(){return await Qt(`${Lt}/dictionary/hcas`)}async function W9(e){return await Qt(`${Lt}/dictionary/hcas`,{method:"PUT",headers:{"Content-Type":"application/json"},body:JSON.stringify({hcas:e})})}async function H9(e){return await Qt(`${Lt}/configs/${e}/precheck`,{method:"POST"})}async function U9(e){return await Qt(`${Lt}/configs/${e}/run`,{method:"POST"})}async function G9(e){return await Qt(`${Lt}/configs/${e}/probe`,{method:"POST"})}async function K9(e){return await Qt(`${Lt}/configs/${e}/collect`,{method:"POST"})}async function Y9(e){return await Qt(`${Lt}/configs/${e}/report`)}function q9({configs:e,currentConfig:t,onSelect:n,onRefresh:r}){const{isOpen:o,onOpen:i,onClose:s}=Pd(),[a,l]=g.useState(""),[c,d]=g.useState(!1),f=tc(),h=async()=>{if(!a.trim()){f({title:"请输入配置文件名",status:"warning",duration:2e3});return}const y=a.endsWith(".yaml")?a:a+".yaml";try{d(!0),await N9(y,{server:{hostname:[],hca:[]},client:{hostname:[],hca:[]}}),f({title:"创建成功！",status:"success",duration:2e3}),s(),l(""),r(),n(y)}catch(v){f({title:"创建失败",description:v.message,status:"error",duration:3e3})}finally{d(!1)}},p=async(y,v,S)=>{if(v||!S){f({title:"无法删除",description:"默认配置文件不可删除",status:"warning",duration:2e3});return}if(window.confirm(`确定要删除配置 "${y}" 吗？`))try{await F9(y),f({title:"删除成功！",status:"success",duration:2e3}),r()}catch(x){f({title:"删除失败",description:x.message,status:"error",duration:3e3})}};return u.jsxs(ae,{w:"280px",bg:"gray.100",borderRight:"1px",borderColor:"gray.200",display:"flex",flexDirection:"column",children:[u.jsx(ae,{p:4,borderBottom:"1px",borderColor:"gray.200",children:u.jsxs(Me,{spacing:2,children:[u.jsx(vt,{leftIcon:u.jsx(Aa,{}),colorScheme:"blue",size:"sm",flex:1,onClick:i,children:"新建配置"}),u.jsx(om,{icon:u.jsx(O9,{}),size:"sm",onClick:r,"aria-label":"刷新列表"})]})}),u.jsx(Ie,{flex:1,overflowY:"auto",spacing:1,p:2,align:"stretch",children:e.map(y=>u.jsxs(Me,{p:3,bg:t===y.name?"blue.500":"white",color:t===y.name?"white":"gray.800",borderRadius:"md",cursor:"pointer",onClick:()=>n(y.name),_hover:{bg:t===y.name?"blue.600":"gray.50"},justify:"space-between",children:[u.jsxs(Me,{spacing:2,flex:1,children:[u.jsx(ee,{fontSize:"lg",children:y.is_default?"⭐":"📄"}),u.jsx(ee,{fontSize:"sm",fontWeight:"medium",isTruncated:!0,children:y.name})]}),y.is_deletable&&u.jsx(om,{icon:u.jsx(I9,{}),size:"xs",colorScheme:"red",variant:"ghost","aria-label":"删除",onClick:v=>{v.stopPropagation(),p(y.name,y.is_default,y.is_deletable)}})]},y.name))}),u.jsxs(Jd,{isOpen:o,onClose:s,children:[u.jsx(sf,{}),u.jsxs(tf,{children:[u.jsx(of,{children:"创建配置文件"}),u.jsx(rf,{}),u.jsx(nf,{children:u.jsxs(ot,{children:[u.jsx(it,{children:"文件名"}),u.jsx(Xo,{placeholder:"例如: my-config.yaml",value:a,onChange:y=>l(y.target.value)})]})}),u.jsxs(Dy,{children:[u.jsx(vt,{variant:"ghost",mr:3,onClick:s,children:"取消"}),u.jsx(vt,{colorScheme:"blue",onClick:h,isLoading:c,children:"创建"})]})]})]})]})}function X9({currentConfig:e,configData:t,originalData:n,loading:r,onSave:o,onCancel:i,onChange:s}){var X,Y,ce,J,z,M,W,q,U,se,F,ie;const[a,l]=g.useState(!1),[c,d]=g.useState(!1),[f,h]=g.useState(""),[p,y]=g.useState([]),[v,S]=g.useState([]),{isOpen:x,onOpen:m,onClose:b}=Pd(),w=tc();if(g.useEffect(()=>{(async()=>{try{const[O,ue]=await Promise.all([aP(),lP()]);y(O),S(ue)}catch(O){console.error("加载字典失败:",O)}})()},[]),!e)return u.jsxs(am,{flex:1,flexDirection:"column",color:"gray.500",children:[u.jsx(ee,{fontSize:"4xl",mb:4,children:"📝"}),u.jsx(ee,{children:"请在左侧选择或创建一个配置文件"})]});if(r||!t)return u.jsx(am,{flex:1,children:u.jsx(Yl,{size:"xl",color:"blue.500"})});const P=(I,O)=>{s({...t,[I]:O})},T=(I,O,ue)=>{s({...t,[I]:{...t[I],[O]:ue}})},C=(I,O,ue)=>{if(ue&&ue.trim()){const fe=t[I][O]||[];fe.includes(ue.trim())||s({...t,[I]:{...t[I],[O]:[...fe,ue.trim()]}})}},R=(I,O,ue)=>{const fe=prompt(`手动输入 ${ue}:`);fe&&fe.trim()&&C(I,O,fe.trim())},$=(I,O,ue)=>{const fe=t[I][O]||[];s({...t,[I]:{...t[I],[O]:fe.filter((Xe,He)=>He!==ue)}})},j=async()=>{try{l(!0),await D9(e,t),w({title:"保存成功！",status:"success",duration:2e3}),o()}catch(I){w({title:"保存失败",description:I.message,status:"error",duration:3e3})}finally{l(!1)}},N=async()=>{try{d(!0),await L9(e),w({title:"✓ 配置验证通过！",status:"success",duration:2e3})}catch(I){let O=I.message;I.errors&&I.errors.length>0&&(O=I.errors.join(`
+`)},j8=0,$i=[];function R8(e){var t=g.useRef([]),n=g.useRef([0,0]),r=g.useRef(),o=g.useState(j8++)[0],i=g.useState(z2)[0],s=g.useRef(e);g.useEffect(function(){s.current=e},[e]),g.useEffect(function(){if(e.inert){document.body.classList.add("block-interactivity-".concat(o));var v=WL([e.lockRef.current],(e.shards||[]).map(_b),!0).filter(Boolean);return v.forEach(function(S){return S.classList.add("allow-interactivity-".concat(o))}),function(){document.body.classList.remove("block-interactivity-".concat(o)),v.forEach(function(S){return S.classList.remove("allow-interactivity-".concat(o))})}}},[e.inert,e.lockRef.current,e.shards]);var a=g.useCallback(function(v,S){if("touches"in v&&v.touches.length===2||v.type==="wheel"&&v.ctrlKey)return!s.current.allowPinchZoom;var x=Wc(v),m=n.current,b="deltaX"in v?v.deltaX:m[0]-x[0],w="deltaY"in v?v.deltaY:m[1]-x[1],P,T=v.target,C=Math.abs(b)>Math.abs(w)?"h":"v";if("touches"in v&&C==="h"&&T.type==="range")return!1;var R=Pb(C,T);if(!R)return!0;if(R?P=C:(P=C==="v"?"h":"v",R=Pb(C,T)),!R)return!1;if(!r.current&&"changedTouches"in v&&(b||w)&&(r.current=P),!P)return!0;var $=r.current||P;return T8($,S,v,$==="h"?b:w)},[]),l=g.useCallback(function(v){var S=v;if(!(!$i.length||$i[$i.length-1]!==i)){var x="deltaY"in S?Tb(S):Wc(S),m=t.current.filter(function(P){return P.name===S.type&&(P.target===S.target||S.target===P.shadowParent)&&_8(P.delta,x)})[0];if(m&&m.should){S.cancelable&&S.preventDefault();return}if(!m){var b=(s.current.shards||[]).map(_b).filter(Boolean).filter(function(P){return P.contains(S.target)}),w=b.length>0?a(S,b[0]):!s.current.noIsolation;w&&S.cancelable&&S.preventDefault()}}},[]),c=g.useCallback(function(v,S,x,m){var b={name:v,delta:S,target:x,should:m,shadowParent:A8(x)};t.current.push(b),setTimeout(function(){t.current=t.current.filter(function(w){return w!==b})},1)},[]),d=g.useCallback(function(v){n.current=Wc(v),r.current=void 0},[]),f=g.useCallback(function(v){c(v.type,Tb(v),v.target,a(v,e.lockRef.current))},[]),h=g.useCallback(function(v){c(v.type,Wc(v),v.target,a(v,e.lockRef.current))},[]);g.useEffect(function(){return $i.push(i),e.setCallbacks({onScrollCapture:f,onWheelCapture:f,onTouchMoveCapture:h}),document.addEventListener("wheel",l,Ai),document.addEventListener("touchmove",l,Ai),document.addEventListener("touchstart",d,Ai),function(){$i=$i.filter(function(v){return v!==i}),document.removeEventListener("wheel",l,Ai),document.removeEventListener("touchmove",l,Ai),document.removeEventListener("touchstart",d,Ai)}},[]);var p=e.removeScrollBar,y=e.inert;return g.createElement(g.Fragment,null,y?g.createElement(i,{styles:E8(o)}):null,p?g.createElement(x8,{noRelative:e.noRelative,gapMode:e.gapMode}):null)}function A8(e){for(var t=null;e!==null;)e instanceof ShadowRoot&&(t=e.host,e=e.host),e=e.parentNode;return t}const $8=HL(O2,R8);var L2=g.forwardRef(function(e,t){return g.createElement(ef,dr({},e,{ref:t,sideCar:$8}))});L2.classNames=ef.classNames;function M8(e){const{autoFocus:t,trapFocus:n,dialogRef:r,initialFocusRef:o,blockScrollOnMount:i,allowPinchZoom:s,finalFocusRef:a,returnFocusOnClose:l,preserveScrollBarGap:c,lockFocusAcrossFrames:d,isOpen:f}=xi(),[h,p]=pC();g.useEffect(()=>{!h&&p&&setTimeout(p)},[h,p]);const y=I2(r,f);return u.jsx(y2,{autoFocus:t,isDisabled:!n,initialFocusRef:o,finalFocusRef:a,restoreFocus:l,contentRef:r,lockFocusAcrossFrames:d,children:u.jsx(L2,{removeScrollBar:!c,allowPinchZoom:s,enabled:y===1&&i,forwardProps:!0,children:e.children})})}const I8={initial:({offsetX:e,offsetY:t,transition:n,transitionEnd:r,delay:o})=>({opacity:0,x:e,y:t,transition:(n==null?void 0:n.exit)??ii.exit(oi.exit,o),transitionEnd:r==null?void 0:r.exit}),enter:({transition:e,transitionEnd:t,delay:n})=>({opacity:1,x:0,y:0,transition:(e==null?void 0:e.enter)??ii.enter(oi.enter,n),transitionEnd:t==null?void 0:t.enter}),exit:({offsetY:e,offsetX:t,transition:n,transitionEnd:r,reverse:o,delay:i})=>{const s={x:t,y:e};return{opacity:0,transition:(n==null?void 0:n.exit)??ii.exit(oi.exit,i),...o?{...s,transitionEnd:r==null?void 0:r.exit}:{transitionEnd:{...s,...r==null?void 0:r.exit}}}}},Ta={initial:"initial",animate:"enter",exit:"exit",variants:I8},O8=g.forwardRef(function(t,n){const{unmountOnExit:r,in:o,reverse:i=!0,className:s,offsetX:a=0,offsetY:l=8,transition:c,transitionEnd:d,delay:f,animatePresenceProps:h,...p}=t,y=r?o&&r:!0,v=o||r?"enter":"exit",S={offsetX:a,offsetY:l,reverse:i,transition:c,transitionEnd:d,delay:f};return u.jsx(Kl,{...h,custom:S,children:y&&u.jsx(Ci.div,{ref:n,className:ne("chakra-offset-slide",s),custom:S,...Ta,animate:v,...p})})});O8.displayName="SlideFade";const z8={exit:({reverse:e,initialScale:t,transition:n,transitionEnd:r,delay:o})=>({opacity:0,...e?{scale:t,transitionEnd:r==null?void 0:r.exit}:{transitionEnd:{scale:t,...r==null?void 0:r.exit}},transition:(n==null?void 0:n.exit)??ii.exit(oi.exit,o)}),enter:({transitionEnd:e,transition:t,delay:n})=>({opacity:1,scale:1,transition:(t==null?void 0:t.enter)??ii.enter(oi.enter,n),transitionEnd:e==null?void 0:e.enter})},B2={initial:"exit",animate:"enter",exit:"exit",variants:z8},N8=g.forwardRef(function(t,n){const{unmountOnExit:r,in:o,reverse:i=!0,initialScale:s=.95,className:a,transition:l,transitionEnd:c,delay:d,animatePresenceProps:f,...h}=t,p=r?o&&r:!0,y=o||r?"enter":"exit",v={initialScale:s,reverse:i,transition:l,transitionEnd:c,delay:d};return u.jsx(Kl,{...f,custom:v,children:p&&u.jsx(Ci.div,{ref:n,className:ne("chakra-offset-slide",a),...B2,animate:y,custom:v,...h})})});N8.displayName="ScaleFade";const D8={slideInBottom:{...Ta,custom:{offsetY:16,reverse:!0}},slideInRight:{...Ta,custom:{offsetX:16,reverse:!0}},slideInTop:{...Ta,custom:{offsetY:-16,reverse:!0}},slideInLeft:{...Ta,custom:{offsetX:-16,reverse:!0}},scale:{...B2,custom:{initialScale:.95,reverse:!0}},none:{}},F8=V(Ci.section),L8=e=>D8[e||"none"],V2=g.forwardRef((e,t)=>{const{preset:n,motionProps:r=L8(n),...o}=e;return u.jsx(F8,{ref:t,...r,...o})});V2.displayName="ModalTransition";const tf=H((e,t)=>{const{className:n,children:r,containerProps:o,motionProps:i,...s}=e,{getDialogProps:a,getDialogContainerProps:l}=xi(),c=a(s,t),d=l(o),f=ne("chakra-modal__content",n),h=Hs(),p={display:"flex",flexDirection:"column",position:"relative",width:"100%",outline:0,...h.dialog},y={display:"flex",width:"100vw",height:"$100vh",position:"fixed",left:0,top:0,...h.dialogContainer},{motionPreset:v}=xi();return u.jsx(M8,{children:u.jsx(V.div,{...d,className:"chakra-modal__content-container",tabIndex:-1,__css:y,children:u.jsx(V2,{preset:v,motionProps:i,className:f,...c,__css:p,children:r})})})});tf.displayName="ModalContent";const nf=H((e,t)=>{const{className:n,...r}=e,{bodyId:o,setBodyMounted:i}=xi();g.useEffect(()=>(i(!0),()=>i(!1)),[i]);const s=ne("chakra-modal__body",n),a=Hs();return u.jsx(V.div,{ref:t,className:s,id:o,...r,__css:a.body})});nf.displayName="ModalBody";const rf=H((e,t)=>{const{onClick:n,className:r,...o}=e,{onClose:i}=xi(),s=ne("chakra-modal__close-btn",r),a=Hs();return u.jsx(hy,{ref:t,__css:a.closeButton,className:s,onClick:ye(n,l=>{l.stopPropagation(),i()}),...o})});rf.displayName="ModalCloseButton";const Dy=H((e,t)=>{const{className:n,...r}=e,o=ne("chakra-modal__footer",n),i=Hs(),s={display:"flex",alignItems:"center",justifyContent:"flex-end",...i.footer};return u.jsx(V.footer,{ref:t,...r,__css:s,className:o})});Dy.displayName="ModalFooter";const of=H((e,t)=>{const{className:n,...r}=e,{headerId:o,setHeaderMounted:i}=xi();g.useEffect(()=>(i(!0),()=>i(!1)),[i]);const s=ne("chakra-modal__header",n),a=Hs(),l={flex:0,...a.header};return u.jsx(V.header,{ref:t,className:s,id:o,...r,__css:l})});of.displayName="ModalHeader";const B8={enter:({transition:e,transitionEnd:t,delay:n}={})=>({opacity:1,transition:(e==null?void 0:e.enter)??ii.enter(oi.enter,n),transitionEnd:t==null?void 0:t.enter}),exit:({transition:e,transitionEnd:t,delay:n}={})=>({opacity:0,transition:(e==null?void 0:e.exit)??ii.exit(oi.exit,n),transitionEnd:t==null?void 0:t.exit})},W2={initial:"exit",animate:"enter",exit:"exit",variants:B8},V8=g.forwardRef(function(t,n){const{unmountOnExit:r,in:o,className:i,transition:s,transitionEnd:a,delay:l,animatePresenceProps:c,...d}=t,f=o||r?"enter":"exit",h=r?o&&r:!0,p={transition:s,transitionEnd:a,delay:l};return u.jsx(Kl,{...c,custom:p,children:h&&u.jsx(Ci.div,{ref:n,className:ne("chakra-fade",i),custom:p,...W2,animate:f,...d})})});V8.displayName="Fade";const W8=V(Ci.div),sf=H((e,t)=>{const{className:n,transition:r,motionProps:o,...i}=e,s=ne("chakra-modal__overlay",n),l={pos:"fixed",left:"0",top:"0",w:"100vw",h:"100vh",...Hs().overlay},{motionPreset:c}=xi(),f=o||(c==="none"?{}:W2);return u.jsx(W8,{...f,__css:l,ref:t,className:s,...i})});sf.displayName="ModalOverlay";const H8=e=>u.jsx(Jn,{viewBox:"0 0 24 24",...e,children:u.jsx("path",{fill:"currentColor",d:"M21,5H3C2.621,5,2.275,5.214,2.105,5.553C1.937,5.892,1.973,6.297,2.2,6.6l9,12 c0.188,0.252,0.485,0.4,0.8,0.4s0.611-0.148,0.8-0.4l9-12c0.228-0.303,0.264-0.708,0.095-1.047C21.725,5.214,21.379,5,21,5z"})}),U8=e=>u.jsx(Jn,{viewBox:"0 0 24 24",...e,children:u.jsx("path",{fill:"currentColor",d:"M12.8,5.4c-0.377-0.504-1.223-0.504-1.6,0l-9,12c-0.228,0.303-0.264,0.708-0.095,1.047 C2.275,18.786,2.621,19,3,19h18c0.379,0,0.725-0.214,0.895-0.553c0.169-0.339,0.133-0.744-0.095-1.047L12.8,5.4z"})});function Eb(e,t,n,r){g.useEffect(()=>{if(!e.current||!r)return;const o=e.current.ownerDocument.defaultView??window,i=Array.isArray(t)?t:[t],s=new o.MutationObserver(a=>{for(const l of a)l.type==="attributes"&&l.attributeName&&i.includes(l.attributeName)&&n(l)});return s.observe(e.current,{attributes:!0,attributeFilter:i}),()=>s.disconnect()})}const G8=50,jb=300;function K8(e,t){const[n,r]=g.useState(!1),[o,i]=g.useState(null),[s,a]=g.useState(!0),l=g.useRef(null),c=()=>clearTimeout(l.current);vj(()=>{o==="increment"&&e(),o==="decrement"&&t()},n?G8:null);const d=g.useCallback(()=>{s&&e(),l.current=setTimeout(()=>{a(!1),r(!0),i("increment")},jb)},[e,s]),f=g.useCallback(()=>{s&&t(),l.current=setTimeout(()=>{a(!1),r(!0),i("decrement")},jb)},[t,s]),h=g.useCallback(()=>{a(!0),r(!1),c()},[]);return g.useEffect(()=>()=>c(),[]),{up:d,down:f,stop:h,isSpinning:n}}const Y8=/^[Ee0-9+\-.]$/;function q8(e){return Y8.test(e)}function X8(e,t){if(e.key==null)return!0;const n=e.ctrlKey||e.altKey||e.metaKey;return!(e.key.length===1)||n?!0:t(e.key)}function Q8(e={}){const{focusInputOnChange:t=!0,clampValueOnBlur:n=!0,keepWithinRange:r=!0,min:o=Number.MIN_SAFE_INTEGER,max:i=Number.MAX_SAFE_INTEGER,step:s=1,isReadOnly:a,isDisabled:l,isRequired:c,isInvalid:d,pattern:f="[0-9]*(.[0-9]+)?",inputMode:h="decimal",allowMouseWheel:p,id:y,onChange:v,precision:S,name:x,"aria-describedby":m,"aria-label":b,"aria-labelledby":w,onFocus:P,onBlur:T,onInvalid:C,getAriaValueText:R,isValidCharacter:$,format:j,parse:N,...te}=e,X=kt(P),Y=kt(T),ce=kt(C),J=kt($??q8),z=kt(R),M=hj(e),{update:W,increment:q,decrement:U}=M,[se,F]=g.useState(!1),ie=!(a||l),I=g.useRef(null),O=g.useRef(null),ue=g.useRef(null),fe=g.useRef(null),Xe=g.useCallback(B=>B.split("").filter(J).join(""),[J]),He=g.useCallback(B=>(N==null?void 0:N(B))??B,[N]),$e=g.useCallback(B=>((j==null?void 0:j(B))??B).toString(),[j]);hi(()=>{(M.valueAsNumber>i||M.valueAsNumber<o)&&(ce==null||ce("rangeOverflow",$e(M.value),M.valueAsNumber))},[M.valueAsNumber,M.value,$e,ce]),Jo(()=>{if(!I.current)return;if(I.current.value!=M.value){const je=He(I.current.value);M.setValue(Xe(je))}},[He,Xe]);const St=g.useCallback((B=s)=>{ie&&q(B)},[q,ie,s]),Bt=g.useCallback((B=s)=>{ie&&U(B)},[U,ie,s]),Q=K8(St,Bt);Eb(ue,"disabled",Q.stop,Q.isSpinning),Eb(fe,"disabled",Q.stop,Q.isSpinning);const Ue=g.useCallback(B=>{if(B.nativeEvent.isComposing)return;const mt=He(B.currentTarget.value);W(Xe(mt)),O.current={start:B.currentTarget.selectionStart,end:B.currentTarget.selectionEnd}},[W,Xe,He]),be=g.useCallback(B=>{var je;X==null||X(B),O.current&&(B.currentTarget.selectionStart=O.current.start??((je=B.currentTarget.value)==null?void 0:je.length),B.currentTarget.selectionEnd=O.current.end??B.currentTarget.selectionStart)},[X]),nt=g.useCallback(B=>{if(B.nativeEvent.isComposing)return;X8(B,J)||B.preventDefault();const je=Mt(B)*s,mt=B.key,L={ArrowUp:()=>St(je),ArrowDown:()=>Bt(je),Home:()=>W(o),End:()=>W(i)}[mt];L&&(B.preventDefault(),L(B))},[J,s,St,Bt,W,o,i]),Mt=B=>{let je=1;return(B.metaKey||B.ctrlKey)&&(je=.1),B.shiftKey&&(je=10),je},_t=g.useMemo(()=>{const B=z==null?void 0:z(M.value);if(B!=null)return B;const je=M.value.toString();return je||void 0},[M.value,z]),lt=g.useCallback(()=>{let B=M.value;if(M.value==="")return;/^[eE]/.test(M.value.toString())?M.setValue(""):(M.valueAsNumber<o&&(B=o),M.valueAsNumber>i&&(B=i),M.cast(B))},[M,i,o]),Zt=g.useCallback(()=>{F(!1),n&&lt()},[n,F,lt]),Vt=g.useCallback(()=>{t&&requestAnimationFrame(()=>{var B;(B=I.current)==null||B.focus()})},[t]),Et=g.useCallback(B=>{B.preventDefault(),Q.up(),Vt()},[Vt,Q]),_n=g.useCallback(B=>{B.preventDefault(),Q.down(),Vt()},[Vt,Q]);mw(()=>I.current,"wheel",B=>{var ve;const mt=(((ve=I.current)==null?void 0:ve.ownerDocument)??document).activeElement===I.current;if(!p||!mt)return;B.preventDefault();const Wt=Mt(B)*s,L=Math.sign(B.deltaY);L===-1?St(Wt):L===1&&Bt(Wt)},{passive:!1});const er=g.useCallback((B={},je=null)=>{const mt=l||r&&M.isAtMax;return{...B,ref:Pt(je,ue),role:"button",tabIndex:-1,onPointerDown:ye(B.onPointerDown,Wt=>{Wt.button!==0||mt||Et(Wt)}),onPointerLeave:ye(B.onPointerLeave,Q.stop),onPointerUp:ye(B.onPointerUp,Q.stop),disabled:mt,"aria-disabled":cs(mt)}},[M.isAtMax,r,Et,Q.stop,l]),Pr=g.useCallback((B={},je=null)=>{const mt=l||r&&M.isAtMin;return{...B,ref:Pt(je,fe),role:"button",tabIndex:-1,onPointerDown:ye(B.onPointerDown,Wt=>{Wt.button!==0||mt||_n(Wt)}),onPointerLeave:ye(B.onPointerLeave,Q.stop),onPointerUp:ye(B.onPointerUp,Q.stop),disabled:mt,"aria-disabled":cs(mt)}},[M.isAtMin,r,_n,Q.stop,l]),Tr=g.useCallback((B={},je=null)=>({name:x,inputMode:h,type:"text",pattern:f,"aria-labelledby":w,"aria-label":b,"aria-describedby":m,id:y,disabled:l,role:"spinbutton",...B,readOnly:B.readOnly??a,"aria-readonly":B.readOnly??a,"aria-required":B.required??c,required:B.required??c,ref:Pt(I,je),value:$e(M.value),"aria-valuemin":o,"aria-valuemax":i,"aria-valuenow":Number.isNaN(M.valueAsNumber)?void 0:M.valueAsNumber,"aria-invalid":cs(d??M.isOutOfRange),"aria-valuetext":_t,autoComplete:"off",autoCorrect:"off",onChange:ye(B.onChange,Ue),onKeyDown:ye(B.onKeyDown,nt),onFocus:ye(B.onFocus,be,()=>F(!0)),onBlur:ye(B.onBlur,Y,Zt)}),[x,h,f,w,b,$e,m,y,l,c,a,d,M.value,M.valueAsNumber,M.isOutOfRange,o,i,_t,Ue,nt,be,Y,Zt]);return{value:$e(M.value),valueAsNumber:M.valueAsNumber,isFocused:se,isDisabled:l,isReadOnly:a,getIncrementButtonProps:er,getDecrementButtonProps:Pr,getInputProps:Tr,htmlProps:te}}const[Z8,af]=qe({name:"NumberInputStylesContext",errorMessage:`useNumberInputStyles returned is 'undefined'. Seems you forgot to wrap the components in "<NumberInput />" `}),[J8,Fy]=qe({name:"NumberInputContext",errorMessage:"useNumberInputContext: `context` is undefined. Seems you forgot to wrap number-input's components within <NumberInput />"}),Fo=H(function(t,n){const r=dn("NumberInput",t),o=tt(t),i=yy(o),{htmlProps:s,...a}=Q8(i),l=g.useMemo(()=>a,[a]);return u.jsx(J8,{value:l,children:u.jsx(Z8,{value:r,children:u.jsx(V.div,{...s,ref:n,className:ne("chakra-numberinput",t.className),__css:{position:"relative",zIndex:0,...r.root}})})})});Fo.displayName="NumberInput";const e9=H(function(t,n){const r=af();return u.jsx(V.div,{"aria-hidden":!0,ref:n,...t,__css:{display:"flex",flexDirection:"column",position:"absolute",top:"0",insetEnd:"0px",margin:"1px",height:"calc(100% - 2px)",zIndex:1,...r.stepperGroup}})});e9.displayName="NumberInputStepper";const Lo=H(function(t,n){const{getInputProps:r}=Fy(),o=r(t,n),i=af();return u.jsx(V.input,{...o,className:ne("chakra-numberinput__field",t.className),__css:{width:"100%",...i.field}})});Lo.displayName="NumberInputField";const H2=V("div",{baseStyle:{display:"flex",justifyContent:"center",alignItems:"center",flex:1,transitionProperty:"common",transitionDuration:"normal",userSelect:"none",cursor:"pointer",lineHeight:"normal"}}),t9=H(function(t,n){const r=af(),{getDecrementButtonProps:o}=Fy(),i=o(t,n);return u.jsx(H2,{...i,__css:r.stepper,children:t.children??u.jsx(H8,{})})});t9.displayName="NumberDecrementStepper";const n9=H(function(t,n){const{getIncrementButtonProps:r}=Fy(),o=r(t,n),i=af();return u.jsx(H2,{...o,__css:i.stepper,children:t.children??u.jsx(U8,{})})});n9.displayName="NumberIncrementStepper";function r9(e,t,n){return(e-t)*100/(n-t)}Bl({"0%":{strokeDasharray:"1, 400",strokeDashoffset:"0"},"50%":{strokeDasharray:"400, 400",strokeDashoffset:"-100"},"100%":{strokeDasharray:"400, 400",strokeDashoffset:"-260"}});Bl({"0%":{transform:"rotate(0deg)"},"100%":{transform:"rotate(360deg)"}});const o9=Bl({"0%":{left:"-40%"},"100%":{left:"100%"}}),i9=Bl({from:{backgroundPosition:"1rem 0"},to:{backgroundPosition:"0 0"}});function s9(e){const{value:t=0,min:n,max:r,valueText:o,getValueText:i,isIndeterminate:s,role:a="progressbar"}=e,l=r9(t,n,r);return{bind:{"data-indeterminate":s?"":void 0,"aria-valuemax":r,"aria-valuemin":n,"aria-valuenow":s?void 0:t,"aria-valuetext":(()=>{if(t!=null)return typeof i=="function"?i(t,l):o})(),role:a},percent:l,value:t}}const[a9,l9]=qe({name:"ProgressStylesContext",errorMessage:`useProgressStyles returned is 'undefined'. Seems you forgot to wrap the components in "<Progress />" `}),c9=H((e,t)=>{const{min:n,max:r,value:o,isIndeterminate:i,role:s,...a}=e,l=s9({value:o,min:n,max:r,isIndeterminate:i,role:s}),d={height:"100%",...l9().filledTrack};return u.jsx(V.div,{ref:t,style:{width:`${l.percent}%`,...a.style},...l.bind,...a,__css:d})}),U2=H((e,t)=>{var C;const{value:n,min:r=0,max:o=100,hasStripe:i,isAnimated:s,children:a,borderRadius:l,isIndeterminate:c,"aria-label":d,"aria-labelledby":f,"aria-valuetext":h,title:p,role:y,...v}=tt(e),S=dn("Progress",e),x=l??((C=S.track)==null?void 0:C.borderRadius),m={animation:`${i9} 1s linear infinite`},P={...!c&&i&&s&&m,...c&&{position:"absolute",willChange:"left",minWidth:"50%",animation:`${o9} 1s ease infinite normal none running`}},T={overflow:"hidden",position:"relative",...S.track};return u.jsx(V.div,{ref:t,borderRadius:x,__css:T,...v,children:u.jsxs(a9,{value:S,children:[u.jsx(c9,{"aria-label":d,"aria-labelledby":f,"aria-valuetext":h,min:r,max:o,value:n,isIndeterminate:c,css:P,borderRadius:x,title:p,role:y}),a]})})});U2.displayName="Progress";const G2=H(function(t,n){const{children:r,placeholder:o,className:i,...s}=t;return u.jsxs(V.select,{...s,ref:n,className:ne("chakra-select",i),children:[o&&u.jsx("option",{value:"",children:o}),r]})});G2.displayName="SelectField";const Ja=H((e,t)=>{var b;const n=dn("Select",e),{rootProps:r,placeholder:o,icon:i,color:s,height:a,h:l,minH:c,minHeight:d,iconColor:f,iconSize:h,...p}=tt(e),[y,v]=lj(p,n5),S=gy(v),x={width:"100%",height:"fit-content",position:"relative",color:s},m={paddingEnd:"2rem",...n.field,_focus:{zIndex:"unset",...(b=n.field)==null?void 0:b._focus}};return u.jsxs(V.div,{className:"chakra-select__wrapper",__css:x,...y,...r,children:[u.jsx(G2,{ref:t,height:l??a,minH:c??d,placeholder:o,...S,__css:m,children:e.children}),u.jsx(K2,{"data-disabled":he(S.disabled),...(f||s)&&{color:f||s},__css:n.icon,...h&&{fontSize:h},children:i})]})});Ja.displayName="Select";const u9=e=>u.jsx("svg",{viewBox:"0 0 24 24",...e,children:u.jsx("path",{fill:"currentColor",d:"M16.59 8.59L12 13.17 7.41 8.59 6 10l6 6 6-6z"})}),d9=V("div",{baseStyle:{position:"absolute",display:"inline-flex",alignItems:"center",justifyContent:"center",pointerEvents:"none",top:"50%",transform:"translateY(-50%)"}}),K2=e=>{const{children:t=u.jsx(u9,{}),...n}=e,r=g.cloneElement(t,{role:"presentation",className:"chakra-select__icon",focusable:!1,"aria-hidden":!0,style:{width:"1em",height:"1em",color:"currentColor"}});return u.jsx(d9,{...n,className:"chakra-select__icon-wrapper",children:g.isValidElement(t)?r:null})};K2.displayName="SelectIcon";const Y2=e=>u.jsx(V.div,{className:"chakra-stack__item",...e,__css:{display:"inline-block",flex:"0 0 auto",minWidth:0,...e.__css}});Y2.displayName="StackItem";function f9(e){const{spacing:t,direction:n}=e,r={column:{my:t,mx:0,borderLeftWidth:0,borderBottomWidth:"1px"},"column-reverse":{my:t,mx:0,borderLeftWidth:0,borderBottomWidth:"1px"},row:{mx:t,my:0,borderLeftWidth:"1px",borderBottomWidth:0},"row-reverse":{mx:t,my:0,borderLeftWidth:"1px",borderBottomWidth:0}};return{"&":Sg(n,o=>r[o])}}const Ly=H((e,t)=>{const{isInline:n,direction:r,align:o,justify:i,spacing:s="0.5rem",wrap:a,children:l,divider:c,className:d,shouldWrapChildren:f,...h}=e,p=n?"row":r??"column",y=g.useMemo(()=>f9({spacing:s,direction:p}),[s,p]),v=!!c,S=!f&&!v,x=g.useMemo(()=>{const b=lw(l);return S?b:b.map((w,P)=>{const T=typeof w.key<"u"?w.key:P,C=P+1===b.length,$=f?u.jsx(Y2,{children:w},T):w;if(!v)return $;const j=g.cloneElement(c,{__css:y}),N=C?null:j;return u.jsxs(g.Fragment,{children:[$,N]},T)})},[c,y,v,S,f,l]),m=ne("chakra-stack",d);return u.jsx(V.div,{ref:t,display:"flex",alignItems:o,justifyContent:i,flexDirection:p,flexWrap:a,gap:v?void 0:s,className:m,...h,children:x})});Ly.displayName="Stack";const Me=H((e,t)=>u.jsx(Ly,{align:"center",...e,direction:"row",ref:t}));Me.displayName="HStack";const Ie=H((e,t)=>u.jsx(Ly,{align:"center",...e,direction:"column",ref:t}));Ie.displayName="VStack";const[h9,By]=qe({name:"StatStylesContext",errorMessage:`useStatStyles returned is 'undefined'. Seems you forgot to wrap the components in "<Stat />" `}),hr=H(function(t,n){const r=dn("Stat",t),o={position:"relative",flex:"1 1 0%",...r.container},{className:i,children:s,...a}=tt(t);return u.jsx(h9,{value:r,children:u.jsx(V.div,{ref:n,...a,className:ne("chakra-stat",i),__css:o,children:u.jsx("dl",{children:s})})})});hr.displayName="Stat";const lf=H(function(t,n){return u.jsx(V.div,{...t,ref:n,role:"group",className:ne("chakra-stat__group",t.className),__css:{display:"flex",flexWrap:"wrap",justifyContent:"space-around",alignItems:"flex-start"}})});lf.displayName="StatGroup";const q2=H(function(t,n){const r=By();return u.jsx(V.dd,{ref:n,...t,className:ne("chakra-stat__help-text",t.className),__css:r.helpText})});q2.displayName="StatHelpText";const pr=H(function(t,n){const r=By();return u.jsx(V.dt,{ref:n,...t,className:ne("chakra-stat__label",t.className),__css:r.label})});pr.displayName="StatLabel";const mr=H(function(t,n){const r=By();return u.jsx(V.dd,{ref:n,...t,className:ne("chakra-stat__number",t.className),__css:{...r.number,fontFeatureSettings:"pnum",fontVariantNumeric:"proportional-nums"}})});mr.displayName="StatNumber";const bu=H(function(t,n){const r=dn("Switch",t),{spacing:o="0.5rem",children:i,...s}=tt(t),{getIndicatorProps:a,getInputProps:l,getCheckboxProps:c,getRootProps:d,getLabelProps:f}=OL(s),h=g.useMemo(()=>({display:"inline-block",position:"relative",verticalAlign:"middle",lineHeight:0,...r.container}),[r.container]),p=g.useMemo(()=>({display:"inline-flex",flexShrink:0,justifyContent:"flex-start",boxSizing:"content-box",cursor:"pointer",...r.track}),[r.track]),y=g.useMemo(()=>({userSelect:"none",marginStart:o,...r.label}),[o,r.label]);return u.jsxs(V.label,{...d(),className:ne("chakra-switch",t.className),__css:h,children:[u.jsx("input",{className:"chakra-switch__input",...l({},n)}),u.jsx(V.span,{...c(),className:"chakra-switch__track",__css:p,children:u.jsx(V.span,{__css:r.thumb,className:"chakra-switch__thumb",...a()})}),i&&u.jsx(V.span,{className:"chakra-switch__label",...f(),__css:y,children:i})]})});bu.displayName="Switch";const[p9,ec]=qe({name:"TableStylesContext",errorMessage:`useTableStyles returned is 'undefined'. Seems you forgot to wrap the components in "<Table />" `}),Ns=H((e,t)=>{const n=dn("Table",e),{className:r,layout:o,...i}=tt(e);return u.jsx(p9,{value:n,children:u.jsx(V.table,{ref:t,__css:{tableLayout:o,...n.table},className:ne("chakra-table",r),...i})})});Ns.displayName="Table";const $l=H((e,t)=>{const n=ec();return u.jsx(V.tbody,{...e,ref:t,__css:n.tbody})}),_e=H(({isNumeric:e,...t},n)=>{const r=ec();return u.jsx(V.td,{...t,ref:n,__css:r.td,"data-is-numeric":e})}),Ee=H(({isNumeric:e,...t},n)=>{const r=ec();return u.jsx(V.th,{...t,ref:n,__css:r.th,"data-is-numeric":e})}),Ml=H((e,t)=>{const n=ec();return u.jsx(V.thead,{...e,ref:t,__css:n.thead})}),gr=H((e,t)=>{const n=ec();return u.jsx(V.tr,{...e,ref:t,__css:n.tr})}),[m9,g9,y9,v9]=OC();function x9(e){const{defaultIndex:t,onChange:n,index:r,isManual:o,isLazy:i,lazyBehavior:s="unmount",orientation:a="horizontal",direction:l="ltr",...c}=e,[d,f]=g.useState(t??0),[h,p]=fj({defaultValue:t??0,value:r,onChange:n});g.useEffect(()=>{r!=null&&f(r)},[r]);const y=y9(),v=g.useId();return{id:`tabs-${e.id??v}`,selectedIndex:h,focusedIndex:d,setSelectedIndex:p,setFocusedIndex:f,isManual:o,isLazy:i,lazyBehavior:s,orientation:a,descendants:y,direction:l,htmlProps:c}}const[b9,cf]=qe({name:"TabsContext",errorMessage:"useTabsContext: `context` is undefined. Seems you forgot to wrap all tabs components within <Tabs />"});function S9(e){const{focusedIndex:t,orientation:n,direction:r}=cf(),o=g9(),i=g.useCallback(s=>{const a=()=>{var b;const m=o.nextEnabled(t);m&&((b=m.node)==null||b.focus())},l=()=>{var b;const m=o.prevEnabled(t);m&&((b=m.node)==null||b.focus())},c=()=>{var b;const m=o.firstEnabled();m&&((b=m.node)==null||b.focus())},d=()=>{var b;const m=o.lastEnabled();m&&((b=m.node)==null||b.focus())},f=n==="horizontal",h=n==="vertical",p=s.key,y=r==="ltr"?"ArrowLeft":"ArrowRight",v=r==="ltr"?"ArrowRight":"ArrowLeft",x={[y]:()=>f&&l(),[v]:()=>f&&a(),ArrowDown:()=>h&&a(),ArrowUp:()=>h&&l(),Home:c,End:d}[p];x&&(s.preventDefault(),x(s))},[o,t,n,r]);return{...e,role:"tablist","aria-orientation":n,onKeyDown:ye(e.onKeyDown,i)}}function w9(e){const{isDisabled:t=!1,isFocusable:n=!1,...r}=e,{setSelectedIndex:o,isManual:i,id:s,setFocusedIndex:a,selectedIndex:l}=cf(),{index:c,register:d}=v9({disabled:t&&!n}),f=c===l,h=()=>{o(c)},p=()=>{a(c),!i&&!(t&&n)&&o(c)};return{...j2({...r,ref:Pt(d,e.ref),isDisabled:t,isFocusable:n,onClick:ye(e.onClick,h)}),id:X2(s,c),role:"tab",tabIndex:f?0:-1,type:"button","aria-selected":f,"aria-controls":Q2(s,c),onFocus:t?void 0:ye(e.onFocus,p)}}const[k9,C9]=qe({});function P9(e){const t=cf(),{id:n,selectedIndex:r}=t,i=lw(e.children).map((s,a)=>g.createElement(k9,{key:s.key??a,value:{isSelected:a===r,id:Q2(n,a),tabId:X2(n,a),selectedIndex:r}},s));return{...e,children:i}}function T9(e){const{children:t,...n}=e,{isLazy:r,lazyBehavior:o}=cf(),{isSelected:i,id:s,tabId:a}=C9(),l=g.useRef(!1);i&&(l.current=!0);const c=hw({wasSelected:l.current,isSelected:i,enabled:r,mode:o});return{tabIndex:0,...n,children:c?t:null,role:"tabpanel","aria-labelledby":a,hidden:!i,id:s}}function X2(e,t){return`${e}--tab-${t}`}function Q2(e,t){return`${e}--tabpanel-${t}`}const[_9,uf]=qe({name:"TabsStylesContext",errorMessage:`useTabsStyles returned is 'undefined'. Seems you forgot to wrap the components in "<Tabs />" `}),Z2=H(function(t,n){const r=dn("Tabs",t),{children:o,className:i,...s}=tt(t),{htmlProps:a,descendants:l,...c}=x9(s),d=g.useMemo(()=>c,[c]),{isFitted:f,...h}=a,p={position:"relative",...r.root};return u.jsx(m9,{value:l,children:u.jsx(b9,{value:d,children:u.jsx(_9,{value:r,children:u.jsx(V.div,{className:ne("chakra-tabs",i),ref:n,...h,__css:p,children:o})})})})});Z2.displayName="Tabs";const Su=H(function(t,n){const r=uf(),o=w9({...t,ref:n}),i={outline:"0",display:"flex",alignItems:"center",justifyContent:"center",...r.tab};return u.jsx(V.button,{...o,className:ne("chakra-tabs__tab",t.className),__css:i})});Su.displayName="Tab";const J2=H(function(t,n){const r=S9({...t,ref:n}),o=uf(),i={display:"flex",...o.tablist};return u.jsx(V.div,{...r,className:ne("chakra-tabs__tablist",t.className),__css:i})});J2.displayName="TabList";const wu=H(function(t,n){const r=T9({...t,ref:n}),o=uf();return u.jsx(V.div,{outline:"0",...r,className:ne("chakra-tabs__tab-panel",t.className),__css:o.tabpanel})});wu.displayName="TabPanel";const eP=H(function(t,n){const r=P9(t),o=uf();return u.jsx(V.div,{...r,width:"100%",ref:n,className:ne("chakra-tabs__tab-panels",t.className),__css:o.tabpanels})});eP.displayName="TabPanels";const[E9,tP]=qe({name:"TagStylesContext",errorMessage:`useTagStyles returned is 'undefined'. Seems you forgot to wrap the components in "<Tag />" `}),_a=H((e,t)=>{const n=dn("Tag",e),r=tt(e),o={display:"inline-flex",verticalAlign:"top",alignItems:"center",maxWidth:"100%",...n.container};return u.jsx(E9,{value:n,children:u.jsx(V.span,{ref:t,...r,__css:o})})});_a.displayName="Tag";const Ea=H((e,t)=>{const n=tP();return u.jsx(V.span,{ref:t,noOfLines:1,...e,__css:n.label})});Ea.displayName="TagLabel";const j9=H((e,t)=>u.jsx(Jn,{ref:t,verticalAlign:"top",marginEnd:"0.5rem",...e}));j9.displayName="TagLeftIcon";const R9=H((e,t)=>u.jsx(Jn,{ref:t,verticalAlign:"top",marginStart:"0.5rem",...e}));R9.displayName="TagRightIcon";const nP=e=>u.jsx(Jn,{verticalAlign:"inherit",viewBox:"0 0 512 512",...e,children:u.jsx("path",{fill:"currentColor",d:"M289.94 256l95-95A24 24 0 00351 127l-95 95-95-95a24 24 0 00-34 34l95 95-95 95a24 24 0 1034 34l95-95 95 95a24 24 0 0034-34z"})});nP.displayName="TagCloseIcon";const ja=H((e,t)=>{const{isDisabled:n,children:r,...o}=e,s={display:"flex",alignItems:"center",justifyContent:"center",outline:"0",...tP().closeButton};return u.jsx(V.button,{ref:t,"aria-label":"close",...o,type:"button",disabled:n,__css:s,children:r||u.jsx(nP,{})})});ja.displayName="TagCloseButton";const A9=["h","minH","height","minHeight"],Sm=H((e,t)=>{const n=Zn("Textarea",e),{className:r,rows:o,...i}=tt(e),s=gy(i),a=o?bg(n,A9):n;return u.jsx(V.textarea,{ref:t,rows:o,...s,className:ne("chakra-textarea",r),__css:a})});Sm.displayName="Textarea";function $9(e,t){const n=e??"bottom",o={"top-start":{ltr:"top-left",rtl:"top-right"},"top-end":{ltr:"top-right",rtl:"top-left"},"bottom-start":{ltr:"bottom-left",rtl:"bottom-right"},"bottom-end":{ltr:"bottom-right",rtl:"bottom-left"}}[n];return(o==null?void 0:o[t])??n}function M9(e,t){const n=o=>({...t,...o,position:$9((o==null?void 0:o.position)??(t==null?void 0:t.position),e)}),r=o=>{const i=n(o),s=MC(i);return ur.notify(s,i)};return r.update=(o,i)=>{ur.update(o,n(i))},r.promise=(o,i)=>{const s=r({...i.loading,status:"loading",duration:null});o.then(a=>r.update(s,{status:"success",duration:5e3,...yn(i.success,a)})).catch(a=>r.update(s,{status:"error",duration:5e3,...yn(i.error,a)}))},r.closeAll=ur.closeAll,r.close=ur.close,r.isActive=ur.isActive,r}function tc(e){const{theme:t}=RC(),n=lL();return g.useMemo(()=>M9(t.direction,{...n,...e}),[e,t.direction,n])}const at=H(function(t,n){const r=Zn("Heading",t),{className:o,...i}=tt(t);return u.jsx(V.h2,{ref:n,className:ne("chakra-heading",t.className),...i,__css:r})});at.displayName="Heading";const ee=H(function(t,n){const r=Zn("Text",t),{className:o,align:i,decoration:s,casing:a,...l}=tt(t),c=cw({textAlign:t.align,textDecoration:t.decoration,textTransform:t.casing});return u.jsx(V.p,{ref:n,className:ne("chakra-text",t.className),...c,...l,__css:r})});ee.displayName="Text";const Ra=H(function(t,n){const{spacing:r="0.5rem",spacingX:o,spacingY:i,children:s,justify:a,direction:l,align:c,className:d,shouldWrapChildren:f,...h}=t,p=g.useMemo(()=>f?g.Children.map(s,(y,v)=>u.jsx(ns,{children:y},v)):s,[s,f]);return u.jsx(V.div,{ref:n,className:ne("chakra-wrap",d),...h,children:u.jsx(V.ul,{className:"chakra-wrap__list",__css:{display:"flex",flexWrap:"wrap",justifyContent:a,alignItems:c,flexDirection:l,listStyleType:"none",gap:r,columnGap:o,rowGap:i,padding:"0"},children:p})})});Ra.displayName="Wrap";const ns=H(function(t,n){const{className:r,...o}=t;return u.jsx(V.li,{ref:n,__css:{display:"flex",alignItems:"flex-start"},className:ne("chakra-wrap__listitem",r),...o})});ns.displayName="WrapItem";const Aa=Vs({d:"M0,12a1.5,1.5,0,0,0,1.5,1.5h8.75a.25.25,0,0,1,.25.25V22.5a1.5,1.5,0,0,0,3,0V13.75a.25.25,0,0,1,.25-.25H22.5a1.5,1.5,0,0,0,0-3H13.75a.25.25,0,0,1-.25-.25V1.5a1.5,1.5,0,0,0-3,0v8.75a.25.25,0,0,1-.25.25H1.5A1.5,1.5,0,0,0,0,12Z",displayName:"AddIcon"}),rP=Vs({displayName:"CheckCircleIcon",d:"M12,0A12,12,0,1,0,24,12,12.014,12.014,0,0,0,12,0Zm6.927,8.2-6.845,9.289a1.011,1.011,0,0,1-1.43.188L5.764,13.769a1,1,0,1,1,1.25-1.562l4.076,3.261,6.227-8.451A1,1,0,1,1,18.927,8.2Z"}),I9=Vs({displayName:"DeleteIcon",path:u.jsx("g",{fill:"currentColor",children:u.jsx("path",{d:"M19.452 7.5H4.547a.5.5 0 00-.5.545l1.287 14.136A2 2 0 007.326 24h9.347a2 2 0 001.992-1.819L19.95 8.045a.5.5 0 00-.129-.382.5.5 0 00-.369-.163zm-9.2 13a.75.75 0 01-1.5 0v-9a.75.75 0 011.5 0zm5 0a.75.75 0 01-1.5 0v-9a.75.75 0 011.5 0zM22 4h-4.75a.25.25 0 01-.25-.25V2.5A2.5 2.5 0 0014.5 0h-5A2.5 2.5 0 007 2.5v1.25a.25.25 0 01-.25.25H2a1 1 0 000 2h20a1 1 0 000-2zM9 3.75V2.5a.5.5 0 01.5-.5h5a.5.5 0 01.5.5v1.25a.25.25 0 01-.25.25h-5.5A.25.25 0 019 3.75z"})})}),O9=Vs({displayName:"RepeatIcon",path:u.jsxs("g",{fill:"currentColor",children:[u.jsx("path",{d:"M10.319,4.936a7.239,7.239,0,0,1,7.1,2.252,1.25,1.25,0,1,0,1.872-1.657A9.737,9.737,0,0,0,9.743,2.5,10.269,10.269,0,0,0,2.378,9.61a.249.249,0,0,1-.271.178l-1.033-.13A.491.491,0,0,0,.6,9.877a.5.5,0,0,0-.019.526l2.476,4.342a.5.5,0,0,0,.373.248.43.43,0,0,0,.062,0,.5.5,0,0,0,.359-.152l3.477-3.593a.5.5,0,0,0-.3-.844L5.15,10.172a.25.25,0,0,1-.2-.333A7.7,7.7,0,0,1,10.319,4.936Z"}),u.jsx("path",{d:"M23.406,14.1a.5.5,0,0,0,.015-.526l-2.5-4.329A.5.5,0,0,0,20.546,9a.489.489,0,0,0-.421.151l-3.456,3.614a.5.5,0,0,0,.3.842l1.848.221a.249.249,0,0,1,.183.117.253.253,0,0,1,.023.216,7.688,7.688,0,0,1-5.369,4.9,7.243,7.243,0,0,1-7.1-2.253,1.25,1.25,0,1,0-1.872,1.656,9.74,9.74,0,0,0,9.549,3.03,10.261,10.261,0,0,0,7.369-7.12.251.251,0,0,1,.27-.179l1.058.127a.422.422,0,0,0,.06,0A.5.5,0,0,0,23.406,14.1Z"})]})}),oP=Vs({displayName:"TimeIcon",path:u.jsxs("g",{fill:"currentColor",children:[u.jsx("path",{d:"M12,0A12,12,0,1,0,24,12,12.014,12.014,0,0,0,12,0Zm0,22A10,10,0,1,1,22,12,10.011,10.011,0,0,1,12,22Z"}),u.jsx("path",{d:"M17.134,15.81,12.5,11.561V6.5a1,1,0,0,0-2,0V12a1,1,0,0,0,.324.738l4.959,4.545a1.01,1.01,0,0,0,1.413-.061A1,1,0,0,0,17.134,15.81Z"})]})}),iP=Vs({d:"M11.983,0a12.206,12.206,0,0,0-8.51,3.653A11.8,11.8,0,0,0,0,12.207,11.779,11.779,0,0,0,11.8,24h.214A12.111,12.111,0,0,0,24,11.791h0A11.766,11.766,0,0,0,11.983,0ZM10.5,16.542a1.476,1.476,0,0,1,1.449-1.53h.027a1.527,1.527,0,0,1,1.523,1.47,1.475,1.475,0,0,1-1.449,1.53h-.027A1.529,1.529,0,0,1,10.5,16.542ZM11,12.5v-6a1,1,0,0,1,2,0v6a1,1,0,1,1-2,0Z",displayName:"WarningIcon"}),Lt="/api";async function Qt(e,t={}){const n=await fetch(e,t);if(!n.ok)throw new Error(`HTTP error! status: ${n.status}`);const r=await n.text();if(!r||r.trim()==="")throw new Error("Empty response from server");const o=JSON.parse(r);if(o.code!==0)throw new Error(o.message||"Unknown error");return o.data}async function z9(){return await Qt(`${Lt}/configs`)}async function sP(e){return await Qt(`${Lt}/configs/${e}`)}async function N9(e,t){return await Qt(`${Lt}/configs`,{method:"POST",headers:{"Content-Type":"application/json"},body:JSON.stringify({name:e,config:t})})}async function D9(e,t){return await Qt(`${Lt}/configs/${e}`,{method:"PUT",headers:{"Content-Type":"application/json"},body:JSON.stringify(t)})}async function F9(e){return await Qt(`${Lt}/configs/${e}`,{method:"DELETE"})}async function L9(e){const t=await fetch(`${Lt}/configs/${e}/validate`,{method:"POST"});if(!t.ok)throw new Error(`HTTP error! status: ${t.status}`);const n=await t.text();if(!n||n.trim()==="")throw new Error("Empty response from server");const r=JSON.parse(n);if(r.code!==0&&r.data&&r.data.errors){const o=new Error(r.message||"Validation failed");throw o.errors=r.data.errors,o}if(r.code!==0)throw new Error(r.message||"Unknown error");return r.data}async function B9(e){return await Qt(`${Lt}/configs/${e}/preview`)}async function aP(){return await Qt(`${Lt}/dictionary/hostnames`)}async function V9(e){return await Qt(`${Lt}/dictionary/hostnames`,{method:"PUT",headers:{"Content-Type":"application/json"},body:JSON.stringify({hostnames:e})})}async function lP(){return await Qt(`${Lt}/dictionary/hcas`)}async function W9(e){return await Qt(`${Lt}/dictionary/hcas`,{method:"PUT",headers:{"Content-Type":"application/json"},body:JSON.stringify({hcas:e})})}async function H9(e){return await Qt(`${Lt}/configs/${e}/precheck?wait=true`,{method:"POST"})}async function U9(e){return await Qt(`${Lt}/configs/${e}/run?wait=true`,{method:"POST"})}async function G9(e){return await Qt(`${Lt}/configs/${e}/probe`,{method:"POST"})}async function K9(e){return await Qt(`${Lt}/configs/${e}/collect?wait=true`,{method:"POST"})}async function Y9(e){return await Qt(`${Lt}/configs/${e}/report`)}function q9({configs:e,currentConfig:t,onSelect:n,onRefresh:r}){const{isOpen:o,onOpen:i,onClose:s}=Pd(),[a,l]=g.useState(""),[c,d]=g.useState(!1),f=tc(),h=async()=>{if(!a.trim()){f({title:"请输入配置文件名",status:"warning",duration:2e3});return}const y=a.endsWith(".yaml")?a:a+".yaml";try{d(!0),await N9(y,{server:{hostname:[],hca:[]},client:{hostname:[],hca:[]}}),f({title:"创建成功！",status:"success",duration:2e3}),s(),l(""),r(),n(y)}catch(v){f({title:"创建失败",description:v.message,status:"error",duration:3e3})}finally{d(!1)}},p=async(y,v,S)=>{if(v||!S){f({title:"无法删除",description:"默认配置文件不可删除",status:"warning",duration:2e3});return}if(window.confirm(`确定要删除配置 "${y}" 吗？`))try{await F9(y),f({title:"删除成功！",status:"success",duration:2e3}),r()}catch(x){f({title:"删除失败",description:x.message,status:"error",duration:3e3})}};return u.jsxs(ae,{w:"280px",bg:"gray.100",borderRight:"1px",borderColor:"gray.200",display:"flex",flexDirection:"column",children:[u.jsx(ae,{p:4,borderBottom:"1px",borderColor:"gray.200",children:u.jsxs(Me,{spacing:2,children:[u.jsx(vt,{leftIcon:u.jsx(Aa,{}),colorScheme:"blue",size:"sm",flex:1,onClick:i,children:"新建配置"}),u.jsx(om,{icon:u.jsx(O9,{}),size:"sm",onClick:r,"aria-label":"刷新列表"})]})}),u.jsx(Ie,{flex:1,overflowY:"auto",spacing:1,p:2,align:"stretch",children:e.map(y=>u.jsxs(Me,{p:3,bg:t===y.name?"blue.500":"white",color:t===y.name?"white":"gray.800",borderRadius:"md",cursor:"pointer",onClick:()=>n(y.name),_hover:{bg:t===y.name?"blue.600":"gray.50"},justify:"space-between",children:[u.jsxs(Me,{spacing:2,flex:1,children:[u.jsx(ee,{fontSize:"lg",children:y.is_default?"⭐":"📄"}),u.jsx(ee,{fontSize:"sm",fontWeight:"medium",isTruncated:!0,children:y.name})]}),y.is_deletable&&u.jsx(om,{icon:u.jsx(I9,{}),size:"xs",colorScheme:"red",variant:"ghost","aria-label":"删除",onClick:v=>{v.stopPropagation(),p(y.name,y.is_default,y.is_deletable)}})]},y.name))}),u.jsxs(Jd,{isOpen:o,onClose:s,children:[u.jsx(sf,{}),u.jsxs(tf,{children:[u.jsx(of,{children:"创建配置文件"}),u.jsx(rf,{}),u.jsx(nf,{children:u.jsxs(ot,{children:[u.jsx(it,{children:"文件名"}),u.jsx(Xo,{placeholder:"例如: my-config.yaml",value:a,onChange:y=>l(y.target.value)})]})}),u.jsxs(Dy,{children:[u.jsx(vt,{variant:"ghost",mr:3,onClick:s,children:"取消"}),u.jsx(vt,{colorScheme:"blue",onClick:h,isLoading:c,children:"创建"})]})]})]})]})}function X9({currentConfig:e,configData:t,originalData:n,loading:r,onSave:o,onCancel:i,onChange:s}){var X,Y,ce,J,z,M,W,q,U,se,F,ie;const[a,l]=g.useState(!1),[c,d]=g.useState(!1),[f,h]=g.useState(""),[p,y]=g.useState([]),[v,S]=g.useState([]),{isOpen:x,onOpen:m,onClose:b}=Pd(),w=tc();if(g.useEffect(()=>{(async()=>{try{const[O,ue]=await Promise.all([aP(),lP()]);y(O),S(ue)}catch(O){console.error("加载字典失败:",O)}})()},[]),!e)return u.jsxs(am,{flex:1,flexDirection:"column",color:"gray.500",children:[u.jsx(ee,{fontSize:"4xl",mb:4,children:"📝"}),u.jsx(ee,{children:"请在左侧选择或创建一个配置文件"})]});if(r||!t)return u.jsx(am,{flex:1,children:u.jsx(Yl,{size:"xl",color:"blue.500"})});const P=(I,O)=>{s({...t,[I]:O})},T=(I,O,ue)=>{s({...t,[I]:{...t[I],[O]:ue}})},C=(I,O,ue)=>{if(ue&&ue.trim()){const fe=t[I][O]||[];fe.includes(ue.trim())||s({...t,[I]:{...t[I],[O]:[...fe,ue.trim()]}})}},R=(I,O,ue)=>{const fe=prompt(`手动输入 ${ue}:`);fe&&fe.trim()&&C(I,O,fe.trim())},$=(I,O,ue)=>{const fe=t[I][O]||[];s({...t,[I]:{...t[I],[O]:fe.filter((Xe,He)=>He!==ue)}})},j=async()=>{try{l(!0),await D9(e,t),w({title:"保存成功！",status:"success",duration:2e3}),o()}catch(I){w({title:"保存失败",description:I.message,status:"error",duration:3e3})}finally{l(!1)}},N=async()=>{try{d(!0),await L9(e),w({title:"✓ 配置验证通过！",status:"success",duration:2e3})}catch(I){let O=I.message;I.errors&&I.errors.length>0&&(O=I.errors.join(`
 `)),w({title:"✗ 配置验证失败",description:O,status:"error",duration:5e3,isClosable:!0})}finally{d(!1)}},te=async()=>{try{const I=await B9(e);h(I.yaml),m()}catch(I){w({title:"预览失败",description:I.message,status:"error",duration:3e3})}};return u.jsxs(ae,{flex:1,display:"flex",flexDirection:"column",children:[u.jsxs(Me,{p:4,borderBottom:"1px",borderColor:"gray.200",justify:"space-between",bg:"white",shadow:"sm",children:[u.jsxs(Me,{spacing:2,children:[u.jsx(ee,{fontSize:"lg",fontWeight:"semibold",color:"gray.700",children:"📄"}),u.jsx(at,{size:"md",color:"gray.700",children:e})]}),u.jsxs(Me,{spacing:2,children:[u.jsx(vt,{size:"sm",colorScheme:"purple",variant:"outline",onClick:te,children:"预览"}),u.jsx(vt,{size:"sm",colorScheme:"blue",variant:"outline",onClick:N,isLoading:c,children:"验证"}),u.jsx(vt,{size:"sm",variant:"ghost",onClick:i,children:"取消"}),u.jsx(vt,{size:"sm",colorScheme:"green",onClick:j,isLoading:a,children:"保存"})]})]}),u.jsx(ae,{flex:1,overflowY:"auto",p:6,bg:"gray.50",children:u.jsxs(Ie,{spacing:6,align:"stretch",children:[u.jsxs(ae,{bg:"white",p:6,borderRadius:"lg",shadow:"sm",children:[u.jsx(at,{size:"md",mb:4,color:"blue.600",children:"基础配置"}),u.jsxs(ts,{columns:{base:1,md:2,lg:3},spacing:4,children:[u.jsxs(ot,{children:[u.jsx(it,{fontSize:"sm",fontWeight:"medium",children:"起始端口"}),u.jsx(Fo,{size:"sm",value:t.start_port||0,min:1,max:65535,onChange:(I,O)=>P("start_port",O),children:u.jsx(Lo,{})})]}),u.jsxs(ot,{children:[u.jsx(it,{fontSize:"sm",fontWeight:"medium",children:"流类型"}),u.jsxs(Ja,{size:"sm",value:t.stream_type||"",onChange:I=>P("stream_type",I.target.value),children:[u.jsx("option",{value:"fullmesh",children:"FullMesh"}),u.jsx("option",{value:"incast",children:"InCast"}),u.jsx("option",{value:"p2p",children:"P2P"})]})]}),u.jsxs(ot,{children:[u.jsx(it,{fontSize:"sm",fontWeight:"medium",children:"队列对数量"}),u.jsx(Fo,{size:"sm",value:t.qp_num||0,min:1,onChange:(I,O)=>P("qp_num",O),children:u.jsx(Lo,{})})]}),u.jsxs(ot,{children:[u.jsx(it,{fontSize:"sm",fontWeight:"medium",children:"消息大小 (字节)"}),u.jsx(Fo,{size:"sm",value:t.message_size_bytes||0,min:1,onChange:(I,O)=>P("message_size_bytes",O),children:u.jsx(Lo,{})})]}),u.jsxs(ot,{children:[u.jsx(it,{fontSize:"sm",fontWeight:"medium",children:"等待时间 (秒)"}),u.jsx(Fo,{size:"sm",value:t.waiting_time_seconds||0,min:0,onChange:(I,O)=>P("waiting_time_seconds",O),children:u.jsx(Lo,{})})]}),u.jsxs(ot,{children:[u.jsx(it,{fontSize:"sm",fontWeight:"medium",children:"速度 (Gbps)"}),u.jsx(Fo,{size:"sm",value:t.speed||0,min:0,onChange:(I,O)=>P("speed",O),children:u.jsx(Lo,{})})]}),u.jsxs(ot,{gridColumn:{md:"span 2"},children:[u.jsx(it,{fontSize:"sm",fontWeight:"medium",children:"输出目录"}),u.jsx(Xo,{size:"sm",value:t.output_base||"",onChange:I=>P("output_base",I.target.value)})]}),u.jsxs(ot,{display:"flex",alignItems:"center",pt:6,children:[u.jsx(bu,{isChecked:t.rdma_cm||!1,onChange:I=>P("rdma_cm",I.target.checked),colorScheme:"blue"}),u.jsx(it,{mb:0,ml:3,fontSize:"sm",children:"使用 RDMA CM"})]})]})]}),u.jsxs(ts,{columns:{base:1,md:2},spacing:6,children:[u.jsxs(ae,{bg:"white",p:6,borderRadius:"lg",shadow:"sm",children:[u.jsx(at,{size:"md",mb:4,color:"teal.600",children:"SSH 配置"}),u.jsxs(Ie,{spacing:4,align:"stretch",children:[u.jsxs(ot,{children:[u.jsx(it,{fontSize:"sm",fontWeight:"medium",children:"SSH 用户名"}),u.jsx(Xo,{size:"sm",value:((X=t.ssh)==null?void 0:X.user)||"",onChange:I=>T("ssh","user",I.target.value),placeholder:"root"})]}),u.jsxs(ot,{children:[u.jsx(it,{fontSize:"sm",fontWeight:"medium",children:"SSH 私钥路径"}),u.jsx(Xo,{size:"sm",value:((Y=t.ssh)==null?void 0:Y.private_key)||"",onChange:I=>T("ssh","private_key",I.target.value),placeholder:"~/.ssh/id_rsa"})]})]})]}),u.jsxs(ae,{bg:"white",p:6,borderRadius:"lg",shadow:"sm",children:[u.jsx(at,{size:"md",mb:4,color:"blue.600",children:"日志配置"}),u.jsxs(Ie,{spacing:4,align:"stretch",children:[u.jsxs(ot,{children:[u.jsx(it,{fontSize:"sm",fontWeight:"medium",children:"日志级别"}),u.jsxs(Ja,{size:"sm",value:((ce=t.logger)==null?void 0:ce.log_level)||"info",onChange:I=>T("logger","log_level",I.target.value),children:[u.jsx("option",{value:"debug",children:"Debug (详细)"}),u.jsx("option",{value:"info",children:"Info (信息)"}),u.jsx("option",{value:"warn",children:"Warn (警告)"}),u.jsx("option",{value:"error",children:"Error (错误)"})]})]}),u.jsxs(ot,{children:[u.jsx(it,{fontSize:"sm",fontWeight:"medium",children:"日志格式"}),u.jsxs(Ja,{size:"sm",value:((J=t.logger)==null?void 0:J.log_format)||"text",onChange:I=>T("logger","log_format",I.target.value),children:[u.jsx("option",{value:"text",children:"Text (文本格式)"}),u.jsx("option",{value:"json",children:"JSON (JSON格式)"})]})]})]})]})]}),u.jsxs(ts,{columns:{base:1,md:2},spacing:6,children:[u.jsxs(ae,{bg:"white",p:6,borderRadius:"lg",shadow:"sm",children:[u.jsx(at,{size:"md",mb:4,color:"green.600",children:"报告配置"}),u.jsxs(Ie,{spacing:4,align:"stretch",children:[u.jsxs(ot,{display:"flex",alignItems:"center",children:[u.jsx(bu,{isChecked:((z=t.report)==null?void 0:z.enable)||!1,onChange:I=>T("report","enable",I.target.checked),colorScheme:"green"}),u.jsx(it,{mb:0,ml:3,fontSize:"sm",children:"启用报告"})]}),u.jsxs(ot,{children:[u.jsx(it,{fontSize:"sm",fontWeight:"medium",children:"报告目录"}),u.jsx(Xo,{size:"sm",value:((M=t.report)==null?void 0:M.dir)||"",onChange:I=>T("report","dir",I.target.value)})]})]})]}),u.jsxs(ae,{bg:"white",p:6,borderRadius:"lg",shadow:"sm",children:[u.jsx(at,{size:"md",mb:4,color:"purple.600",children:"运行配置"}),u.jsxs(Ie,{spacing:4,align:"stretch",children:[u.jsxs(ot,{display:"flex",alignItems:"center",children:[u.jsx(bu,{isChecked:((W=t.run)==null?void 0:W.infinitely)||!1,onChange:I=>T("run","infinitely",I.target.checked),colorScheme:"purple"}),u.jsx(it,{mb:0,ml:3,fontSize:"sm",children:"无限运行"})]}),u.jsxs(ot,{children:[u.jsx(it,{fontSize:"sm",fontWeight:"medium",children:"运行时长 (秒)"}),u.jsx(Fo,{size:"sm",value:((q=t.run)==null?void 0:q.duration_seconds)||0,min:0,onChange:(I,O)=>T("run","duration_seconds",O),children:u.jsx(Lo,{})})]})]})]})]}),u.jsxs(ts,{columns:{base:1,md:2},spacing:6,children:[u.jsxs(ae,{bg:"white",p:6,borderRadius:"lg",shadow:"sm",children:[u.jsx(at,{size:"md",mb:4,color:"cyan.600",children:"服务器配置"}),u.jsxs(Ie,{spacing:4,align:"stretch",children:[u.jsxs(ot,{children:[u.jsx(it,{fontSize:"sm",fontWeight:"medium",children:"主机名"}),u.jsx(Ra,{spacing:2,mb:2,minH:"40px",p:2,bg:"gray.50",borderRadius:"md",children:(((U=t.server)==null?void 0:U.hostname)||[]).map((I,O)=>u.jsx(ns,{children:u.jsxs(_a,{size:"md",colorScheme:"cyan",variant:"subtle",children:[u.jsx(Ea,{children:I}),u.jsx(ja,{onClick:()=>$("server","hostname",O)})]})},O))}),u.jsxs(wa,{children:[u.jsx(ka,{as:vt,size:"xs",leftIcon:u.jsx(Aa,{}),colorScheme:"cyan",variant:"outline",children:"添加主机名"}),u.jsxs(Pa,{maxH:"300px",overflowY:"auto",children:[p.length>0?u.jsxs(u.Fragment,{children:[p.map((I,O)=>u.jsx(Rr,{onClick:()=>C("server","hostname",I),fontSize:"sm",children:I},O)),u.jsx(Ca,{})]}):null,u.jsx(Rr,{onClick:()=>R("server","hostname","主机名"),fontWeight:"bold",color:"blue.600",fontSize:"sm",children:"✏️ 手动输入..."})]})]})]}),u.jsxs(ot,{children:[u.jsx(it,{fontSize:"sm",fontWeight:"medium",children:"HCA 列表"}),u.jsx(Ra,{spacing:2,mb:2,minH:"40px",p:2,bg:"gray.50",borderRadius:"md",children:(((se=t.server)==null?void 0:se.hca)||[]).map((I,O)=>u.jsx(ns,{children:u.jsxs(_a,{size:"md",colorScheme:"teal",variant:"subtle",children:[u.jsx(Ea,{children:I}),u.jsx(ja,{onClick:()=>$("server","hca",O)})]})},O))}),u.jsxs(wa,{children:[u.jsx(ka,{as:vt,size:"xs",leftIcon:u.jsx(Aa,{}),colorScheme:"teal",variant:"outline",children:"添加 HCA"}),u.jsxs(Pa,{maxH:"300px",overflowY:"auto",children:[v.length>0?u.jsxs(u.Fragment,{children:[v.map((I,O)=>u.jsx(Rr,{onClick:()=>C("server","hca",I),fontSize:"sm",children:I},O)),u.jsx(Ca,{})]}):null,u.jsx(Rr,{onClick:()=>R("server","hca","HCA"),fontWeight:"bold",color:"blue.600",fontSize:"sm",children:"✏️ 手动输入..."})]})]})]})]})]}),u.jsxs(ae,{bg:"white",p:6,borderRadius:"lg",shadow:"sm",children:[u.jsx(at,{size:"md",mb:4,color:"orange.600",children:"客户端配置"}),u.jsxs(Ie,{spacing:4,align:"stretch",children:[u.jsxs(ot,{children:[u.jsx(it,{fontSize:"sm",fontWeight:"medium",children:"主机名"}),u.jsx(Ra,{spacing:2,mb:2,minH:"40px",p:2,bg:"gray.50",borderRadius:"md",children:(((F=t.client)==null?void 0:F.hostname)||[]).map((I,O)=>u.jsx(ns,{children:u.jsxs(_a,{size:"md",colorScheme:"orange",variant:"subtle",children:[u.jsx(Ea,{children:I}),u.jsx(ja,{onClick:()=>$("client","hostname",O)})]})},O))}),u.jsxs(wa,{children:[u.jsx(ka,{as:vt,size:"xs",leftIcon:u.jsx(Aa,{}),colorScheme:"orange",variant:"outline",children:"添加主机名"}),u.jsxs(Pa,{maxH:"300px",overflowY:"auto",children:[p.length>0?u.jsxs(u.Fragment,{children:[p.map((I,O)=>u.jsx(Rr,{onClick:()=>C("client","hostname",I),fontSize:"sm",children:I},O)),u.jsx(Ca,{})]}):null,u.jsx(Rr,{onClick:()=>R("client","hostname","主机名"),fontWeight:"bold",color:"blue.600",fontSize:"sm",children:"✏️ 手动输入..."})]})]})]}),u.jsxs(ot,{children:[u.jsx(it,{fontSize:"sm",fontWeight:"medium",children:"HCA 列表"}),u.jsx(Ra,{spacing:2,mb:2,minH:"40px",p:2,bg:"gray.50",borderRadius:"md",children:(((ie=t.client)==null?void 0:ie.hca)||[]).map((I,O)=>u.jsx(ns,{children:u.jsxs(_a,{size:"md",colorScheme:"pink",variant:"subtle",children:[u.jsx(Ea,{children:I}),u.jsx(ja,{onClick:()=>$("client","hca",O)})]})},O))}),u.jsxs(wa,{children:[u.jsx(ka,{as:vt,size:"xs",leftIcon:u.jsx(Aa,{}),colorScheme:"pink",variant:"outline",children:"添加 HCA"}),u.jsxs(Pa,{maxH:"300px",overflowY:"auto",children:[v.length>0?u.jsxs(u.Fragment,{children:[v.map((I,O)=>u.jsx(Rr,{onClick:()=>C("client","hca",I),fontSize:"sm",children:I},O)),u.jsx(Ca,{})]}):null,u.jsx(Rr,{onClick:()=>R("client","hca","HCA"),fontWeight:"bold",color:"blue.600",fontSize:"sm",children:"✏️ 手动输入..."})]})]})]})]})]})]})]})}),u.jsxs(Jd,{isOpen:x,onClose:b,size:"4xl",children:[u.jsx(sf,{}),u.jsxs(tf,{maxH:"90vh",children:[u.jsxs(of,{children:["配置预览 - ",e]}),u.jsx(rf,{}),u.jsx(nf,{pb:6,overflow:"auto",children:u.jsx(NC,{display:"block",whiteSpace:"pre",p:4,borderRadius:"md",bg:"gray.50",fontSize:"sm",fontFamily:"monospace",overflowX:"auto",children:f})})]})]})]})}function Q9({configs:e,currentConfig:t,configData:n,originalData:r,loading:o,onConfigSelect:i,onRefresh:s,onConfigCreate:a,onConfigDelete:l,onConfigUpdate:c,onConfigChange:d}){return u.jsxs(DC,{h:"100vh",overflow:"hidden",children:[u.jsx(q9,{configs:e,currentConfig:t,onSelect:i,onRefresh:s}),u.jsx(ae,{flex:"1",overflow:"auto",children:u.jsx(X9,{configData:n,originalData:r,currentConfig:t,loading:o,onSave:c,onCancel:()=>d(JSON.parse(JSON.stringify(r))),onChange:d})})]})}function Z9(){const[e,t]=g.useState(""),[n,r]=g.useState(""),[o,i]=g.useState(!1),s=tc(),a=async()=>{try{const[d,f]=await Promise.all([aP(),lP()]);t(d.join(`
 `)),r(f.join(`
 `))}catch(d){s({title:"加载字典失败",description:d.message,status:"error",duration:3e3})}};g.useEffect(()=>{a()},[]);const l=async()=>{try{i(!0);const d=e.split(`